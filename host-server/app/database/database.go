@@ -2,33 +2,40 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/wylu1037/polyglot-plugin-host-server/config"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/metrics"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// NewDatabase initializes and returns a new database connection
-func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
+// NewDatabase initializes and returns a new database connection. GORM logs
+// through the host logger, queries are traced and the connection pool is
+// reported in metrics.
+func NewDatabase(cfg *config.Config, log *slog.Logger, recorder *metrics.Metrics, tracer trace.TracerProvider) (*gorm.DB, error) {
 	// Build DSN from config
 	dsn := cfg.GetDatabaseDSN()
 
 	// Configure GORM logger based on config
-	gormLogger := logger.Default
+	gormLogger := logger.NewSlogLogger(log.With("component", "gorm"), logger.Config{
+		SlowThreshold:             200 * time.Millisecond,
+		IgnoreRecordNotFoundError: true,
+	})
 	switch cfg.Database.LogLevel {
 	case "silent":
-		gormLogger = logger.Default.LogMode(logger.Silent)
+		gormLogger = gormLogger.LogMode(logger.Silent)
 	case "error":
-		gormLogger = logger.Default.LogMode(logger.Error)
+		gormLogger = gormLogger.LogMode(logger.Error)
 	case "warn":
-		gormLogger = logger.Default.LogMode(logger.Warn)
-	case "info":
-		gormLogger = logger.Default.LogMode(logger.Info)
+		gormLogger = gormLogger.LogMode(logger.Warn)
 	default:
-		gormLogger = logger.Default.LogMode(logger.Info)
+		gormLogger = gormLogger.LogMode(logger.Info)
 	}
 
 	// Open database connection
@@ -41,6 +48,9 @@ func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := db.Use(tracing.NewGormPlugin(tracer)); err != nil {
+		return nil, fmt.Errorf("failed to register database tracing: %w", err)
+	}
 
 	// Get underlying SQL database
 	sqlDB, err := db.DB()
@@ -67,8 +77,12 @@ func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Printf("Successfully connected to database: %s@%s:%d/%s",
-		cfg.Database.User, cfg.Database.Host, cfg.Database.Port, cfg.Database.Database)
+	if err := recorder.Register(collectors.NewDBStatsCollector(sqlDB, cfg.Database.Database)); err != nil {
+		return nil, fmt.Errorf("failed to register database metrics: %w", err)
+	}
+
+	log.Info("Connected to database",
+		"user", cfg.Database.User, "host", cfg.Database.Host, "port", cfg.Database.Port, "database", cfg.Database.Database)
 
 	return db, nil
 }
@@ -2,7 +2,7 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
 	"gorm.io/gorm"
@@ -10,13 +10,13 @@ import (
 
 // AutoMigrate runs database schema migrations and seeds initial data.
 func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running database migrations...")
+	slog.Info("Running database migrations")
 
-	if err := db.AutoMigrate(&models.Plugin{}); err != nil {
+	if err := db.AutoMigrate(&models.Plugin{}, &models.PluginState{}, &models.PluginAlias{}, &models.Pipeline{}, &models.PluginPolicy{}); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	log.Println("Database migrations completed successfully.")
+	slog.Info("Database migrations completed")
 
 	// Seed initial data
 	if err := Seed(db); err != nil {
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// PipelineStep calls one plugin method as part of a pipeline. Params holds
+// the static parameters; Inputs maps parameter paths (e.g. "data.name") to
+// the value they take from the pipeline input ("input.name") or the result
+// of an earlier step ("steps.mask"). Encode lists parameters that are sent
+// JSON encoded, for methods that take JSON documents as strings.
+type PipelineStep struct {
+	Name      string            `json:"name"`                // 步骤名称，在流水线内唯一
+	Namespace string            `json:"namespace,omitempty"` // 插件命名空间，默认 default
+	Plugin    string            `json:"plugin"`              // 插件名称，调用其最新的激活版本
+	Method    string            `json:"method"`
+	Params    map[string]any    `json:"params,omitempty"`
+	Inputs    map[string]string `json:"inputs,omitempty"`   // 参数路径 -> 来源路径
+	Encode    map[string]string `json:"encode,omitempty"`   // 参数 -> 编码方式，目前仅支持 json
+	OnError   string            `json:"on_error,omitempty"` // abort（默认）或 continue
+	Retries   int               `json:"retries,omitempty"`  // 失败后的重试次数
+}
+
+type PipelineSteps []PipelineStep
+
+func (s *PipelineSteps) Scan(value any) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s PipelineSteps) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// Pipeline chains plugin method calls that the host runs server-side
+type Pipeline struct {
+	ID          uint          `gorm:"primarykey" json:"id"`
+	Name        string        `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+	Description string        `gorm:"type:text" json:"description"`
+	Steps       PipelineSteps `gorm:"type:jsonb;not null" json:"steps"`
+	CreatedAt   int64         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   int64         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Pipeline) TableName() string {
+	return "pipelines"
+}
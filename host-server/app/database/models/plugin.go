@@ -8,11 +8,12 @@ import (
 type PluginStatus string
 
 const (
-	PluginStatusActive     PluginStatus = "active"     // 激活
-	PluginStatusInactive   PluginStatus = "inactive"   // 未激活
-	PluginStatusDisabled   PluginStatus = "disabled"   // 已禁用
-	PluginStatusError      PluginStatus = "error"      // 错误状态
-	PluginStatusInstalling PluginStatus = "installing" // 安装中
+	PluginStatusActive        PluginStatus = "active"         // 激活
+	PluginStatusInactive      PluginStatus = "inactive"       // 未激活
+	PluginStatusDisabled      PluginStatus = "disabled"       // 已禁用
+	PluginStatusError         PluginStatus = "error"          // 错误状态
+	PluginStatusInstalling    PluginStatus = "installing"     // 安装中
+	PluginStatusLimitExceeded PluginStatus = "limit_exceeded" // 超出资源限制
 )
 
 type PluginType = string
@@ -60,8 +61,10 @@ type Plugin struct {
 	Type            PluginType     `gorm:"type:varchar(50);not null;index" json:"type"`
 	Description     string         `gorm:"type:text" json:"description"`
 	Status          PluginStatus   `gorm:"type:varchar(20);not null;default:'inactive';index" json:"status"`
+	StatusReason    string         `gorm:"type:text" json:"status_reason,omitempty"` // 最近一次进入当前状态的原因（如崩溃信息）
 	BinaryPath      string         `gorm:"type:varchar(500);not null" json:"binary_path"`
 	DownloadURL     string         `gorm:"type:varchar(500)" json:"download_url"`
+	Checksum        string         `gorm:"type:varchar(64)" json:"checksum"` // 二进制文件的 SHA-256（十六进制）
 	Protocol        PluginProtocol `gorm:"type:varchar(20);not null;default:'grpc'" json:"protocol"`
 	ProtocolVersion int            `gorm:"not null;default:1" json:"protocol_version"`
 	OS              string         `gorm:"type:varchar(20);not null;default:'linux'" json:"os"`   // 操作系统
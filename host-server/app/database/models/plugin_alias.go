@@ -0,0 +1,18 @@
+package models
+
+// PluginAlias pins a version alias of a plugin, such as "stable" or "lts", to
+// one of its versions. Calls by name that select the alias reach that
+// version until the alias is moved, so clients survive upgrades.
+type PluginAlias struct {
+	ID        uint   `gorm:"primarykey" json:"id"`
+	Namespace string `gorm:"type:varchar(100);not null;uniqueIndex:idx_plugin_alias" json:"namespace"`
+	Name      string `gorm:"type:varchar(100);not null;uniqueIndex:idx_plugin_alias" json:"name"`
+	Alias     string `gorm:"type:varchar(50);not null;uniqueIndex:idx_plugin_alias" json:"alias"`
+	Version   string `gorm:"type:varchar(50);not null" json:"version"`
+	CreatedAt int64  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt int64  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (PluginAlias) TableName() string {
+	return "plugin_aliases"
+}
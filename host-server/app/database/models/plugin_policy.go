@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Effect of a plugin policy
+const (
+	PolicyEffectAllow = "allow"
+	PolicyEffectDeny  = "deny"
+)
+
+// PolicyWildcard matches any principal, namespace, plugin or method
+const PolicyWildcard = "*"
+
+// PolicyConstraint is a condition on a call parameter, such as
+// {"param": "epsilon", "op": "gte", "value": 0.5}. Param is a dotted path
+// into the call's parameters.
+type PolicyConstraint struct {
+	Param string `json:"param"`
+	Op    string `json:"op"` // eq, ne, lt, lte, gt, gte, in or not_in
+	Value any    `json:"value"`
+}
+
+type PolicyConstraints []PolicyConstraint
+
+func (c *PolicyConstraints) Scan(value any) error {
+	if value == nil {
+		*c = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+func (c PolicyConstraints) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+// PluginPolicy allows or denies principals calling a method of a plugin.
+// Principal, Namespace, Plugin and Method are names or "*"; the policy
+// applies to a call they all match when each of its constraints holds.
+type PluginPolicy struct {
+	ID          uint              `gorm:"primarykey" json:"id"`
+	Name        string            `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+	Description string            `gorm:"type:text" json:"description"`
+	Effect      string            `gorm:"type:varchar(10);not null" json:"effect"` // allow or deny
+	Principal   string            `gorm:"type:varchar(100);not null" json:"principal"`
+	Namespace   string            `gorm:"type:varchar(100);not null;index:idx_plugin_policy_target" json:"namespace"`
+	Plugin      string            `gorm:"type:varchar(100);not null;index:idx_plugin_policy_target" json:"plugin"`
+	Method      string            `gorm:"type:varchar(100);not null" json:"method"`
+	Constraints PolicyConstraints `gorm:"type:jsonb" json:"constraints,omitempty"`
+	CreatedAt   int64             `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   int64             `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (PluginPolicy) TableName() string {
+	return "plugin_policies"
+}
@@ -0,0 +1,18 @@
+package models
+
+// PluginState is one key of the key-value state a plugin keeps through host
+// services. State is scoped by namespace and name rather than plugin ID, so
+// it survives upgrades to a new version of the plugin.
+type PluginState struct {
+	ID        uint   `gorm:"primarykey" json:"id"`
+	Namespace string `gorm:"type:varchar(100);not null;uniqueIndex:idx_plugin_state_key" json:"namespace"`
+	Plugin    string `gorm:"type:varchar(100);not null;uniqueIndex:idx_plugin_state_key" json:"plugin"`
+	Key       string `gorm:"type:varchar(255);not null;uniqueIndex:idx_plugin_state_key" json:"key"`
+	Value     []byte `gorm:"type:bytea" json:"value"`
+	CreatedAt int64  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt int64  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (PluginState) TableName() string {
+	return "plugin_states"
+}
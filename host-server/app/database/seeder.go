@@ -2,7 +2,7 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
 	"gorm.io/gorm"
@@ -31,10 +31,9 @@ func GetSeedData() *SeedData {
 				OS:              "darwin",
 				Arch:            "arm64",
 				Config: models.JSONMap{
-					"default_format": "csv",
-					"csv_delimiter":  ",",
-					"html_styled":    true,
-					"txt_format":     "key-value",
+					"csv_delimiter": ",",
+					"html_styled":   true,
+					"txt_format":    "key-value",
 				},
 				Metadata: models.JSONMap{
 					"author":      "Polyglot Team",
@@ -100,7 +99,7 @@ func GetSeedData() *SeedData {
 }
 
 func Seed(db *gorm.DB) error {
-	log.Println("🌱 Starting database seeding...")
+	slog.Info("Seeding database")
 
 	seedData := GetSeedData()
 
@@ -113,21 +112,21 @@ func Seed(db *gorm.DB) error {
 	})
 
 	if err != nil {
-		log.Printf("❌ Seeding failed: %v\n", err)
+		slog.Error("Seeding database failed", "error", err)
 		return err
 	}
 
-	log.Println("✅ Database seeding completed successfully")
+	slog.Info("Database seeding completed")
 	return nil
 }
 
 func seedPlugins(tx *gorm.DB, plugins []models.Plugin) error {
 	if len(plugins) == 0 {
-		log.Println("⏭️  No plugins to seed, skipping...")
+		slog.Info("No plugins to seed")
 		return nil
 	}
 
-	log.Printf("📦 Seeding %d plugin(s)...\n", len(plugins))
+	slog.Info("Seeding plugins", "count", len(plugins))
 
 	for _, plugin := range plugins {
 		result := tx.Clauses(clause.OnConflict{
@@ -148,7 +147,7 @@ func seedPlugins(tx *gorm.DB, plugins []models.Plugin) error {
 		}
 
 		if result.RowsAffected > 0 {
-			log.Printf("✓ Plugin upserted: %s@%s\n", plugin.Name, plugin.Version)
+			slog.Info("Plugin upserted", "plugin", plugin.Name, "version", plugin.Version)
 		}
 	}
 
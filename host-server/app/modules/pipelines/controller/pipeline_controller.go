@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	_ "github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/request"
+	_ "github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/response"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/service"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
+)
+
+type PipelineController interface {
+	CreatePipeline(c echo.Context) error
+	ListPipelines(c echo.Context) error
+	GetPipeline(c echo.Context) error
+	UpdatePipeline(c echo.Context) error
+	DeletePipeline(c echo.Context) error
+	RunPipeline(c echo.Context) error
+}
+
+type pipelineController struct {
+	service service.PipelineService
+}
+
+func NewPipelineController(service service.PipelineService) PipelineController {
+	return &pipelineController{
+		service: service,
+	}
+}
+
+// CreatePipeline godoc
+// @Summary      Create a pipeline
+// @Description  Create a pipeline of plugin method calls. Step inputs map parameters to the pipeline input (input.<path>) or to the result of an earlier step (steps.<name>.<path>); they are checked when the pipeline is saved
+// @Tags         Pipelines
+// @Accept       json
+// @Produce      json
+// @Param        request body request.PipelineRequest true "Pipeline definition"
+// @Success      201 {object} models.Pipeline
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      409 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/pipelines [post]
+func (ctrl *pipelineController) CreatePipeline(c echo.Context) error {
+	var req request.PipelineRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request body format").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	pipeline, err := ctrl.service.CreatePipeline(&req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, pipeline)
+}
+
+// ListPipelines godoc
+// @Summary      List pipelines
+// @Description  Get all pipelines, ordered by name
+// @Tags         Pipelines
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} models.Pipeline
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/pipelines [get]
+func (ctrl *pipelineController) ListPipelines(c echo.Context) error {
+	pipelines, err := ctrl.service.ListPipelines()
+	if err != nil {
+		return errors.ErrInternalServer.WithDetails("Failed to list pipelines").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, pipelines)
+}
+
+// GetPipeline godoc
+// @Summary      Get pipeline details
+// @Description  Get a pipeline and its steps by ID
+// @Tags         Pipelines
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Pipeline ID" minimum(1)
+// @Success      200 {object} models.Pipeline
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/pipelines/{id} [get]
+func (ctrl *pipelineController) GetPipeline(c echo.Context) error {
+	var req request.PipelineIDRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid pipeline ID").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	pipeline, err := ctrl.service.GetPipeline(req.ID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrPipelineNotFound.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, pipeline)
+}
+
+// UpdatePipeline godoc
+// @Summary      Update a pipeline
+// @Description  Replace the name, description and steps of a pipeline
+// @Tags         Pipelines
+// @Accept       json
+// @Produce      json
+// @Param        id      path int                     true "Pipeline ID" minimum(1)
+// @Param        request body request.PipelineRequest true "Pipeline definition"
+// @Success      200 {object} models.Pipeline
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      409 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/pipelines/{id} [put]
+func (ctrl *pipelineController) UpdatePipeline(c echo.Context) error {
+	var id request.PipelineIDRequest
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, &id); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid pipeline ID").WithInternal(err)
+	}
+
+	if err := c.Validate(&id); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	var req request.PipelineRequest
+	if err := (&echo.DefaultBinder{}).BindBody(c, &req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request body format").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	pipeline, err := ctrl.service.UpdatePipeline(id.ID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, pipeline)
+}
+
+// DeletePipeline godoc
+// @Summary      Delete a pipeline
+// @Description  Remove a pipeline
+// @Tags         Pipelines
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Pipeline ID" minimum(1)
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/pipelines/{id} [delete]
+func (ctrl *pipelineController) DeletePipeline(c echo.Context) error {
+	var req request.PipelineIDRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid pipeline ID").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	if err := ctrl.service.DeletePipeline(req.ID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Pipeline deleted successfully",
+	})
+}
+
+// RunPipeline godoc
+// @Summary      Run a pipeline
+// @Description  Run the steps of a pipeline in order inside the host. A failed step aborts the run unless its on_error is continue, in which case only the steps reading its result are skipped; steps with retries are retried first. Every step reports its own outcome. With dry_run, no plugin is called: every step is resolved to an active plugin and its parameters are mapped and validated as far as they are known before the run
+// @Tags         Pipelines
+// @Accept       json
+// @Produce      json
+// @Param        id      path int                        true "Pipeline ID" minimum(1)
+// @Param        request body request.RunPipelineRequest true "Pipeline input"
+// @Success      200 {object} response.PipelineRunResponse
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/pipelines/{id}/run [post]
+func (ctrl *pipelineController) RunPipeline(c echo.Context) error {
+	var req request.RunPipelineRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request format").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	result, err := ctrl.service.RunPipeline(c.Request().Context(), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
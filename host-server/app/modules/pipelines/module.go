@@ -0,0 +1,15 @@
+package pipelines
+
+import (
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/controller"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/repository"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/service"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Options(
+	fx.Provide(NewRoute),
+	fx.Provide(repository.NewPipelineRepository),
+	fx.Provide(service.NewPipelineService),
+	fx.Provide(controller.NewPipelineController),
+)
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"gorm.io/gorm"
+)
+
+type PipelineRepository interface {
+	Create(pipeline *models.Pipeline) error
+	FindByID(id uint) (*models.Pipeline, error)
+	FindByName(name string) (*models.Pipeline, error)
+	FindAll() ([]*models.Pipeline, error)
+	Update(pipeline *models.Pipeline) error
+	Delete(id uint) error
+}
+
+type pipelineRepository struct {
+	db *gorm.DB
+}
+
+func NewPipelineRepository(db *gorm.DB) PipelineRepository {
+	return &pipelineRepository{
+		db: db,
+	}
+}
+
+func (r *pipelineRepository) Create(pipeline *models.Pipeline) error {
+	if err := r.db.Create(pipeline).Error; err != nil {
+		return fmt.Errorf("failed to create pipeline: %w", err)
+	}
+	return nil
+}
+
+func (r *pipelineRepository) FindByID(id uint) (*models.Pipeline, error) {
+	var pipeline models.Pipeline
+	if err := r.db.First(&pipeline, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("pipeline not found")
+		}
+		return nil, fmt.Errorf("failed to find pipeline: %w", err)
+	}
+	return &pipeline, nil
+}
+
+func (r *pipelineRepository) FindByName(name string) (*models.Pipeline, error) {
+	var pipeline models.Pipeline
+	if err := r.db.Where("name = ?", name).First(&pipeline).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Not found is not an error
+		}
+		return nil, fmt.Errorf("failed to find pipeline: %w", err)
+	}
+	return &pipeline, nil
+}
+
+func (r *pipelineRepository) FindAll() ([]*models.Pipeline, error) {
+	var pipelines []*models.Pipeline
+	if err := r.db.Order("name").Find(&pipelines).Error; err != nil {
+		return nil, fmt.Errorf("failed to find pipelines: %w", err)
+	}
+	return pipelines, nil
+}
+
+func (r *pipelineRepository) Update(pipeline *models.Pipeline) error {
+	if err := r.db.Save(pipeline).Error; err != nil {
+		return fmt.Errorf("failed to update pipeline: %w", err)
+	}
+	return nil
+}
+
+func (r *pipelineRepository) Delete(id uint) error {
+	if err := r.db.Delete(&models.Pipeline{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete pipeline: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package request
+
+import "github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+
+type PipelineRequest struct {
+	Name        string                `json:"name" validate:"required,max=100"`
+	Description string                `json:"description"`
+	Steps       []models.PipelineStep `json:"steps" validate:"required,min=1"`
+}
+
+type PipelineIDRequest struct {
+	ID uint `param:"id" validate:"required,gt=0"`
+}
+
+type RunPipelineRequest struct {
+	ID     uint           `param:"id" validate:"required,gt=0"` // Pipeline ID from path parameter
+	Input  map[string]any `json:"input"`                        // Pipeline input, read by step inputs as input.<path>
+	DryRun bool           `json:"dry_run"`                      // Resolve and validate every step without calling plugins
+}
@@ -0,0 +1,34 @@
+package response
+
+// PipelineRunResponse is the outcome of a pipeline run. Result is the result
+// of the last step; every step reports its own outcome.
+type PipelineRunResponse struct {
+	PipelineID uint                 `json:"pipeline_id"`
+	DryRun     bool                 `json:"dry_run"`
+	Success    bool                 `json:"success"`
+	Result     any                  `json:"result,omitempty"`
+	Steps      []PipelineStepResult `json:"steps"`
+}
+
+// PipelineStepResult is the outcome of one step of a run
+type PipelineStepResult struct {
+	Name       string             `json:"name"`
+	PluginID   uint               `json:"plugin_id,omitempty"`
+	Method     string             `json:"method"`
+	Status     string             `json:"status"` // succeeded, failed, skipped, or planned in a dry run
+	Attempts   int                `json:"attempts,omitempty"`
+	DurationMs int64              `json:"duration_ms"`
+	Params     map[string]any     `json:"params,omitempty"`   // Params sent; in a dry run those known before the run
+	Deferred   []string           `json:"deferred,omitempty"` // Dry run: params that take the result of an earlier step
+	Result     any                `json:"result,omitempty"`
+	Reason     string             `json:"reason,omitempty"` // Why the step was skipped
+	Error      *PipelineStepError `json:"error,omitempty"`
+}
+
+// PipelineStepError describes why a step failed, using the error codes of
+// the plugin call endpoints
+type PipelineStepError struct {
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+	Details   string `json:"details,omitempty"`
+}
@@ -0,0 +1,38 @@
+package pipelines
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/controller"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
+)
+
+type Route struct {
+	app        *echo.Echo
+	controller controller.PipelineController
+}
+
+func NewRoute(
+	app *echo.Echo,
+	controller controller.PipelineController,
+) *Route {
+	return &Route{
+		app:        app,
+		controller: controller,
+	}
+}
+
+func (r *Route) Register() {
+	api := r.app.Group("/api/pipelines")
+
+	// Pipelines may call plugins of any namespace
+	viewer := auth.Require(auth.RoleViewer, auth.Everywhere)
+	operator := auth.Require(auth.RoleOperator, auth.Everywhere)
+	admin := auth.Require(auth.RoleAdmin, auth.Everywhere)
+
+	api.POST("", r.controller.CreatePipeline, admin)
+	api.GET("", r.controller.ListPipelines, viewer)
+	api.GET("/:id", r.controller.GetPipeline, viewer)
+	api.PUT("/:id", r.controller.UpdatePipeline, admin)
+	api.DELETE("/:id", r.controller.DeletePipeline, admin)
+	api.POST("/:id/run", r.controller.RunPipeline, operator)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/repository"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/request"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines/response"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/plugin"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/policy"
+)
+
+type PipelineService interface {
+	CreatePipeline(req *request.PipelineRequest) (*models.Pipeline, error)
+	ListPipelines() ([]*models.Pipeline, error)
+	GetPipeline(id uint) (*models.Pipeline, error)
+	UpdatePipeline(id uint, req *request.PipelineRequest) (*models.Pipeline, error)
+	DeletePipeline(id uint) error
+	RunPipeline(ctx context.Context, req *request.RunPipelineRequest) (*response.PipelineRunResponse, error)
+}
+
+type pipelineService struct {
+	repo   repository.PipelineRepository
+	engine *plugin.PipelineEngine
+}
+
+func NewPipelineService(repo repository.PipelineRepository, engine *plugin.PipelineEngine) PipelineService {
+	return &pipelineService{
+		repo:   repo,
+		engine: engine,
+	}
+}
+
+func (s *pipelineService) CreatePipeline(req *request.PipelineRequest) (*models.Pipeline, error) {
+	if err := plugin.ValidatePipeline(req.Steps); err != nil {
+		return nil, errors.ErrPipelineInvalid.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	existing, err := s.repo.FindByName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.ErrPipelineAlreadyExists.WithDetails("A pipeline named '" + req.Name + "' already exists")
+	}
+
+	pipeline := &models.Pipeline{
+		Name:        req.Name,
+		Description: req.Description,
+		Steps:       req.Steps,
+	}
+	if err := s.repo.Create(pipeline); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+func (s *pipelineService) ListPipelines() ([]*models.Pipeline, error) {
+	return s.repo.FindAll()
+}
+
+func (s *pipelineService) GetPipeline(id uint) (*models.Pipeline, error) {
+	pipeline, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.ErrPipelineNotFound.WithInternal(err)
+	}
+	return pipeline, nil
+}
+
+func (s *pipelineService) UpdatePipeline(id uint, req *request.PipelineRequest) (*models.Pipeline, error) {
+	pipeline, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.ErrPipelineNotFound.WithInternal(err)
+	}
+
+	if err := plugin.ValidatePipeline(req.Steps); err != nil {
+		return nil, errors.ErrPipelineInvalid.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	if req.Name != pipeline.Name {
+		existing, err := s.repo.FindByName(req.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, errors.ErrPipelineAlreadyExists.WithDetails("A pipeline named '" + req.Name + "' already exists")
+		}
+	}
+
+	pipeline.Name = req.Name
+	pipeline.Description = req.Description
+	pipeline.Steps = req.Steps
+	if err := s.repo.Update(pipeline); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+func (s *pipelineService) DeletePipeline(id uint) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return errors.ErrPipelineNotFound.WithInternal(err)
+	}
+	return s.repo.Delete(id)
+}
+
+// RunPipeline runs a stored pipeline with the request's input. Failed steps
+// are reported in the response; only a pipeline that cannot be loaded fails
+// the request.
+func (s *pipelineService) RunPipeline(ctx context.Context, req *request.RunPipelineRequest) (*response.PipelineRunResponse, error) {
+	pipeline, err := s.repo.FindByID(req.ID)
+	if err != nil {
+		return nil, errors.ErrPipelineNotFound.WithInternal(err)
+	}
+
+	// The definition may predate a stricter check
+	if err := plugin.ValidatePipeline(pipeline.Steps); err != nil {
+		return nil, errors.ErrPipelineInvalid.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	input := req.Input
+	if input == nil {
+		input = map[string]any{}
+	}
+
+	run := s.engine.Run(ctx, pipeline.Steps, input, req.DryRun)
+
+	resp := &response.PipelineRunResponse{
+		PipelineID: pipeline.ID,
+		DryRun:     req.DryRun,
+		Success:    run.Success,
+		Result:     run.Result,
+		Steps:      make([]response.PipelineStepResult, len(run.Steps)),
+	}
+	for i, outcome := range run.Steps {
+		resp.Steps[i] = response.PipelineStepResult{
+			Name:       outcome.Name,
+			PluginID:   outcome.PluginID,
+			Method:     outcome.Method,
+			Status:     outcome.Status,
+			Attempts:   outcome.Attempts,
+			DurationMs: outcome.Duration.Milliseconds(),
+			Params:     outcome.Params,
+			Deferred:   outcome.Deferred,
+			Result:     outcome.Result,
+			Reason:     outcome.Reason,
+			Error:      stepError(outcome),
+		}
+	}
+	return resp, nil
+}
+
+// stepError maps a step failure to the error code a single plugin call
+// failing the same way would get
+func stepError(outcome plugin.StepOutcome) *response.PipelineStepError {
+	if outcome.Err == nil {
+		return nil
+	}
+
+	kind := errors.ErrPluginCallFailed
+	switch {
+	case outcome.CtxErr == context.DeadlineExceeded:
+		kind = errors.ErrPluginCallTimeout
+	case outcome.CtxErr == context.Canceled:
+		kind = errors.ErrPluginCallCanceled
+	case plugin.IsInvalidParams(outcome.Err):
+		kind = errors.ErrPluginInvalidParams
+	case plugin.IsPluginUnavailable(outcome.Err):
+		kind = errors.ErrPluginNotFound
+	case policy.IsDenied(outcome.Err):
+		kind = errors.ErrPluginCallDenied
+	}
+
+	return &response.PipelineStepError{
+		ErrorCode: kind.ErrorCode,
+		Message:   kind.Message,
+		Details:   outcome.Err.Error(),
+	}
+}
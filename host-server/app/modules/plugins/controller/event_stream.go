@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/plugin"
+)
+
+// eventStreamKeepAlive is how often an idle event stream sends a comment, so
+// proxies do not close it
+const eventStreamKeepAlive = 15 * time.Second
+
+// streamEvents sends log entries as server-sent events, recent ones first,
+// until the client disconnects
+func streamEvents(c echo.Context, recent []plugin.LogEntry, entries <-chan plugin.LogEntry) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	for _, entry := range recent {
+		if err := writeEvent(res, entry); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case entry := <-entries:
+			if err := writeEvent(res, entry); err != nil {
+				return nil
+			}
+		case <-keepAlive.C:
+			if _, err := res.Write([]byte(": keep-alive\n\n")); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+func writeEvent(res *echo.Response, entry plugin.LogEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = res.Write(append(append([]byte("data: "), data...), '\n', '\n'))
+	return err
+}
@@ -2,11 +2,15 @@ package controller
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
 	_ "github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/request"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/response"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/service"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
 	"github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
 )
 
@@ -17,7 +21,19 @@ type PluginController interface {
 	ActivatePlugin(c echo.Context) error
 	DeactivatePlugin(c echo.Context) error
 	UninstallPlugin(c echo.Context) error
+	UpdatePluginConfig(c echo.Context) error
+	UpgradePlugin(c echo.Context) error
 	CallPlugin(c echo.Context) error
+	CallPluginByName(c echo.Context) error
+	ListPluginAliases(c echo.Context) error
+	SetPluginAlias(c echo.Context) error
+	DeletePluginAlias(c echo.Context) error
+	ScanPlugins(c echo.Context) error
+	CallPluginMethod(c echo.Context) error
+	CallPluginBatch(c echo.Context) error
+	StreamPluginMethod(c echo.Context) error
+	OpenAPIDocument(c echo.Context) error
+	GetPluginLogs(c echo.Context) error
 }
 
 type pluginController struct {
@@ -32,14 +48,19 @@ func NewPluginController(service service.PluginService) PluginController {
 
 // InstallPlugin godoc
 // @Summary      Install a new plugin
-// @Description  Install a plugin from a download URL
+// @Description  Install a plugin from a download URL. The binary must match the given SHA-256 checksum and, for namespaces with trusted publisher keys, carry a valid ed25519 signature. Dependencies declared in metadata.dependencies must be well-formed and must not form a cycle; they need not be installed until activation
 // @Tags         Plugins
 // @Accept       json
 // @Produce      json
 // @Param        request body request.InstallPluginRequest true "Plugin installation request"
 // @Success      201 {object} models.Plugin
 // @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      422 {object} errors.AppError
 // @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /api/plugins/install [post]
 func (ctrl *pluginController) InstallPlugin(c echo.Context) error {
 	var req request.InstallPluginRequest
@@ -51,8 +72,15 @@ func (ctrl *pluginController) InstallPlugin(c echo.Context) error {
 		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
 	}
 
+	if err := auth.Authorize(c.Request().Context(), req.Namespace, auth.RoleAdmin); err != nil {
+		return err
+	}
+
 	plugin, err := ctrl.service.InstallPlugin(&req)
 	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
 		return errors.ErrPluginInstallFailed.WithInternal(err)
 	}
 
@@ -61,18 +89,22 @@ func (ctrl *pluginController) InstallPlugin(c echo.Context) error {
 
 // ListPlugins godoc
 // @Summary      List all plugins
-// @Description  Get a list of all installed plugins with optional filters
+// @Description  Get a list of all installed plugins with optional filters. Active plugins also report whether they are running or idle. Only plugins of namespaces the caller may view are listed
 // @Tags         Plugins
 // @Accept       json
 // @Produce      json
 // @Param        namespace query string false "Filter by namespace"
 // @Param        type      query string false "Filter by plugin type"
-// @Param        status    query string false "Filter by plugin status" Enums(active, inactive, disabled, error, installing)
+// @Param        status    query string false "Filter by plugin status" Enums(active, inactive, disabled, error, installing, limit_exceeded)
 // @Param        os        query string false "Filter by operating system" Enums(linux, darwin, windows)
 // @Param        arch      query string false "Filter by architecture" Enums(amd64, arm64)
-// @Success      200 {array} models.Plugin
+// @Success      200 {array} response.PluginInfo
 // @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
 // @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /api/plugins [get]
 func (ctrl *pluginController) ListPlugins(c echo.Context) error {
 	var req request.ListPluginsRequest
@@ -89,19 +121,28 @@ func (ctrl *pluginController) ListPlugins(c echo.Context) error {
 		return errors.ErrInternalServer.WithDetails("Failed to list plugins").WithInternal(err)
 	}
 
+	principal := auth.PrincipalFrom(c.Request().Context())
+	plugins = lo.Filter(plugins, func(plugin *response.PluginInfo, _ int) bool {
+		return principal == nil || principal.Allows(plugin.Namespace, auth.RoleViewer)
+	})
+
 	return c.JSON(http.StatusOK, plugins)
 }
 
 // GetPlugin godoc
 // @Summary      Get plugin details
-// @Description  Get detailed information about a specific plugin by ID
+// @Description  Get detailed information about a specific plugin by ID. Active plugins also report whether they are running or idle, and running plugins the state of their process pool
 // @Tags         Plugins
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "Plugin ID" minimum(1)
-// @Success      200 {object} models.Plugin
+// @Success      200 {object} response.PluginInfo
 // @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
 // @Failure      404 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /api/plugins/{id} [get]
 func (ctrl *pluginController) GetPlugin(c echo.Context) error {
 	var req request.PluginIDRequest
@@ -123,14 +164,19 @@ func (ctrl *pluginController) GetPlugin(c echo.Context) error {
 
 // ActivatePlugin godoc
 // @Summary      Activate a plugin
-// @Description  Activate a previously installed plugin
+// @Description  Activate a previously installed plugin. Plugin dependencies declared in its metadata are resolved against the installed plugins and inactive ones are activated first; activation fails with PLUGIN_DEPENDENCY_UNMET, listing every unmet dependency, when some are not installed
 // @Tags         Plugins
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "Plugin ID" minimum(1)
 // @Success      200 {object} map[string]string
 // @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      422 {object} errors.AppError
 // @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /api/plugins/{id}/activate [post]
 func (ctrl *pluginController) ActivatePlugin(c echo.Context) error {
 	var req request.PluginIDRequest
@@ -142,7 +188,10 @@ func (ctrl *pluginController) ActivatePlugin(c echo.Context) error {
 		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
 	}
 
-	if err := ctrl.service.ActivatePlugin(req.ID); err != nil {
+	if err := ctrl.service.ActivatePlugin(c.Request().Context(), req.ID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
 		return errors.ErrPluginActivateFailed.WithInternal(err)
 	}
 
@@ -153,14 +202,19 @@ func (ctrl *pluginController) ActivatePlugin(c echo.Context) error {
 
 // DeactivatePlugin godoc
 // @Summary      Deactivate a plugin
-// @Description  Deactivate an active plugin
+// @Description  Deactivate an active plugin. Fails with PLUGIN_IN_USE while active plugins depend on it
 // @Tags         Plugins
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "Plugin ID" minimum(1)
 // @Success      200 {object} map[string]string
 // @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      409 {object} errors.AppError
 // @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /api/plugins/{id}/deactivate [post]
 func (ctrl *pluginController) DeactivatePlugin(c echo.Context) error {
 	var req request.PluginIDRequest
@@ -173,6 +227,9 @@ func (ctrl *pluginController) DeactivatePlugin(c echo.Context) error {
 	}
 
 	if err := ctrl.service.DeactivatePlugin(req.ID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
 		return errors.ErrPluginDeactivateFailed.WithInternal(err)
 	}
 
@@ -183,14 +240,19 @@ func (ctrl *pluginController) DeactivatePlugin(c echo.Context) error {
 
 // UninstallPlugin godoc
 // @Summary      Uninstall a plugin
-// @Description  Remove a plugin from the system
+// @Description  Remove a plugin from the system. Fails with PLUGIN_IN_USE while active plugins depend on it
 // @Tags         Plugins
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "Plugin ID" minimum(1)
 // @Success      200 {object} map[string]string
 // @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      409 {object} errors.AppError
 // @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /api/plugins/{id} [delete]
 func (ctrl *pluginController) UninstallPlugin(c echo.Context) error {
 	var req request.PluginIDRequest
@@ -203,6 +265,9 @@ func (ctrl *pluginController) UninstallPlugin(c echo.Context) error {
 	}
 
 	if err := ctrl.service.UninstallPlugin(req.ID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
 		return errors.ErrPluginUninstallFailed.WithInternal(err)
 	}
 
@@ -211,17 +276,106 @@ func (ctrl *pluginController) UninstallPlugin(c echo.Context) error {
 	})
 }
 
+// UpdatePluginConfig godoc
+// @Summary      Update plugin config
+// @Description  Merge the body into the plugin's config, removing keys set to null. The settings are validated against the config schema the plugin declares and sent to its running processes, which use them as defaults for call parameters. The pool, lifecycle and limits keys configure the host and cannot be changed here
+// @Tags         Plugins
+// @Accept       json
+// @Produce      json
+// @Param        id     path int            true "Plugin ID" minimum(1)
+// @Param        config body map[string]any true "Config changes"
+// @Success      200 {object} models.Plugin
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/{id}/config [patch]
+func (ctrl *pluginController) UpdatePluginConfig(c echo.Context) error {
+	var req request.PluginIDRequest
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, &req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid plugin ID").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	var patch map[string]any
+	if err := (&echo.DefaultBinder{}).BindBody(c, &patch); err != nil {
+		return errors.ErrBadRequest.WithDetails("Request body must be a JSON object").WithInternal(err)
+	}
+
+	plugin, err := ctrl.service.UpdatePluginConfig(c.Request().Context(), req.ID, patch)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, plugin)
+}
+
+// UpgradePlugin godoc
+// @Summary      Upgrade a plugin
+// @Description  Replace an installed plugin with another version of it, keeping its ID, config and pinned aliases. The config body is merged into the current config as with PATCH /api/plugins/{id}/config; metadata replaces the current metadata, which is kept when omitted. The new version is downloaded, verified like an install, started and health-checked, and its settings are validated against the config schema it declares while the current version keeps serving. For an active plugin, calls are then switched over to the new version in one step and calls in flight finish on the current version before its processes are stopped. If the new version fails any of these checks, it is removed and the current version stays in place
+// @Tags         Plugins
+// @Accept       json
+// @Produce      json
+// @Param        id      path int                          true "Plugin ID" minimum(1)
+// @Param        request body request.UpgradePluginRequest true "Plugin upgrade request"
+// @Success      200 {object} response.PluginUpgrade
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      409 {object} errors.AppError
+// @Failure      422 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/{id}/upgrade [post]
+func (ctrl *pluginController) UpgradePlugin(c echo.Context) error {
+	var req request.UpgradePluginRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request format").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	upgrade, err := ctrl.service.UpgradePlugin(c.Request().Context(), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrPluginUpgradeFailed.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, upgrade)
+}
+
 // CallPlugin godoc
 // @Summary      Call a plugin method
-// @Description  Execute a specific method on an active plugin
+// @Description  Execute a specific method on an active plugin. Parameters keep their JSON types and are validated against the method's declared parameter schema; the result is returned as JSON
 // @Tags         Plugins
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "Plugin ID" minimum(1)
 // @Param        request body request.CallPluginRequest true "Plugin call request"
-// @Success      200 {object} map[string]interface{}
+// @Success      200 {object} any
 // @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
 // @Failure      500 {object} errors.AppError
+// @Failure      504 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /api/plugins/{id}/call [post]
 func (ctrl *pluginController) CallPlugin(c echo.Context) error {
 	var req request.CallPluginRequest
@@ -233,10 +387,411 @@ func (ctrl *pluginController) CallPlugin(c echo.Context) error {
 		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
 	}
 
-	result, err := ctrl.service.CallPlugin(req.ID, &req)
+	result, err := ctrl.service.CallPlugin(c.Request().Context(), req.ID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrPluginCallFailed.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// CallPluginByName godoc
+// @Summary      Call a plugin method by plugin name
+// @Description  Execute a method on the active version of a plugin that the version selector picks: a pinned alias, the latest or stable channel, an exact version, or a semver range, which selects the highest matching version. Without a selector the latest version is called. The version that served the call is returned in the X-Plugin-Version and X-Plugin-ID headers
+// @Tags         Plugins
+// @Accept       json
+// @Produce      json
+// @Param        namespace path string true "Plugin namespace"
+// @Param        name path string true "Plugin name"
+// @Param        version query string false "Version selector: alias, latest, stable, exact version or semver range"
+// @Param        request body request.CallPluginByNameRequest true "Plugin call request"
+// @Success      200 {object} any
+// @Header       200 {string} X-Plugin-Version "Version that served the call"
+// @Header       200 {integer} X-Plugin-ID "ID of the plugin that served the call"
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Failure      504 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/by-name/{namespace}/{name}/call [post]
+func (ctrl *pluginController) CallPluginByName(c echo.Context) error {
+	var req request.CallPluginByNameRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request format").WithInternal(err)
+	}
+	// The default binder only reads the query string of GET, DELETE and HEAD requests
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid version selector").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	result, record, err := ctrl.service.CallPluginByName(c.Request().Context(), &req)
+	if record != nil {
+		c.Response().Header().Set("X-Plugin-Version", record.Version)
+		c.Response().Header().Set("X-Plugin-ID", strconv.FormatUint(uint64(record.ID), 10))
+	}
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrPluginCallFailed.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// ListPluginAliases godoc
+// @Summary      List plugin version aliases
+// @Description  List the latest and stable channels and the pinned aliases of a plugin, with the version each one selects now
+// @Tags         Plugins
+// @Accept       json
+// @Produce      json
+// @Param        namespace path string true "Plugin namespace"
+// @Param        name path string true "Plugin name"
+// @Success      200 {array} response.PluginAlias
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/by-name/{namespace}/{name}/aliases [get]
+func (ctrl *pluginController) ListPluginAliases(c echo.Context) error {
+	var req request.PluginNameRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid plugin name").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	aliases, err := ctrl.service.ListPluginAliases(req.Namespace, req.Name)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, aliases)
+}
+
+// SetPluginAlias godoc
+// @Summary      Pin a plugin version alias
+// @Description  Pin an alias of a plugin to one of its installed versions, so calls selecting the alias keep reaching that version across upgrades. Pinning latest or stable overrides the channel. Aliases cannot look like versions or version ranges
+// @Tags         Plugins
+// @Accept       json
+// @Produce      json
+// @Param        namespace path string true "Plugin namespace"
+// @Param        name path string true "Plugin name"
+// @Param        alias path string true "Alias name"
+// @Param        request body request.SetPluginAliasRequest true "Version to pin the alias to"
+// @Success      200 {object} models.PluginAlias
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/by-name/{namespace}/{name}/aliases/{alias} [put]
+func (ctrl *pluginController) SetPluginAlias(c echo.Context) error {
+	var req request.SetPluginAliasRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request format").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	alias, err := ctrl.service.SetPluginAlias(req.Namespace, req.Name, req.Alias, req.Version)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, alias)
+}
+
+// DeletePluginAlias godoc
+// @Summary      Unpin a plugin version alias
+// @Description  Remove a pinned alias of a plugin. Unpinned latest and stable go back to following the active versions
+// @Tags         Plugins
+// @Accept       json
+// @Produce      json
+// @Param        namespace path string true "Plugin namespace"
+// @Param        name path string true "Plugin name"
+// @Param        alias path string true "Alias name"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/by-name/{namespace}/{name}/aliases/{alias} [delete]
+func (ctrl *pluginController) DeletePluginAlias(c echo.Context) error {
+	var req request.PluginAliasRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request format").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	if err := ctrl.service.DeletePluginAlias(req.Namespace, req.Name, req.Alias); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Plugin alias deleted successfully",
+	})
+}
+
+// ScanPlugins godoc
+// @Summary      Scan the plugin directory
+// @Description  Walk the plugin directory for binaries laid out as {namespace}/{type}/{name}/{version}/{os}_{arch}/plugin, read their metadata and register any that are not known yet. Active plugins are left untouched
+// @Tags         Plugins
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} plugin.ScanResult
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/scan [post]
+func (ctrl *pluginController) ScanPlugins(c echo.Context) error {
+	result, err := ctrl.service.ScanPlugins(c.Request().Context())
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrPluginScanFailed.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// CallPluginMethod godoc
+// @Summary      Call a plugin method by path
+// @Description  Execute a method on an active plugin with the request body as its parameters. This is the route the generated plugin method operations point at
+// @Tags         Plugins
+// @Accept       json
+// @Produce      json
+// @Param        id     path int    true "Plugin ID" minimum(1)
+// @Param        method path string true "Method name"
+// @Param        params body object true "Method parameters"
+// @Success      200 {object} any
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Failure      504 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/{id}/methods/{method} [post]
+func (ctrl *pluginController) CallPluginMethod(c echo.Context) error {
+	var req request.CallPluginMethodRequest
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, &req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid plugin ID or method").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	params := map[string]any{}
+	if err := (&echo.DefaultBinder{}).BindBody(c, &params); err != nil {
+		return errors.ErrBadRequest.WithDetails("Request body must be a JSON object of parameters").WithInternal(err)
+	}
+
+	result, err := ctrl.service.CallPlugin(c.Request().Context(), req.ID, &request.CallPluginRequest{
+		ID:     req.ID,
+		Method: req.Method,
+		Params: params,
+	})
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrPluginCallFailed.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// CallPluginBatch godoc
+// @Summary      Call plugin methods in a batch
+// @Description  Execute an array of method calls on an active plugin in one request. Calls run in parallel up to the configured batch concurrency; each result reports its own success or error and results are returned in request order
+// @Tags         Plugins
+// @Accept       json
+// @Produce      json
+// @Param        id    path int                     true "Plugin ID" minimum(1)
+// @Param        items body []request.BatchCallItem true "Method calls"
+// @Success      200 {object} response.BatchCallResponse
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/{id}/batch [post]
+func (ctrl *pluginController) CallPluginBatch(c echo.Context) error {
+	var req request.PluginIDRequest
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, &req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid plugin ID").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	var items []request.BatchCallItem
+	if err := (&echo.DefaultBinder{}).BindBody(c, &items); err != nil {
+		return errors.ErrBadRequest.WithDetails("Request body must be a JSON array of {method, params} items").WithInternal(err)
+	}
+	if len(items) == 0 {
+		return errors.ErrValidationFailed.WithDetails("Batch must contain at least one item")
+	}
+
+	result, err := ctrl.service.CallPluginBatch(c.Request().Context(), req.ID, items)
 	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
 		return errors.ErrPluginCallFailed.WithInternal(err)
 	}
 
 	return c.JSON(http.StatusOK, result)
 }
+
+// StreamPluginMethod godoc
+// @Summary      Stream a plugin method
+// @Description  Execute a streaming method on an active plugin. The request body is streamed to the plugin as the method's stream input parameter and the output is streamed back with chunked transfer encoding as it is produced. Other parameters are passed in the query string and typed by the method descriptor. Errors after the output has started are reported in the X-Plugin-Error trailer
+// @Tags         Plugins
+// @Accept       octet-stream
+// @Produce      octet-stream
+// @Param        id     path  int    true "Plugin ID" minimum(1)
+// @Param        method path  string true "Method name"
+// @Param        input  body  string true "Stream input"
+// @Success      200 {string} string "Method output, in the content type set by the plugin"
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Failure      504 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/{id}/methods/{method}/stream [post]
+func (ctrl *pluginController) StreamPluginMethod(c echo.Context) error {
+	var req request.CallPluginMethodRequest
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, &req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid plugin ID or method").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	output := newStreamResponse(c.Response())
+	err := ctrl.service.StreamPlugin(c.Request().Context(), req.ID, req.Method, c.QueryParams(), c.Request().Body, output)
+	if err != nil {
+		// Once output has been sent the status line is gone, so the error
+		// can only be reported in the trailer
+		if c.Response().Committed {
+			c.Response().Header().Set(StreamErrorTrailer, err.Error())
+			return nil
+		}
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrPluginCallFailed.WithInternal(err)
+	}
+
+	return output.Close()
+}
+
+// OpenAPIDocument godoc
+// @Summary      OpenAPI document of plugin methods
+// @Description  OpenAPI 3 document with one operation per method of every active plugin, generated from the method descriptors the plugins advertise
+// @Tags         Plugins
+// @Produce      json
+// @Success      200 {object} openapi.Document
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/openapi.json [get]
+func (ctrl *pluginController) OpenAPIDocument(c echo.Context) error {
+	doc, err := ctrl.service.OpenAPIDocument()
+	if err != nil {
+		return errors.ErrInternalServer.WithDetails("Failed to generate plugin OpenAPI document").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, doc)
+}
+
+// GetPluginLogs godoc
+// @Summary      Get plugin logs
+// @Description  Recent log entries of a plugin, oldest first: what its processes wrote to stdout (info) and stderr (hclog JSON lines at their own level, other lines at debug), and what the host logged about starting and stopping them. Entries are kept in memory, up to plugin.log_buffer_size per plugin, across restarts and upgrades. With follow=true the response is a server-sent event stream with one entry per event, the recent ones first, that stays open until the client disconnects
+// @Tags         Plugins
+// @Produce      json
+// @Produce      text/event-stream
+// @Param        id     path  int  true  "Plugin ID" minimum(1)
+// @Param        tail   query int  false "Number of recent entries; 0 returns all kept" minimum(0)
+// @Param        follow query bool false "Stream new entries as server-sent events"
+// @Success      200 {array} plugin.LogEntry
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/plugins/{id}/logs [get]
+func (ctrl *pluginController) GetPluginLogs(c echo.Context) error {
+	var req request.PluginLogsRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid plugin ID or log query").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	logs, err := ctrl.service.PluginLogs(req.ID)
+	if err != nil {
+		return errors.ErrPluginNotFound.WithInternal(err)
+	}
+
+	if !req.Follow {
+		return c.JSON(http.StatusOK, logs.Tail(req.Tail))
+	}
+
+	recent, entries, stop := logs.Follow(req.Tail)
+	defer stop()
+	return streamEvents(c, recent, entries)
+}
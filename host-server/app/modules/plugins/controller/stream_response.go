@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StreamErrorTrailer carries the error of a streaming call that failed after
+// its output had started
+const StreamErrorTrailer = "X-Plugin-Error"
+
+// defaultStreamContentType is used when the plugin does not set a content type
+const defaultStreamContentType = echo.MIMEOctetStream
+
+// streamResponse writes plugin output to the HTTP response as it arrives.
+// Headers are sent with the first chunk, so errors raised before any output
+// can still be answered with a regular error response.
+type streamResponse struct {
+	res         *echo.Response
+	contentType string
+}
+
+func newStreamResponse(res *echo.Response) *streamResponse {
+	return &streamResponse{res: res, contentType: defaultStreamContentType}
+}
+
+func (w *streamResponse) SetContentType(contentType string) {
+	if !w.res.Committed {
+		w.contentType = contentType
+	}
+}
+
+func (w *streamResponse) Write(p []byte) (int, error) {
+	w.commit()
+	n, err := w.res.Write(p)
+	if err != nil {
+		return n, err
+	}
+	w.res.Flush()
+	return n, nil
+}
+
+// Close sends the headers of a stream that produced no output
+func (w *streamResponse) Close() error {
+	w.commit()
+	return nil
+}
+
+func (w *streamResponse) commit() {
+	if w.res.Committed {
+		return
+	}
+	w.res.Header().Set(echo.HeaderContentType, w.contentType)
+	w.res.Header().Set("Trailer", StreamErrorTrailer)
+	w.res.WriteHeader(http.StatusOK)
+}
@@ -10,6 +10,8 @@ import (
 var Module = fx.Options(
 	fx.Provide(NewRoute),
 	fx.Provide(repository.NewPluginRepository),
+	fx.Provide(repository.NewPluginStateRepository),
+	fx.Provide(repository.NewPluginAliasRepository),
 	fx.Provide(service.NewPluginService),
 	fx.Provide(controller.NewPluginController),
 )
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PluginAliasRepository stores the version aliases pinned for plugins,
+// scoped by plugin namespace and name
+type PluginAliasRepository interface {
+	FindAll(namespace, name string) ([]*models.PluginAlias, error)
+	Find(namespace, name, alias string) (*models.PluginAlias, error)
+	Set(namespace, name, alias, version string) (*models.PluginAlias, error)
+	Delete(namespace, name, alias string) (bool, error)
+
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) PluginAliasRepository
+}
+
+type pluginAliasRepository struct {
+	db *gorm.DB
+}
+
+func NewPluginAliasRepository(db *gorm.DB) PluginAliasRepository {
+	return &pluginAliasRepository{
+		db: db,
+	}
+}
+
+func (r *pluginAliasRepository) WithContext(ctx context.Context) PluginAliasRepository {
+	return &pluginAliasRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
+func (r *pluginAliasRepository) FindAll(namespace, name string) ([]*models.PluginAlias, error) {
+	var aliases []*models.PluginAlias
+	err := r.db.Where("namespace = ? AND name = ?", namespace, name).Order("alias").Find(&aliases).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plugin aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// Find returns nil when the alias is not pinned
+func (r *pluginAliasRepository) Find(namespace, name, alias string) (*models.PluginAlias, error) {
+	var pinned models.PluginAlias
+	err := r.db.Where("namespace = ? AND name = ? AND alias = ?", namespace, name, alias).First(&pinned).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plugin alias: %w", err)
+	}
+	return &pinned, nil
+}
+
+// Set pins alias to version, moving it if it is already pinned
+func (r *pluginAliasRepository) Set(namespace, name, alias, version string) (*models.PluginAlias, error) {
+	pinned := models.PluginAlias{Namespace: namespace, Name: name, Alias: alias, Version: version}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "namespace"}, {Name: "name"}, {Name: "alias"}},
+		DoUpdates: clause.Assignments(map[string]any{"version": version, "updated_at": time.Now().Unix()}),
+	}).Create(&pinned).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to set plugin alias: %w", err)
+	}
+	return r.Find(namespace, name, alias)
+}
+
+// Delete unpins alias and reports whether it was pinned
+func (r *pluginAliasRepository) Delete(namespace, name, alias string) (bool, error) {
+	result := r.db.Where("namespace = ? AND name = ? AND alias = ?", namespace, name, alias).Delete(&models.PluginAlias{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete plugin alias: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
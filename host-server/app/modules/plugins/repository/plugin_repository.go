@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
@@ -14,9 +15,18 @@ type PluginRepository interface {
 	Update(plugin *models.Plugin) error
 	Delete(id uint) error
 	UpdateStatus(id uint, status models.PluginStatus) error
+	UpdateStatusWithReason(id uint, status models.PluginStatus, reason string) error
 	FindByType(pluginType models.PluginType) ([]*models.Plugin, error)
 	FindByNameAndVersion(name, version string) (*models.Plugin, error)
+	FindByNamespaceNameAndVersion(namespace, name, version string) (*models.Plugin, error)
 	UpdateLastUsedAt(id uint, timestamp int64) error
+	UpdateProtocolVersion(id uint, version int) error
+	UpdateMetadata(id uint, metadata models.JSONMap) error
+	UpdateConfig(id uint, config models.JSONMap) error
+
+	// WithContext returns the repository running its queries with ctx, so
+	// they are traced as part of the request and canceled with it
+	WithContext(ctx context.Context) PluginRepository
 }
 
 type pluginRepository struct {
@@ -29,6 +39,12 @@ func NewPluginRepository(db *gorm.DB) PluginRepository {
 	}
 }
 
+func (r *pluginRepository) WithContext(ctx context.Context) PluginRepository {
+	return &pluginRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
 func (r *pluginRepository) Create(plugin *models.Plugin) error {
 	if err := r.db.Create(plugin).Error; err != nil {
 		return fmt.Errorf("failed to create plugin: %w", err)
@@ -77,7 +93,15 @@ func (r *pluginRepository) Delete(id uint) error {
 }
 
 func (r *pluginRepository) UpdateStatus(id uint, status models.PluginStatus) error {
-	if err := r.db.Model(&models.Plugin{}).Where("id = ?", id).Update("status", status).Error; err != nil {
+	return r.UpdateStatusWithReason(id, status, "")
+}
+
+func (r *pluginRepository) UpdateStatusWithReason(id uint, status models.PluginStatus, reason string) error {
+	updates := map[string]any{
+		"status":        status,
+		"status_reason": reason,
+	}
+	if err := r.db.Model(&models.Plugin{}).Where("id = ?", id).Updates(updates).Error; err != nil {
 		return fmt.Errorf("failed to update plugin status: %w", err)
 	}
 	return nil
@@ -102,9 +126,41 @@ func (r *pluginRepository) FindByNameAndVersion(name, version string) (*models.P
 	return &plugin, nil
 }
 
+func (r *pluginRepository) FindByNamespaceNameAndVersion(namespace, name, version string) (*models.Plugin, error) {
+	var plugin models.Plugin
+	if err := r.db.Where("namespace = ? AND name = ? AND version = ?", namespace, name, version).First(&plugin).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Not found is not an error
+		}
+		return nil, fmt.Errorf("failed to find plugin: %w", err)
+	}
+	return &plugin, nil
+}
+
 func (r *pluginRepository) UpdateLastUsedAt(id uint, timestamp int64) error {
 	if err := r.db.Model(&models.Plugin{}).Where("id = ?", id).Update("last_used_at", timestamp).Error; err != nil {
 		return fmt.Errorf("failed to update last used at: %w", err)
 	}
 	return nil
 }
+
+func (r *pluginRepository) UpdateProtocolVersion(id uint, version int) error {
+	if err := r.db.Model(&models.Plugin{}).Where("id = ?", id).Update("protocol_version", version).Error; err != nil {
+		return fmt.Errorf("failed to update protocol version: %w", err)
+	}
+	return nil
+}
+
+func (r *pluginRepository) UpdateMetadata(id uint, metadata models.JSONMap) error {
+	if err := r.db.Model(&models.Plugin{}).Where("id = ?", id).Update("metadata", metadata).Error; err != nil {
+		return fmt.Errorf("failed to update plugin metadata: %w", err)
+	}
+	return nil
+}
+
+func (r *pluginRepository) UpdateConfig(id uint, config models.JSONMap) error {
+	if err := r.db.Model(&models.Plugin{}).Where("id = ?", id).Update("config", config).Error; err != nil {
+		return fmt.Errorf("failed to update plugin config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PluginStateRepository stores the key-value state of plugins, scoped by
+// plugin namespace and name
+type PluginStateRepository interface {
+	Get(namespace, plugin, key string) ([]byte, bool, error)
+	Set(namespace, plugin, key string, value []byte) error
+	CompareAndSet(namespace, plugin, key string, expected, value []byte) (bool, error)
+	Delete(namespace, plugin, key string) error
+}
+
+type pluginStateRepository struct {
+	db *gorm.DB
+}
+
+func NewPluginStateRepository(db *gorm.DB) PluginStateRepository {
+	return &pluginStateRepository{
+		db: db,
+	}
+}
+
+func (r *pluginStateRepository) Get(namespace, plugin, key string) ([]byte, bool, error) {
+	var state models.PluginState
+	err := r.db.Where("namespace = ? AND plugin = ? AND key = ?", namespace, plugin, key).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get plugin state: %w", err)
+	}
+	return state.Value, true, nil
+}
+
+func (r *pluginStateRepository) Set(namespace, plugin, key string, value []byte) error {
+	state := models.PluginState{Namespace: namespace, Plugin: plugin, Key: key, Value: value}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "namespace"}, {Name: "plugin"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]any{"value": value, "updated_at": time.Now().Unix()}),
+	}).Create(&state).Error
+	if err != nil {
+		return fmt.Errorf("failed to set plugin state: %w", err)
+	}
+	return nil
+}
+
+// CompareAndSet writes value only if key holds expected, or does not exist
+// when expected is empty, and reports whether it did
+func (r *pluginStateRepository) CompareAndSet(namespace, plugin, key string, expected, value []byte) (bool, error) {
+	if len(expected) == 0 {
+		state := models.PluginState{Namespace: namespace, Plugin: plugin, Key: key, Value: value}
+		result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&state)
+		if result.Error != nil {
+			return false, fmt.Errorf("failed to set plugin state: %w", result.Error)
+		}
+		return result.RowsAffected == 1, nil
+	}
+
+	written := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var state models.PluginState
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("namespace = ? AND plugin = ? AND key = ?", namespace, plugin, key).
+			First(&state).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(state.Value, expected) {
+			return nil
+		}
+		written = true
+		return tx.Model(&state).Update("value", value).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to set plugin state: %w", err)
+	}
+	return written, nil
+}
+
+func (r *pluginStateRepository) Delete(namespace, plugin, key string) error {
+	err := r.db.Where("namespace = ? AND plugin = ? AND key = ?", namespace, plugin, key).Delete(&models.PluginState{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete plugin state: %w", err)
+	}
+	return nil
+}
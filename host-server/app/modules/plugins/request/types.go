@@ -4,31 +4,88 @@ import "github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
 
 type InstallPluginRequest struct {
 	DownloadURL string         `json:"downloadURL" validate:"required,url"`
-	Namespace   string         `json:"namespace" validate:"required"`                // 新增：命名空间
-	Name        string         `json:"name" validate:"required"`
-	Version     string         `json:"version" validate:"required"`
-	Type        string         `json:"type" validate:"required"`                    // 修改：从枚举改为 string
+	Namespace   string         `json:"namespace" validate:"required,pathsegment"`    // 新增：命名空间
+	Name        string         `json:"name" validate:"required,pathsegment"`
+	Version     string         `json:"version" validate:"required,pathsegment"`
+	Type        string         `json:"type" validate:"required,pathsegment"`        // 修改：从枚举改为 string
 	OS          string         `json:"os" validate:"required,oneof=linux darwin windows"` // 新增：操作系统
 	Arch        string         `json:"arch" validate:"required,oneof=amd64 arm64"` // 新增：架构
 	Description string         `json:"description"`
+	Checksum    string         `json:"checksum" validate:"required,len=64,hexadecimal"` // 二进制文件的 SHA-256（十六进制）
+	Signature   string         `json:"signature" validate:"omitempty,base64"`           // 可选：对 SHA-256 摘要的 ed25519 签名（base64）
 	Config      models.JSONMap `json:"config"`
 	Metadata    models.JSONMap `json:"metadata"`
 }
 
+// UpgradePluginRequest replaces an installed plugin with another version of it
+type UpgradePluginRequest struct {
+	ID          uint           `param:"id" validate:"required,gt=0"`
+	DownloadURL string         `json:"downloadURL" validate:"required,url"`
+	Version     string         `json:"version" validate:"required,pathsegment"`
+	Checksum    string         `json:"checksum" validate:"required,len=64,hexadecimal"` // 新版本二进制文件的 SHA-256（十六进制）
+	Signature   string         `json:"signature" validate:"omitempty,base64"`           // 可选：对 SHA-256 摘要的 ed25519 签名（base64）
+	Config      map[string]any `json:"config"`                                          // 可选：迁移配置时合并的补丁，值为 null 的键被删除
+	Metadata    models.JSONMap `json:"metadata"`                                        // 可选：新版本的元数据，省略时沿用当前版本的（如 dependencies）
+}
+
 type CallPluginRequest struct {
 	ID     uint           `param:"id" validate:"required,gt=0"` // Plugin ID from path parameter
 	Method string         `json:"method" validate:"required"`   // Method name from request body
 	Params map[string]any `json:"params" validate:"required"`   // Method parameters from request body
 }
 
+type CallPluginMethodRequest struct {
+	ID     uint   `param:"id" validate:"required,gt=0"` // Plugin ID from path parameter
+	Method string `param:"method" validate:"required"`  // Method name from path parameter
+}
+
+type BatchCallItem struct {
+	Method string         `json:"method"` // 方法名
+	Params map[string]any `json:"params"` // 方法参数
+}
+
 type ListPluginsRequest struct {
 	Namespace string `query:"namespace" validate:"omitempty"` // 新增：按命名空间过滤
 	Type      string `query:"type" validate:"omitempty"`      // 修改：移除枚举限制
-	Status    string `query:"status" validate:"omitempty,oneof=active inactive disabled error installing"`
+	Status    string `query:"status" validate:"omitempty,oneof=active inactive disabled error installing limit_exceeded"`
 	OS        string `query:"os" validate:"omitempty"`        // 新增：按 OS 过滤
 	Arch      string `query:"arch" validate:"omitempty"`      // 新增：按架构过滤
 }
 
+// CallPluginByNameRequest calls the version of a plugin a selector picks
+type CallPluginByNameRequest struct {
+	Namespace string         `param:"namespace" validate:"required"`
+	Name      string         `param:"name" validate:"required"`
+	Version   string         `query:"version"` // 版本选择器：别名、latest、stable、精确版本或语义化版本范围，默认 latest
+	Method    string         `json:"method" validate:"required"`
+	Params    map[string]any `json:"params" validate:"required"`
+}
+
+type PluginNameRequest struct {
+	Namespace string `param:"namespace" validate:"required"`
+	Name      string `param:"name" validate:"required"`
+}
+
+type PluginAliasRequest struct {
+	Namespace string `param:"namespace" validate:"required"`
+	Name      string `param:"name" validate:"required"`
+	Alias     string `param:"alias" validate:"required,max=50"`
+}
+
+type SetPluginAliasRequest struct {
+	Namespace string `param:"namespace" validate:"required"`
+	Name      string `param:"name" validate:"required"`
+	Alias     string `param:"alias" validate:"required,max=50"`
+	Version   string `json:"version" validate:"required"` // 别名固定到的已安装版本
+}
+
 type PluginIDRequest struct {
 	ID uint `param:"id" validate:"required,gt=0"`
 }
+
+// PluginLogsRequest reads the recent log entries of a plugin
+type PluginLogsRequest struct {
+	ID     uint `param:"id" validate:"required,gt=0"`
+	Tail   int  `query:"tail" validate:"gte=0"` // 返回最近的条数，0 表示缓冲区中的全部
+	Follow bool `query:"follow"`                // 是否以 SSE 持续推送新的日志
+}
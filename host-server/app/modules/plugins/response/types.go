@@ -1,8 +1,63 @@
 package response
 
+import (
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/plugin"
+)
+
 type Response struct {
 	Success bool   `json:"success"`
 	Message string `json:"message,omitempty"`
 	Data    any    `json:"data,omitempty"`
 	Error   string `json:"error,omitempty"`
 }
+
+// BatchCallResponse holds the results of a batch call, in request order
+type BatchCallResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+// BatchItemResult is the outcome of one call of a batch
+type BatchItemResult struct {
+	Index   int             `json:"index"`
+	Method  string          `json:"method"`
+	Success bool            `json:"success"`
+	Result  any             `json:"result,omitempty"`
+	Error   *BatchItemError `json:"error,omitempty"`
+}
+
+// BatchItemError describes why one call of a batch failed, using the
+// error codes of the single-call endpoints
+type BatchItemError struct {
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+	Details   string `json:"details,omitempty"`
+}
+
+// PluginAlias is a version alias of a plugin and the version it selects now
+type PluginAlias struct {
+	Alias    string `json:"alias"`
+	Version  string `json:"version,omitempty"`   // 当前选中的版本，没有匹配的激活版本时为空
+	PluginID uint   `json:"plugin_id,omitempty"` // 当前选中版本的插件 ID
+	Pinned   bool   `json:"pinned"`              // 是否固定到某个版本；未固定的 latest、stable 随激活版本变化
+	Active   bool   `json:"active"`              // 选中的版本是否已激活
+}
+
+// PluginInfo is a plugin record with the runtime state of its processes.
+// An active plugin is either running or idle, i.e. stopped until its next call.
+type PluginInfo struct {
+	models.Plugin
+	RuntimeStatus string            `json:"runtime_status,omitempty"` // 运行状态：running、idle 或 restarting，仅在插件已加载时返回
+	Pool          *plugin.PoolStats `json:"pool,omitempty"`           // 进程池状态，仅在插件运行时返回
+}
+
+// PluginUpgrade is a plugin record after an upgrade, with how calls to the
+// previous version were drained
+type PluginUpgrade struct {
+	models.Plugin
+	PreviousVersion string `json:"previous_version"`
+	InFlightCalls   int64  `json:"in_flight_calls"` // 切换到新版本时仍在旧版本上执行的调用数
+	Drained         bool   `json:"drained"`         // 这些调用是否在旧版本进程停止前全部完成
+}
@@ -1,33 +1,79 @@
 package plugins
 
 import (
+	"strconv"
+
 	"github.com/labstack/echo/v4"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/controller"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/repository"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
 )
 
 type Route struct {
 	app        *echo.Echo
 	controller controller.PluginController
+	repo       repository.PluginRepository
 }
 
 func NewRoute(
 	app *echo.Echo,
 	controller controller.PluginController,
+	repo repository.PluginRepository,
 ) *Route {
 	return &Route{
 		app:        app,
 		controller: controller,
+		repo:       repo,
 	}
 }
 
 func (r *Route) Register() {
 	api := r.app.Group("/api/plugins")
 
-	api.POST("/install", r.controller.InstallPlugin)
-	api.GET("", r.controller.ListPlugins)
-	api.GET("/:id", r.controller.GetPlugin)
-	api.POST("/:id/activate", r.controller.ActivatePlugin)
-	api.POST("/:id/deactivate", r.controller.DeactivatePlugin)
-	api.DELETE("/:id", r.controller.UninstallPlugin)
-	api.POST("/:id/call", r.controller.CallPlugin)
+	// Roles are checked in the namespace of the plugin a route acts on
+	viewer := auth.Require(auth.RoleViewer, r.pluginNamespace)
+	operator := auth.Require(auth.RoleOperator, r.pluginNamespace)
+	admin := auth.Require(auth.RoleAdmin, r.pluginNamespace)
+
+	// The namespace of installs is checked once the body was read
+	api.POST("/install", r.controller.InstallPlugin, auth.RequireAny(auth.RoleAdmin))
+	api.POST("/scan", r.controller.ScanPlugins, auth.Require(auth.RoleAdmin, auth.Everywhere))
+	api.GET("/openapi.json", r.controller.OpenAPIDocument, auth.Require(auth.RoleViewer, auth.Everywhere))
+	// The document behind /docs/plugins, which browsers load without credentials
+	r.app.GET("/docs/plugins/openapi.json", r.controller.OpenAPIDocument)
+	// Only plugins of namespaces the principal may view are listed
+	api.GET("", r.controller.ListPlugins, auth.RequireAny(auth.RoleViewer))
+	api.GET("/:id", r.controller.GetPlugin, viewer)
+	api.GET("/:id/logs", r.controller.GetPluginLogs, viewer)
+	api.POST("/:id/activate", r.controller.ActivatePlugin, operator)
+	api.POST("/:id/deactivate", r.controller.DeactivatePlugin, operator)
+	api.DELETE("/:id", r.controller.UninstallPlugin, admin)
+	api.PATCH("/:id/config", r.controller.UpdatePluginConfig, admin)
+	api.POST("/:id/upgrade", r.controller.UpgradePlugin, admin)
+	api.POST("/:id/call", r.controller.CallPlugin, operator)
+	api.POST("/:id/batch", r.controller.CallPluginBatch, operator)
+	api.POST("/:id/methods/:method", r.controller.CallPluginMethod, operator)
+	api.POST("/:id/methods/:method/stream", r.controller.StreamPluginMethod, operator)
+
+	byName := api.Group("/by-name/:namespace/:name")
+	namespace := auth.PathNamespace("namespace")
+	byName.POST("/call", r.controller.CallPluginByName, auth.Require(auth.RoleOperator, namespace))
+	byName.GET("/aliases", r.controller.ListPluginAliases, auth.Require(auth.RoleViewer, namespace))
+	byName.PUT("/aliases/:alias", r.controller.SetPluginAlias, auth.Require(auth.RoleAdmin, namespace))
+	byName.DELETE("/aliases/:alias", r.controller.DeletePluginAlias, auth.Require(auth.RoleAdmin, namespace))
+}
+
+// pluginNamespace is the namespace of the plugin in the :id path parameter
+func (r *Route) pluginNamespace(c echo.Context) (string, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		return "", errors.ErrBadRequest.WithDetails("Invalid plugin ID").WithInternal(err)
+	}
+
+	plugin, err := r.repo.WithContext(c.Request().Context()).FindByID(uint(id))
+	if err != nil {
+		return "", errors.ErrPluginNotFound.WithInternal(err)
+	}
+	return plugin.Namespace, nil
 }
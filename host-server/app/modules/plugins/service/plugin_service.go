@@ -1,67 +1,122 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/repository"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/request"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/response"
+	"github.com/wylu1037/polyglot-plugin-host-server/config"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
 	"github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/openapi"
 	"github.com/wylu1037/polyglot-plugin-host-server/internal/plugin"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/policy"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/validator"
 	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type PluginService interface {
 	InstallPlugin(req *request.InstallPluginRequest) (*models.Plugin, error)
-	ActivatePlugin(id uint) error
+	ActivatePlugin(ctx context.Context, id uint) error
 	DeactivatePlugin(id uint) error
 	UninstallPlugin(id uint) error
-	ListPlugins(req *request.ListPluginsRequest) ([]*models.Plugin, error)
-	GetPluginInfo(id uint) (*models.Plugin, error)
-	CallPlugin(id uint, req *request.CallPluginRequest) (any, error)
+	ListPlugins(req *request.ListPluginsRequest) ([]*response.PluginInfo, error)
+	GetPluginInfo(id uint) (*response.PluginInfo, error)
+	UpdatePluginConfig(ctx context.Context, id uint, patch map[string]any) (*models.Plugin, error)
+	UpgradePlugin(ctx context.Context, req *request.UpgradePluginRequest) (*response.PluginUpgrade, error)
+	CallPlugin(ctx context.Context, id uint, req *request.CallPluginRequest) (any, error)
+	CallPluginByName(ctx context.Context, req *request.CallPluginByNameRequest) (any, *models.Plugin, error)
+	ListPluginAliases(namespace, name string) ([]*response.PluginAlias, error)
+	SetPluginAlias(namespace, name, alias, version string) (*models.PluginAlias, error)
+	DeletePluginAlias(namespace, name, alias string) error
+	CallPluginBatch(ctx context.Context, id uint, items []request.BatchCallItem) (*response.BatchCallResponse, error)
+	StreamPlugin(ctx context.Context, id uint, method string, query url.Values, input io.Reader, output common.StreamWriter) error
+	ScanPlugins(ctx context.Context) (*plugin.ScanResult, error)
+	PluginLogs(id uint) (*plugin.LogBuffer, error)
+	OpenAPIDocument() (*openapi.Document, error)
 }
 
 type pluginService struct {
-	repo      repository.PluginRepository
-	manager   *plugin.Manager
-	pluginDir string
+	repo             repository.PluginRepository
+	aliases          repository.PluginAliasRepository
+	manager          *plugin.Manager
+	discoverer       *plugin.Discoverer
+	dependencies     *plugin.DependencyResolver
+	versions         *plugin.VersionSelector
+	policies         *policy.Enforcer
+	pluginDir        string
+	batchConcurrency int
+	maxBatchSize     int
 }
 
+const (
+	// defaultBatchConcurrency bounds the parallel calls of a batch when not configured
+	defaultBatchConcurrency = 8
+	// defaultMaxBatchSize bounds the size of a batch when not configured
+	defaultMaxBatchSize = 1000
+)
+
 func NewPluginService(
 	repo repository.PluginRepository,
+	aliases repository.PluginAliasRepository,
 	manager *plugin.Manager,
-	pluginDir string,
+	discoverer *plugin.Discoverer,
+	policies *policy.Enforcer,
+	cfg *config.Config,
 ) PluginService {
+	batchConcurrency := cfg.Plugin.BatchConcurrency
+	if batchConcurrency <= 0 {
+		batchConcurrency = defaultBatchConcurrency
+	}
+	maxBatchSize := cfg.Plugin.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = defaultMaxBatchSize
+	}
+
 	return &pluginService{
-		repo:      repo,
-		manager:   manager,
-		pluginDir: pluginDir,
+		repo:             repo,
+		aliases:          aliases,
+		manager:          manager,
+		discoverer:       discoverer,
+		dependencies:     plugin.NewDependencyResolver(repo),
+		versions:         plugin.NewVersionSelector(repo, aliases),
+		policies:         policies,
+		pluginDir:        cfg.Plugin.Dir,
+		batchConcurrency: batchConcurrency,
+		maxBatchSize:     maxBatchSize,
 	}
 }
 
 func (s *pluginService) InstallPlugin(req *request.InstallPluginRequest) (*models.Plugin, error) {
-	existing, err := s.repo.FindByNameAndVersion(req.Name, req.Version)
+	existing, err := s.repo.FindByNamespaceNameAndVersion(req.Namespace, req.Name, req.Version)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check existing plugin: %w", err)
 	}
 	if existing != nil {
-		return nil, fmt.Errorf("plugin %s version %s already exists", req.Name, req.Version)
+		return nil, fmt.Errorf("plugin %s/%s version %s already exists", req.Namespace, req.Name, req.Version)
 	}
 
-	binaryPath := filepath.Join(
-		s.pluginDir,
-		req.Namespace,
-		req.Type,
-		req.Name,
-		req.Version,
-		fmt.Sprintf("%s_%s", req.OS, req.Arch),
-		"plugin",
-	)
+	binaryPath, err := s.binaryPath(req.Namespace, req.Type, req.Name, req.Version, req.OS, req.Arch)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := strings.ToLower(req.Checksum)
 
 	pluginRecord := &models.Plugin{
+		Namespace:       req.Namespace,
 		Name:            req.Name,
 		Version:         req.Version,
 		Type:            req.Type,
@@ -69,18 +124,33 @@ func (s *pluginService) InstallPlugin(req *request.InstallPluginRequest) (*model
 		Status:          models.PluginStatusInstalling,
 		BinaryPath:      binaryPath,
 		DownloadURL:     req.DownloadURL,
+		Checksum:        checksum,
 		Protocol:        models.PluginProtocolGRPC,
 		ProtocolVersion: 1,
+		OS:              req.OS,
+		Arch:            req.Arch,
 		Config:          req.Config,
 		Metadata:        req.Metadata,
 	}
 
+	if err := s.dependencies.Validate(pluginRecord); err != nil {
+		return nil, dependencyError(err)
+	}
+
 	if err := s.repo.Create(pluginRecord); err != nil {
 		return nil, fmt.Errorf("failed to create plugin record: %w", err)
 	}
 
-	if err := s.manager.DownloadPlugin(req.DownloadURL, binaryPath); err != nil {
-		s.repo.UpdateStatus(pluginRecord.ID, models.PluginStatusError)
+	integrity := plugin.Integrity{
+		Namespace: req.Namespace,
+		Checksum:  checksum,
+		Signature: req.Signature,
+	}
+	if err := s.manager.DownloadPlugin(req.DownloadURL, binaryPath, integrity); err != nil {
+		s.repo.UpdateStatusWithReason(pluginRecord.ID, models.PluginStatusError, err.Error())
+		if plugin.IsIntegrityCheckFailed(err) {
+			return nil, errors.ErrPluginIntegrityFailed.WithInternal(err)
+		}
 		return nil, fmt.Errorf("failed to download plugin: %w", err)
 	}
 
@@ -91,7 +161,35 @@ func (s *pluginService) InstallPlugin(req *request.InstallPluginRequest) (*model
 	return s.repo.FindByID(pluginRecord.ID)
 }
 
-func (s *pluginService) ActivatePlugin(id uint) error {
+// binaryPath returns where the binary of one plugin version is stored. Each
+// part must be a single path element, so a request cannot place a binary
+// outside its namespace's directory.
+func (s *pluginService) binaryPath(namespace, pluginType, name, version, os, arch string) (string, error) {
+	for _, segment := range []string{namespace, pluginType, name, version, os, arch} {
+		if !validator.IsPathSegment(segment) {
+			return "", errors.ErrValidationFailed.WithDetails(fmt.Sprintf("'%s' must not contain path separators or be '.' or '..'", segment))
+		}
+	}
+
+	namespaceDir := filepath.Join(s.pluginDir, namespace)
+	path := filepath.Join(
+		namespaceDir,
+		pluginType,
+		name,
+		version,
+		fmt.Sprintf("%s_%s", os, arch),
+		"plugin",
+	)
+	if !strings.HasPrefix(path, namespaceDir+string(filepath.Separator)) {
+		return "", errors.ErrValidationFailed.WithDetails(fmt.Sprintf("binary path %s is outside of %s", path, namespaceDir))
+	}
+	return path, nil
+}
+
+// ActivatePlugin activates a plugin after the plugins it depends on. If a
+// dependency fails to activate, the dependencies activated so far are
+// deactivated again.
+func (s *pluginService) ActivatePlugin(ctx context.Context, id uint) error {
 	pluginRecord, err := s.repo.FindByID(id)
 	if err != nil {
 		return fmt.Errorf("failed to find plugin: %w", err)
@@ -101,23 +199,139 @@ func (s *pluginService) ActivatePlugin(id uint) error {
 		return nil
 	}
 
-	if pluginRecord.Status != models.PluginStatusInactive && pluginRecord.Status != models.PluginStatusDisabled {
+	order, err := s.dependencies.ActivationOrder(pluginRecord)
+	if err != nil {
+		return dependencyError(err)
+	}
+
+	return s.activateInOrder(ctx, id, order)
+}
+
+// activateInOrder activates the plugins of an activation order one after
+// the other, deactivating them again if one fails. id is the plugin the
+// others are activated for. The principal in ctx needs the operator role in
+// the namespace of every plugin in the order, since dependencies may live in
+// other namespaces; nothing is activated otherwise.
+func (s *pluginService) activateInOrder(ctx context.Context, id uint, order []*models.Plugin) error {
+	for _, record := range order {
+		if err := auth.Authorize(ctx, record.Namespace, auth.RoleOperator); err != nil {
+			return err
+		}
+	}
+
+	for i, record := range order {
+		err := s.activatePlugin(record)
+		if err == nil {
+			continue
+		}
+
+		s.rollbackActivation(order[:i])
+		if record.ID != id {
+			return errors.ErrPluginActivateFailed.WithDetails(fmt.Sprintf("failed to activate dependency %s/%s@%s: %v", record.Namespace, record.Name, record.Version, err)).WithInternal(err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// rollbackActivation deactivates dependencies activated for a plugin that
+// failed to come up, in reverse order
+func (s *pluginService) rollbackActivation(activated []*models.Plugin) {
+	for i := len(activated) - 1; i >= 0; i-- {
+		if err := s.deactivatePlugin(activated[i].ID); err != nil {
+			slog.Warn("Failed to deactivate dependency", "plugin_id", activated[i].ID, "plugin", activated[i].Name, "error", err)
+		}
+	}
+}
+
+// activatePlugin loads one plugin and records what it advertises
+func (s *pluginService) activatePlugin(pluginRecord *models.Plugin) error {
+	id := pluginRecord.ID
+
+	if pluginRecord.Status != models.PluginStatusInactive &&
+		pluginRecord.Status != models.PluginStatusDisabled &&
+		pluginRecord.Status != models.PluginStatusError &&
+		pluginRecord.Status != models.PluginStatusLimitExceeded {
 		return fmt.Errorf("plugin cannot be activated from status: %s", pluginRecord.Status)
 	}
 
-	if err := s.manager.LoadPlugin(id, pluginRecord.BinaryPath, pluginRecord.Name); err != nil {
-		s.repo.UpdateStatus(id, models.PluginStatusError)
+	if err := s.manager.LoadPlugin(plugin.SpecFromModel(pluginRecord)); err != nil {
+		s.repo.UpdateStatusWithReason(id, models.PluginStatusError, err.Error())
+		if plugin.IsProtocolIncompatible(err) {
+			return errors.ErrPluginProtocolIncompatible.WithInternal(err)
+		}
+		if plugin.IsIntegrityCheckFailed(err) {
+			return errors.ErrPluginIntegrityFailed.WithInternal(err)
+		}
 		return fmt.Errorf("failed to load plugin: %w", err)
 	}
 
+	if err := s.recordProtocolVersion(id); err != nil {
+		s.manager.UnloadPlugin(id)
+		return err
+	}
+
+	if err := s.recordMethods(pluginRecord); err != nil {
+		s.manager.UnloadPlugin(id)
+		return err
+	}
+
 	if err := s.repo.UpdateStatus(id, models.PluginStatusActive); err != nil {
 		s.manager.UnloadPlugin(id)
 		return fmt.Errorf("failed to update plugin status: %w", err)
 	}
 
+	// On-demand plugins only ran to be verified; their first call starts them again
+	if lifecycle, err := s.manager.LifecycleFor(plugin.SpecFromModel(pluginRecord)); err == nil && lifecycle.OnDemand {
+		s.manager.SuspendPlugin(id)
+	}
+
+	return nil
+}
+
+// recordProtocolVersion persists the protocol version negotiated with a loaded plugin
+func (s *pluginService) recordProtocolVersion(id uint) error {
+	version, err := s.manager.NegotiatedVersion(id)
+	if err != nil {
+		return fmt.Errorf("failed to get negotiated protocol version: %w", err)
+	}
+
+	if err := s.repo.UpdateProtocolVersion(id, version); err != nil {
+		return fmt.Errorf("failed to record protocol version: %w", err)
+	}
+
 	return nil
 }
 
+// recordMethods stores the method descriptors and config schema advertised
+// by a loaded plugin in its metadata, where calls and config changes are
+// validated against them
+func (s *pluginService) recordMethods(record *models.Plugin) error {
+	methods, err := s.manager.DescribeMethods(context.Background(), record.ID)
+	if err != nil {
+		return fmt.Errorf("failed to describe plugin methods: %w", err)
+	}
+	schema, err := s.manager.DescribeConfig(context.Background(), record.ID)
+	if err != nil {
+		return fmt.Errorf("failed to describe plugin config: %w", err)
+	}
+
+	metadata := record.Metadata
+	if metadata == nil {
+		metadata = models.JSONMap{}
+	}
+	metadata["methods"] = methods
+	metadata["config_schema"] = schema
+
+	if err := s.repo.UpdateMetadata(record.ID, metadata); err != nil {
+		return fmt.Errorf("failed to record plugin methods: %w", err)
+	}
+
+	return nil
+}
+
+// DeactivatePlugin deactivates a plugin unless active plugins depend on it
 func (s *pluginService) DeactivatePlugin(id uint) error {
 	pluginRecord, err := s.repo.FindByID(id)
 	if err != nil {
@@ -128,6 +342,14 @@ func (s *pluginService) DeactivatePlugin(id uint) error {
 		return nil
 	}
 
+	if err := s.checkNotRequired(pluginRecord); err != nil {
+		return err
+	}
+
+	return s.deactivatePlugin(id)
+}
+
+func (s *pluginService) deactivatePlugin(id uint) error {
 	if err := s.manager.UnloadPlugin(id); err != nil {
 		return fmt.Errorf("failed to unload plugin: %w", err)
 	}
@@ -145,8 +367,12 @@ func (s *pluginService) UninstallPlugin(id uint) error {
 		return fmt.Errorf("failed to find plugin: %w", err)
 	}
 
+	if err := s.checkNotRequired(pluginRecord); err != nil {
+		return err
+	}
+
 	if pluginRecord.Status == models.PluginStatusActive {
-		if err := s.DeactivatePlugin(id); err != nil {
+		if err := s.deactivatePlugin(id); err != nil {
 			return fmt.Errorf("failed to deactivate plugin: %w", err)
 		}
 	}
@@ -158,11 +384,39 @@ func (s *pluginService) UninstallPlugin(id uint) error {
 	if err := s.repo.Delete(id); err != nil {
 		return fmt.Errorf("failed to delete plugin record: %w", err)
 	}
+	s.manager.DropLogs(id)
+
+	return nil
+}
 
+// checkNotRequired fails with ErrPluginInUse when active plugins depend on an
+// active plugin that is about to go away
+func (s *pluginService) checkNotRequired(record *models.Plugin) error {
+	if record.Status != models.PluginStatusActive {
+		return nil
+	}
+	if err := s.dependencies.CheckNotRequired(record); err != nil {
+		return dependencyError(err)
+	}
 	return nil
 }
 
-func (s *pluginService) ListPlugins(req *request.ListPluginsRequest) ([]*models.Plugin, error) {
+// dependencyError maps dependency resolution failures to API errors
+func dependencyError(err error) error {
+	switch {
+	case plugin.IsDependencyUnmet(err):
+		return errors.ErrPluginDependencyUnmet.WithDetails(err.Error()).WithInternal(err)
+	case plugin.IsDependencyCycle(err):
+		return errors.ErrPluginDependencyCycle.WithDetails(err.Error()).WithInternal(err)
+	case plugin.IsInvalidDependency(err):
+		return errors.ErrPluginInvalid.WithDetails(err.Error()).WithInternal(err)
+	case plugin.IsPluginInUse(err):
+		return errors.ErrPluginInUse.WithDetails(err.Error()).WithInternal(err)
+	}
+	return err
+}
+
+func (s *pluginService) ListPlugins(req *request.ListPluginsRequest) ([]*response.PluginInfo, error) {
 	filters := make(map[string]any)
 	if req.Namespace != "" {
 		filters["namespace"] = req.Namespace
@@ -180,15 +434,296 @@ func (s *pluginService) ListPlugins(req *request.ListPluginsRequest) ([]*models.
 		filters["arch"] = req.Arch
 	}
 
-	return s.repo.FindAll(filters)
+	plugins, err := s.repo.FindAll(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*response.PluginInfo, len(plugins))
+	for i, record := range plugins {
+		infos[i] = &response.PluginInfo{
+			Plugin:        *record,
+			RuntimeStatus: s.manager.RuntimeStatus(record.ID),
+		}
+	}
+	return infos, nil
 }
 
-func (s *pluginService) GetPluginInfo(id uint) (*models.Plugin, error) {
-	return s.repo.FindByID(id)
+// PluginLogs returns the buffer of recent log entries of an installed plugin
+func (s *pluginService) PluginLogs(id uint) (*plugin.LogBuffer, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.manager.Logs(id), nil
 }
 
-func (s *pluginService) CallPlugin(id uint, req *request.CallPluginRequest) (any, error) {
-	pluginRecord, err := s.repo.FindByID(id)
+// GetPluginInfo returns the plugin record with its runtime status and, while
+// it is running, a snapshot of its process pool
+func (s *pluginService) GetPluginInfo(id uint) (*response.PluginInfo, error) {
+	record, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &response.PluginInfo{
+		Plugin:        *record,
+		RuntimeStatus: s.manager.RuntimeStatus(id),
+	}
+	if stats, running := s.manager.PoolStats(id); running {
+		info.Pool = stats
+	}
+	return info, nil
+}
+
+// UpdatePluginConfig merges patch into a plugin's config: keys set to null
+// are removed, all others replaced. The resulting settings are validated
+// against the config schema the plugin declares and, while the plugin is
+// loaded, sent to its processes before the config is stored. Host config
+// such as "pool" or "limits" cannot be changed this way.
+func (s *pluginService) UpdatePluginConfig(ctx context.Context, id uint, patch map[string]any) (*models.Plugin, error) {
+	repo := s.repo.WithContext(ctx)
+	record, err := repo.FindByID(id)
+	if err != nil {
+		return nil, errors.ErrPluginNotFound.WithInternal(err)
+	}
+
+	config, err := mergeConfig(record.Config, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := plugin.PluginSettings(config)
+	if err := plugin.ValidateSettings(plugin.ConfigSchemaFromMetadata(record.Metadata), settings); err != nil {
+		return nil, errors.ErrPluginInvalidConfig.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	if s.manager.RuntimeStatus(id) != "" {
+		configureCtx, cancel := s.manager.CallContext(ctx, id)
+		defer cancel()
+
+		if err := s.manager.ConfigurePlugin(configureCtx, id, settings); err != nil {
+			// Processes that took the new settings go back to the stored ones
+			if restoreErr := s.manager.ConfigurePlugin(configureCtx, id, plugin.PluginSettings(record.Config)); restoreErr != nil {
+				slog.Warn("Failed to restore plugin config", "plugin_id", id, "plugin", record.Name, "error", restoreErr)
+			}
+			if plugin.IsInvalidConfig(err) {
+				return nil, errors.ErrPluginInvalidConfig.WithDetails(err.Error()).WithInternal(err)
+			}
+			return nil, fmt.Errorf("failed to configure plugin: %w", err)
+		}
+	}
+
+	if err := repo.UpdateConfig(id, config); err != nil {
+		return nil, err
+	}
+	record.Config = config
+	return record, nil
+}
+
+// UpgradePlugin replaces an installed plugin with another version of it. The
+// record keeps its ID, so calls by ID, pinned aliases and the config carry
+// over; the config may be patched on the way, as with UpdatePluginConfig.
+// The new version is downloaded and started, and its settings validated
+// against the schema it declares, while the current version keeps serving.
+// An active plugin is switched over without dropping calls: calls in flight
+// finish on the current version before its processes are stopped. If the new
+// version fails to start or a check fails, it is removed again and the
+// current version stays in place.
+func (s *pluginService) UpgradePlugin(ctx context.Context, req *request.UpgradePluginRequest) (*response.PluginUpgrade, error) {
+	record, err := s.repo.FindByID(req.ID)
+	if err != nil {
+		return nil, errors.ErrPluginNotFound.WithInternal(err)
+	}
+	if record.Status == models.PluginStatusInstalling {
+		return nil, errors.ErrConflict.WithDetails("plugin is still being installed")
+	}
+
+	existing, err := s.repo.FindByNamespaceNameAndVersion(record.Namespace, record.Name, req.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing plugin: %w", err)
+	}
+	if existing != nil {
+		return nil, errors.ErrPluginAlreadyExists.WithDetails(fmt.Sprintf("version %s of plugin %s/%s is already installed", req.Version, record.Namespace, record.Name))
+	}
+
+	config, err := mergeConfig(record.Config, req.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Methods and config schema are recorded from the new version once it runs
+	metadata := models.JSONMap{}
+	source := req.Metadata
+	if source == nil {
+		source = record.Metadata
+	}
+	for key, value := range source {
+		if key != "methods" && key != "config_schema" {
+			metadata[key] = value
+		}
+	}
+
+	upgraded := *record
+	upgraded.Version = req.Version
+	upgraded.BinaryPath, err = s.binaryPath(record.Namespace, record.Type, record.Name, req.Version, record.OS, record.Arch)
+	if err != nil {
+		return nil, err
+	}
+	upgraded.DownloadURL = req.DownloadURL
+	upgraded.Checksum = strings.ToLower(req.Checksum)
+	upgraded.Config = config
+	upgraded.Metadata = metadata
+
+	if err := s.dependencies.Validate(&upgraded); err != nil {
+		return nil, dependencyError(err)
+	}
+
+	// A running plugin needs the dependencies of the new version to be active
+	// and must keep satisfying the plugins that depend on it
+	active := record.Status == models.PluginStatusActive
+	var dependencies []*models.Plugin
+	if active {
+		if err := s.dependencies.CheckUpgrade(record, &upgraded); err != nil {
+			return nil, dependencyError(err)
+		}
+		dependencies, err = s.dependencies.ActivationOrder(&upgraded)
+		if err != nil {
+			return nil, dependencyError(err)
+		}
+	}
+
+	integrity := plugin.Integrity{
+		Namespace: record.Namespace,
+		Checksum:  upgraded.Checksum,
+		Signature: req.Signature,
+	}
+	if err := s.manager.DownloadPlugin(req.DownloadURL, upgraded.BinaryPath, integrity); err != nil {
+		return nil, upgradeError(err)
+	}
+
+	if err := s.activateInOrder(ctx, record.ID, dependencies); err != nil {
+		os.Remove(upgraded.BinaryPath)
+		return nil, err
+	}
+
+	// The record is switched over right before calls are, once the new
+	// version passed every check
+	stored := false
+	check := func(ctx context.Context, advertised *common.MetadataResponse, negotiated int) error {
+		schema := plugin.ConfigSchemaFromResponse(advertised)
+		if err := plugin.ValidateSettings(schema, plugin.PluginSettings(config)); err != nil {
+			return err
+		}
+
+		upgraded.ProtocolVersion = negotiated
+		upgraded.Metadata["methods"] = plugin.MethodsFromResponse(advertised)
+		upgraded.Metadata["config_schema"] = schema
+		if err := s.repo.Update(&upgraded); err != nil {
+			return err
+		}
+		stored = true
+		return nil
+	}
+
+	spec := plugin.SpecFromModel(&upgraded)
+	result := &plugin.UpgradeResult{Drained: true}
+	if active {
+		result, err = s.manager.UpgradePlugin(ctx, spec, check)
+	} else {
+		advertised, negotiated, inspectErr := s.manager.InspectPlugin(ctx, spec)
+		err = inspectErr
+		if err == nil {
+			err = check(ctx, advertised, negotiated)
+		}
+	}
+	if err != nil {
+		if stored {
+			if restoreErr := s.repo.Update(record); restoreErr != nil {
+				slog.Warn("Failed to restore plugin after a failed upgrade", "plugin_id", record.ID, "plugin", record.Name, "error", restoreErr)
+			}
+		}
+		s.rollbackActivation(dependencies)
+		os.Remove(upgraded.BinaryPath)
+		return nil, upgradeError(err)
+	}
+
+	if err := os.Remove(record.BinaryPath); err != nil && !os.IsNotExist(err) {
+		slog.Warn("Failed to remove plugin binary", "plugin_id", record.ID, "plugin", record.Name, "version", record.Version, "error", err)
+	}
+	s.repinAliases(record, upgraded.Version)
+
+	// On-demand plugins only ran to be verified; their first call starts them again
+	if lifecycle, err := s.manager.LifecycleFor(spec); active && err == nil && lifecycle.OnDemand {
+		s.manager.SuspendPlugin(record.ID)
+	}
+
+	slog.Info("Upgraded plugin", "plugin_id", record.ID, "plugin", record.Name, "previous_version", record.Version, "version", upgraded.Version)
+
+	return &response.PluginUpgrade{
+		Plugin:          upgraded,
+		PreviousVersion: record.Version,
+		InFlightCalls:   result.InFlight,
+		Drained:         result.Drained,
+	}, nil
+}
+
+// repinAliases moves the aliases pinned to the version a plugin was upgraded
+// from to the version it was upgraded to
+func (s *pluginService) repinAliases(record *models.Plugin, version string) {
+	aliases, err := s.aliases.FindAll(record.Namespace, record.Name)
+	if err != nil {
+		slog.Warn("Failed to repin plugin aliases", "namespace", record.Namespace, "plugin", record.Name, "error", err)
+		return
+	}
+	for _, alias := range aliases {
+		if alias.Version != record.Version {
+			continue
+		}
+		if _, err := s.aliases.Set(record.Namespace, record.Name, alias.Alias, version); err != nil {
+			slog.Warn("Failed to repin plugin alias", "namespace", record.Namespace, "plugin", record.Name, "alias", alias.Alias, "error", err)
+		}
+	}
+}
+
+// upgradeError maps the reasons an upgrade was rolled back to API errors
+func upgradeError(err error) error {
+	if appErr, ok := err.(*errors.AppError); ok {
+		return appErr
+	}
+	switch {
+	case plugin.IsProtocolIncompatible(err):
+		return errors.ErrPluginProtocolIncompatible.WithInternal(err)
+	case plugin.IsIntegrityCheckFailed(err):
+		return errors.ErrPluginIntegrityFailed.WithInternal(err)
+	case plugin.IsInvalidConfig(err):
+		return errors.ErrPluginInvalidConfig.WithDetails(err.Error()).WithInternal(err)
+	}
+	return errors.ErrPluginUpgradeFailed.WithInternal(err)
+}
+
+// mergeConfig returns a copy of config with patch applied: keys set to null
+// are removed, all others replaced. Host config cannot be patched.
+func mergeConfig(config models.JSONMap, patch map[string]any) (models.JSONMap, error) {
+	merged := models.JSONMap{}
+	for key, value := range config {
+		merged[key] = value
+	}
+	for key, value := range patch {
+		if plugin.IsHostConfigKey(key) {
+			return nil, errors.ErrPluginInvalidConfig.WithDetails(fmt.Sprintf("'%s' configures the host and cannot be changed through this endpoint", key))
+		}
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged, nil
+}
+
+func (s *pluginService) CallPlugin(ctx context.Context, id uint, req *request.CallPluginRequest) (any, error) {
+	repo := s.repo.WithContext(ctx)
+	pluginRecord, err := repo.FindByID(id)
 	if err != nil {
 		return nil, errors.ErrPluginNotFound.WithInternal(err)
 	}
@@ -197,30 +732,39 @@ func (s *pluginService) CallPlugin(id uint, req *request.CallPluginRequest) (any
 		return nil, fmt.Errorf("plugin is not active")
 	}
 
+	params, err := validateCallParams(pluginRecord, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeCall(ctx, pluginRecord, req.Method, params); err != nil {
+		return nil, err
+	}
+
 	now := time.Now().Unix()
-	s.repo.UpdateLastUsedAt(id, now)
+	repo.UpdateLastUsedAt(id, now)
 
 	clientInterface, err := s.manager.GetPluginClient(id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get plugin client: %w", err)
 	}
 
-	pluginClient, ok := clientInterface.(common.PluginInterface)
+	pluginClient, ok := clientInterface.(common.ContextPluginInterface)
 	if !ok {
-		return nil, fmt.Errorf("plugin does not implement common.PluginInterface")
+		return nil, fmt.Errorf("plugin does not implement common.ContextPluginInterface")
 	}
 
-	stringParams := make(map[string]string)
-	for key, value := range req.Params {
-		if strValue, ok := value.(string); ok {
-			stringParams[key] = strValue
-		} else {
-			stringParams[key] = fmt.Sprintf("%v", value)
-		}
-	}
+	callCtx, cancel := s.manager.CallContext(ctx, id)
+	defer cancel()
 
-	result, err := pluginClient.Execute(req.Method, stringParams)
+	result, err := pluginClient.ExecuteContext(callCtx, req.Method, params)
 	if err != nil {
+		switch callCtx.Err() {
+		case context.DeadlineExceeded:
+			return nil, errors.ErrPluginCallTimeout.WithInternal(err)
+		case context.Canceled:
+			return nil, errors.ErrPluginCallCanceled.WithInternal(err)
+		}
 		return nil, fmt.Errorf("plugin execution failed: %w", err)
 	}
 
@@ -233,8 +777,369 @@ func (s *pluginService) CallPlugin(id uint, req *request.CallPluginRequest) (any
 	}
 
 	if result.Result == nil {
-		return "", nil
+		return nil, nil
+	}
+
+	return result.Result.AsInterface(), nil
+}
+
+// CallPluginByName calls the active version of a plugin that the request's
+// version selector picks and returns the plugin record that served the call
+func (s *pluginService) CallPluginByName(ctx context.Context, req *request.CallPluginByNameRequest) (any, *models.Plugin, error) {
+	record, err := s.selectVersion(ctx, req.Namespace, req.Name, req.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.CallPlugin(ctx, record.ID, &request.CallPluginRequest{
+		ID:     record.ID,
+		Method: req.Method,
+		Params: req.Params,
+	})
+	return result, record, err
+}
+
+// selectVersion resolves a version selector against the active versions of a
+// plugin and its pinned aliases
+func (s *pluginService) selectVersion(ctx context.Context, namespace, name, selector string) (*models.Plugin, error) {
+	record, err := s.versions.Select(ctx, namespace, name, selector)
+	if err != nil {
+		if plugin.IsVersionNotFound(err) {
+			return nil, errors.ErrPluginVersionNotFound.WithDetails(fmt.Sprintf("plugin %s/%s: %v", namespace, name, err)).WithInternal(err)
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// ListPluginAliases returns the latest and stable channels and the pinned
+// aliases of a plugin, with the version each one selects now
+func (s *pluginService) ListPluginAliases(namespace, name string) ([]*response.PluginAlias, error) {
+	installed, err := s.repo.FindAll(map[string]any{"namespace": namespace, "name": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plugin versions: %w", err)
+	}
+	if len(installed) == 0 {
+		return nil, errors.ErrPluginNotFound.WithDetails(fmt.Sprintf("plugin %s/%s is not installed", namespace, name))
+	}
+
+	pinned, err := s.versions.PinnedAliases(context.Background(), namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*models.Plugin
+	for _, record := range installed {
+		if record.Status == models.PluginStatusActive {
+			active = append(active, record)
+		}
+	}
+
+	names := []string{plugin.ChannelLatest, plugin.ChannelStable}
+	for alias := range pinned {
+		if alias != plugin.ChannelLatest && alias != plugin.ChannelStable {
+			names = append(names, alias)
+		}
+	}
+	sort.Strings(names[2:])
+
+	aliases := make([]*response.PluginAlias, 0, len(names))
+	for _, alias := range names {
+		version, isPinned := pinned[alias]
+		info := &response.PluginAlias{Alias: alias, Version: version, Pinned: isPinned}
+		if record, err := plugin.SelectVersion(active, alias, pinned); err == nil {
+			info.Version = record.Version
+			info.PluginID = record.ID
+			info.Active = true
+		} else if isPinned {
+			for _, record := range installed {
+				if record.Version == version {
+					info.PluginID = record.ID
+				}
+			}
+		}
+		aliases = append(aliases, info)
+	}
+	return aliases, nil
+}
+
+// SetPluginAlias pins alias to an installed version of a plugin. Pinning
+// latest or stable overrides the channel until the alias is deleted.
+func (s *pluginService) SetPluginAlias(namespace, name, alias, version string) (*models.PluginAlias, error) {
+	if err := plugin.ValidateAlias(alias); err != nil {
+		return nil, errors.ErrPluginInvalidAlias.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	record, err := s.repo.FindByNamespaceNameAndVersion(namespace, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, errors.ErrPluginNotFound.WithDetails(fmt.Sprintf("version %s of plugin %s/%s is not installed", version, namespace, name))
+	}
+
+	return s.aliases.Set(namespace, name, alias, record.Version)
+}
+
+// DeletePluginAlias unpins an alias; latest and stable go back to following
+// the active versions
+func (s *pluginService) DeletePluginAlias(namespace, name, alias string) error {
+	deleted, err := s.aliases.Delete(namespace, name, alias)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.ErrNotFound.WithDetails(fmt.Sprintf("alias '%s' of plugin %s/%s is not pinned", alias, namespace, name))
+	}
+	return nil
+}
+
+// CallPluginBatch executes items against one plugin with at most
+// batchConcurrency calls in flight. Each item succeeds or fails on its own;
+// results are returned in request order. Only problems with the plugin as a
+// whole fail the request.
+func (s *pluginService) CallPluginBatch(ctx context.Context, id uint, items []request.BatchCallItem) (*response.BatchCallResponse, error) {
+	if len(items) > s.maxBatchSize {
+		return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("batch has %d items, the limit is %d", len(items), s.maxBatchSize))
+	}
+
+	repo := s.repo.WithContext(ctx)
+	pluginRecord, err := repo.FindByID(id)
+	if err != nil {
+		return nil, errors.ErrPluginNotFound.WithInternal(err)
+	}
+
+	if pluginRecord.Status != models.PluginStatusActive {
+		return nil, fmt.Errorf("plugin is not active")
+	}
+
+	clientInterface, err := s.manager.GetPluginClient(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plugin client: %w", err)
+	}
+
+	pluginClient, ok := clientInterface.(common.ContextPluginInterface)
+	if !ok {
+		return nil, fmt.Errorf("plugin does not implement common.ContextPluginInterface")
+	}
+
+	policies, err := s.policies.Load(ctx, pluginRecord.Namespace, pluginRecord.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugin policies: %w", err)
+	}
+
+	results := make([]response.BatchItemResult, len(items))
+	methods := plugin.MethodsFromMetadata(pluginRecord.Metadata)
+
+	// Items that fail validation or that policies deny are answered without
+	// reaching the plugin
+	calls := make([]plugin.BatchCall, 0, len(items))
+	callIndexes := make([]int, 0, len(items))
+	for i, item := range items {
+		results[i] = response.BatchItemResult{Index: i, Method: item.Method}
+
+		if item.Method == "" {
+			results[i].Error = batchItemError(errors.ErrPluginInvalidParams, fmt.Errorf("method is required"))
+			continue
+		}
+		params, err := plugin.BuildCallParams(pluginRecord, methods, item.Method, item.Params)
+		if err != nil {
+			results[i].Error = batchItemError(errors.ErrPluginInvalidParams, err)
+			continue
+		}
+		if decision := policies.Decide(ctx, item.Method, params.AsMap()); !decision.Allowed {
+			results[i].Error = batchItemError(errors.ErrPluginCallDenied, &policy.DeniedError{Decision: decision})
+			continue
+		}
+
+		calls = append(calls, plugin.BatchCall{Method: item.Method, Params: params})
+		callIndexes = append(callIndexes, i)
+	}
+
+	if len(calls) > 0 {
+		now := time.Now().Unix()
+		repo.UpdateLastUsedAt(id, now)
+	}
+
+	callContext := func(ctx context.Context) (context.Context, context.CancelFunc) {
+		return s.manager.CallContext(ctx, id)
+	}
+	outcomes := plugin.ExecuteBatch(ctx, pluginClient, calls, s.batchConcurrency, callContext)
+	for j, outcome := range outcomes {
+		result := &results[callIndexes[j]]
+
+		switch {
+		case outcome.CtxErr == context.DeadlineExceeded:
+			result.Error = batchItemError(errors.ErrPluginCallTimeout, outcome.Err)
+		case outcome.CtxErr == context.Canceled:
+			result.Error = batchItemError(errors.ErrPluginCallCanceled, outcome.Err)
+		case outcome.Err != nil:
+			result.Error = batchItemError(errors.ErrPluginCallFailed, fmt.Errorf("plugin execution failed: %w", outcome.Err))
+		case !outcome.Response.Success:
+			errMsg := "unknown error"
+			if outcome.Response.Error != nil {
+				errMsg = *outcome.Response.Error
+			}
+			result.Error = batchItemError(errors.ErrPluginCallFailed, fmt.Errorf("plugin returned error: %s", errMsg))
+		default:
+			result.Success = true
+			if outcome.Response.Result != nil {
+				result.Result = outcome.Response.Result.AsInterface()
+			}
+		}
+	}
+
+	resp := &response.BatchCallResponse{Results: results}
+	for _, result := range results {
+		if result.Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+	return resp, nil
+}
+
+// batchItemError describes err with the code and message of kind. The shared
+// AppError values are only read, never modified, since batch items are
+// resolved concurrently.
+func batchItemError(kind *errors.AppError, err error) *response.BatchItemError {
+	return &response.BatchItemError{
+		ErrorCode: kind.ErrorCode,
+		Message:   kind.Message,
+		Details:   err.Error(),
+	}
+}
+
+// StreamPlugin runs a streaming method: input is forwarded to the plugin as it
+// is read and the plugin's output is written to output as it arrives. Query
+// parameters are typed and validated against the method descriptor.
+func (s *pluginService) StreamPlugin(ctx context.Context, id uint, method string, query url.Values, input io.Reader, output common.StreamWriter) error {
+	repo := s.repo.WithContext(ctx)
+	pluginRecord, err := repo.FindByID(id)
+	if err != nil {
+		return errors.ErrPluginNotFound.WithInternal(err)
+	}
+
+	if pluginRecord.Status != models.PluginStatusActive {
+		return fmt.Errorf("plugin is not active")
+	}
+
+	params, err := validateStreamParams(pluginRecord, method, query)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorizeCall(ctx, pluginRecord, method, params); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	repo.UpdateLastUsedAt(id, now)
+
+	clientInterface, err := s.manager.GetPluginClient(id)
+	if err != nil {
+		return fmt.Errorf("failed to get plugin client: %w", err)
+	}
+
+	pluginClient, ok := clientInterface.(common.StreamingPluginInterface)
+	if !ok {
+		return errors.ErrPluginStreamUnsupported.WithDetails("plugin client does not implement common.StreamingPluginInterface")
+	}
+
+	callCtx, cancel := s.manager.CallContext(ctx, id)
+	defer cancel()
+
+	if err := pluginClient.ExecuteStream(callCtx, method, params, input, output); err != nil {
+		if plugin.IsStreamingUnsupported(err) {
+			return errors.ErrPluginStreamUnsupported.WithDetails(fmt.Sprintf("plugin '%s' does not support streaming", pluginRecord.Name)).WithInternal(err)
+		}
+		switch callCtx.Err() {
+		case context.DeadlineExceeded:
+			return errors.ErrPluginCallTimeout.WithInternal(err)
+		case context.Canceled:
+			return errors.ErrPluginCallCanceled.WithInternal(err)
+		}
+		return fmt.Errorf("plugin execution failed: %w", err)
+	}
+
+	return nil
+}
+
+// authorizeCall checks the plugin policies for a call about to be made with
+// its final params, denied calls failing with PLUGIN_CALL_DENIED
+func (s *pluginService) authorizeCall(ctx context.Context, record *models.Plugin, method string, params *structpb.Struct) error {
+	err := s.policies.Authorize(ctx, record, method, params.AsMap())
+	if policy.IsDenied(err) {
+		return errors.ErrPluginCallDenied.WithDetails(err.Error()).WithInternal(err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check plugin policies: %w", err)
+	}
+	return nil
+}
+
+// validateStreamParams types and validates the query parameters of a streaming
+// call. Plugins without method descriptors get the raw query strings.
+func validateStreamParams(record *models.Plugin, method string, query url.Values) (*structpb.Struct, error) {
+	methods := plugin.MethodsFromMetadata(record.Metadata)
+	if len(methods) == 0 {
+		params := make(map[string]any, len(query))
+		for name := range query {
+			params[name] = query.Get(name)
+		}
+		return structpb.NewStruct(params)
+	}
+
+	descriptor, ok := plugin.FindMethod(methods, method)
+	if !ok {
+		return nil, errors.ErrPluginInvalidParams.WithDetails(fmt.Sprintf("plugin '%s' has no method '%s'", record.Name, method))
+	}
+	if descriptor.StreamInput == "" {
+		return nil, errors.ErrPluginStreamUnsupported.WithDetails(fmt.Sprintf("method '%s' of plugin '%s' does not accept streamed input", method, record.Name))
+	}
+
+	params, err := descriptor.ParamsFromQuery(query)
+	if err != nil {
+		return nil, errors.ErrPluginInvalidParams.WithDetails(err.Error()).WithInternal(err)
+	}
+	params, err = descriptor.ValidateStreamParams(params)
+	if err != nil {
+		return nil, errors.ErrPluginInvalidParams.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	wireParams, err := structpb.NewStruct(params)
+	if err != nil {
+		return nil, errors.ErrPluginInvalidParams.WithDetails(err.Error()).WithInternal(err)
+	}
+	return wireParams, nil
+}
+
+// validateCallParams checks the call against the method descriptors recorded
+// in the plugin's metadata and converts the parameters to their wire form
+func validateCallParams(record *models.Plugin, req *request.CallPluginRequest) (*structpb.Struct, error) {
+	params, err := plugin.BuildCallParams(record, plugin.MethodsFromMetadata(record.Metadata), req.Method, req.Params)
+	if err != nil {
+		return nil, errors.ErrPluginInvalidParams.WithDetails(err.Error()).WithInternal(err)
+	}
+	return params, nil
+}
+
+func (s *pluginService) ScanPlugins(ctx context.Context) (*plugin.ScanResult, error) {
+	result, err := s.discoverer.Scan(ctx)
+	if err != nil {
+		return nil, errors.ErrPluginScanFailed.WithInternal(err)
+	}
+	return result, nil
+}
+
+func (s *pluginService) OpenAPIDocument() (*openapi.Document, error) {
+	plugins, err := s.repo.FindAll(map[string]any{
+		"status": models.PluginStatusActive,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find active plugins: %w", err)
 	}
 
-	return *result.Result, nil
+	return openapi.Build(plugins), nil
 }
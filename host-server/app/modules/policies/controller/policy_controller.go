@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	_ "github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/request"
+	_ "github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/response"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/service"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/policy"
+)
+
+type PolicyController interface {
+	CreatePolicy(c echo.Context) error
+	ListPolicies(c echo.Context) error
+	GetPolicy(c echo.Context) error
+	UpdatePolicy(c echo.Context) error
+	DeletePolicy(c echo.Context) error
+	TestPolicy(c echo.Context) error
+}
+
+type policyController struct {
+	service service.PolicyService
+}
+
+func NewPolicyController(service service.PolicyService) PolicyController {
+	return &policyController{
+		service: service,
+	}
+}
+
+// CreatePolicy godoc
+// @Summary      Create a plugin policy
+// @Description  Allow or deny principals calling plugin methods. Principal, namespace, plugin and method are names or "*" (the default). A policy applies to a call while all of its constraints on the call's parameters hold. A deny policy that applies denies the call; a method that allow policies target can only be called when one of them applies
+// @Tags         Policies
+// @Accept       json
+// @Produce      json
+// @Param        request body request.PolicyRequest true "Policy definition"
+// @Success      201 {object} models.PluginPolicy
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      409 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/policies [post]
+func (ctrl *policyController) CreatePolicy(c echo.Context) error {
+	var req request.PolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request body format").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	record, err := ctrl.service.CreatePolicy(&req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, record)
+}
+
+// ListPolicies godoc
+// @Summary      List plugin policies
+// @Description  Get all plugin policies, ordered by name
+// @Tags         Policies
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} models.PluginPolicy
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/policies [get]
+func (ctrl *policyController) ListPolicies(c echo.Context) error {
+	policies, err := ctrl.service.ListPolicies()
+	if err != nil {
+		return errors.ErrInternalServer.WithDetails("Failed to list policies").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, policies)
+}
+
+// GetPolicy godoc
+// @Summary      Get plugin policy details
+// @Description  Get a plugin policy by ID
+// @Tags         Policies
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Policy ID" minimum(1)
+// @Success      200 {object} models.PluginPolicy
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/policies/{id} [get]
+func (ctrl *policyController) GetPolicy(c echo.Context) error {
+	var req request.PolicyIDRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid policy ID").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	record, err := ctrl.service.GetPolicy(req.ID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrPolicyNotFound.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, record)
+}
+
+// UpdatePolicy godoc
+// @Summary      Update a plugin policy
+// @Description  Replace the definition of a plugin policy. Calls are decided with the new definition from the next one on
+// @Tags         Policies
+// @Accept       json
+// @Produce      json
+// @Param        id      path int                   true "Policy ID" minimum(1)
+// @Param        request body request.PolicyRequest true "Policy definition"
+// @Success      200 {object} models.PluginPolicy
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      409 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/policies/{id} [put]
+func (ctrl *policyController) UpdatePolicy(c echo.Context) error {
+	var id request.PolicyIDRequest
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, &id); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid policy ID").WithInternal(err)
+	}
+
+	if err := c.Validate(&id); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	var req request.PolicyRequest
+	if err := (&echo.DefaultBinder{}).BindBody(c, &req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request body format").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	record, err := ctrl.service.UpdatePolicy(id.ID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, record)
+}
+
+// DeletePolicy godoc
+// @Summary      Delete a plugin policy
+// @Description  Remove a plugin policy
+// @Tags         Policies
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Policy ID" minimum(1)
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      404 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/policies/{id} [delete]
+func (ctrl *policyController) DeletePolicy(c echo.Context) error {
+	var req request.PolicyIDRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid policy ID").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	if err := ctrl.service.DeletePolicy(req.ID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Policy deleted successfully",
+	})
+}
+
+// TestPolicy godoc
+// @Summary      Test plugin policies
+// @Description  Decide whether a principal may call a plugin method with the given parameters, without calling it, and explain the decision with every policy considered. The principal defaults to the caller; testing another principal needs the admin role in all namespaces. Parameters are taken as given, without the defaults of the method descriptor
+// @Tags         Policies
+// @Accept       json
+// @Produce      json
+// @Param        request body request.TestPolicyRequest true "Plugin call to decide on"
+// @Success      200 {object} response.PolicyDecision
+// @Failure      400 {object} errors.AppError
+// @Failure      401 {object} errors.AppError
+// @Failure      403 {object} errors.AppError
+// @Failure      500 {object} errors.AppError
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/policies/test [post]
+func (ctrl *policyController) TestPolicy(c echo.Context) error {
+	var req request.TestPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ErrBadRequest.WithDetails("Invalid request body format").WithInternal(err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return errors.ErrValidationFailed.WithDetails(err.Error()).WithInternal(err)
+	}
+
+	ctx := c.Request().Context()
+	principal := policy.PrincipalName(ctx)
+	if req.Principal != "" && req.Principal != principal {
+		if err := auth.Authorize(ctx, auth.AllNamespaces, auth.RoleAdmin); err != nil {
+			return err
+		}
+		principal = req.Principal
+	}
+
+	decision, err := ctrl.service.TestPolicy(ctx, principal, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return appErr
+		}
+		return errors.ErrInternalServer.WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, decision)
+}
@@ -0,0 +1,17 @@
+package policies
+
+import (
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/controller"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/repository"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/service"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/policy"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Options(
+	fx.Provide(NewRoute),
+	fx.Provide(repository.NewPolicyRepository),
+	fx.Provide(policy.NewEnforcer),
+	fx.Provide(service.NewPolicyService),
+	fx.Provide(controller.NewPolicyController),
+)
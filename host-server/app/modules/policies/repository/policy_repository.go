@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"gorm.io/gorm"
+)
+
+type PolicyRepository interface {
+	Create(policy *models.PluginPolicy) error
+	FindByID(id uint) (*models.PluginPolicy, error)
+	FindByName(name string) (*models.PluginPolicy, error)
+	FindAll() ([]*models.PluginPolicy, error)
+	// FindForPlugin returns the policies that may apply to calls of a plugin,
+	// those naming its namespace and name or "*", in creation order
+	FindForPlugin(namespace, plugin string) ([]*models.PluginPolicy, error)
+	Update(policy *models.PluginPolicy) error
+	Delete(id uint) error
+
+	// WithContext returns the repository running its queries with ctx, so
+	// they are traced as part of the request
+	WithContext(ctx context.Context) PolicyRepository
+}
+
+type policyRepository struct {
+	db *gorm.DB
+}
+
+func NewPolicyRepository(db *gorm.DB) PolicyRepository {
+	return &policyRepository{
+		db: db,
+	}
+}
+
+func (r *policyRepository) WithContext(ctx context.Context) PolicyRepository {
+	return &policyRepository{
+		db: r.db.WithContext(ctx),
+	}
+}
+
+func (r *policyRepository) Create(policy *models.PluginPolicy) error {
+	if err := r.db.Create(policy).Error; err != nil {
+		return fmt.Errorf("failed to create policy: %w", err)
+	}
+	return nil
+}
+
+func (r *policyRepository) FindByID(id uint) (*models.PluginPolicy, error) {
+	var policy models.PluginPolicy
+	if err := r.db.First(&policy, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("policy not found")
+		}
+		return nil, fmt.Errorf("failed to find policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (r *policyRepository) FindByName(name string) (*models.PluginPolicy, error) {
+	var policy models.PluginPolicy
+	if err := r.db.Where("name = ?", name).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Not found is not an error
+		}
+		return nil, fmt.Errorf("failed to find policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (r *policyRepository) FindAll() ([]*models.PluginPolicy, error) {
+	var policies []*models.PluginPolicy
+	if err := r.db.Order("name").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to find policies: %w", err)
+	}
+	return policies, nil
+}
+
+func (r *policyRepository) FindForPlugin(namespace, plugin string) ([]*models.PluginPolicy, error) {
+	var policies []*models.PluginPolicy
+	err := r.db.
+		Where("namespace IN ? AND plugin IN ?", []string{namespace, models.PolicyWildcard}, []string{plugin, models.PolicyWildcard}).
+		Order("id").
+		Find(&policies).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find policies: %w", err)
+	}
+	return policies, nil
+}
+
+func (r *policyRepository) Update(policy *models.PluginPolicy) error {
+	if err := r.db.Save(policy).Error; err != nil {
+		return fmt.Errorf("failed to update policy: %w", err)
+	}
+	return nil
+}
+
+func (r *policyRepository) Delete(id uint) error {
+	if err := r.db.Delete(&models.PluginPolicy{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package request
+
+import "github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+
+// PolicyRequest defines a plugin policy. Principal, namespace, plugin and
+// method default to "*", which matches any.
+type PolicyRequest struct {
+	Name        string                    `json:"name" validate:"required,max=100"`
+	Description string                    `json:"description"`
+	Effect      string                    `json:"effect" validate:"required,oneof=allow deny"`
+	Principal   string                    `json:"principal" validate:"max=100"`
+	Namespace   string                    `json:"namespace" validate:"max=100"`
+	Plugin      string                    `json:"plugin" validate:"max=100"`
+	Method      string                    `json:"method" validate:"max=100"`
+	Constraints []models.PolicyConstraint `json:"constraints"` // The policy only applies while all of them hold
+}
+
+type PolicyIDRequest struct {
+	ID uint `param:"id" validate:"required,gt=0"`
+}
+
+// TestPolicyRequest is a plugin call to decide on without making it
+type TestPolicyRequest struct {
+	Principal string         `json:"principal"` // Defaults to the caller; testing others needs the admin role
+	Namespace string         `json:"namespace" validate:"required"`
+	Plugin    string         `json:"plugin" validate:"required"`
+	Method    string         `json:"method" validate:"required"`
+	Params    map[string]any `json:"params"`
+}
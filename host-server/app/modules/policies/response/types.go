@@ -0,0 +1,19 @@
+package response
+
+// PolicyDecision is whether a call would be allowed, and why
+type PolicyDecision struct {
+	Allowed     bool               `json:"allowed"`
+	Principal   string             `json:"principal"`
+	Reason      string             `json:"reason"`
+	PolicyID    uint               `json:"policy_id,omitempty"` // The policy that decided; unset when no policy applied
+	Evaluations []PolicyEvaluation `json:"evaluations"`
+}
+
+// PolicyEvaluation explains whether one policy applies to the call
+type PolicyEvaluation struct {
+	PolicyID uint   `json:"policy_id"`
+	Name     string `json:"name"`
+	Effect   string `json:"effect"`
+	Applies  bool   `json:"applies"`
+	Reason   string `json:"reason"` // Which part of the policy does not match, or which constraints do not hold
+}
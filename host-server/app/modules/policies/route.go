@@ -0,0 +1,38 @@
+package policies
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/controller"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
+)
+
+type Route struct {
+	app        *echo.Echo
+	controller controller.PolicyController
+}
+
+func NewRoute(
+	app *echo.Echo,
+	controller controller.PolicyController,
+) *Route {
+	return &Route{
+		app:        app,
+		controller: controller,
+	}
+}
+
+func (r *Route) Register() {
+	api := r.app.Group("/api/policies")
+
+	// Policies may apply to plugins of any namespace
+	viewer := auth.Require(auth.RoleViewer, auth.Everywhere)
+	admin := auth.Require(auth.RoleAdmin, auth.Everywhere)
+
+	api.POST("", r.controller.CreatePolicy, admin)
+	api.GET("", r.controller.ListPolicies, viewer)
+	// Principals may test their own calls; testing others' is checked by the handler
+	api.POST("/test", r.controller.TestPolicy, auth.RequireAny(auth.RoleViewer))
+	api.GET("/:id", r.controller.GetPolicy, viewer)
+	api.PUT("/:id", r.controller.UpdatePolicy, admin)
+	api.DELETE("/:id", r.controller.DeletePolicy, admin)
+}
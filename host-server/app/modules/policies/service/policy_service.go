@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/repository"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/request"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/response"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/policy"
+)
+
+type PolicyService interface {
+	CreatePolicy(req *request.PolicyRequest) (*models.PluginPolicy, error)
+	ListPolicies() ([]*models.PluginPolicy, error)
+	GetPolicy(id uint) (*models.PluginPolicy, error)
+	UpdatePolicy(id uint, req *request.PolicyRequest) (*models.PluginPolicy, error)
+	DeletePolicy(id uint) error
+	TestPolicy(ctx context.Context, principal string, req *request.TestPolicyRequest) (*response.PolicyDecision, error)
+}
+
+type policyService struct {
+	repo     repository.PolicyRepository
+	enforcer *policy.Enforcer
+}
+
+func NewPolicyService(repo repository.PolicyRepository, enforcer *policy.Enforcer) PolicyService {
+	return &policyService{
+		repo:     repo,
+		enforcer: enforcer,
+	}
+}
+
+func (s *policyService) CreatePolicy(req *request.PolicyRequest) (*models.PluginPolicy, error) {
+	record := &models.PluginPolicy{}
+	if err := applyPolicyRequest(record, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindByName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.ErrPolicyAlreadyExists.WithDetails("A policy named '" + req.Name + "' already exists")
+	}
+
+	if err := s.repo.Create(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (s *policyService) ListPolicies() ([]*models.PluginPolicy, error) {
+	return s.repo.FindAll()
+}
+
+func (s *policyService) GetPolicy(id uint) (*models.PluginPolicy, error) {
+	record, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.ErrPolicyNotFound.WithInternal(err)
+	}
+	return record, nil
+}
+
+func (s *policyService) UpdatePolicy(id uint, req *request.PolicyRequest) (*models.PluginPolicy, error) {
+	record, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.ErrPolicyNotFound.WithInternal(err)
+	}
+
+	if req.Name != record.Name {
+		existing, err := s.repo.FindByName(req.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, errors.ErrPolicyAlreadyExists.WithDetails("A policy named '" + req.Name + "' already exists")
+		}
+	}
+
+	if err := applyPolicyRequest(record, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (s *policyService) DeletePolicy(id uint) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return errors.ErrPolicyNotFound.WithInternal(err)
+	}
+	return s.repo.Delete(id)
+}
+
+// TestPolicy decides on a call of principal without making it and explains
+// the decision with every policy considered. Params are taken as given,
+// without the defaults the plugin's method descriptor would fill in.
+func (s *policyService) TestPolicy(ctx context.Context, principal string, req *request.TestPolicyRequest) (*response.PolicyDecision, error) {
+	params := req.Params
+	if params == nil {
+		params = map[string]any{}
+	}
+
+	decision, err := s.enforcer.Explain(ctx, policy.Call{
+		Principal: principal,
+		Namespace: req.Namespace,
+		Plugin:    req.Plugin,
+		Method:    req.Method,
+		Params:    params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.PolicyDecision{
+		Allowed:     decision.Allowed,
+		Principal:   principal,
+		Reason:      decision.Reason,
+		Evaluations: make([]response.PolicyEvaluation, len(decision.Evaluations)),
+	}
+	if decision.Policy != nil {
+		resp.PolicyID = decision.Policy.ID
+	}
+	for i, evaluation := range decision.Evaluations {
+		resp.Evaluations[i] = response.PolicyEvaluation{
+			PolicyID: evaluation.Policy.ID,
+			Name:     evaluation.Policy.Name,
+			Effect:   evaluation.Policy.Effect,
+			Applies:  evaluation.Applies,
+			Reason:   evaluation.Reason,
+		}
+	}
+	return resp, nil
+}
+
+// applyPolicyRequest sets the fields of record from req and validates them.
+// Omitted targets match any.
+func applyPolicyRequest(record *models.PluginPolicy, req *request.PolicyRequest) error {
+	orWildcard := func(value string) string {
+		if value == "" {
+			return models.PolicyWildcard
+		}
+		return value
+	}
+
+	record.Name = req.Name
+	record.Description = req.Description
+	record.Effect = req.Effect
+	record.Principal = orWildcard(req.Principal)
+	record.Namespace = orWildcard(req.Namespace)
+	record.Plugin = orWildcard(req.Plugin)
+	record.Method = orWildcard(req.Method)
+	record.Constraints = req.Constraints
+
+	if err := policy.Validate(record); err != nil {
+		return errors.ErrPolicyInvalid.WithDetails(err.Error()).WithInternal(err)
+	}
+	return nil
+}
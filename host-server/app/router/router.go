@@ -1,21 +1,31 @@
 package router
 
 import (
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies"
 )
 
 type Router struct {
-	plugins *plugins.Route
+	plugins   *plugins.Route
+	pipelines *pipelines.Route
+	policies  *policies.Route
 }
 
 func NewRouter(
 	plugins *plugins.Route,
+	pipelines *pipelines.Route,
+	policies *policies.Route,
 ) *Router {
 	return &Router{
-		plugins: plugins,
+		plugins:   plugins,
+		pipelines: pipelines,
+		policies:  policies,
 	}
 }
 
 func (r *Router) Register() {
 	r.plugins.Register()
+	r.pipelines.Register()
+	r.policies.Register()
 }
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"log/slog"
 	"time"
 
 	"github.com/wylu1037/polyglot-plugin-host-server/app/database"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/pipelines"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/router"
 	"github.com/wylu1037/polyglot-plugin-host-server/config"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
 	"github.com/wylu1037/polyglot-plugin-host-server/internal/bootstrap"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/logging"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/metrics"
 	"github.com/wylu1037/polyglot-plugin-host-server/internal/plugin"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/tracing"
 	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
 
 	_ "github.com/wylu1037/polyglot-plugin-host-server/docs"
 )
@@ -26,18 +34,45 @@ import (
 // @host      localhost:8080
 // @BasePath  /
 // @schemes http https
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Static API key configured under auth.api_keys
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT signed with a key of the configured JWKS file, sent as "Bearer <token>"
 // @tag.name plugins
 // @tag.description Plugin management operations
+// @tag.name pipelines
+// @tag.description Server-side pipelines of plugin method calls
+// @tag.name policies
+// @tag.description Per-method authorization policies for plugin calls
 func main() {
+	// Sandboxed plugins are started through the host binary, which isolates
+	// itself and then executes the plugin
+	if plugin.SandboxInit() {
+		return
+	}
+
 	app := fx.New(
 		fx.StartTimeout(2*time.Minute),
+		fx.WithLogger(func(logger *slog.Logger) fxevent.Logger {
+			return &fxevent.SlogLogger{Logger: logger.With("component", "fx")}
+		}),
 		fx.Supply(""),
 		fx.Provide(config.Load),
+		fx.Provide(logging.New),
+		fx.Provide(metrics.New),
+		fx.Provide(tracing.New),
+		fx.Provide(auth.New),
 		fx.Provide(database.NewDatabase),
 		fx.Provide(router.NewRouter),
 		fx.Provide(bootstrap.NewEchoApp),
 		plugin.Module,
 		plugins.Module,
+		pipelines.Module,
+		policies.Module,
 		fx.Invoke(database.AutoMigrate),
 		fx.Invoke(bootstrap.Start),
 	)
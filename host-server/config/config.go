@@ -14,6 +14,8 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Plugin   PluginConfig   `mapstructure:"plugin"`
 	Log      LogConfig      `mapstructure:"log"`
+	Tracing  TracingConfig  `mapstructure:"tracing"`
+	Auth     AuthConfig     `mapstructure:"auth"`
 }
 
 // ServerConfig holds server-related configuration
@@ -43,19 +45,99 @@ type DatabaseConfig struct {
 
 // PluginConfig holds plugin-related configuration
 type PluginConfig struct {
-	Dir              string        `mapstructure:"dir"`
-	Protocol         string        `mapstructure:"protocol"` // "grpc" or "netrpc"
-	HandshakeTimeout time.Duration `mapstructure:"handshake_timeout"`
-	StartupTimeout   time.Duration `mapstructure:"startup_timeout"`
-	DownloadTimeout  time.Duration `mapstructure:"download_timeout"`
-	AutoLoad         []string      `mapstructure:"auto_load"` // Plugin names to load on startup
+	Dir              string                       `mapstructure:"dir"`
+	Protocol         string                       `mapstructure:"protocol"` // "grpc" or "netrpc"
+	HandshakeTimeout time.Duration                `mapstructure:"handshake_timeout"`
+	StartupTimeout   time.Duration                `mapstructure:"startup_timeout"`
+	DownloadTimeout  time.Duration                `mapstructure:"download_timeout"`
+	CallTimeout      time.Duration                `mapstructure:"call_timeout"`      // Per-call deadline for plugin method invocations
+	BatchConcurrency int                          `mapstructure:"batch_concurrency"` // Calls of one batch request run in parallel against a plugin
+	MaxBatchSize     int                          `mapstructure:"max_batch_size"`    // Maximum number of calls in one batch request
+	AutoLoad         []string                     `mapstructure:"auto_load"`         // Plugin names to load on startup
+	ScanOnStartup    bool                         `mapstructure:"scan_on_startup"`   // Register binaries found under dir on startup
+	Supervisor       SupervisorConfig             `mapstructure:"supervisor"`
+	Pool             PoolConfig                   `mapstructure:"pool"`
+	Lifecycle        LifecycleConfig              `mapstructure:"lifecycle"`
+	CgroupRoot       string                       `mapstructure:"cgroup_root"`     // cgroup v2 directory for plugins with resource limits (Linux)
+	Sandbox          map[string]SandboxConfig     `mapstructure:"sandbox"`         // Sandbox policy per namespace; "*" applies to all others
+	TrustedKeys      map[string][]string          `mapstructure:"trusted_keys"`    // Base64 ed25519 publisher keys per namespace
+	Secrets          map[string]map[string]string `mapstructure:"secrets"`         // Secrets plugins look up through host services, per plugin as namespace/name
+	HostCalls        map[string][]string          `mapstructure:"host_calls"`      // Namespaces plugins may call through host services besides their own, per namespace
+	LogBufferSize    int                          `mapstructure:"log_buffer_size"` // Recent log entries kept in memory per plugin
+}
+
+// SupervisorConfig holds plugin health supervision and restart configuration
+type SupervisorConfig struct {
+	HealthInterval time.Duration `mapstructure:"health_interval"` // Interval between health probes
+	HealthTimeout  time.Duration `mapstructure:"health_timeout"`  // Timeout for a single health probe
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // Delay before the first restart attempt
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`     // Maximum delay between restart attempts
+	MaxRestarts    int           `mapstructure:"max_restarts"`    // Restarts allowed within restart_window
+	RestartWindow  time.Duration `mapstructure:"restart_window"`  // Window the restart budget applies to
+}
+
+// PoolConfig holds the default process pool sizing of loaded plugins.
+// A plugin can override it with a "pool" object in its config.
+type PoolConfig struct {
+	MinSize     int           `mapstructure:"min_size"`     // Processes kept running per loaded plugin
+	MaxSize     int           `mapstructure:"max_size"`     // Processes a plugin may grow to under concurrent load
+	Strategy    string        `mapstructure:"strategy"`     // least-loaded or round-robin
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"` // Idle time after which processes above min_size are stopped
+}
+
+// LifecycleConfig holds when the processes of active plugins run.
+// A plugin can override it with a "lifecycle" object in its config.
+type LifecycleConfig struct {
+	OnDemand     bool          `mapstructure:"on_demand"`     // Start plugins on their first call instead of at boot
+	IdleShutdown time.Duration `mapstructure:"idle_shutdown"` // Stop plugins not called for this long; 0 keeps them running
+}
+
+// SandboxConfig holds how the plugins of one namespace are isolated from the
+// host (Linux only)
+type SandboxConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`         // Run the namespace's plugins in a sandbox
+	AllowNetwork   bool     `mapstructure:"allow_network"`   // Share the host network instead of an empty network namespace
+	AllowEnv       []string `mapstructure:"allow_env"`       // Host environment variables passed to the plugin
+	DisableSeccomp bool     `mapstructure:"disable_seccomp"` // Allow all syscalls instead of the seccomp allowlist
 }
 
 // LogConfig holds logging configuration
 type LogConfig struct {
 	Level  string `mapstructure:"level"`  // debug, info, warn, error
 	Format string `mapstructure:"format"` // json, console
-	Output string `mapstructure:"output"` // stdout, file path
+	Output string `mapstructure:"output"` // stdout, stderr, file path
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Exporter    string  `mapstructure:"exporter"`     // none, otlp, stdout
+	Endpoint    string  `mapstructure:"endpoint"`     // OTLP/gRPC collector address, host:port
+	Insecure    bool    `mapstructure:"insecure"`     // Connect to the collector without TLS
+	ServiceName string  `mapstructure:"service_name"` // service.name of the host's spans
+	SampleRatio float64 `mapstructure:"sample_ratio"` // Fraction of new traces recorded; incoming sampling decisions are kept
+}
+
+// AuthConfig holds authentication of API requests and the roles of the
+// principals they authenticate
+type AuthConfig struct {
+	Enabled bool           `mapstructure:"enabled"`  // Require credentials on /api (the default); when off, requests act as an anonymous admin
+	APIKeys []APIKeyConfig `mapstructure:"api_keys"` // Static keys, sent in the X-API-Key header
+	JWT     JWTConfig      `mapstructure:"jwt"`      // Bearer tokens, sent in the Authorization header
+}
+
+// APIKeyConfig is a static API key and the roles of the principal it authenticates
+type APIKeyConfig struct {
+	Name  string            `mapstructure:"name"`  // Principal name
+	Key   string            `mapstructure:"key"`   // The key, or env:NAME to read it from the host environment
+	Roles map[string]string `mapstructure:"roles"` // viewer, operator or admin per namespace; "*" applies to all namespaces
+}
+
+// JWTConfig holds how bearer tokens are verified
+type JWTConfig struct {
+	JWKSFile   string `mapstructure:"jwks_file"`   // Local JWKS file with the public keys tokens are signed with; empty disables JWT
+	Issuer     string `mapstructure:"issuer"`      // Required iss claim, if set
+	Audience   string `mapstructure:"audience"`    // Required aud claim, if set
+	RolesClaim string `mapstructure:"roles_claim"` // Claim mapping namespaces to roles, like api_keys[].roles
 }
 
 // Load loads configuration from file and environment variables
@@ -72,6 +154,8 @@ func Load(configPath string) (*Config, error) {
 		v.AddConfigPath("./config")
 	}
 
+	setDefaults(v)
+
 	// Enable environment variable reading
 	v.SetEnvPrefix("PLUGIN_HOST")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -97,6 +181,60 @@ func Load(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// setDefaults registers fallback values used when neither the config file
+// nor the environment provides a key
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.read_timeout", 30*time.Second)
+	v.SetDefault("server.write_timeout", 30*time.Second)
+	v.SetDefault("server.shutdown_timeout", 10*time.Second)
+
+	v.SetDefault("database.host", "localhost")
+	v.SetDefault("database.port", 5432)
+	v.SetDefault("database.user", "postgres")
+	v.SetDefault("database.database", "plugin_host")
+	v.SetDefault("database.ssl_mode", "disable")
+	v.SetDefault("database.log_level", "warn")
+
+	v.SetDefault("plugin.dir", "./bin/plugins")
+	v.SetDefault("plugin.protocol", "netrpc")
+	v.SetDefault("plugin.handshake_timeout", 10*time.Second)
+	v.SetDefault("plugin.startup_timeout", 30*time.Second)
+	v.SetDefault("plugin.download_timeout", 5*time.Minute)
+	v.SetDefault("plugin.call_timeout", 30*time.Second)
+	v.SetDefault("plugin.batch_concurrency", 8)
+	v.SetDefault("plugin.max_batch_size", 1000)
+	v.SetDefault("plugin.scan_on_startup", true)
+	v.SetDefault("plugin.supervisor.health_interval", 10*time.Second)
+	v.SetDefault("plugin.supervisor.health_timeout", 3*time.Second)
+	v.SetDefault("plugin.supervisor.initial_backoff", time.Second)
+	v.SetDefault("plugin.supervisor.max_backoff", 30*time.Second)
+	v.SetDefault("plugin.supervisor.max_restarts", 5)
+	v.SetDefault("plugin.supervisor.restart_window", 10*time.Minute)
+	v.SetDefault("plugin.pool.min_size", 1)
+	v.SetDefault("plugin.pool.max_size", 1)
+	v.SetDefault("plugin.pool.strategy", "least-loaded")
+	v.SetDefault("plugin.pool.idle_timeout", 5*time.Minute)
+	v.SetDefault("plugin.lifecycle.on_demand", false)
+	v.SetDefault("plugin.lifecycle.idle_shutdown", time.Duration(0))
+	v.SetDefault("plugin.cgroup_root", "/sys/fs/cgroup/polyglot-plugins")
+	v.SetDefault("plugin.log_buffer_size", 1000)
+
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.format", "console")
+	v.SetDefault("log.output", "stdout")
+
+	v.SetDefault("tracing.exporter", "none")
+	v.SetDefault("tracing.endpoint", "localhost:4317")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.service_name", "polyglot-plugin-host")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+
+	v.SetDefault("auth.enabled", true)
+	v.SetDefault("auth.jwt.roles_claim", "roles")
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	// Validate server config
@@ -133,6 +271,18 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid plugin protocol: %s (must be 'grpc' or 'netrpc')", c.Plugin.Protocol)
 	}
 
+	// Validate plugin pool
+	if c.Plugin.Pool.Strategy != "" && c.Plugin.Pool.Strategy != "least-loaded" && c.Plugin.Pool.Strategy != "round-robin" {
+		return fmt.Errorf("invalid plugin pool strategy: %s (must be 'least-loaded' or 'round-robin')", c.Plugin.Pool.Strategy)
+	}
+	if c.Plugin.Pool.MaxSize > 0 && c.Plugin.Pool.MaxSize < c.Plugin.Pool.MinSize {
+		return fmt.Errorf("invalid plugin pool: max_size %d is below min_size %d", c.Plugin.Pool.MaxSize, c.Plugin.Pool.MinSize)
+	}
+
+	if c.Plugin.Lifecycle.IdleShutdown < 0 {
+		return fmt.Errorf("invalid plugin lifecycle idle_shutdown: %s", c.Plugin.Lifecycle.IdleShutdown)
+	}
+
 	// Validate log level
 	validLogLevels := map[string]bool{
 		"debug": true,
@@ -143,6 +293,27 @@ func (c *Config) Validate() error {
 	if !validLogLevels[c.Log.Level] {
 		return fmt.Errorf("invalid log level: %s", c.Log.Level)
 	}
+	if c.Log.Format != "" && c.Log.Format != "json" && c.Log.Format != "console" {
+		return fmt.Errorf("invalid log format: %s (must be 'json' or 'console')", c.Log.Format)
+	}
+
+	// Validate tracing
+	if c.Tracing.Exporter != "" && c.Tracing.Exporter != "none" && c.Tracing.Exporter != "otlp" && c.Tracing.Exporter != "stdout" {
+		return fmt.Errorf("invalid tracing exporter: %s (must be 'none', 'otlp' or 'stdout')", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("invalid tracing sample_ratio: %v (must be between 0 and 1)", c.Tracing.SampleRatio)
+	}
+
+	// Validate auth
+	if c.Auth.Enabled && len(c.Auth.APIKeys) == 0 && c.Auth.JWT.JWKSFile == "" {
+		return fmt.Errorf("auth is enabled but neither api_keys nor jwt.jwks_file is configured (set auth.enabled to false to run without authentication)")
+	}
+	for i, key := range c.Auth.APIKeys {
+		if key.Name == "" || key.Key == "" {
+			return fmt.Errorf("invalid auth api_keys[%d]: name and key are required", i)
+		}
+	}
 
 	return nil
 }
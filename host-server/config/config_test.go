@@ -6,11 +6,21 @@ import (
 	"time"
 )
 
-func TestLoad_Defaults(t *testing.T) {
+// loadWithoutAuth loads the default config with auth turned off, since auth
+// is on by default and the defaults configure no credentials
+func loadWithoutAuth(t *testing.T) *Config {
+	t.Helper()
+	t.Setenv("PLUGIN_HOST_AUTH_ENABLED", "false")
+
 	cfg, err := Load("")
 	if err != nil {
 		t.Fatalf("Failed to load config: %v", err)
 	}
+	return cfg
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg := loadWithoutAuth(t)
 
 	// Check server defaults
 	if cfg.Server.Host != "0.0.0.0" {
@@ -25,6 +35,10 @@ func TestLoad_Defaults(t *testing.T) {
 		t.Errorf("Expected plugin protocol 'netrpc', got '%s'", cfg.Plugin.Protocol)
 	}
 
+	if cfg.Plugin.BatchConcurrency != 8 {
+		t.Errorf("Expected plugin batch concurrency 8, got %d", cfg.Plugin.BatchConcurrency)
+	}
+
 	// Check log defaults
 	if cfg.Log.Level != "info" {
 		t.Errorf("Expected log level 'info', got '%s'", cfg.Log.Level)
@@ -36,10 +50,7 @@ func TestLoad_EnvOverride(t *testing.T) {
 	os.Setenv("PLUGIN_HOST_SERVER_PORT", "9090")
 	defer os.Unsetenv("PLUGIN_HOST_SERVER_PORT")
 
-	cfg, err := Load("")
-	if err != nil {
-		t.Fatalf("Failed to load config: %v", err)
-	}
+	cfg := loadWithoutAuth(t)
 
 	if cfg.Server.Port != 9090 {
 		t.Errorf("Expected server port 9090 from env var, got %d", cfg.Server.Port)
@@ -70,6 +81,50 @@ func TestValidate_InvalidProtocol(t *testing.T) {
 	}
 }
 
+func TestValidate_Tracing(t *testing.T) {
+	cfg := loadWithoutAuth(t)
+	if cfg.Tracing.Exporter != "none" || cfg.Tracing.SampleRatio != 1 {
+		t.Errorf("Expected tracing disabled with every trace sampled, got %+v", cfg.Tracing)
+	}
+
+	cfg.Tracing.Exporter = "jaeger"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for unknown tracing exporter, got nil")
+	}
+
+	cfg.Tracing.Exporter = "otlp"
+	cfg.Tracing.SampleRatio = 1.5
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for sample ratio above 1, got nil")
+	}
+}
+
+func TestValidate_Auth(t *testing.T) {
+	if _, err := Load(""); err == nil {
+		t.Error("Expected the default config to fail validation without auth credentials, got nil")
+	}
+
+	cfg := loadWithoutAuth(t)
+	if cfg.Auth.JWT.RolesClaim != "roles" {
+		t.Errorf("Expected the roles claim by default, got %+v", cfg.Auth)
+	}
+
+	cfg.Auth.Enabled = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for auth without credentials, got nil")
+	}
+
+	cfg.Auth.APIKeys = []APIKeyConfig{{Name: "ci"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected validation error for API key without key, got nil")
+	}
+
+	cfg.Auth.APIKeys[0].Key = "env:CI_API_KEY"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got error: %v", err)
+	}
+}
+
 func TestGetServerAddr(t *testing.T) {
 	cfg := &Config{
 		Server: ServerConfig{
@@ -85,10 +140,7 @@ func TestGetServerAddr(t *testing.T) {
 }
 
 func TestTimeoutDefaults(t *testing.T) {
-	cfg, err := Load("")
-	if err != nil {
-		t.Fatalf("Failed to load config: %v", err)
-	}
+	cfg := loadWithoutAuth(t)
 
 	if cfg.Server.ReadTimeout != 30*time.Second {
 		t.Errorf("Expected read timeout 30s, got %v", cfg.Server.ReadTimeout)
@@ -24,9 +24,17 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/plugins": {
+        "/api/pipelines": {
             "get": {
-                "description": "Get a list of all installed plugins with optional filters",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all pipelines, ordered by name",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,69 +42,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Plugins"
-                ],
-                "summary": "List all plugins",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Filter by namespace",
-                        "name": "namespace",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by plugin type",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "active",
-                            "inactive",
-                            "disabled",
-                            "error",
-                            "installing"
-                        ],
-                        "type": "string",
-                        "description": "Filter by plugin status",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "linux",
-                            "darwin",
-                            "windows"
-                        ],
-                        "type": "string",
-                        "description": "Filter by operating system",
-                        "name": "os",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "amd64",
-                            "arm64"
-                        ],
-                        "type": "string",
-                        "description": "Filter by architecture",
-                        "name": "arch",
-                        "in": "query"
-                    }
+                    "Pipelines"
                 ],
+                "summary": "List pipelines",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Plugin"
+                                "$ref": "#/definitions/models.Pipeline"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/errors.AppError"
                         }
@@ -108,11 +74,17 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/plugins/install": {
+            },
             "post": {
-                "description": "Install a plugin from a download URL",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a pipeline of plugin method calls. Step inputs map parameters to the pipeline input (input.<path>) or to the result of an earlier step (steps.<name>.<path>); they are checked when the pipeline is saved",
                 "consumes": [
                     "application/json"
                 ],
@@ -120,17 +92,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Plugins"
+                    "Pipelines"
                 ],
-                "summary": "Install a new plugin",
+                "summary": "Create a pipeline",
                 "parameters": [
                     {
-                        "description": "Plugin installation request",
+                        "description": "Pipeline definition",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.InstallPluginRequest"
+                            "$ref": "#/definitions/request.PipelineRequest"
                         }
                     }
                 ],
@@ -138,7 +110,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Plugin"
+                            "$ref": "#/definitions/models.Pipeline"
                         }
                     },
                     "400": {
@@ -147,6 +119,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/errors.AppError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -156,9 +146,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/plugins/{id}": {
+        "/api/pipelines/{id}": {
             "get": {
-                "description": "Get detailed information about a specific plugin by ID",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a pipeline and its steps by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -166,14 +164,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Plugins"
+                    "Pipelines"
                 ],
-                "summary": "Get plugin details",
+                "summary": "Get pipeline details",
                 "parameters": [
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "Plugin ID",
+                        "description": "Pipeline ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -183,7 +181,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Plugin"
+                            "$ref": "#/definitions/models.Pipeline"
                         }
                     },
                     "400": {
@@ -192,6 +190,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/errors.AppError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -200,8 +210,16 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "Remove a plugin from the system",
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the name, description and steps of a pipeline",
                 "consumes": [
                     "application/json"
                 ],
@@ -209,27 +227,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Plugins"
+                    "Pipelines"
                 ],
-                "summary": "Uninstall a plugin",
+                "summary": "Update a pipeline",
                 "parameters": [
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "Plugin ID",
+                        "description": "Pipeline ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Pipeline definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.PipelineRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Pipeline"
                         }
                     },
                     "400": {
@@ -238,6 +262,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/errors.AppError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -245,11 +293,17 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/plugins/{id}/activate": {
-            "post": {
-                "description": "Activate a previously installed plugin",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a pipeline",
                 "consumes": [
                     "application/json"
                 ],
@@ -257,14 +311,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Plugins"
+                    "Pipelines"
                 ],
-                "summary": "Activate a plugin",
+                "summary": "Delete a pipeline",
                 "parameters": [
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "Plugin ID",
+                        "description": "Pipeline ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -286,6 +340,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/errors.AppError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -295,9 +367,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/plugins/{id}/call": {
+        "/api/pipelines/{id}/run": {
             "post": {
-                "description": "Execute a specific method on an active plugin",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Run the steps of a pipeline in order inside the host. A failed step aborts the run unless its on_error is continue, in which case only the steps reading its result are skipped; steps with retries are retried first. Every step reports its own outcome. With dry_run, no plugin is called: every step is resolved to an active plugin and its parameters are mapped and validated as far as they are known before the run",
                 "consumes": [
                     "application/json"
                 ],
@@ -305,25 +385,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Plugins"
+                    "Pipelines"
                 ],
-                "summary": "Call a plugin method",
+                "summary": "Run a pipeline",
                 "parameters": [
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "Plugin ID",
+                        "description": "Pipeline ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Plugin call request",
+                        "description": "Pipeline input",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.CallPluginRequest"
+                            "$ref": "#/definitions/request.RunPipelineRequest"
                         }
                     }
                 ],
@@ -331,8 +411,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/response.PipelineRunResponse"
                         }
                     },
                     "400": {
@@ -341,6 +420,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/errors.AppError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -350,9 +447,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/plugins/{id}/deactivate": {
-            "post": {
-                "description": "Deactivate an active plugin",
+        "/api/plugins": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all installed plugins with optional filters. Active plugins also report whether they are running or idle. Only plugins of namespaces the caller may view are listed",
                 "consumes": [
                     "application/json"
                 ],
@@ -362,78 +467,3119 @@ const docTemplate = `{
                 "tags": [
                     "Plugins"
                 ],
-                "summary": "Deactivate a plugin",
+                "summary": "List all plugins",
                 "parameters": [
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Plugin ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                        "type": "string",
+                        "description": "Filter by namespace",
+                        "name": "namespace",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/errors.AppError"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Filter by plugin type",
+                        "name": "type",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/errors.AppError"
-                        }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "errors.AppError": {
-            "type": "object",
-            "properties": {
-                "details": {
-                    "description": "Additional details",
-                    "type": "string"
+                    {
+                        "enum": [
+                            "active",
+                            "inactive",
+                            "disabled",
+                            "error",
+                            "installing",
+                            "limit_exceeded"
+                        ],
+                        "type": "string",
+                        "description": "Filter by plugin status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "linux",
+                            "darwin",
+                            "windows"
+                        ],
+                        "type": "string",
+                        "description": "Filter by operating system",
+                        "name": "os",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "amd64",
+                            "arm64"
+                        ],
+                        "type": "string",
+                        "description": "Filter by architecture",
+                        "name": "arch",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.PluginInfo"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/by-name/{namespace}/{name}/aliases": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the latest and stable channels and the pinned aliases of a plugin, with the version each one selects now",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "List plugin version aliases",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plugin namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Plugin name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.PluginAlias"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/by-name/{namespace}/{name}/aliases/{alias}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pin an alias of a plugin to one of its installed versions, so calls selecting the alias keep reaching that version across upgrades. Pinning latest or stable overrides the channel. Aliases cannot look like versions or version ranges",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Pin a plugin version alias",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plugin namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Plugin name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Alias name",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Version to pin the alias to",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetPluginAliasRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PluginAlias"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a pinned alias of a plugin. Unpinned latest and stable go back to following the active versions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Unpin a plugin version alias",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plugin namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Plugin name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Alias name",
+                        "name": "alias",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/by-name/{namespace}/{name}/call": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Execute a method on the active version of a plugin that the version selector picks: a pinned alias, the latest or stable channel, an exact version, or a semver range, which selects the highest matching version. Without a selector the latest version is called. The version that served the call is returned in the X-Plugin-Version and X-Plugin-ID headers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Call a plugin method by plugin name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plugin namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Plugin name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version selector: alias, latest, stable, exact version or semver range",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Plugin call request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CallPluginByNameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {},
+                        "headers": {
+                            "X-Plugin-ID": {
+                                "type": "integer",
+                                "description": "ID of the plugin that served the call"
+                            },
+                            "X-Plugin-Version": {
+                                "type": "string",
+                                "description": "Version that served the call"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/install": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Install a plugin from a download URL. The binary must match the given SHA-256 checksum and, for namespaces with trusted publisher keys, carry a valid ed25519 signature. Dependencies declared in metadata.dependencies must be well-formed and must not form a cycle; they need not be installed until activation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Install a new plugin",
+                "parameters": [
+                    {
+                        "description": "Plugin installation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.InstallPluginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Plugin"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/openapi.json": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "OpenAPI 3 document with one operation per method of every active plugin, generated from the method descriptors the plugins advertise",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "OpenAPI document of plugin methods",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/openapi.Document"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/scan": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Walk the plugin directory for binaries laid out as {namespace}/{type}/{name}/{version}/{os}_{arch}/plugin, read their metadata and register any that are not known yet. Active plugins are left untouched",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Scan the plugin directory",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/plugin.ScanResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get detailed information about a specific plugin by ID. Active plugins also report whether they are running or idle, and running plugins the state of their process pool",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Get plugin details",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.PluginInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a plugin from the system. Fails with PLUGIN_IN_USE while active plugins depend on it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Uninstall a plugin",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}/activate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Activate a previously installed plugin. Plugin dependencies declared in its metadata are resolved against the installed plugins and inactive ones are activated first; activation fails with PLUGIN_DEPENDENCY_UNMET, listing every unmet dependency, when some are not installed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Activate a plugin",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Execute an array of method calls on an active plugin in one request. Calls run in parallel up to the configured batch concurrency; each result reports its own success or error and results are returned in request order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Call plugin methods in a batch",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Method calls",
+                        "name": "items",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.BatchCallItem"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.BatchCallResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}/call": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Execute a specific method on an active plugin. Parameters keep their JSON types and are validated against the method's declared parameter schema; the result is returned as JSON",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Call a plugin method",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Plugin call request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CallPluginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}/config": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Merge the body into the plugin's config, removing keys set to null. The settings are validated against the config schema the plugin declares and sent to its running processes, which use them as defaults for call parameters. The pool, lifecycle and limits keys configure the host and cannot be changed here",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Update plugin config",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Config changes",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {}
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Plugin"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}/deactivate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deactivate an active plugin. Fails with PLUGIN_IN_USE while active plugins depend on it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Deactivate a plugin",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}/logs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Recent log entries of a plugin, oldest first: what its processes wrote to stdout (info) and stderr (hclog JSON lines at their own level, other lines at debug), and what the host logged about starting and stopping them. Entries are kept in memory, up to plugin.log_buffer_size per plugin, across restarts and upgrades. With follow=true the response is a server-sent event stream with one entry per event, the recent ones first, that stays open until the client disconnects",
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Get plugin logs",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Number of recent entries; 0 returns all kept",
+                        "name": "tail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream new entries as server-sent events",
+                        "name": "follow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/plugin.LogEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}/methods/{method}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Execute a method on an active plugin with the request body as its parameters. This is the route the generated plugin method operations point at",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Call a plugin method by path",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Method name",
+                        "name": "method",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Method parameters",
+                        "name": "params",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}/methods/{method}/stream": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Execute a streaming method on an active plugin. The request body is streamed to the plugin as the method's stream input parameter and the output is streamed back with chunked transfer encoding as it is produced. Other parameters are passed in the query string and typed by the method descriptor. Errors after the output has started are reported in the X-Plugin-Error trailer",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Stream a plugin method",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Method name",
+                        "name": "method",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Stream input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Method output, in the content type set by the plugin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/plugins/{id}/upgrade": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace an installed plugin with another version of it, keeping its ID, config and pinned aliases. The config body is merged into the current config as with PATCH /api/plugins/{id}/config; metadata replaces the current metadata, which is kept when omitted. The new version is downloaded, verified like an install, started and health-checked, and its settings are validated against the config schema it declares while the current version keeps serving. For an active plugin, calls are then switched over to the new version in one step and calls in flight finish on the current version before its processes are stopped. If the new version fails any of these checks, it is removed and the current version stays in place",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Plugins"
+                ],
+                "summary": "Upgrade a plugin",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Plugin ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Plugin upgrade request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.UpgradePluginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.PluginUpgrade"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/policies": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all plugin policies, ordered by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "List plugin policies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PluginPolicy"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allow or deny principals calling plugin methods. Principal, namespace, plugin and method are names or \"*\" (the default). A policy applies to a call while all of its constraints on the call's parameters hold. A deny policy that applies denies the call; a method that allow policies target can only be called when one of them applies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Create a plugin policy",
+                "parameters": [
+                    {
+                        "description": "Policy definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.PolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.PluginPolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/policies/test": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Decide whether a principal may call a plugin method with the given parameters, without calling it, and explain the decision with every policy considered. The principal defaults to the caller; testing another principal needs the admin role in all namespaces. Parameters are taken as given, without the defaults of the method descriptor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Test plugin policies",
+                "parameters": [
+                    {
+                        "description": "Plugin call to decide on",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.TestPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.PolicyDecision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/policies/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a plugin policy by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Get plugin policy details",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PluginPolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the definition of a plugin policy. Calls are decided with the new definition from the next one on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Update a plugin policy",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Policy definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.PolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PluginPolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a plugin policy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Delete a plugin policy",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "errors.AppError": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "description": "Additional details",
+                    "type": "string"
+                },
+                "errorCode": {
+                    "description": "Machine-readable error code",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Human-readable error message",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Request path",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "Always false for errors",
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "description": "Unix timestamp",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.JSONMap": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "models.Pipeline": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PipelineStep"
+                    }
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PipelineStep": {
+            "type": "object",
+            "properties": {
+                "encode": {
+                    "description": "参数 -> 编码方式，目前仅支持 json",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "inputs": {
+                    "description": "参数路径 -> 来源路径",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "method": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "步骤名称，在流水线内唯一",
+                    "type": "string"
+                },
+                "namespace": {
+                    "description": "插件命名空间，默认 default",
+                    "type": "string"
+                },
+                "on_error": {
+                    "description": "abort（默认）或 continue",
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "plugin": {
+                    "description": "插件名称，调用其最新的激活版本",
+                    "type": "string"
+                },
+                "retries": {
+                    "description": "失败后的重试次数",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Plugin": {
+            "type": "object",
+            "properties": {
+                "arch": {
+                    "description": "架构",
+                    "type": "string"
+                },
+                "binary_path": {
+                    "type": "string"
+                },
+                "checksum": {
+                    "description": "二进制文件的 SHA-256（十六进制）",
+                    "type": "string"
+                },
+                "config": {
+                    "$ref": "#/definitions/models.JSONMap"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "description": "结构化元数据，存储 PluginMetadata",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.JSONMap"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "description": "命名空间",
+                    "type": "string"
+                },
+                "os": {
+                    "description": "操作系统",
+                    "type": "string"
+                },
+                "protocol": {
+                    "$ref": "#/definitions/models.PluginProtocol"
+                },
+                "protocol_version": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.PluginStatus"
+                },
+                "status_reason": {
+                    "description": "最近一次进入当前状态的原因（如崩溃信息）",
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/models.PluginType"
+                },
+                "updated_at": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PluginAlias": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PluginPolicy": {
+            "type": "object",
+            "properties": {
+                "constraints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PolicyConstraint"
+                    }
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "effect": {
+                    "description": "allow or deny",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "plugin": {
+                    "type": "string"
+                },
+                "principal": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PluginProtocol": {
+            "type": "string",
+            "enum": [
+                "grpc",
+                "net-rpc"
+            ],
+            "x-enum-comments": {
+                "PluginProtocolGRPC": "gRPC 协议",
+                "PluginProtocolNetRPC": "net/rpc 协议"
+            },
+            "x-enum-descriptions": [
+                "gRPC 协议",
+                "net/rpc 协议"
+            ],
+            "x-enum-varnames": [
+                "PluginProtocolGRPC",
+                "PluginProtocolNetRPC"
+            ]
+        },
+        "models.PluginStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "inactive",
+                "disabled",
+                "error",
+                "installing",
+                "limit_exceeded"
+            ],
+            "x-enum-comments": {
+                "PluginStatusActive": "激活",
+                "PluginStatusDisabled": "已禁用",
+                "PluginStatusError": "错误状态",
+                "PluginStatusInactive": "未激活",
+                "PluginStatusInstalling": "安装中",
+                "PluginStatusLimitExceeded": "超出资源限制"
+            },
+            "x-enum-descriptions": [
+                "激活",
+                "未激活",
+                "已禁用",
+                "错误状态",
+                "安装中",
+                "超出资源限制"
+            ],
+            "x-enum-varnames": [
+                "PluginStatusActive",
+                "PluginStatusInactive",
+                "PluginStatusDisabled",
+                "PluginStatusError",
+                "PluginStatusInstalling",
+                "PluginStatusLimitExceeded"
+            ]
+        },
+        "models.PluginType": {
+            "type": "string",
+            "enum": [
+                "data-processing",
+                "security",
+                "integration",
+                "extension"
+            ],
+            "x-enum-comments": {
+                "PluginTypeDataProcessing": "数据处理：脱敏、转换、匿名化等",
+                "PluginTypeExtension": "扩展：通用扩展功能",
+                "PluginTypeIntegration": "集成：外部系统对接",
+                "PluginTypeSecurity": "安全：加密、验证等"
+            },
+            "x-enum-descriptions": [
+                "数据处理：脱敏、转换、匿名化等",
+                "安全：加密、验证等",
+                "集成：外部系统对接",
+                "扩展：通用扩展功能"
+            ],
+            "x-enum-varnames": [
+                "PluginTypeDataProcessing",
+                "PluginTypeSecurity",
+                "PluginTypeIntegration",
+                "PluginTypeExtension"
+            ]
+        },
+        "models.PolicyConstraint": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "description": "eq, ne, lt, lte, gt, gte, in or not_in",
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                },
+                "value": {}
+            }
+        },
+        "openapi.Components": {
+            "type": "object",
+            "properties": {
+                "schemas": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/openapi.Schema"
+                    }
+                },
+                "securitySchemes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/openapi.SecurityScheme"
+                    }
+                }
+            }
+        },
+        "openapi.Document": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "$ref": "#/definitions/openapi.Components"
+                },
+                "info": {
+                    "$ref": "#/definitions/openapi.Info"
+                },
+                "openapi": {
+                    "type": "string"
+                },
+                "paths": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/openapi.PathItem"
+                    }
+                },
+                "security": {
+                    "description": "Credentials every operation accepts",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/openapi.SecurityRequirement"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/openapi.Tag"
+                    }
+                }
+            }
+        },
+        "openapi.Example": {
+            "type": "object",
+            "properties": {
+                "summary": {
+                    "type": "string"
+                },
+                "value": {}
+            }
+        },
+        "openapi.Header": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "schema": {
+                    "$ref": "#/definitions/openapi.Schema"
+                }
+            }
+        },
+        "openapi.Info": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "openapi.MediaType": {
+            "type": "object",
+            "properties": {
+                "examples": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/openapi.Example"
+                    }
+                },
+                "schema": {
+                    "$ref": "#/definitions/openapi.Schema"
+                }
+            }
+        },
+        "openapi.Operation": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "operationId": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/openapi.Parameter"
+                    }
+                },
+                "requestBody": {
+                    "$ref": "#/definitions/openapi.RequestBody"
+                },
+                "responses": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/openapi.Response"
+                    }
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "openapi.Parameter": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "in": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "schema": {
+                    "$ref": "#/definitions/openapi.Schema"
+                }
+            }
+        },
+        "openapi.PathItem": {
+            "type": "object",
+            "properties": {
+                "post": {
+                    "$ref": "#/definitions/openapi.Operation"
+                }
+            }
+        },
+        "openapi.RequestBody": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/openapi.MediaType"
+                    }
+                },
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "openapi.Response": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/openapi.MediaType"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/openapi.Header"
+                    }
+                }
+            }
+        },
+        "openapi.Schema": {
+            "type": "object",
+            "properties": {
+                "$ref": {
+                    "type": "string"
+                },
+                "additionalProperties": {},
+                "default": {},
+                "description": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "items": {
+                    "$ref": "#/definitions/openapi.Schema"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/openapi.Schema"
+                    }
+                },
+                "required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "openapi.SecurityRequirement": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "array",
+                "items": {
+                    "type": "string"
+                }
+            }
+        },
+        "openapi.SecurityScheme": {
+            "type": "object",
+            "properties": {
+                "bearerFormat": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "in": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scheme": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "openapi.Tag": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "plugin.LogEntry": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "level": {
+                    "description": "trace, debug, info, warn or error",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Plugin version whose process logged the entry",
+                    "type": "string"
+                }
+            }
+        },
+        "plugin.PoolStats": {
+            "type": "object",
+            "properties": {
+                "in_flight": {
+                    "type": "integer"
+                },
+                "max_size": {
+                    "type": "integer"
+                },
+                "min_size": {
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "Running processes",
+                    "type": "integer"
+                },
+                "starting": {
+                    "description": "Processes being started to absorb load",
+                    "type": "integer"
+                },
+                "strategy": {
+                    "type": "string"
+                },
+                "workers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/plugin.WorkerStats"
+                    }
+                }
+            }
+        },
+        "plugin.ScanIssue": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "plugin.ScanResult": {
+            "type": "object",
+            "properties": {
+                "registered": {
+                    "description": "Newly discovered plugins",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Plugin"
+                    }
+                },
+                "skipped": {
+                    "description": "Binaries that could not be registered",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/plugin.ScanIssue"
+                    }
+                },
+                "unchanged": {
+                    "description": "Known plugins with nothing to update",
+                    "type": "integer"
+                },
+                "updated": {
+                    "description": "Known plugins whose record was refreshed",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Plugin"
+                    }
+                }
+            }
+        },
+        "plugin.WorkerStats": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer"
+                },
+                "in_flight": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "integer"
+                },
+                "pid": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "request.BatchCallItem": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "description": "方法名",
+                    "type": "string"
+                },
+                "params": {
+                    "description": "方法参数",
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "request.CallPluginByNameRequest": {
+            "type": "object",
+            "required": [
+                "method",
+                "name",
+                "namespace",
+                "params"
+            ],
+            "properties": {
+                "method": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "version": {
+                    "description": "版本选择器：别名、latest、stable、精确版本或语义化版本范围，默认 latest",
+                    "type": "string"
+                }
+            }
+        },
+        "request.CallPluginRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "method",
+                "params"
+            ],
+            "properties": {
+                "id": {
+                    "description": "Plugin ID from path parameter",
+                    "type": "integer"
+                },
+                "method": {
+                    "description": "Method name from request body",
+                    "type": "string"
+                },
+                "params": {
+                    "description": "Method parameters from request body",
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "request.InstallPluginRequest": {
+            "type": "object",
+            "required": [
+                "arch",
+                "checksum",
+                "downloadURL",
+                "name",
+                "namespace",
+                "os",
+                "type",
+                "version"
+            ],
+            "properties": {
+                "arch": {
+                    "description": "新增：架构",
+                    "type": "string",
+                    "enum": [
+                        "amd64",
+                        "arm64"
+                    ]
+                },
+                "checksum": {
+                    "description": "二进制文件的 SHA-256（十六进制）",
+                    "type": "string"
+                },
+                "config": {
+                    "$ref": "#/definitions/models.JSONMap"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "downloadURL": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.JSONMap"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "description": "新增：命名空间",
+                    "type": "string"
+                },
+                "os": {
+                    "description": "新增：操作系统",
+                    "type": "string",
+                    "enum": [
+                        "linux",
+                        "darwin",
+                        "windows"
+                    ]
+                },
+                "signature": {
+                    "description": "可选：对 SHA-256 摘要的 ed25519 签名（base64）",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "修改：从枚举改为 string",
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.PipelineRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "steps"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "steps": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.PipelineStep"
+                    }
+                }
+            }
+        },
+        "request.PolicyRequest": {
+            "type": "object",
+            "required": [
+                "effect",
+                "name"
+            ],
+            "properties": {
+                "constraints": {
+                    "description": "The policy only applies while all of them hold",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PolicyConstraint"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "effect": {
+                    "type": "string",
+                    "enum": [
+                        "allow",
+                        "deny"
+                    ]
+                },
+                "method": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "namespace": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "plugin": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "principal": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "request.RunPipelineRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "dry_run": {
+                    "description": "Resolve and validate every step without calling plugins",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "Pipeline ID from path parameter",
+                    "type": "integer"
+                },
+                "input": {
+                    "description": "Pipeline input, read by step inputs as input.<path>",
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "request.SetPluginAliasRequest": {
+            "type": "object",
+            "required": [
+                "alias",
+                "name",
+                "namespace",
+                "version"
+            ],
+            "properties": {
+                "alias": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "别名固定到的已安装版本",
+                    "type": "string"
+                }
+            }
+        },
+        "request.TestPolicyRequest": {
+            "type": "object",
+            "required": [
+                "method",
+                "namespace",
+                "plugin"
+            ],
+            "properties": {
+                "method": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "plugin": {
+                    "type": "string"
+                },
+                "principal": {
+                    "description": "Defaults to the caller; testing others needs the admin role",
+                    "type": "string"
+                }
+            }
+        },
+        "request.UpgradePluginRequest": {
+            "type": "object",
+            "required": [
+                "checksum",
+                "downloadURL",
+                "id",
+                "version"
+            ],
+            "properties": {
+                "checksum": {
+                    "description": "新版本二进制文件的 SHA-256（十六进制）",
+                    "type": "string"
+                },
+                "config": {
+                    "description": "可选：迁移配置时合并的补丁，值为 null 的键被删除",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "downloadURL": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "description": "可选：新版本的元数据，省略时沿用当前版本的（如 dependencies）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.JSONMap"
+                        }
+                    ]
+                },
+                "signature": {
+                    "description": "可选：对 SHA-256 摘要的 ed25519 签名（base64）",
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.BatchCallResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.BatchItemResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.BatchItemError": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "string"
+                },
+                "errorCode": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/response.BatchItemError"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "result": {},
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.PipelineRunResponse": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "pipeline_id": {
+                    "type": "integer"
+                },
+                "result": {},
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.PipelineStepResult"
+                    }
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.PipelineStepError": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "string"
+                },
+                "errorCode": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.PipelineStepResult": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
                 },
-                "errorCode": {
-                    "description": "Machine-readable error code",
-                    "type": "string"
+                "deferred": {
+                    "description": "Dry run: params that take the result of an earlier step",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "message": {
-                    "description": "Human-readable error message",
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "$ref": "#/definitions/response.PipelineStepError"
+                },
+                "method": {
                     "type": "string"
                 },
-                "path": {
-                    "description": "Request path",
+                "name": {
                     "type": "string"
                 },
-                "success": {
-                    "description": "Always false for errors",
-                    "type": "boolean"
+                "params": {
+                    "description": "Params sent; in a dry run those known before the run",
+                    "type": "object",
+                    "additionalProperties": {}
                 },
-                "timestamp": {
-                    "description": "Unix timestamp",
+                "plugin_id": {
                     "type": "integer"
+                },
+                "reason": {
+                    "description": "Why the step was skipped",
+                    "type": "string"
+                },
+                "result": {},
+                "status": {
+                    "description": "succeeded, failed, skipped, or planned in a dry run",
+                    "type": "string"
                 }
             }
         },
-        "models.JSONMap": {
+        "response.PluginAlias": {
             "type": "object",
-            "additionalProperties": {}
+            "properties": {
+                "active": {
+                    "description": "选中的版本是否已激活",
+                    "type": "boolean"
+                },
+                "alias": {
+                    "type": "string"
+                },
+                "pinned": {
+                    "description": "是否固定到某个版本；未固定的 latest、stable 随激活版本变化",
+                    "type": "boolean"
+                },
+                "plugin_id": {
+                    "description": "当前选中版本的插件 ID",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "当前选中的版本，没有匹配的激活版本时为空",
+                    "type": "string"
+                }
+            }
         },
-        "models.Plugin": {
+        "response.PluginInfo": {
             "type": "object",
             "properties": {
                 "arch": {
@@ -443,6 +3589,10 @@ const docTemplate = `{
                 "binary_path": {
                     "type": "string"
                 },
+                "checksum": {
+                    "description": "二进制文件的 SHA-256（十六进制）",
+                    "type": "string"
+                },
                 "config": {
                     "$ref": "#/definitions/models.JSONMap"
                 },
@@ -480,15 +3630,31 @@ const docTemplate = `{
                     "description": "操作系统",
                     "type": "string"
                 },
+                "pool": {
+                    "description": "进程池状态，仅在插件运行时返回",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/plugin.PoolStats"
+                        }
+                    ]
+                },
                 "protocol": {
                     "$ref": "#/definitions/models.PluginProtocol"
                 },
                 "protocol_version": {
                     "type": "integer"
                 },
+                "runtime_status": {
+                    "description": "运行状态：running、idle 或 restarting，仅在插件已加载时返回",
+                    "type": "string"
+                },
                 "status": {
                     "$ref": "#/definitions/models.PluginStatus"
                 },
+                "status_reason": {
+                    "description": "最近一次进入当前状态的原因（如崩溃信息）",
+                    "type": "string"
+                },
                 "type": {
                     "$ref": "#/definitions/models.PluginType"
                 },
@@ -500,168 +3666,164 @@ const docTemplate = `{
                 }
             }
         },
-        "models.PluginProtocol": {
-            "type": "string",
-            "enum": [
-                "grpc",
-                "net-rpc"
-            ],
-            "x-enum-comments": {
-                "PluginProtocolGRPC": "gRPC 协议",
-                "PluginProtocolNetRPC": "net/rpc 协议"
-            },
-            "x-enum-descriptions": [
-                "gRPC 协议",
-                "net/rpc 协议"
-            ],
-            "x-enum-varnames": [
-                "PluginProtocolGRPC",
-                "PluginProtocolNetRPC"
-            ]
-        },
-        "models.PluginStatus": {
-            "type": "string",
-            "enum": [
-                "active",
-                "inactive",
-                "disabled",
-                "error",
-                "installing"
-            ],
-            "x-enum-comments": {
-                "PluginStatusActive": "激活",
-                "PluginStatusDisabled": "已禁用",
-                "PluginStatusError": "错误状态",
-                "PluginStatusInactive": "未激活",
-                "PluginStatusInstalling": "安装中"
-            },
-            "x-enum-descriptions": [
-                "激活",
-                "未激活",
-                "已禁用",
-                "错误状态",
-                "安装中"
-            ],
-            "x-enum-varnames": [
-                "PluginStatusActive",
-                "PluginStatusInactive",
-                "PluginStatusDisabled",
-                "PluginStatusError",
-                "PluginStatusInstalling"
-            ]
-        },
-        "models.PluginType": {
-            "type": "string",
-            "enum": [
-                "data-processing",
-                "security",
-                "integration",
-                "extension"
-            ],
-            "x-enum-comments": {
-                "PluginTypeDataProcessing": "数据处理：脱敏、转换、匿名化等",
-                "PluginTypeExtension": "扩展：通用扩展功能",
-                "PluginTypeIntegration": "集成：外部系统对接",
-                "PluginTypeSecurity": "安全：加密、验证等"
-            },
-            "x-enum-descriptions": [
-                "数据处理：脱敏、转换、匿名化等",
-                "安全：加密、验证等",
-                "集成：外部系统对接",
-                "扩展：通用扩展功能"
-            ],
-            "x-enum-varnames": [
-                "PluginTypeDataProcessing",
-                "PluginTypeSecurity",
-                "PluginTypeIntegration",
-                "PluginTypeExtension"
-            ]
-        },
-        "request.CallPluginRequest": {
+        "response.PluginUpgrade": {
             "type": "object",
-            "required": [
-                "id",
-                "method",
-                "params"
-            ],
             "properties": {
-                "id": {
-                    "description": "Plugin ID from path parameter",
-                    "type": "integer"
+                "arch": {
+                    "description": "架构",
+                    "type": "string"
                 },
-                "method": {
-                    "description": "Method name from request body",
+                "binary_path": {
                     "type": "string"
                 },
-                "params": {
-                    "description": "Method parameters from request body",
-                    "type": "object",
-                    "additionalProperties": {}
-                }
-            }
-        },
-        "request.InstallPluginRequest": {
-            "type": "object",
-            "required": [
-                "arch",
-                "downloadURL",
-                "name",
-                "namespace",
-                "os",
-                "type",
-                "version"
-            ],
-            "properties": {
-                "arch": {
-                    "description": "新增：架构",
-                    "type": "string",
-                    "enum": [
-                        "amd64",
-                        "arm64"
-                    ]
+                "checksum": {
+                    "description": "二进制文件的 SHA-256（十六进制）",
+                    "type": "string"
                 },
                 "config": {
                     "$ref": "#/definitions/models.JSONMap"
                 },
+                "created_at": {
+                    "type": "integer"
+                },
                 "description": {
                     "type": "string"
                 },
-                "downloadURL": {
+                "download_url": {
                     "type": "string"
                 },
+                "drained": {
+                    "description": "这些调用是否在旧版本进程停止前全部完成",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "in_flight_calls": {
+                    "description": "切换到新版本时仍在旧版本上执行的调用数",
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "integer"
+                },
                 "metadata": {
-                    "$ref": "#/definitions/models.JSONMap"
+                    "description": "结构化元数据，存储 PluginMetadata",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.JSONMap"
+                        }
+                    ]
                 },
                 "name": {
                     "type": "string"
                 },
                 "namespace": {
-                    "description": "新增：命名空间",
+                    "description": "命名空间",
                     "type": "string"
                 },
                 "os": {
-                    "description": "新增：操作系统",
-                    "type": "string",
-                    "enum": [
-                        "linux",
-                        "darwin",
-                        "windows"
-                    ]
+                    "description": "操作系统",
+                    "type": "string"
                 },
-                "type": {
-                    "description": "修改：从枚举改为 string",
+                "previous_version": {
+                    "type": "string"
+                },
+                "protocol": {
+                    "$ref": "#/definitions/models.PluginProtocol"
+                },
+                "protocol_version": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.PluginStatus"
+                },
+                "status_reason": {
+                    "description": "最近一次进入当前状态的原因（如崩溃信息）",
                     "type": "string"
                 },
+                "type": {
+                    "$ref": "#/definitions/models.PluginType"
+                },
+                "updated_at": {
+                    "type": "integer"
+                },
                 "version": {
                     "type": "string"
                 }
             }
+        },
+        "response.PolicyDecision": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "evaluations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.PolicyEvaluation"
+                    }
+                },
+                "policy_id": {
+                    "description": "The policy that decided; unset when no policy applied",
+                    "type": "integer"
+                },
+                "principal": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.PolicyEvaluation": {
+            "type": "object",
+            "properties": {
+                "applies": {
+                    "type": "boolean"
+                },
+                "effect": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "policy_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Which part of the policy does not match, or which constraints do not hold",
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Static API key configured under auth.api_keys",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT signed with a key of the configured JWKS file, sent as \"Bearer <token>\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     },
     "tags": [
         {
             "description": "Plugin management operations",
             "name": "plugins"
+        },
+        {
+            "description": "Server-side pipelines of plugin method calls",
+            "name": "pipelines"
+        },
+        {
+            "description": "Per-method authorization policies for plugin calls",
+            "name": "policies"
         }
     ]
 }`
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/config"
+)
+
+// APIKeyHeader is the request header API keys are sent in
+const APIKeyHeader = "X-API-Key"
+
+// keyEnvPrefix marks keys read from the host environment
+const keyEnvPrefix = "env:"
+
+// errInvalidAPIKey is returned for keys that match no configured key
+var errInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyAuthenticator authenticates requests by a static key in the
+// X-API-Key header
+type APIKeyAuthenticator struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	digest    [sha256.Size]byte // Keys are compared by digest, in constant time
+	principal *Principal
+}
+
+// NewAPIKeyAuthenticator creates an authenticator for the configured keys.
+// Keys configured as "env:NAME" are read from the host environment once.
+func NewAPIKeyAuthenticator(keys []config.APIKeyConfig) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{keys: make([]apiKey, 0, len(keys))}
+	for _, key := range keys {
+		value := key.Key
+		if name, ok := strings.CutPrefix(value, keyEnvPrefix); ok {
+			value = os.Getenv(name)
+			if value == "" {
+				return nil, fmt.Errorf("API key %s: environment variable %s is not set", key.Name, name)
+			}
+		}
+
+		roles, err := parseRoles(key.Roles)
+		if err != nil {
+			return nil, fmt.Errorf("API key %s: %w", key.Name, err)
+		}
+		a.keys = append(a.keys, apiKey{
+			digest:    sha256.Sum256([]byte(value)),
+			principal: &Principal{Name: qualifiedName(MethodAPIKey, key.Name), Method: MethodAPIKey, Roles: roles},
+		})
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	value := r.Header.Get(APIKeyHeader)
+	if value == "" {
+		return nil, ErrNoCredentials
+	}
+
+	digest := sha256.Sum256([]byte(value))
+	var match *Principal
+	for _, key := range a.keys {
+		if subtle.ConstantTimeCompare(digest[:], key.digest[:]) == 1 {
+			match = key.principal
+		}
+	}
+	if match == nil {
+		return nil, errInvalidAPIKey
+	}
+	return match, nil
+}
@@ -0,0 +1,308 @@
+// Package auth authenticates requests to the host API and authorizes them by
+// role. Principals authenticate with a static API key or a JWT verified
+// against a local JWKS file, and hold a role per plugin namespace.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/wylu1037/polyglot-plugin-host-server/config"
+	apperrors "github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
+)
+
+// Role is what a principal may do in a namespace. Each role includes the
+// ones below it.
+type Role int
+
+const (
+	RoleNone     Role = iota
+	RoleViewer        // Read plugins, their logs and aliases, and pipelines
+	RoleOperator      // Also call plugins, run pipelines and activate or deactivate plugins
+	RoleAdmin         // Also install, upgrade, configure and uninstall plugins, pin aliases and manage pipelines
+)
+
+// AllNamespaces grants a role in every namespace. Routes that are not
+// scoped to one namespace, such as scans and pipelines, require it.
+const AllNamespaces = "*"
+
+// Authentication methods of principals. Names of principals are prefixed
+// with their method, so an API key and a JWT subject of the same name are
+// different principals.
+const (
+	MethodAPIKey    = "api_key"
+	MethodJWT       = "jwt"
+	MethodPlugin    = "plugin"
+	MethodAnonymous = "anonymous"
+)
+
+// ParseRole parses a configured role name
+func ParseRole(name string) (Role, error) {
+	switch strings.ToLower(name) {
+	case "viewer":
+		return RoleViewer, nil
+	case "operator":
+		return RoleOperator, nil
+	case "admin":
+		return RoleAdmin, nil
+	default:
+		return RoleNone, fmt.Errorf("unknown role: %s (must be 'viewer', 'operator' or 'admin')", name)
+	}
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// parseRoles parses roles per namespace
+func parseRoles(names map[string]string) (map[string]Role, error) {
+	roles := make(map[string]Role, len(names))
+	for namespace, name := range names {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %w", namespace, err)
+		}
+		roles[namespace] = role
+	}
+	return roles, nil
+}
+
+// Principal is who a request was authenticated as
+type Principal struct {
+	Name   string          `json:"name"`   // The method and the key name, subject or plugin, e.g. api_key:ci
+	Method string          `json:"method"` // api_key, jwt, plugin or anonymous
+	Roles  map[string]Role `json:"-"`      // Role per namespace; AllNamespaces applies to all of them
+}
+
+// qualifiedName is the name of a principal authenticated by method as name
+func qualifiedName(method, name string) string {
+	return method + ":" + name
+}
+
+// PluginPrincipal is the principal of a plugin calling another plugin
+// through host services. It holds no roles.
+func PluginPrincipal(namespace, name string) *Principal {
+	return &Principal{Name: qualifiedName(MethodPlugin, namespace+"/"+name), Method: MethodPlugin}
+}
+
+// Role returns the principal's role in namespace, the higher of the one
+// granted there and the one granted in all namespaces
+func (p *Principal) Role(namespace string) Role {
+	return max(p.Roles[namespace], p.Roles[AllNamespaces])
+}
+
+// Allows reports whether the principal holds at least role in namespace
+func (p *Principal) Allows(namespace string, role Role) bool {
+	return p.Role(namespace) >= role
+}
+
+// AllowsAny reports whether the principal holds at least role in some namespace
+func (p *Principal) AllowsAny(role Role) bool {
+	for _, granted := range p.Roles {
+		if granted >= role {
+			return true
+		}
+	}
+	return false
+}
+
+// anonymous is the principal of every request while authentication is disabled
+var anonymous = &Principal{
+	Name:   "anonymous",
+	Method: MethodAnonymous,
+	Roles:  map[string]Role{AllNamespaces: RoleAdmin},
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal a request's context carries, or nil
+// outside the API
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// ErrNoCredentials is returned by an Authenticator for requests without
+// credentials of its kind, so the next one can try
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator authenticates requests from the credentials they carry.
+// Credentials of its kind that are not valid are an error other than
+// ErrNoCredentials.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Auth authenticates the requests to /api
+type Auth struct {
+	enabled        bool
+	authenticators []Authenticator
+}
+
+// New builds the authenticators the auth config describes
+func New(cfg *config.Config) (*Auth, error) {
+	a := &Auth{enabled: cfg.Auth.Enabled}
+	if !a.enabled {
+		return a, nil
+	}
+
+	if len(cfg.Auth.APIKeys) > 0 {
+		authenticator, err := NewAPIKeyAuthenticator(cfg.Auth.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		a.authenticators = append(a.authenticators, authenticator)
+	}
+	if cfg.Auth.JWT.JWKSFile != "" {
+		authenticator, err := NewJWTAuthenticator(cfg.Auth.JWT)
+		if err != nil {
+			return nil, err
+		}
+		a.authenticators = append(a.authenticators, authenticator)
+	}
+	return a, nil
+}
+
+// Enabled reports whether requests need credentials
+func (a *Auth) Enabled() bool {
+	return a.enabled
+}
+
+// Authenticate tries each authenticator in turn on a request
+func (a *Auth) Authenticate(r *http.Request) (*Principal, error) {
+	if !a.enabled {
+		return anonymous, nil
+	}
+
+	for _, authenticator := range a.authenticators {
+		principal, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// Middleware authenticates every request to /api and puts the principal in
+// its context. Requests without valid credentials are rejected with
+// UNAUTHORIZED before they reach a route.
+func (a *Auth) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !strings.HasPrefix(req.URL.Path, "/api/") {
+				return next(c)
+			}
+
+			principal, err := a.Authenticate(req)
+			if err != nil {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="plugin-host"`)
+				if errors.Is(err, ErrNoCredentials) {
+					return reject(apperrors.ErrUnauthorized, "Missing API key or bearer token")
+				}
+				return reject(apperrors.ErrUnauthorized, "Invalid credentials").WithInternal(err)
+			}
+
+			c.SetRequest(req.WithContext(WithPrincipal(req.Context(), principal)))
+			return next(c)
+		}
+	}
+}
+
+// reject returns a copy of err with details. The package-level AppErrors
+// are shared by all requests, so a rejection must not write to them.
+func reject(err *apperrors.AppError, details string) *apperrors.AppError {
+	rejection := *err
+	rejection.Details = details
+	return &rejection
+}
+
+// NamespaceFunc returns the plugin namespace a request acts on
+type NamespaceFunc func(c echo.Context) (string, error)
+
+// PathNamespace reads the namespace from a path parameter
+func PathNamespace(param string) NamespaceFunc {
+	return func(c echo.Context) (string, error) {
+		return c.Param(param), nil
+	}
+}
+
+// Everywhere is the NamespaceFunc of routes acting on all namespaces
+func Everywhere(c echo.Context) (string, error) {
+	return AllNamespaces, nil
+}
+
+// Authorize returns FORBIDDEN unless the principal in ctx holds at least
+// role in namespace. Handlers use it where the namespace is only known once
+// the request body was read.
+func Authorize(ctx context.Context, namespace string, role Role) error {
+	principal := PrincipalFrom(ctx)
+	if principal == nil {
+		return reject(apperrors.ErrUnauthorized, "Request was not authenticated")
+	}
+	if !principal.Allows(namespace, role) {
+		return reject(apperrors.ErrForbidden,
+			fmt.Sprintf("%s needs the %s role in namespace %s", principal.Name, role, namespace))
+	}
+	return nil
+}
+
+// Require rejects requests whose principal does not hold at least role in
+// the namespace the request acts on with FORBIDDEN
+func Require(role Role, namespace NamespaceFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			if PrincipalFrom(ctx) == nil {
+				return reject(apperrors.ErrUnauthorized, "Request was not authenticated")
+			}
+
+			ns, err := namespace(c)
+			if err != nil {
+				return err
+			}
+			if err := Authorize(ctx, ns, role); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireAny rejects requests whose principal does not hold at least role in
+// any namespace with FORBIDDEN. Handlers of such routes limit what they
+// return to the namespaces the principal may see.
+func RequireAny(role Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := PrincipalFrom(c.Request().Context())
+			if principal == nil {
+				return reject(apperrors.ErrUnauthorized, "Request was not authenticated")
+			}
+			if !principal.AllowsAny(role) {
+				return reject(apperrors.ErrForbidden,
+					fmt.Sprintf("%s needs the %s role in some namespace", principal.Name, role))
+			}
+			return next(c)
+		}
+	}
+}
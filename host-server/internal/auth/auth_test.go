@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/wylu1037/polyglot-plugin-host-server/config"
+	apperrors "github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
+)
+
+func newTestAuth(t *testing.T) *Auth {
+	t.Setenv("OPS_API_KEY", "ops-secret")
+	a, err := New(&config.Config{Auth: config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "ci", Key: "ci-secret", Roles: map[string]string{"acme": "operator"}},
+			{Name: "ops", Key: "env:OPS_API_KEY", Roles: map[string]string{"*": "admin"}},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return a
+}
+
+// serve handles a request to path with the authentication middleware and
+// the given route middleware in front of a handler answering 200
+func serve(a *Auth, path, key string, route ...echo.MiddlewareFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.HTTPErrorHandler = apperrors.APIErrorHandler
+	e.Use(a.Middleware())
+	e.GET("/api/plugins/by-name/:namespace/:name/aliases", func(c echo.Context) error {
+		return c.String(http.StatusOK, PrincipalFrom(c.Request().Context()).Name)
+	}, route...)
+	e.GET("/docs", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if key != "" {
+		req.Header.Set(APIKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	a := newTestAuth(t)
+	operator := Require(RoleOperator, PathNamespace("namespace"))
+
+	tests := []struct {
+		name   string
+		path   string
+		key    string
+		status int
+	}{
+		{"no credentials", "/api/plugins/by-name/acme/tool/aliases", "", http.StatusUnauthorized},
+		{"invalid key", "/api/plugins/by-name/acme/tool/aliases", "guess", http.StatusUnauthorized},
+		{"role in namespace", "/api/plugins/by-name/acme/tool/aliases", "ci-secret", http.StatusOK},
+		{"no role in namespace", "/api/plugins/by-name/other/tool/aliases", "ci-secret", http.StatusForbidden},
+		{"role in all namespaces", "/api/plugins/by-name/other/tool/aliases", "ops-secret", http.StatusOK},
+		{"outside the API", "/docs", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(a, tt.path, tt.key, operator)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+
+	if rec := serve(a, "/api/plugins/by-name/acme/tool/aliases", "ci-secret", Require(RoleAdmin, Everywhere)); rec.Code != http.StatusForbidden {
+		t.Errorf("operator of acme passed an admin route, status = %d", rec.Code)
+	}
+	if rec := serve(a, "/api/plugins/by-name/other/tool/aliases", "ci-secret", RequireAny(RoleViewer)); rec.Code != http.StatusOK {
+		t.Errorf("operator of acme was refused a route needing any viewer role, status = %d", rec.Code)
+	}
+	if rec := serve(a, "/api/plugins/by-name/acme/tool/aliases", "ci-secret"); rec.Body.String() != "api_key:ci" {
+		t.Errorf("principal = %s, want the key name prefixed with api_key:", rec.Body)
+	}
+}
+
+func TestMiddleware_Disabled(t *testing.T) {
+	a, err := New(&config.Config{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	rec := serve(a, "/api/plugins/by-name/acme/tool/aliases", "", Require(RoleAdmin, Everywhere))
+	if rec.Code != http.StatusOK || rec.Body.String() != "anonymous" {
+		t.Errorf("status = %d, body = %s, want the anonymous admin", rec.Code, rec.Body)
+	}
+}
+
+func TestAuthorize_KeepsSharedErrors(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), &Principal{Name: "api_key:ci"})
+
+	var rejection *apperrors.AppError
+	if err := Authorize(ctx, "acme", RoleViewer); !errors.As(err, &rejection) || rejection.HTTPStatus != http.StatusForbidden {
+		t.Fatalf("Authorize() error = %v, want FORBIDDEN", err)
+	}
+	if rejection == apperrors.ErrForbidden || apperrors.ErrForbidden.Details != "" {
+		t.Errorf("Authorize() wrote %q to the shared ErrForbidden", apperrors.ErrForbidden.Details)
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]config.AuthConfig{
+		"unknown role":      {APIKeys: []config.APIKeyConfig{{Name: "ci", Key: "secret", Roles: map[string]string{"acme": "owner"}}}},
+		"unset env var":     {APIKeys: []config.APIKeyConfig{{Name: "ci", Key: "env:POLYGLOT_TEST_UNSET_KEY"}}},
+		"missing JWKS file": {JWT: config.JWTConfig{JWKSFile: "/nonexistent/jwks.json"}},
+	} {
+		cfg.Enabled = true
+		if _, err := New(&config.Config{Auth: cfg}); err == nil {
+			t.Errorf("New() accepted a config with a %s", name)
+		}
+	}
+}
+
+func TestPrincipal_Role(t *testing.T) {
+	p := &Principal{Roles: map[string]Role{"acme": RoleAdmin, AllNamespaces: RoleViewer}}
+
+	if got := p.Role("acme"); got != RoleAdmin {
+		t.Errorf("Role(acme) = %s, want admin", got)
+	}
+	if got := p.Role("other"); got != RoleViewer {
+		t.Errorf("Role(other) = %s, want viewer", got)
+	}
+	if p.Allows("other", RoleOperator) {
+		t.Error("Allows(other, operator) = true for a viewer")
+	}
+	if !p.AllowsAny(RoleAdmin) {
+		t.Error("AllowsAny(admin) = false for an admin of acme")
+	}
+}
@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/wylu1037/polyglot-plugin-host-server/config"
+)
+
+// jwtLeeway is the clock skew tolerated when checking exp, nbf and iat
+const jwtLeeway = 30 * time.Second
+
+// JWTAuthenticator authenticates requests by a bearer token in the
+// Authorization header. Tokens must be signed with a key of the local JWKS
+// file and carry sub and exp claims; the principal is named after sub and
+// takes its roles from the roles claim, an object of roles per namespace.
+// The file is read again when a token names a key it does not hold, so keys
+// can be rotated without restarting the host.
+type JWTAuthenticator struct {
+	file       string
+	rolesClaim string
+	parser     *jwt.Parser
+
+	mu      sync.RWMutex
+	keys    map[string]crypto.PublicKey // by kid
+	modTime time.Time                   // of the file the keys were read from
+}
+
+// NewJWTAuthenticator creates an authenticator for tokens signed with the
+// keys of the configured JWKS file
+func NewJWTAuthenticator(cfg config.JWTConfig) (*JWTAuthenticator, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	a := &JWTAuthenticator{
+		file:       cfg.JWKSFile,
+		rolesClaim: cfg.RolesClaim,
+		parser:     jwt.NewParser(options...),
+	}
+	if a.rolesClaim == "" {
+		a.rolesClaim = "roles"
+	}
+	if err := a.reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := strings.CutPrefix(r.Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !ok || token == "" {
+		return nil, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(token, claims, a.key); err != nil {
+		return nil, err
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, errors.New("token has no sub claim")
+	}
+	roles, err := a.roles(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{Name: qualifiedName(MethodJWT, subject), Method: MethodJWT, Roles: roles}, nil
+}
+
+// roles reads the roles claim of a token
+func (a *JWTAuthenticator) roles(claims jwt.MapClaims) (map[string]Role, error) {
+	raw, exists := claims[a.rolesClaim]
+	if !exists {
+		return map[string]Role{}, nil
+	}
+	granted, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s claim is not an object of roles per namespace", a.rolesClaim)
+	}
+
+	names := make(map[string]string, len(granted))
+	for namespace, role := range granted {
+		name, ok := role.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s claim: role of namespace %s is not a string", a.rolesClaim, namespace)
+		}
+		names[namespace] = name
+	}
+	return parseRoles(names)
+}
+
+// key returns the key a token is signed with, by its kid header. Tokens
+// without kid are accepted while the JWKS holds a single key.
+func (a *JWTAuthenticator) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := a.lookup(kid); ok {
+		return key, nil
+	}
+	if err := a.reload(); err != nil {
+		return nil, err
+	}
+	if key, ok := a.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no key %q in JWKS", kid)
+}
+
+func (a *JWTAuthenticator) lookup(kid string) (crypto.PublicKey, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if kid == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, true
+		}
+	}
+	key, ok := a.keys[kid]
+	return key, ok
+}
+
+// reload reads the JWKS file again if it changed since it was last read
+func (a *JWTAuthenticator) reload() error {
+	info, err := os.Stat(a.file)
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.keys != nil && info.ModTime().Equal(a.modTime) {
+		return nil
+	}
+
+	keys, err := loadJWKS(a.file)
+	if err != nil {
+		return err
+	}
+	a.keys = keys
+	a.modTime = info.ModTime()
+	return nil
+}
+
+// jsonWebKey is a public key of a JWKS (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// loadJWKS reads the signature keys of a JWKS file, by kid. RSA, EC (P-256,
+// P-384, P-521) and Ed25519 keys are supported.
+func loadJWKS(path string) (map[string]crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS %s has no signature keys", path)
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URL(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n: %w", err)
+		}
+		e, err := decodeBase64URL(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid e")
+		}
+		exponent := new(big.Int).SetBytes(e)
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := decodeBase64URL(k.X)
+		y, errY := decodeBase64URL(k.Y)
+		size := (curve.Params().BitSize + 7) / 8
+		if errX != nil || errY != nil || len(x) > size || len(y) > size {
+			return nil, errors.New("invalid x or y")
+		}
+		point := make([]byte, 1+2*size)
+		point[0] = 4 // Uncompressed
+		copy(point[1+size-len(x):1+size], x)
+		copy(point[1+2*size-len(y):], y)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBase64URL(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid x")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/wylu1037/polyglot-plugin-host-server/config"
+)
+
+// writeJWKS writes the public keys to a JWKS file, by kid
+func writeJWKS(t *testing.T, path string, keys map[string]crypto.PublicKey) {
+	t.Helper()
+	encode := base64.RawURLEncoding.EncodeToString
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for kid, key := range keys {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, jsonWebKey{Kty: "RSA", Kid: kid, Use: "sig",
+				N: encode(key.N.Bytes()), E: encode(big.NewInt(int64(key.E)).Bytes())})
+		case *ecdsa.PublicKey:
+			point, err := key.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			size := (len(point) - 1) / 2
+			set.Keys = append(set.Keys, jsonWebKey{Kty: "EC", Kid: kid, Crv: key.Curve.Params().Name,
+				X: encode(point[1 : 1+size]), Y: encode(point[1+size:])})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, jsonWebKey{Kty: "OKP", Kid: kid, Crv: "Ed25519", X: encode(key)})
+		}
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key crypto.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func bearer(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/plugins", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]crypto.PublicKey{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey, "ed": edPublic})
+	a, err := NewJWTAuthenticator(config.JWTConfig{JWKSFile: path, Issuer: "idp", Audience: "plugin-host", RolesClaim: "roles"})
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator() error = %v", err)
+	}
+
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":   "alice",
+			"iss":   "idp",
+			"aud":   "plugin-host",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": map[string]any{"acme": "operator"},
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	for _, tc := range []struct {
+		kid    string
+		method jwt.SigningMethod
+		key    crypto.PrivateKey
+	}{
+		{"rsa", jwt.SigningMethodRS256, rsaKey},
+		{"ec", jwt.SigningMethodES256, ecKey},
+		{"ed", jwt.SigningMethodEdDSA, edKey},
+	} {
+		principal, err := a.Authenticate(bearer(sign(t, tc.method, tc.key, tc.kid, claims(nil))))
+		if err != nil {
+			t.Fatalf("Authenticate() with %s key error = %v", tc.kid, err)
+		}
+		if principal.Name != "jwt:alice" || principal.Method != MethodJWT || principal.Role("acme") != RoleOperator {
+			t.Errorf("Authenticate() with %s key = %+v", tc.kid, principal)
+		}
+	}
+
+	rejected := map[string]string{
+		"expired":      sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"without exp":  sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims(jwt.MapClaims{"exp": nil})),
+		"without sub":  sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims(jwt.MapClaims{"sub": nil})),
+		"other issuer": sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims(jwt.MapClaims{"iss": "other"})),
+		"unknown role": sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims(jwt.MapClaims{"roles": map[string]any{"acme": "owner"}})),
+		"wrong key":    sign(t, jwt.SigningMethodRS256, rsaKey, "ec", claims(nil)),
+		"unknown kid":  sign(t, jwt.SigningMethodRS256, rsaKey, "gone", claims(nil)),
+		"hmac":         sign(t, jwt.SigningMethodHS256, []byte("secret"), "rsa", claims(nil)),
+		"without kid":  sign(t, jwt.SigningMethodRS256, rsaKey, "", claims(nil)), // The JWKS has several keys
+	}
+	for name, token := range rejected {
+		if _, err := a.Authenticate(bearer(token)); err == nil || errors.Is(err, ErrNoCredentials) {
+			t.Errorf("Authenticate() accepted a token %s, error = %v", name, err)
+		}
+	}
+
+	if _, err := a.Authenticate(httptest.NewRequest(http.MethodGet, "/api/plugins", nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Authenticate() without token error = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestJWTAuthenticator_Rotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPublic, newKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]crypto.PublicKey{"old": &oldKey.PublicKey})
+	a, err := NewJWTAuthenticator(config.JWTConfig{JWKSFile: path})
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator() error = %v", err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	// A single key also verifies tokens without kid
+	if _, err := a.Authenticate(bearer(sign(t, jwt.SigningMethodRS256, oldKey, "", jwt.MapClaims{"sub": "ci", "exp": exp}))); err != nil {
+		t.Errorf("Authenticate() without kid error = %v", err)
+	}
+
+	writeJWKS(t, path, map[string]crypto.PublicKey{"new": newPublic})
+	// Make sure the modification time changes on filesystems with coarse timestamps
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Authenticate(bearer(sign(t, jwt.SigningMethodEdDSA, newKey, "new", jwt.MapClaims{"sub": "ci", "exp": exp}))); err != nil {
+		t.Errorf("Authenticate() with rotated key error = %v", err)
+	}
+	if _, err := a.Authenticate(bearer(sign(t, jwt.SigningMethodRS256, oldKey, "old", jwt.MapClaims{"sub": "ci", "exp": exp}))); err == nil {
+		t.Error("Authenticate() accepted a token signed with a removed key")
+	}
+}
@@ -11,21 +11,26 @@ const scalarHTML = `
 <!DOCTYPE html>
 <html>
 <head>
-    <title>Polyglot Plugin Host Server - API Documentation</title>
+    <title>{{.Title}}</title>
     <meta charset="utf-8"/>
     <meta name="viewport" content="width=device-width, initial-scale=1">
 </head>
 <body>
     <script 
         id="api-reference" 
-        data-url="/swagger.json"
-        data-configuration='{"theme":"purple","darkMode":false}'
+        data-url="{{.SpecURL}}"
+        data-configuration='{"theme":"purple","darkMode":false,"authentication":{"preferredSecurityScheme":"ApiKeyAuth"}}'
     ></script>
     <script src="https://cdn.jsdelivr.net/npm/@scalar/api-reference"></script>
 </body>
 </html>
 `
 
+type scalarPage struct {
+	Title   string
+	SpecURL string
+}
+
 func RegisterScalarDocs(e *echo.Echo) {
 	e.GET("/swagger.json", func(c echo.Context) error {
 		return c.File("docs/swagger.json")
@@ -34,13 +39,26 @@ func RegisterScalarDocs(e *echo.Echo) {
 		return c.File("docs/swagger.yaml")
 	})
 
-	e.GET("/docs", func(c echo.Context) error {
-		tmpl, err := template.New("scalar").Parse(scalarHTML)
-		if err != nil {
-			return err
+	tmpl := template.Must(template.New("scalar").Parse(scalarHTML))
+	serveDocs := func(page scalarPage) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return tmpl.Execute(c.Response().Writer, page)
 		}
-		return tmpl.Execute(c.Response().Writer, nil)
-	})
+	}
+
+	e.GET("/docs", serveDocs(scalarPage{
+		Title:   "Polyglot Plugin Host Server - API Documentation",
+		SpecURL: "/swagger.json",
+	}))
+
+	// Generated at request time from the method descriptors of active plugins.
+	// The browser loads the document without credentials, so the plugins
+	// module also serves it outside /api; calls made from the page send the
+	// API key or token entered there.
+	e.GET("/docs/plugins", serveDocs(scalarPage{
+		Title:   "Polyglot Plugin Host Server - Plugin Methods",
+		SpecURL: "/docs/plugins/openapi.json",
+	}))
 
 	// Redirect root to docs
 	e.GET("/", func(c echo.Context) error {
@@ -2,14 +2,19 @@ package bootstrap
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/router"
 	"github.com/wylu1037/polyglot-plugin-host-server/config"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
 	"github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/metrics"
 	"github.com/wylu1037/polyglot-plugin-host-server/internal/validator"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
 
@@ -29,26 +34,96 @@ func Start(lc fx.Lifecycle, p WebServerParams) {
 			// Start server
 			go func() {
 				addr := p.Config.GetServerAddr()
-				fmt.Printf("Starting server on %s\n", addr)
-				if err := p.Echo.Start(addr); err != nil {
-					fmt.Printf("Server error: %v\n", err)
+				slog.Info("Starting server", "addr", addr)
+				if err := p.Echo.Start(addr); err != nil && err != http.ErrServerClosed {
+					slog.Error("Server error", "error", err)
 				}
 			}()
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			fmt.Println("Shutting down server...")
+			slog.Info("Shutting down server")
 			return p.Echo.Shutdown(ctx)
 		},
 	})
 }
 
-func NewEchoApp(config *config.Config) *echo.Echo {
+func NewEchoApp(config *config.Config, logger *slog.Logger, recorder *metrics.Metrics, tracer trace.TracerProvider, authenticator *auth.Auth) *echo.Echo {
 	e := echo.New()
 	e.HideBanner = true
+	e.HidePort = true
 	e.Validator = validator.New()
 	e.HTTPErrorHandler = errors.APIErrorHandler
-	e.Use(middleware.Logger(), middleware.Recover(), middleware.CORS())
+	e.StdLogger = slog.NewLogLogger(logger.Handler(), slog.LevelError)
+	e.Use(
+		requestTracer(config.Tracing.ServiceName, tracer),
+		requestLogger(logger.With("component", "http")),
+		middleware.Recover(),
+		middleware.CORS(),
+		authenticator.Middleware(),
+	)
+	if !authenticator.Enabled() {
+		logger.Warn("Authentication is disabled, every API request is handled as an anonymous admin")
+	}
 	RegisterScalarDocs(e) // Register Scalar API documentation
+	e.GET("/metrics", echo.WrapHandler(recorder.Handler()))
 	return e
 }
+
+// requestTracer records a server span for every request but metrics scrapes,
+// continuing the caller's trace when the request carries a traceparent header.
+// Handlers find the span in the request's context.
+func requestTracer(service string, tracer trace.TracerProvider) echo.MiddlewareFunc {
+	return otelecho.Middleware(service,
+		otelecho.WithTracerProvider(tracer),
+		otelecho.WithSkipper(func(c echo.Context) bool {
+			return c.Path() == "/metrics"
+		}),
+	)
+}
+
+// requestLogger logs every request once it has been handled, at warn level
+// for client errors and error level for server errors
+func requestLogger(logger *slog.Logger) echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogMethod:    true,
+		LogURI:       true,
+		LogStatus:    true,
+		LogLatency:   true,
+		LogRemoteIP:  true,
+		LogRequestID: true,
+		LogError:     true,
+		HandleError:  true, // Let the error handler set the status before it is logged
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			level := slog.LevelInfo
+			switch {
+			case v.Status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case v.Status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", v.Method),
+				slog.String("uri", v.URI),
+				slog.Int("status", v.Status),
+				slog.Duration("latency", v.Latency),
+				slog.String("remote_ip", v.RemoteIP),
+			}
+			if v.RequestID != "" {
+				attrs = append(attrs, slog.String("request_id", v.RequestID))
+			}
+			if principal := auth.PrincipalFrom(c.Request().Context()); principal != nil {
+				attrs = append(attrs, slog.String("principal", principal.Name))
+			}
+			if span := trace.SpanContextFromContext(c.Request().Context()); span.HasTraceID() {
+				attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+			}
+			if v.Error != nil {
+				attrs = append(attrs, slog.String("error", v.Error.Error()))
+			}
+			logger.LogAttrs(c.Request().Context(), level, "request", attrs...)
+			return nil
+		},
+	})
+}
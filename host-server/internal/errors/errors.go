@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -67,16 +68,47 @@ const (
 )
 
 const (
-	ErrCodePluginNotFound         = "PLUGIN_NOT_FOUND"
-	ErrCodePluginAlreadyExists    = "PLUGIN_ALREADY_EXISTS"
-	ErrCodePluginInvalid          = "PLUGIN_INVALID"
-	ErrCodePluginInstallFailed    = "PLUGIN_INSTALL_FAILED"
-	ErrCodePluginActivateFailed   = "PLUGIN_ACTIVATE_FAILED"
-	ErrCodePluginDeactivateFailed = "PLUGIN_DEACTIVATE_FAILED"
-	ErrCodePluginUninstallFailed  = "PLUGIN_UNINSTALL_FAILED"
-	ErrCodePluginCallFailed       = "PLUGIN_CALL_FAILED"
+	ErrCodePluginNotFound             = "PLUGIN_NOT_FOUND"
+	ErrCodePluginAlreadyExists        = "PLUGIN_ALREADY_EXISTS"
+	ErrCodePluginInvalid              = "PLUGIN_INVALID"
+	ErrCodePluginInstallFailed        = "PLUGIN_INSTALL_FAILED"
+	ErrCodePluginActivateFailed       = "PLUGIN_ACTIVATE_FAILED"
+	ErrCodePluginDeactivateFailed     = "PLUGIN_DEACTIVATE_FAILED"
+	ErrCodePluginUninstallFailed      = "PLUGIN_UNINSTALL_FAILED"
+	ErrCodePluginCallFailed           = "PLUGIN_CALL_FAILED"
+	ErrCodePluginCallTimeout          = "PLUGIN_CALL_TIMEOUT"
+	ErrCodePluginCallCanceled         = "PLUGIN_CALL_CANCELED"
+	ErrCodePluginProtocolIncompatible = "PLUGIN_PROTOCOL_INCOMPATIBLE"
+	ErrCodePluginIntegrityFailed      = "PLUGIN_INTEGRITY_FAILED"
+	ErrCodePluginScanFailed           = "PLUGIN_SCAN_FAILED"
+	ErrCodePluginInvalidParams        = "PLUGIN_INVALID_PARAMS"
+	ErrCodePluginStreamUnsupported    = "PLUGIN_STREAM_UNSUPPORTED"
+	ErrCodePluginInvalidConfig        = "PLUGIN_INVALID_CONFIG"
+	ErrCodePluginDependencyUnmet      = "PLUGIN_DEPENDENCY_UNMET"
+	ErrCodePluginDependencyCycle      = "PLUGIN_DEPENDENCY_CYCLE"
+	ErrCodePluginInUse                = "PLUGIN_IN_USE"
+	ErrCodePluginVersionNotFound      = "PLUGIN_VERSION_NOT_FOUND"
+	ErrCodePluginInvalidAlias         = "PLUGIN_INVALID_ALIAS"
+	ErrCodePluginUpgradeFailed        = "PLUGIN_UPGRADE_FAILED"
+	ErrCodePluginCallDenied           = "PLUGIN_CALL_DENIED"
 )
 
+const (
+	ErrCodePipelineNotFound      = "PIPELINE_NOT_FOUND"
+	ErrCodePipelineAlreadyExists = "PIPELINE_ALREADY_EXISTS"
+	ErrCodePipelineInvalid       = "PIPELINE_INVALID"
+)
+
+const (
+	ErrCodePolicyNotFound      = "POLICY_NOT_FOUND"
+	ErrCodePolicyAlreadyExists = "POLICY_ALREADY_EXISTS"
+	ErrCodePolicyInvalid       = "POLICY_INVALID"
+)
+
+// StatusClientClosedRequest is the non-standard status used when the client
+// went away before the response was written (nginx convention).
+const StatusClientClosedRequest = 499
+
 // Predefined errors
 var (
 	ErrBadRequest         = NewAppError(ErrCodeBadRequest, "Bad request", http.StatusBadRequest)
@@ -88,14 +120,37 @@ var (
 	ErrInternalServer     = NewAppError(ErrCodeInternalServer, "Internal server error", http.StatusInternalServerError)
 	ErrServiceUnavailable = NewAppError(ErrCodeServiceUnavailable, "Service unavailable", http.StatusServiceUnavailable)
 
-	ErrPluginNotFound         = NewAppError(ErrCodePluginNotFound, "Plugin not found", http.StatusNotFound)
-	ErrPluginAlreadyExists    = NewAppError(ErrCodePluginAlreadyExists, "Plugin already exists", http.StatusConflict)
-	ErrPluginInvalid          = NewAppError(ErrCodePluginInvalid, "Invalid plugin", http.StatusBadRequest)
-	ErrPluginInstallFailed    = NewAppError(ErrCodePluginInstallFailed, "Failed to install plugin", http.StatusInternalServerError)
-	ErrPluginActivateFailed   = NewAppError(ErrCodePluginActivateFailed, "Failed to activate plugin", http.StatusInternalServerError)
-	ErrPluginDeactivateFailed = NewAppError(ErrCodePluginDeactivateFailed, "Failed to deactivate plugin", http.StatusInternalServerError)
-	ErrPluginUninstallFailed  = NewAppError(ErrCodePluginUninstallFailed, "Failed to uninstall plugin", http.StatusInternalServerError)
-	ErrPluginCallFailed       = NewAppError(ErrCodePluginCallFailed, "Failed to call plugin", http.StatusInternalServerError)
+	ErrPluginNotFound             = NewAppError(ErrCodePluginNotFound, "Plugin not found", http.StatusNotFound)
+	ErrPluginAlreadyExists        = NewAppError(ErrCodePluginAlreadyExists, "Plugin already exists", http.StatusConflict)
+	ErrPluginInvalid              = NewAppError(ErrCodePluginInvalid, "Invalid plugin", http.StatusBadRequest)
+	ErrPluginInstallFailed        = NewAppError(ErrCodePluginInstallFailed, "Failed to install plugin", http.StatusInternalServerError)
+	ErrPluginActivateFailed       = NewAppError(ErrCodePluginActivateFailed, "Failed to activate plugin", http.StatusInternalServerError)
+	ErrPluginDeactivateFailed     = NewAppError(ErrCodePluginDeactivateFailed, "Failed to deactivate plugin", http.StatusInternalServerError)
+	ErrPluginUninstallFailed      = NewAppError(ErrCodePluginUninstallFailed, "Failed to uninstall plugin", http.StatusInternalServerError)
+	ErrPluginCallFailed           = NewAppError(ErrCodePluginCallFailed, "Failed to call plugin", http.StatusInternalServerError)
+	ErrPluginCallTimeout          = NewAppError(ErrCodePluginCallTimeout, "Plugin call timed out", http.StatusGatewayTimeout)
+	ErrPluginCallCanceled         = NewAppError(ErrCodePluginCallCanceled, "Plugin call canceled", StatusClientClosedRequest)
+	ErrPluginProtocolIncompatible = NewAppError(ErrCodePluginProtocolIncompatible, "Plugin protocol version incompatible", http.StatusUnprocessableEntity)
+	ErrPluginIntegrityFailed      = NewAppError(ErrCodePluginIntegrityFailed, "Plugin integrity verification failed", http.StatusUnprocessableEntity)
+	ErrPluginScanFailed           = NewAppError(ErrCodePluginScanFailed, "Failed to scan plugin directory", http.StatusInternalServerError)
+	ErrPluginInvalidParams        = NewAppError(ErrCodePluginInvalidParams, "Invalid plugin call parameters", http.StatusBadRequest)
+	ErrPluginStreamUnsupported    = NewAppError(ErrCodePluginStreamUnsupported, "Plugin method does not support streaming", http.StatusBadRequest)
+	ErrPluginInvalidConfig        = NewAppError(ErrCodePluginInvalidConfig, "Invalid plugin config", http.StatusBadRequest)
+	ErrPluginDependencyUnmet      = NewAppError(ErrCodePluginDependencyUnmet, "Plugin dependencies are not installed", http.StatusUnprocessableEntity)
+	ErrPluginDependencyCycle      = NewAppError(ErrCodePluginDependencyCycle, "Plugin dependencies form a cycle", http.StatusUnprocessableEntity)
+	ErrPluginInUse                = NewAppError(ErrCodePluginInUse, "Plugin is required by active plugins", http.StatusConflict)
+	ErrPluginVersionNotFound      = NewAppError(ErrCodePluginVersionNotFound, "No active plugin version matches", http.StatusNotFound)
+	ErrPluginInvalidAlias         = NewAppError(ErrCodePluginInvalidAlias, "Invalid plugin version alias", http.StatusBadRequest)
+	ErrPluginUpgradeFailed        = NewAppError(ErrCodePluginUpgradeFailed, "Failed to upgrade plugin", http.StatusInternalServerError)
+	ErrPluginCallDenied           = NewAppError(ErrCodePluginCallDenied, "Plugin call denied by policy", http.StatusForbidden)
+
+	ErrPipelineNotFound      = NewAppError(ErrCodePipelineNotFound, "Pipeline not found", http.StatusNotFound)
+	ErrPipelineAlreadyExists = NewAppError(ErrCodePipelineAlreadyExists, "Pipeline already exists", http.StatusConflict)
+	ErrPipelineInvalid       = NewAppError(ErrCodePipelineInvalid, "Invalid pipeline", http.StatusBadRequest)
+
+	ErrPolicyNotFound      = NewAppError(ErrCodePolicyNotFound, "Policy not found", http.StatusNotFound)
+	ErrPolicyAlreadyExists = NewAppError(ErrCodePolicyAlreadyExists, "Policy already exists", http.StatusConflict)
+	ErrPolicyInvalid       = NewAppError(ErrCodePolicyInvalid, "Invalid policy", http.StatusBadRequest)
 )
 
 func APIErrorHandler(err error, c echo.Context) {
@@ -131,10 +186,9 @@ func APIErrorHandler(err error, c echo.Context) {
 		}
 	}
 
-	c.Logger().Error(err)
-
+	// The request logger logs err along with the request
 	if err := c.JSON(appErr.HTTPStatus, appErr); err != nil {
-		c.Logger().Error(err)
+		slog.Error("Failed to write error response", "path", c.Request().URL.Path, "error", err)
 	}
 }
 
@@ -0,0 +1,69 @@
+// Package logging builds the host's structured logger from its log config.
+// Echo, GORM and the processes of plugins all log through it.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/config"
+)
+
+// New builds the logger the log config describes: JSON or text ("console")
+// records at or above its level, written to stdout, stderr or a file. It also
+// becomes slog's default, which packages without an injected logger use.
+func New(cfg *config.Config) (*slog.Logger, error) {
+	output, err := openOutput(cfg.Log.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: ParseLevel(cfg.Log.Level)}
+	var handler slog.Handler
+	if cfg.Log.Format == "json" {
+		handler = slog.NewJSONHandler(output, options)
+	} else {
+		handler = slog.NewTextHandler(output, options)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// ParseLevel maps a configured log level onto slog's; unknown levels are info
+func ParseLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// openOutput opens a log output, appending to files and creating their
+// directory if needed. The file stays open for the life of the process.
+func openOutput(output string) (io.Writer, error) {
+	switch output {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log output: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/config"
+)
+
+func TestNew_JSONFile(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	output := filepath.Join(t.TempDir(), "logs", "host.log")
+	logger, err := New(&config.Config{Log: config.LogConfig{Level: "warn", Format: "json", Output: output}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Info("dropped")
+	logger.Warn("kept", "plugin_id", 7)
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read log output: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("log output has %d lines, want only the warning: %s", len(lines), data)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("log output is not JSON: %v", err)
+	}
+	if record["msg"] != "kept" || record["level"] != "WARN" || record["plugin_id"] != float64(7) {
+		t.Errorf("log record = %v", record)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+		"":      slog.LevelInfo,
+	}
+	for level, want := range tests {
+		if got := ParseLevel(level); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Package metrics holds the Prometheus collectors of the host, served on
+// /metrics. Plugins are labelled "namespace/name" with their version.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics is the registry of the host and the collectors it records into
+type Metrics struct {
+	registry *prometheus.Registry
+
+	PluginCalls        *prometheus.CounterVec   // plugin, version, method
+	PluginCallErrors   *prometheus.CounterVec   // plugin, version, method, code
+	PluginCallDuration *prometheus.HistogramVec // plugin, version, method
+	PluginRestarts     *prometheus.CounterVec   // plugin, version, result
+	DownloadDuration   *prometheus.HistogramVec // result
+	DownloadBytes      prometheus.Counter
+}
+
+// Results of restarts and downloads
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// MethodOther is the method label value of calls to methods a plugin does
+// not declare, so callers cannot create a series per method name
+const MethodOther = "other"
+
+// New creates the host's collectors in a registry of their own, next to the
+// Go runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		PluginCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "plugin_calls_total",
+			Help: "Plugin method calls, including those that failed.",
+		}, []string{"plugin", "version", "method"}),
+		PluginCallErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "plugin_call_errors_total",
+			Help: "Plugin method calls that failed, by API error code.",
+		}, []string{"plugin", "version", "method", "code"}),
+		PluginCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "plugin_call_duration_seconds",
+			Help:    "Duration of plugin method calls, including waiting for a free process.",
+			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"plugin", "version", "method"}),
+		PluginRestarts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "plugin_restarts_total",
+			Help: "Restarts of crashed or unresponsive plugins by the supervisor.",
+		}, []string{"plugin", "version", "result"}),
+		DownloadDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "plugin_download_duration_seconds",
+			Help:    "Duration of plugin binary downloads, including verification.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+		}, []string{"result"}),
+		DownloadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "plugin_download_bytes_total",
+			Help: "Bytes of plugin binaries downloaded.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.PluginCalls,
+		m.PluginCallErrors,
+		m.PluginCallDuration,
+		m.PluginRestarts,
+		m.DownloadDuration,
+		m.DownloadBytes,
+	)
+	return m
+}
+
+// Register adds collectors that read state at scrape time, such as the
+// process pools of plugins or the database connection pool
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	return m.registry.Register(collector)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// PluginLabel is the plugin label value of a plugin
+func PluginLabel(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
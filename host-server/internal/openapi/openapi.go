@@ -0,0 +1,397 @@
+// Package openapi synthesizes an OpenAPI 3 document describing the methods
+// of active plugins, one operation per method, from their stored descriptors.
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/plugin"
+)
+
+// Version is the OpenAPI specification version of generated documents
+const Version = "3.0.3"
+
+// Document is the subset of an OpenAPI 3 document the host generates
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Tags       []Tag                 `json:"tags"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security,omitempty"` // Credentials every operation accepts
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type PathItem struct {
+	Post *Operation `json:"post,omitempty"`
+}
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema   *Schema            `json:"schema,omitempty"`
+	Examples map[string]Example `json:"examples,omitempty"`
+}
+
+type Example struct {
+	Summary string `json:"summary,omitempty"`
+	Value   any    `json:"value"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Default              any                `json:"default,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityRequirement maps the names of security schemes to their scopes
+type SecurityRequirement map[string][]string
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// MethodPath is the route each generated operation points at
+func MethodPath(pluginID uint, method string) string {
+	return fmt.Sprintf("/api/plugins/%d/methods/%s", pluginID, method)
+}
+
+// StreamMethodPath is the route each generated streaming operation points at
+func StreamMethodPath(pluginID uint, method string) string {
+	return MethodPath(pluginID, method) + "/stream"
+}
+
+// Build generates the document for the given plugins. Plugins without
+// method descriptors in their metadata contribute no operations.
+func Build(plugins []*models.Plugin) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "Polyglot Plugin Methods",
+			Description: "Methods of the active plugins, generated from the descriptors they advertise",
+			Version:     "1.0",
+		},
+		Tags:  []Tag{},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"AppError": appErrorSchema(),
+			},
+			SecuritySchemes: map[string]SecurityScheme{
+				"ApiKeyAuth": {
+					Type:        "apiKey",
+					Description: "Static API key configured under auth.api_keys",
+					Name:        auth.APIKeyHeader,
+					In:          "header",
+				},
+				"BearerAuth": {
+					Type:         "http",
+					Description:  "JWT signed with a key of the configured JWKS file",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+				},
+			},
+		},
+		Security: []SecurityRequirement{{"ApiKeyAuth": {}}, {"BearerAuth": {}}},
+	}
+
+	sorted := make([]*models.Plugin, len(plugins))
+	copy(sorted, plugins)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	for _, record := range sorted {
+		methods := plugin.MethodsFromMetadata(record.Metadata)
+		if len(methods) == 0 {
+			continue
+		}
+
+		tag := fmt.Sprintf("%s/%s@%s", record.Namespace, record.Name, record.Version)
+		doc.Tags = append(doc.Tags, Tag{Name: tag, Description: record.Description})
+
+		for _, method := range methods {
+			doc.Paths[MethodPath(record.ID, method.Name)] = PathItem{
+				Post: buildOperation(record, tag, method),
+			}
+			if method.StreamInput != "" {
+				doc.Paths[StreamMethodPath(record.ID, method.Name)] = PathItem{
+					Post: buildStreamOperation(record, tag, method),
+				}
+			}
+		}
+	}
+
+	return doc
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+func buildOperation(record *models.Plugin, tag string, method plugin.MethodMetadata) *Operation {
+	op := &Operation{
+		OperationID: nonIdentifier.ReplaceAllString(fmt.Sprintf("%s_%s_%s", record.Name, record.Version, method.Name), "_"),
+		Summary:     method.Name,
+		Description: method.Description,
+		Tags:        []string{tag},
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {
+					Schema:   paramsSchema(method),
+					Examples: requestExamples(method),
+				},
+			},
+		},
+		Responses: map[string]Response{
+			"200": {
+				Description: returnDescription(method),
+				Content: map[string]MediaType{
+					"application/json": {
+						Schema:   resultSchema(method),
+						Examples: resultExamples(method),
+					},
+				},
+			},
+		},
+	}
+
+	addErrorResponses(op)
+	return op
+}
+
+// buildStreamOperation describes the streaming form of a method: the stream
+// input parameter is the request body and the other parameters move to the
+// query string.
+func buildStreamOperation(record *models.Plugin, tag string, method plugin.MethodMetadata) *Operation {
+	input := method.Parameters[method.StreamInput]
+
+	op := &Operation{
+		OperationID: nonIdentifier.ReplaceAllString(fmt.Sprintf("%s_%s_%s_stream", record.Name, record.Version, method.Name), "_"),
+		Summary:     method.Name + " (streaming)",
+		Description: method.Description,
+		Tags:        []string{tag},
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/octet-stream": {
+					Schema: &Schema{Type: "string", Format: "binary", Description: input.Description},
+				},
+			},
+		},
+		Responses: map[string]Response{
+			"200": {
+				Description: returnDescription(method),
+				Headers: map[string]Header{
+					"X-Plugin-Error": {
+						Description: "Trailer set when the call fails after output has started",
+						Schema:      &Schema{Type: "string"},
+					},
+				},
+				Content: map[string]MediaType{
+					"application/octet-stream": {Schema: &Schema{Type: "string", Format: "binary"}},
+				},
+			},
+		},
+	}
+
+	names := make([]string, 0, len(method.Parameters))
+	for name := range method.Parameters {
+		if name != method.StreamInput {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		param := method.Parameters[name]
+		schema := typeSchema(param.Type)
+		schema.Default = param.Default
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      schema,
+		})
+	}
+
+	addErrorResponses(op)
+	return op
+}
+
+func addErrorResponses(op *Operation) {
+	for _, status := range []string{"400", "404", "500", "504"} {
+		op.Responses[status] = Response{
+			Description: errorDescriptions[status],
+			Content: map[string]MediaType{
+				"application/json": {Schema: &Schema{Ref: "#/components/schemas/AppError"}},
+			},
+		}
+	}
+}
+
+var errorDescriptions = map[string]string{
+	"400": "Invalid parameters",
+	"404": "Plugin not found",
+	"500": "Plugin call failed",
+	"504": "Plugin call timed out",
+}
+
+func paramsSchema(method plugin.MethodMetadata) *Schema {
+	schema := &Schema{Type: "object"}
+
+	if len(method.Parameters) == 0 {
+		// Undescribed parameters are passed through unchecked
+		schema.AdditionalProperties = true
+		return schema
+	}
+
+	schema.Properties = make(map[string]*Schema, len(method.Parameters))
+	schema.AdditionalProperties = false
+	for name, param := range method.Parameters {
+		property := typeSchema(param.Type)
+		property.Description = param.Description
+		property.Default = param.Default
+		schema.Properties[name] = property
+		if param.Required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+
+	return schema
+}
+
+func resultSchema(method plugin.MethodMetadata) *Schema {
+	schema := typeSchema(method.Returns.Type)
+	schema.Description = method.Returns.Description
+	return schema
+}
+
+func returnDescription(method plugin.MethodMetadata) string {
+	if method.Returns.Description != "" {
+		return method.Returns.Description
+	}
+	return "Method result"
+}
+
+// typeSchema maps the type names used in ParamSchema and ReturnSchema to JSON Schema
+func typeSchema(paramType string) *Schema {
+	switch paramType {
+	case "string":
+		return &Schema{Type: "string"}
+	case "int", "integer":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "number", "float":
+		return &Schema{Type: "number", Format: "double"}
+	case "bool", "boolean":
+		return &Schema{Type: "boolean"}
+	case "object":
+		return &Schema{Type: "object", AdditionalProperties: true}
+	case "array":
+		return &Schema{Type: "array", Items: &Schema{}}
+	default:
+		return &Schema{}
+	}
+}
+
+func requestExamples(method plugin.MethodMetadata) map[string]Example {
+	if len(method.Examples) == 0 {
+		return nil
+	}
+	examples := make(map[string]Example, len(method.Examples))
+	for i, example := range method.Examples {
+		examples[exampleKey(i)] = Example{Summary: example.Description, Value: example.Params}
+	}
+	return examples
+}
+
+func resultExamples(method plugin.MethodMetadata) map[string]Example {
+	examples := make(map[string]Example)
+	for i, example := range method.Examples {
+		if example.Result != nil {
+			examples[exampleKey(i)] = Example{Summary: example.Description, Value: example.Result}
+		}
+	}
+	if len(examples) == 0 {
+		return nil
+	}
+	return examples
+}
+
+func exampleKey(i int) string {
+	return fmt.Sprintf("example%d", i+1)
+}
+
+func appErrorSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success":   {Type: "boolean"},
+			"message":   {Type: "string"},
+			"errorCode": {Type: "string"},
+			"details":   {Type: "string"},
+			"path":      {Type: "string"},
+			"timestamp": {Type: "integer", Format: "int64"},
+		},
+	}
+}
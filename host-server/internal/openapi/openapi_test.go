@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/plugin"
+)
+
+func TestBuild(t *testing.T) {
+	plugins := []*models.Plugin{
+		{
+			ID:        3,
+			Namespace: "builtin",
+			Name:      "dpanonymizer",
+			Version:   "1.0.0",
+			Metadata: models.JSONMap{
+				"methods": []plugin.MethodMetadata{
+					{
+						Name: "DPCount",
+						Parameters: map[string]plugin.ParamSchema{
+							"values":                     {Type: "array", Required: true},
+							"epsilon":                    {Type: "number", Required: true},
+							"max_partitions_contributed": {Type: "int", Default: float64(1)},
+						},
+						Returns: plugin.ReturnSchema{Type: "int", Description: "Noisy count"},
+						Examples: []plugin.MethodExample{
+							{Params: map[string]any{"values": []any{1.0}, "epsilon": 1.0}, Result: 1.0},
+						},
+					},
+				},
+			},
+		},
+		{
+			// Seeded plugins carry no method descriptors
+			ID:       1,
+			Name:     "converter",
+			Metadata: models.JSONMap{"author": "Polyglot Team"},
+		},
+	}
+
+	doc := Build(plugins)
+
+	if len(doc.Paths) != 1 || len(doc.Tags) != 1 {
+		t.Fatalf("Build() produced %d paths and %d tags, want 1 each", len(doc.Paths), len(doc.Tags))
+	}
+
+	item, ok := doc.Paths["/api/plugins/3/methods/DPCount"]
+	if !ok || item.Post == nil {
+		t.Fatalf("Build() paths = %v, missing DPCount operation", doc.Paths)
+	}
+
+	op := item.Post
+	if op.OperationID != "dpanonymizer_1_0_0_DPCount" {
+		t.Errorf("OperationID = %s", op.OperationID)
+	}
+
+	params := op.RequestBody.Content["application/json"].Schema
+	if len(params.Required) != 2 || params.Required[0] != "epsilon" || params.Required[1] != "values" {
+		t.Errorf("Required = %v, want [epsilon values]", params.Required)
+	}
+	if got := params.Properties["max_partitions_contributed"]; got.Type != "integer" || got.Default != float64(1) {
+		t.Errorf("max_partitions_contributed schema = %+v", got)
+	}
+	if got := params.Properties["values"]; got.Type != "array" || got.Items == nil {
+		t.Errorf("values schema = %+v", got)
+	}
+
+	result := op.Responses["200"].Content["application/json"]
+	if result.Schema.Type != "integer" || len(result.Examples) != 1 {
+		t.Errorf("200 response = %+v", result)
+	}
+
+	if len(doc.Security) != 2 || doc.Components.SecuritySchemes["ApiKeyAuth"].Name != "X-API-Key" {
+		t.Errorf("security = %v with schemes %v, want the API key and bearer schemes", doc.Security, doc.Components.SecuritySchemes)
+	}
+}
+
+func TestBuildStreamingMethod(t *testing.T) {
+	plugins := []*models.Plugin{
+		{
+			ID:      1,
+			Name:    "converter",
+			Version: "1.0.0",
+			Metadata: models.JSONMap{
+				"methods": []plugin.MethodMetadata{
+					{
+						Name: "ConvertToCSV",
+						Parameters: map[string]plugin.ParamSchema{
+							"data":      {Type: "string", Required: true},
+							"delimiter": {Type: "string", Default: ","},
+						},
+						Returns:     plugin.ReturnSchema{Type: "string"},
+						StreamInput: "data",
+					},
+				},
+			},
+		},
+	}
+
+	doc := Build(plugins)
+
+	item, ok := doc.Paths[StreamMethodPath(1, "ConvertToCSV")]
+	if !ok || item.Post == nil {
+		t.Fatalf("Build() paths = %v, missing streaming operation", doc.Paths)
+	}
+	if _, ok := doc.Paths[MethodPath(1, "ConvertToCSV")]; !ok {
+		t.Errorf("Build() dropped the regular operation of a streaming method")
+	}
+
+	op := item.Post
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "delimiter" || op.Parameters[0].In != "query" {
+		t.Errorf("Parameters = %+v, want the delimiter query parameter only", op.Parameters)
+	}
+	if _, ok := op.RequestBody.Content["application/octet-stream"]; !ok {
+		t.Errorf("RequestBody = %+v, want a raw stream body", op.RequestBody)
+	}
+}
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// BatchCall is a single invocation within a batch
+type BatchCall struct {
+	Method string
+	Params *structpb.Struct
+}
+
+// BatchOutcome is the result of a BatchCall. CtxErr is set when the call's
+// context ended before the call completed.
+type BatchOutcome struct {
+	Response *common.ExecuteResponse
+	Err      error
+	CtxErr   error
+}
+
+// ExecuteBatch runs calls against client with at most concurrency calls in
+// flight and returns their outcomes in call order. Every call gets its own
+// context from callContext, so a slow call only times out itself. Calls not
+// yet started when ctx is done are reported with ctx's error.
+func ExecuteBatch(
+	ctx context.Context,
+	client common.ContextPluginInterface,
+	calls []BatchCall,
+	concurrency int,
+	callContext func(context.Context) (context.Context, context.CancelFunc),
+) []BatchOutcome {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	outcomes := make([]BatchOutcome, len(calls))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, call := range calls {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			outcomes[i] = BatchOutcome{Err: ctx.Err(), CtxErr: ctx.Err()}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, call BatchCall) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			callCtx, cancel := callContext(ctx)
+			defer cancel()
+
+			resp, err := client.ExecuteContext(callCtx, call.Method, call.Params)
+			outcome := BatchOutcome{Response: resp, Err: err}
+			if err != nil {
+				outcome.CtxErr = callCtx.Err()
+			}
+			outcomes[i] = outcome
+		}(i, call)
+	}
+
+	wg.Wait()
+	return outcomes
+}
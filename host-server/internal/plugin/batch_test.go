@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// echoClient answers every call with its method name after a delay taken
+// from the "delay_ms" parameter, recording the peak number of calls in flight
+type echoClient struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (c *echoClient) GetMetadataContext(ctx context.Context) (*common.MetadataResponse, error) {
+	return &common.MetadataResponse{}, nil
+}
+
+func (c *echoClient) ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (*common.ExecuteResponse, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+
+	delay := time.Duration(params.GetFields()["delay_ms"].GetNumberValue()) * time.Millisecond
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return &common.ExecuteResponse{Success: true, Result: structpb.NewStringValue(method)}, nil
+}
+
+func TestExecuteBatch_OrderAndConcurrency(t *testing.T) {
+	client := &echoClient{}
+
+	calls := make([]BatchCall, 8)
+	for i := range calls {
+		// Earlier calls take longer, so completion order is the reverse of call order
+		calls[i] = BatchCall{
+			Method: string(rune('A' + i)),
+			Params: common.MustStruct(map[string]any{"delay_ms": float64(len(calls)-i) * 5}),
+		}
+	}
+
+	callContext := func(ctx context.Context) (context.Context, context.CancelFunc) {
+		return context.WithTimeout(ctx, time.Second)
+	}
+	outcomes := ExecuteBatch(context.Background(), client, calls, 3, callContext)
+
+	for i, outcome := range outcomes {
+		if outcome.Err != nil {
+			t.Fatalf("call %d failed: %v", i, outcome.Err)
+		}
+		if got := outcome.Response.Result.GetStringValue(); got != calls[i].Method {
+			t.Errorf("outcome %d = %s, want %s", i, got, calls[i].Method)
+		}
+	}
+	if peak := client.peak.Load(); peak > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", peak)
+	}
+}
+
+func TestExecuteBatch_PerCallTimeout(t *testing.T) {
+	calls := []BatchCall{
+		{Method: "slow", Params: common.MustStruct(map[string]any{"delay_ms": 200.0})},
+		{Method: "fast", Params: common.MustStruct(map[string]any{"delay_ms": 0.0})},
+	}
+
+	callContext := func(ctx context.Context) (context.Context, context.CancelFunc) {
+		return context.WithTimeout(ctx, 50*time.Millisecond)
+	}
+	outcomes := ExecuteBatch(context.Background(), &echoClient{}, calls, 2, callContext)
+
+	if outcomes[0].Err == nil || outcomes[0].CtxErr != context.DeadlineExceeded {
+		t.Errorf("slow call outcome = %+v, want DeadlineExceeded", outcomes[0])
+	}
+	if outcomes[1].Err != nil || !outcomes[1].Response.Success {
+		t.Errorf("fast call outcome = %+v, want success", outcomes[1])
+	}
+}
@@ -0,0 +1,347 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/validator"
+)
+
+// Dependency types. Only plugin dependencies are resolved by the host;
+// libraries and services are provided outside of it and are not checked.
+const (
+	DependencyTypePlugin  = "plugin"
+	DependencyTypeLibrary = "library"
+	DependencyTypeService = "service"
+)
+
+// ErrInvalidDependency is returned for dependency declarations that cannot be resolved
+var ErrInvalidDependency = errors.New("invalid plugin dependency")
+
+// IsInvalidDependency reports whether err was caused by a malformed dependency declaration
+func IsInvalidDependency(err error) bool {
+	return errors.Is(err, ErrInvalidDependency)
+}
+
+// ErrDependencyUnmet is returned when a plugin requires plugins that are not installed
+var ErrDependencyUnmet = errors.New("unmet plugin dependencies")
+
+// IsDependencyUnmet reports whether err was caused by missing dependencies
+func IsDependencyUnmet(err error) bool {
+	return errors.Is(err, ErrDependencyUnmet)
+}
+
+// ErrDependencyCycle is returned when plugins depend on each other in a cycle
+var ErrDependencyCycle = errors.New("plugin dependency cycle")
+
+// IsDependencyCycle reports whether err was caused by a dependency cycle
+func IsDependencyCycle(err error) bool {
+	return errors.Is(err, ErrDependencyCycle)
+}
+
+// ErrPluginInUse is returned when a plugin cannot go away because active
+// plugins depend on it
+var ErrPluginInUse = errors.New("plugin is required by active plugins")
+
+// IsPluginInUse reports whether err was caused by active dependents
+func IsPluginInUse(err error) bool {
+	return errors.Is(err, ErrPluginInUse)
+}
+
+// UnmetDependency is a dependency no installed plugin satisfies
+type UnmetDependency struct {
+	Plugin     string // the plugin declaring it, as namespace/name@version
+	Dependency Dependency
+	Installed  []string // installed versions of the required plugin
+}
+
+func (u UnmetDependency) String() string {
+	installed := "none installed"
+	if len(u.Installed) > 0 {
+		installed = "installed: " + strings.Join(u.Installed, ", ")
+	}
+	return fmt.Sprintf("%s requires %s %s (%s)", u.Plugin, u.Dependency.Name, u.Dependency.Version, installed)
+}
+
+// DependencyError lists every unmet dependency of a plugin and of the
+// plugins it depends on
+type DependencyError struct {
+	Unmet []UnmetDependency
+}
+
+func (e *DependencyError) Error() string {
+	unmet := make([]string, len(e.Unmet))
+	for i, u := range e.Unmet {
+		unmet[i] = u.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrDependencyUnmet, strings.Join(unmet, "; "))
+}
+
+func (e *DependencyError) Unwrap() error {
+	return ErrDependencyUnmet
+}
+
+// DependenciesFromMetadata extracts the dependencies declared in a plugin
+// record's metadata. Unlike methods, a malformed declaration is an error:
+// ignoring it would let the plugin run without what it requires.
+func DependenciesFromMetadata(metadata map[string]any) ([]Dependency, error) {
+	raw, ok := metadata["dependencies"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDependency, err)
+	}
+
+	var dependencies []Dependency
+	if err := json.Unmarshal(data, &dependencies); err != nil {
+		return nil, fmt.Errorf("%w: dependencies must be a list of {name, version, type}: %v", ErrInvalidDependency, err)
+	}
+
+	for _, dependency := range dependencies {
+		if err := validator.Validate(&dependency); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDependency, err)
+		}
+		switch dependency.Type {
+		case DependencyTypePlugin, DependencyTypeLibrary, DependencyTypeService:
+		default:
+			return nil, fmt.Errorf("%w: dependency '%s' has unknown type '%s'", ErrInvalidDependency, dependency.Name, dependency.Type)
+		}
+		if _, err := ParseVersionRange(dependency.Version); err != nil {
+			return nil, fmt.Errorf("%w: dependency '%s': %v", ErrInvalidDependency, dependency.Name, err)
+		}
+	}
+	return dependencies, nil
+}
+
+// DependencyPlugins finds installed plugins for the resolver
+type DependencyPlugins interface {
+	FindAll(filters map[string]any) ([]*models.Plugin, error)
+}
+
+// DependencyResolver resolves the plugin dependencies plugins declare in
+// their metadata against the installed plugins. A dependency names a plugin
+// in the namespace of the plugin declaring it, or as namespace/name.
+type DependencyResolver struct {
+	plugins DependencyPlugins
+}
+
+func NewDependencyResolver(plugins DependencyPlugins) *DependencyResolver {
+	return &DependencyResolver{plugins: plugins}
+}
+
+// Validate checks the dependency declarations of a plugin that is about to
+// be installed, and of the plugins it depends on, for malformed entries and
+// cycles. Dependencies that are not installed yet are fine until activation.
+func (r *DependencyResolver) Validate(record *models.Plugin) error {
+	graph := r.newGraph(record)
+	return graph.visit(record)
+}
+
+// ActivationOrder returns the plugins that have to be activated for record
+// to run: its inactive dependencies, transitively, each after the plugins it
+// depends on, and record last. Active plugins satisfy a dependency before
+// any other installed version; otherwise the highest matching version is
+// chosen. All unmet dependencies are reported together in a DependencyError.
+func (r *DependencyResolver) ActivationOrder(record *models.Plugin) ([]*models.Plugin, error) {
+	graph := r.newGraph(record)
+	if err := graph.visit(record); err != nil {
+		return nil, err
+	}
+	if len(graph.unmet) > 0 {
+		return nil, &DependencyError{Unmet: graph.unmet}
+	}
+	return graph.order, nil
+}
+
+// CheckNotRequired returns an ErrPluginInUse error naming the active plugins
+// that depend on record and that no other active plugin satisfies, so record
+// cannot be deactivated or uninstalled.
+func (r *DependencyResolver) CheckNotRequired(record *models.Plugin) error {
+	return r.checkReplaceable(record, nil)
+}
+
+// CheckUpgrade returns an ErrPluginInUse error naming the active plugins that
+// depend on record and that neither upgraded, the version record is about to
+// be upgraded to, nor any other active plugin satisfies.
+func (r *DependencyResolver) CheckUpgrade(record, upgraded *models.Plugin) error {
+	return r.checkReplaceable(record, upgraded)
+}
+
+// checkReplaceable looks for active dependents that rely on record and that
+// are not satisfied by replacement, if any, or another active plugin
+func (r *DependencyResolver) checkReplaceable(record, replacement *models.Plugin) error {
+	active, err := r.plugins.FindAll(map[string]any{"status": models.PluginStatusActive})
+	if err != nil {
+		return fmt.Errorf("failed to find active plugins: %w", err)
+	}
+
+	var dependents []string
+	for _, dependent := range active {
+		if dependent.ID == record.ID {
+			continue
+		}
+		// Active plugins with malformed declarations predate dependency
+		// resolution and cannot hold record back
+		dependencies, err := DependenciesFromMetadata(dependent.Metadata)
+		if err != nil {
+			continue
+		}
+
+		for _, dependency := range dependencies {
+			if dependency.Type != DependencyTypePlugin || !satisfies(dependent, dependency, record) {
+				continue
+			}
+			satisfiedElsewhere := replacement != nil && satisfies(dependent, dependency, replacement)
+			for _, other := range active {
+				if other.ID != record.ID && satisfies(dependent, dependency, other) {
+					satisfiedElsewhere = true
+					break
+				}
+			}
+			if !satisfiedElsewhere {
+				dependents = append(dependents, fmt.Sprintf("%s (requires %s %s)", pluginKey(dependent), dependency.Name, dependency.Version))
+			}
+		}
+	}
+
+	if len(dependents) > 0 {
+		return fmt.Errorf("%w: %s is required by %s", ErrPluginInUse, pluginKey(record), strings.Join(dependents, ", "))
+	}
+	return nil
+}
+
+// dependencyGraph walks the dependencies of a plugin depth-first
+type dependencyGraph struct {
+	plugins DependencyPlugins
+	root    *models.Plugin
+	visited map[string]bool // finished plugins
+	path    []string        // plugins being visited, to detect cycles
+	order   []*models.Plugin
+	unmet   []UnmetDependency
+}
+
+func (r *DependencyResolver) newGraph(root *models.Plugin) *dependencyGraph {
+	return &dependencyGraph{
+		plugins: r.plugins,
+		root:    root,
+		visited: make(map[string]bool),
+	}
+}
+
+func (g *dependencyGraph) visit(record *models.Plugin) error {
+	key := pluginKey(record)
+	if g.visited[key] {
+		return nil
+	}
+	for i, visiting := range g.path {
+		if visiting == key {
+			cycle := append(append([]string{}, g.path[i:]...), key)
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+		}
+	}
+
+	dependencies, err := DependenciesFromMetadata(record.Metadata)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", key, err)
+	}
+
+	g.path = append(g.path, key)
+	for _, dependency := range dependencies {
+		if dependency.Type != DependencyTypePlugin {
+			continue
+		}
+
+		candidate, installed, err := g.choose(record, dependency)
+		if err != nil {
+			return err
+		}
+		if candidate == nil {
+			g.unmet = append(g.unmet, UnmetDependency{Plugin: key, Dependency: dependency, Installed: installed})
+			continue
+		}
+		// Active plugins were resolved when they were activated
+		if candidate.Status == models.PluginStatusActive {
+			continue
+		}
+		if err := g.visit(candidate); err != nil {
+			return err
+		}
+	}
+	g.path = g.path[:len(g.path)-1]
+
+	g.visited[key] = true
+	if record.Status != models.PluginStatusActive {
+		g.order = append(g.order, record)
+	}
+	return nil
+}
+
+// choose returns the installed plugin that satisfies dependency of record,
+// or nil and the versions that are installed
+func (g *dependencyGraph) choose(record *models.Plugin, dependency Dependency) (*models.Plugin, []string, error) {
+	namespace, name := dependencyTarget(record, dependency)
+	candidates, err := g.plugins.FindAll(map[string]any{"namespace": namespace, "name": name})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find plugin %s/%s: %w", namespace, name, err)
+	}
+	// The root is not stored yet while it is being installed
+	if g.root.ID == 0 && g.root.Namespace == namespace && g.root.Name == name {
+		candidates = append(candidates, g.root)
+	}
+
+	var chosen *models.Plugin
+	installed := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Status == models.PluginStatusInstalling && candidate != g.root {
+			continue
+		}
+		installed = append(installed, candidate.Version)
+		if !satisfies(record, dependency, candidate) {
+			continue
+		}
+		if chosen == nil || preferCandidate(candidate, chosen) {
+			chosen = candidate
+		}
+	}
+	return chosen, installed, nil
+}
+
+// preferCandidate reports whether a should satisfy a dependency rather than b
+func preferCandidate(a, b *models.Plugin) bool {
+	aActive, bActive := a.Status == models.PluginStatusActive, b.Status == models.PluginStatusActive
+	if aActive != bActive {
+		return aActive
+	}
+	return compareVersions(a.Version, b.Version) > 0
+}
+
+// satisfies reports whether candidate satisfies dependency of record
+func satisfies(record *models.Plugin, dependency Dependency, candidate *models.Plugin) bool {
+	namespace, name := dependencyTarget(record, dependency)
+	if candidate.Namespace != namespace || candidate.Name != name {
+		return false
+	}
+	versions, err := ParseVersionRange(dependency.Version)
+	if err != nil {
+		return false
+	}
+	return versions.Contains(candidate.Version)
+}
+
+// dependencyTarget returns the namespace and name of the plugin a dependency names
+func dependencyTarget(record *models.Plugin, dependency Dependency) (string, string) {
+	if namespace, name, qualified := strings.Cut(dependency.Name, "/"); qualified {
+		return namespace, name
+	}
+	return record.Namespace, dependency.Name
+}
+
+func pluginKey(record *models.Plugin) string {
+	return fmt.Sprintf("%s/%s@%s", record.Namespace, record.Name, record.Version)
+}
@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+)
+
+// fakeInstalledPlugins answers FindAll from a fixed set of plugins
+type fakeInstalledPlugins []*models.Plugin
+
+func (f fakeInstalledPlugins) FindAll(filters map[string]any) ([]*models.Plugin, error) {
+	var found []*models.Plugin
+	for _, p := range f {
+		if ns, ok := filters["namespace"]; ok && p.Namespace != ns {
+			continue
+		}
+		if name, ok := filters["name"]; ok && p.Name != name {
+			continue
+		}
+		if status, ok := filters["status"]; ok && p.Status != status {
+			continue
+		}
+		found = append(found, p)
+	}
+	return found, nil
+}
+
+func installedPlugin(id uint, name, version string, status models.PluginStatus, dependencies ...map[string]any) *models.Plugin {
+	metadata := models.JSONMap{}
+	if len(dependencies) > 0 {
+		list := make([]any, len(dependencies))
+		for i, dependency := range dependencies {
+			list[i] = dependency
+		}
+		metadata["dependencies"] = list
+	}
+	return &models.Plugin{ID: id, Namespace: "default", Name: name, Version: version, Status: status, Metadata: metadata}
+}
+
+func requires(name, versions string) map[string]any {
+	return map[string]any{"name": name, "version": versions, "type": DependencyTypePlugin}
+}
+
+func names(plugins []*models.Plugin) string {
+	keys := make([]string, len(plugins))
+	for i, p := range plugins {
+		keys[i] = p.Name + "@" + p.Version
+	}
+	return strings.Join(keys, " ")
+}
+
+func TestDependencyResolver_ActivationOrder(t *testing.T) {
+	report := installedPlugin(1, "report", "1.0.0", models.PluginStatusInactive,
+		requires("converter", "^1.0.0"), requires("desensitization", ">=1.1.0"),
+		map[string]any{"name": "libpdf", "version": "2", "type": DependencyTypeLibrary})
+	plugins := fakeInstalledPlugins{
+		report,
+		installedPlugin(2, "converter", "1.0.0", models.PluginStatusInactive),
+		installedPlugin(3, "converter", "1.4.0", models.PluginStatusInactive, requires("desensitization", "1.x")),
+		installedPlugin(4, "converter", "2.0.0", models.PluginStatusInactive),
+		installedPlugin(5, "desensitization", "1.2.0", models.PluginStatusInactive),
+	}
+
+	order, err := NewDependencyResolver(plugins).ActivationOrder(report)
+	if err != nil {
+		t.Fatalf("ActivationOrder() error = %v", err)
+	}
+	if got, want := names(order), "desensitization@1.2.0 converter@1.4.0 report@1.0.0"; got != want {
+		t.Errorf("ActivationOrder() = %s, want %s", got, want)
+	}
+
+	// An active version that matches is kept rather than activating another
+	plugins[1].Status = models.PluginStatusActive
+	order, err = NewDependencyResolver(plugins).ActivationOrder(report)
+	if err != nil {
+		t.Fatalf("ActivationOrder() error = %v", err)
+	}
+	if got, want := names(order), "desensitization@1.2.0 report@1.0.0"; got != want {
+		t.Errorf("ActivationOrder() = %s, want %s", got, want)
+	}
+}
+
+func TestDependencyResolver_UnmetDependencies(t *testing.T) {
+	report := installedPlugin(1, "report", "1.0.0", models.PluginStatusInactive,
+		requires("converter", "^2.0.0"), requires("other/archiver", "*"))
+	plugins := fakeInstalledPlugins{report, installedPlugin(2, "converter", "1.4.0", models.PluginStatusActive)}
+
+	_, err := NewDependencyResolver(plugins).ActivationOrder(report)
+	if !IsDependencyUnmet(err) {
+		t.Fatalf("ActivationOrder() error = %v, want ErrDependencyUnmet", err)
+	}
+	for _, want := range []string{"converter ^2.0.0 (installed: 1.4.0)", "other/archiver * (none installed)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not list %q", err, want)
+		}
+	}
+}
+
+func TestDependencyResolver_Cycle(t *testing.T) {
+	a := installedPlugin(1, "a", "1.0.0", models.PluginStatusInactive, requires("b", "1"))
+	plugins := fakeInstalledPlugins{a, installedPlugin(2, "b", "1.0.0", models.PluginStatusInactive, requires("a", "1"))}
+
+	_, err := NewDependencyResolver(plugins).ActivationOrder(a)
+	if !IsDependencyCycle(err) || !strings.Contains(err.Error(), "default/a@1.0.0 -> default/b@1.0.0 -> default/a@1.0.0") {
+		t.Errorf("ActivationOrder() error = %v, want the cycle a -> b -> a", err)
+	}
+
+	// A plugin being installed closes a cycle with the plugins depending on it
+	c := installedPlugin(0, "c", "1.0.0", models.PluginStatusInstalling, requires("d", "1"))
+	plugins = fakeInstalledPlugins{installedPlugin(3, "d", "1.0.0", models.PluginStatusInactive, requires("c", "1"))}
+	if err := NewDependencyResolver(plugins).Validate(c); !IsDependencyCycle(err) {
+		t.Errorf("Validate() error = %v, want ErrDependencyCycle", err)
+	}
+
+	// Missing dependencies are fine at install time
+	c.Metadata["dependencies"] = []any{requires("missing", "1")}
+	if err := NewDependencyResolver(plugins).Validate(c); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestDependenciesFromMetadata_Invalid(t *testing.T) {
+	for _, dependencies := range []any{
+		"converter",
+		[]any{map[string]any{"name": "converter", "type": DependencyTypePlugin}},
+		[]any{map[string]any{"name": "converter", "version": "1", "type": "module"}},
+		[]any{requires("converter", "latest")},
+	} {
+		if _, err := DependenciesFromMetadata(map[string]any{"dependencies": dependencies}); !IsInvalidDependency(err) {
+			t.Errorf("DependenciesFromMetadata(%v) error = %v, want ErrInvalidDependency", dependencies, err)
+		}
+	}
+}
+
+func TestDependencyResolver_CheckNotRequired(t *testing.T) {
+	converter := installedPlugin(2, "converter", "1.0.0", models.PluginStatusActive)
+	plugins := fakeInstalledPlugins{
+		installedPlugin(1, "report", "1.0.0", models.PluginStatusActive, requires("converter", "^1.0.0")),
+		converter,
+	}
+
+	err := NewDependencyResolver(plugins).CheckNotRequired(converter)
+	if !IsPluginInUse(err) || !strings.Contains(err.Error(), "default/report@1.0.0") {
+		t.Fatalf("CheckNotRequired() error = %v, want ErrPluginInUse naming report", err)
+	}
+
+	// Another active version that satisfies the dependent frees this one
+	plugins = append(plugins, installedPlugin(3, "converter", "1.1.0", models.PluginStatusActive))
+	if err := NewDependencyResolver(plugins).CheckNotRequired(converter); err != nil {
+		t.Errorf("CheckNotRequired() error = %v", err)
+	}
+}
+
+func TestDependencyResolver_CheckUpgrade(t *testing.T) {
+	converter := installedPlugin(2, "converter", "1.0.0", models.PluginStatusActive)
+	plugins := fakeInstalledPlugins{
+		installedPlugin(1, "report", "1.0.0", models.PluginStatusActive, requires("converter", "^1.0.0")),
+		converter,
+	}
+	resolver := NewDependencyResolver(plugins)
+
+	if err := resolver.CheckUpgrade(converter, installedPlugin(2, "converter", "1.3.0", models.PluginStatusActive)); err != nil {
+		t.Errorf("CheckUpgrade() to a compatible version error = %v", err)
+	}
+
+	err := resolver.CheckUpgrade(converter, installedPlugin(2, "converter", "2.0.0", models.PluginStatusActive))
+	if !IsPluginInUse(err) || !strings.Contains(err.Error(), "default/report@1.0.0") {
+		t.Errorf("CheckUpgrade() to a breaking version error = %v, want ErrPluginInUse naming report", err)
+	}
+}
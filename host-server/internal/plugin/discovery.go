@@ -0,0 +1,241 @@
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/repository"
+)
+
+// ScanResult summarizes a plugin directory scan
+type ScanResult struct {
+	Registered []*models.Plugin `json:"registered"` // Newly discovered plugins
+	Updated    []*models.Plugin `json:"updated"`    // Known plugins whose record was refreshed
+	Unchanged  int              `json:"unchanged"`  // Known plugins with nothing to update
+	Skipped    []ScanIssue      `json:"skipped"`    // Binaries that could not be registered
+}
+
+// ScanIssue explains why a binary found during a scan was skipped
+type ScanIssue struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// SignatureFileName is the file next to a plugin binary that holds its
+// base64 ed25519 signature. Scans of namespaces with trusted publisher keys
+// skip binaries without a valid one.
+const SignatureFileName = "plugin.sig"
+
+// Discoverer registers plugin binaries found under the plugin directory, so
+// that dropping a binary into the standard layout is enough to install it.
+type Discoverer struct {
+	manager *Manager
+	repo    repository.PluginRepository
+	baseDir string
+	mu      sync.Mutex // serializes scans so concurrent runs don't race on upserts
+}
+
+func NewDiscoverer(manager *Manager, repo repository.PluginRepository, baseDir string) *Discoverer {
+	return &Discoverer{
+		manager: manager,
+		repo:    repo,
+		baseDir: baseDir,
+	}
+}
+
+// Scan walks the plugin directory, launches every binary built for this
+// platform to read its metadata, and upserts the matching plugin records.
+func (d *Discoverer) Scan(ctx context.Context) (*ScanResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := &ScanResult{
+		Registered: []*models.Plugin{},
+		Updated:    []*models.Plugin{},
+		Skipped:    []ScanIssue{},
+	}
+
+	if _, err := os.Stat(d.baseDir); os.IsNotExist(err) {
+		return result, nil
+	}
+
+	err := filepath.WalkDir(d.baseDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() || entry.Name() != PluginBinaryName {
+			return nil
+		}
+
+		record, created, err := d.register(ctx, path)
+		switch {
+		case err != nil:
+			result.Skipped = append(result.Skipped, ScanIssue{Path: path, Reason: err.Error()})
+		case record == nil:
+			result.Unchanged++
+		case created:
+			result.Registered = append(result.Registered, record)
+		default:
+			result.Updated = append(result.Updated, record)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan plugin directory '%s': %w", d.baseDir, err)
+	}
+
+	return result, nil
+}
+
+// register upserts the record for a single binary. It returns a nil record
+// when the plugin is already known and nothing changed.
+func (d *Discoverer) register(ctx context.Context, path string) (*models.Plugin, bool, error) {
+	pluginPath, err := ParsePluginPath(d.baseDir, path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if pluginPath.OS != runtime.GOOS || pluginPath.Arch != runtime.GOARCH {
+		return nil, false, fmt.Errorf("built for %s_%s, host is %s_%s",
+			pluginPath.OS, pluginPath.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// A binary dropped on disk must pass the same trust check as an install
+	if err := d.verify(path, pluginPath.Namespace, checksum); err != nil {
+		return nil, false, err
+	}
+
+	version := pluginPath.SemanticVersion()
+	existing, err := d.repo.FindByNamespaceNameAndVersion(pluginPath.Namespace, pluginPath.Name, version)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing != nil && existing.Status == models.PluginStatusActive {
+		// Never swap the record of a running plugin underneath it
+		if existing.Checksum != "" && existing.Checksum != checksum {
+			return nil, false, fmt.Errorf("binary changed on disk while plugin is active; deactivate it before rescanning")
+		}
+		return nil, false, nil
+	}
+	if existing != nil && existing.BinaryPath == path && existing.Checksum == checksum {
+		return nil, false, nil
+	}
+
+	metadata, negotiated, err := d.manager.InspectPlugin(ctx, scanSpec(pluginPath, existing, path, checksum))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if metadata.Name != pluginPath.Name {
+		return nil, false, fmt.Errorf("plugin reports name '%s' but lives in directory '%s'", metadata.Name, pluginPath.Name)
+	}
+
+	record := existing
+	created := record == nil
+	if created {
+		record = &models.Plugin{
+			Namespace: pluginPath.Namespace,
+			Name:      pluginPath.Name,
+			Version:   version,
+			Status:    models.PluginStatusInactive,
+			Protocol:  models.PluginProtocolGRPC,
+		}
+	}
+
+	record.Type = pluginPath.Type
+	record.Description = metadata.Description
+	record.BinaryPath = path
+	record.Checksum = checksum
+	record.ProtocolVersion = negotiated
+	record.OS = pluginPath.OS
+	record.Arch = pluginPath.Arch
+	if record.Metadata == nil {
+		record.Metadata = models.JSONMap{}
+	}
+	record.Metadata["methods"] = MethodsFromResponse(metadata)
+	record.Metadata["config_schema"] = ConfigSchemaFromResponse(metadata)
+	record.Metadata["capabilities"] = metadata.Capabilities
+
+	if created {
+		err = d.repo.Create(record)
+	} else {
+		err = d.repo.Update(record)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return record, created, nil
+}
+
+// scanSpec is the spec a scanned binary is inspected with. It carries the
+// namespace, so the binary runs in the namespace's sandbox, and for known
+// plugins the pool, lifecycle and resource limits they are loaded with.
+func scanSpec(pluginPath *PluginPath, existing *models.Plugin, path, checksum string) PluginSpec {
+	spec := PluginSpec{
+		Namespace: pluginPath.Namespace,
+		Name:      pluginPath.Name,
+		Version:   pluginPath.SemanticVersion(),
+	}
+	if existing != nil {
+		spec = SpecFromModel(existing)
+	}
+	spec.Path = path
+	spec.Checksum = checksum
+	return spec
+}
+
+// verify checks a binary against the trusted publisher keys of its
+// namespace. The signature, if any, is read from SignatureFileName next to it.
+func (d *Discoverer) verify(path, namespace, checksum string) error {
+	signature, err := os.ReadFile(filepath.Join(filepath.Dir(path), SignatureFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read plugin signature: %w", err)
+	}
+
+	digest, err := DecodeChecksum(checksum)
+	if err != nil {
+		return err
+	}
+
+	return d.manager.trustStore.Verify(digest, Integrity{
+		Namespace: namespace,
+		Checksum:  checksum,
+		Signature: strings.TrimSpace(string(signature)),
+	})
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open plugin binary: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("failed to hash plugin binary: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
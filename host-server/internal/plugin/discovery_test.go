@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+)
+
+// writeScannedBinary places a fake plugin binary in the scan layout and
+// returns its path and SHA-256 digest
+func writeScannedBinary(t *testing.T, baseDir, namespace string) (string, []byte) {
+	t.Helper()
+
+	dir := filepath.Join(baseDir, namespace, "data-processing", "converter", "v1.0.0", runtime.GOOS+"_"+runtime.GOARCH)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("#!/bin/sh\nexit 1\n")
+	path := filepath.Join(dir, PluginBinaryName)
+	if err := os.WriteFile(path, content, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256(content)
+	return path, digest[:]
+}
+
+func TestDiscoverer_RejectsUnsignedBinaryInTrustedNamespace(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewTrustStore(map[string][]string{
+		"official": {base64.StdEncoding.EncodeToString(publicKey)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseDir := t.TempDir()
+	d := NewDiscoverer(NewManager(nil, &ManagerConfig{TrustStore: store}), nil, baseDir)
+	path, digest := writeScannedBinary(t, baseDir, "official")
+
+	_, _, err = d.register(context.Background(), path)
+	if !IsIntegrityCheckFailed(err) {
+		t.Fatalf("register() error = %v, want an integrity check failure for an unsigned binary", err)
+	}
+
+	forged := ed25519.Sign(privateKey, []byte("something else"))
+	sigPath := filepath.Join(filepath.Dir(path), SignatureFileName)
+	if err := os.WriteFile(sigPath, []byte(base64.StdEncoding.EncodeToString(forged)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.register(context.Background(), path); !IsIntegrityCheckFailed(err) {
+		t.Fatalf("register() error = %v, want an integrity check failure for a forged signature", err)
+	}
+
+	signature := ed25519.Sign(privateKey, digest)
+	if err := os.WriteFile(sigPath, []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.verify(path, "official", hex.EncodeToString(digest)); err != nil {
+		t.Errorf("verify() error = %v, want a signed binary to pass", err)
+	}
+}
+
+func TestScanSpec(t *testing.T) {
+	pluginPath := &PluginPath{Namespace: "untrusted", Name: "converter", Version: "v1.0.0"}
+
+	spec := scanSpec(pluginPath, nil, "/plugins/new", "abc")
+	if spec.Namespace != "untrusted" || spec.Version != "1.0.0" || spec.Path != "/plugins/new" || spec.Checksum != "abc" {
+		t.Errorf("scanSpec() = %+v, want the namespace, version, path and checksum of the scanned binary", spec)
+	}
+
+	existing := &models.Plugin{
+		ID: 7, Namespace: "untrusted", Name: "converter", Version: "1.0.0", BinaryPath: "/plugins/old",
+		Config: models.JSONMap{"limits": map[string]any{"memory": "64MiB"}},
+	}
+	spec = scanSpec(pluginPath, existing, "/plugins/new", "abc")
+	if spec.ID != 7 || spec.Namespace != "untrusted" || spec.Limits == nil {
+		t.Errorf("scanSpec() = %+v, want the known plugin's namespace and limits", spec)
+	}
+	if spec.Path != "/plugins/new" || spec.Checksum != "abc" {
+		t.Errorf("scanSpec() path = %s, checksum = %s, want the scanned binary's", spec.Path, spec.Checksum)
+	}
+}
@@ -0,0 +1,222 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// StateStore keeps the key-value state plugins read and write through host
+// services. State is scoped by plugin namespace and name.
+type StateStore interface {
+	Get(namespace, plugin, key string) ([]byte, bool, error)
+	Set(namespace, plugin, key string, value []byte) error
+	CompareAndSet(namespace, plugin, key string, expected, value []byte) (bool, error)
+	Delete(namespace, plugin, key string) error
+}
+
+// ErrNoStateStore is returned by the state host services when the manager
+// has no StateStore
+var ErrNoStateStore = errors.New("plugin state is not available")
+
+// CallPolicies decides whether a plugin may make a call to another plugin
+// through host services. The policy Enforcer implements it.
+type CallPolicies interface {
+	Authorize(ctx context.Context, record *models.Plugin, method string, params map[string]any) error
+}
+
+// secretEnvPrefix marks a configured secret whose value is read from the
+// host environment variable named after it
+const secretEnvPrefix = "env:"
+
+// hostServices implements common.HostServices for the processes of one
+// plugin. State and secrets are those of the plugin's namespace and name.
+type hostServices struct {
+	m    *Manager
+	spec PluginSpec
+}
+
+// connectHost hands a plugin process its host services. Plugins that predate
+// them or do not use them keep running without.
+func (m *Manager) connectHost(ctx context.Context, raw any, spec PluginSpec) error {
+	connector, ok := raw.(interface {
+		ConnectHost(ctx context.Context, host common.HostServices) error
+	})
+	if !ok {
+		return nil
+	}
+
+	err := connector.ConnectHost(ctx, &hostServices{m: m, spec: spec})
+	if errors.Is(err, common.ErrHostServicesUnsupported) {
+		return nil
+	}
+	return err
+}
+
+// Log records an entry like the plugin's own output: in the host log and the
+// plugin's log buffer, tagged with the plugin
+func (h *hostServices) Log(ctx context.Context, level, message string, fields map[string]string) error {
+	args := make([]any, 0, 2*len(fields))
+	for key, value := range fields {
+		args = append(args, key, value)
+	}
+	h.m.logSinkFor(h.spec).Accept("host", logLevel(level), message, args...)
+	return nil
+}
+
+func logLevel(level string) hclog.Level {
+	switch strings.ToLower(level) {
+	case common.LogLevelDebug:
+		return hclog.Debug
+	case common.LogLevelWarn:
+		return hclog.Warn
+	case common.LogLevelError:
+		return hclog.Error
+	default:
+		return hclog.Info
+	}
+}
+
+func (h *hostServices) GetState(ctx context.Context, key string) ([]byte, bool, error) {
+	if h.m.state == nil {
+		return nil, false, ErrNoStateStore
+	}
+	return h.m.state.Get(h.spec.Namespace, h.spec.Name, key)
+}
+
+func (h *hostServices) SetState(ctx context.Context, key string, value []byte) error {
+	if h.m.state == nil {
+		return ErrNoStateStore
+	}
+	return h.m.state.Set(h.spec.Namespace, h.spec.Name, key, value)
+}
+
+func (h *hostServices) CompareAndSetState(ctx context.Context, key string, expected, value []byte) (bool, error) {
+	if h.m.state == nil {
+		return false, ErrNoStateStore
+	}
+	return h.m.state.CompareAndSet(h.spec.Namespace, h.spec.Name, key, expected, value)
+}
+
+func (h *hostServices) DeleteState(ctx context.Context, key string) error {
+	if h.m.state == nil {
+		return ErrNoStateStore
+	}
+	return h.m.state.Delete(h.spec.Namespace, h.spec.Name, key)
+}
+
+// GetSecret returns a secret configured for the plugin as namespace/name,
+// so a plugin of the same name in another namespace cannot read it. Secrets
+// configured as "env:NAME" are read from the host environment on every call.
+func (h *hostServices) GetSecret(ctx context.Context, name string) (string, error) {
+	value, ok := h.m.secrets[h.spec.Namespace+"/"+h.spec.Name][name]
+	if !ok {
+		return "", common.ErrSecretNotFound
+	}
+	if variable, fromEnv := strings.CutPrefix(value, secretEnvPrefix); fromEnv {
+		value, ok = os.LookupEnv(variable)
+		if !ok {
+			return "", common.ErrSecretNotFound
+		}
+	}
+	return value, nil
+}
+
+// CallPlugin executes a method of another loaded plugin. The target is a
+// plugin name, in the caller's namespace or as namespace/name, optionally
+// followed by @ and a version selector. Plugins can only call plugins of
+// their own namespace and those their namespace is allowed to call, see
+// ManagerConfig.HostCalls. Parameters are checked against the target
+// method's descriptor like those of API calls and are then checked against
+// the policies with the caller as principal, plugin:<namespace>/<name>. The
+// call is bounded by the target's call timeout.
+func (h *hostServices) CallPlugin(ctx context.Context, target, method string, params *structpb.Struct) (*structpb.Value, error) {
+	record, err := h.m.resolveCallTarget(ctx, h.spec, target)
+	if err != nil {
+		return nil, err
+	}
+
+	callParams, err := BuildCallParams(record, MethodsFromMetadata(record.Metadata), method, params.AsMap())
+	if err != nil {
+		return nil, err
+	}
+
+	if h.m.policies != nil {
+		callerCtx := auth.WithPrincipal(ctx, auth.PluginPrincipal(h.spec.Namespace, h.spec.Name))
+		if err := h.m.policies.Authorize(callerCtx, record, method, callParams.AsMap()); err != nil {
+			return nil, err
+		}
+	}
+
+	clientInterface, err := h.m.GetPluginClient(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	pluginClient, ok := clientInterface.(common.ContextPluginInterface)
+	if !ok {
+		return nil, fmt.Errorf("plugin '%s' does not implement common.ContextPluginInterface", target)
+	}
+
+	callCtx, cancel := h.m.CallContext(ctx, record.ID)
+	defer cancel()
+
+	resp, err := pluginClient.ExecuteContext(callCtx, method, callParams)
+	if err != nil {
+		return nil, fmt.Errorf("call to plugin '%s' failed: %w", target, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("plugin '%s' returned error: %s", target, resp.GetError())
+	}
+	return resp.Result, nil
+}
+
+// resolveCallTarget finds the plugin version a caller's target names. The
+// version is selected like for calls by name through the API: among the
+// active versions, with the plugin's pinned aliases, latest by default. A
+// plugin cannot call itself: the call could wait for the very process that
+// makes it.
+func (m *Manager) resolveCallTarget(ctx context.Context, caller PluginSpec, target string) (*models.Plugin, error) {
+	if m.versions == nil {
+		return nil, fmt.Errorf("calls to other plugins are not available")
+	}
+
+	target, selector, _ := strings.Cut(target, "@")
+	namespace, name := caller.Namespace, target
+	if ns, n, qualified := strings.Cut(target, "/"); qualified {
+		namespace, name = ns, n
+	}
+	if namespace == caller.Namespace && name == caller.Name {
+		return nil, fmt.Errorf("plugin '%s' cannot call itself", target)
+	}
+	if !m.mayCall(caller.Namespace, namespace) {
+		return nil, fmt.Errorf("plugins of namespace '%s' may not call plugins of namespace '%s'", caller.Namespace, namespace)
+	}
+
+	record, err := m.versions.Select(ctx, namespace, name, selector)
+	if err != nil {
+		return nil, fmt.Errorf("plugin '%s/%s': %w", namespace, name, err)
+	}
+	return record, nil
+}
+
+// mayCall reports whether plugins of namespace caller may call plugins of
+// namespace target through host services
+func (m *Manager) mayCall(caller, target string) bool {
+	if caller == target {
+		return true
+	}
+	for _, allowed := range m.hostCalls[caller] {
+		if allowed == target || allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
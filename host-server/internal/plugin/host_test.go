@@ -0,0 +1,260 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/repository"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// memoryStateStore is a StateStore backed by common.MemoryHost, keyed by
+// namespace/plugin/key
+type memoryStateStore struct {
+	host common.MemoryHost
+}
+
+func (s *memoryStateStore) Get(namespace, plugin, key string) ([]byte, bool, error) {
+	return s.host.GetState(context.Background(), namespace+"/"+plugin+"/"+key)
+}
+
+func (s *memoryStateStore) Set(namespace, plugin, key string, value []byte) error {
+	return s.host.SetState(context.Background(), namespace+"/"+plugin+"/"+key, value)
+}
+
+func (s *memoryStateStore) CompareAndSet(namespace, plugin, key string, expected, value []byte) (bool, error) {
+	return s.host.CompareAndSetState(context.Background(), namespace+"/"+plugin+"/"+key, expected, value)
+}
+
+func (s *memoryStateStore) Delete(namespace, plugin, key string) error {
+	return s.host.DeleteState(context.Background(), namespace+"/"+plugin+"/"+key)
+}
+
+func TestHostServices_StateIsScopedToPlugin(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(nil, &ManagerConfig{State: &memoryStateStore{}})
+	a := &hostServices{m: m, spec: PluginSpec{Namespace: "builtin", Name: "dpanonymizer"}}
+	b := &hostServices{m: m, spec: PluginSpec{Namespace: "builtin", Name: "converter"}}
+
+	if err := a.SetState(ctx, "budget", []byte("1")); err != nil {
+		t.Fatalf("SetState() error = %v", err)
+	}
+	if _, found, _ := b.GetState(ctx, "budget"); found {
+		t.Error("GetState() found another plugin's state")
+	}
+
+	written, err := a.CompareAndSetState(ctx, "budget", []byte("0"), []byte("2"))
+	if err != nil || written {
+		t.Errorf("CompareAndSetState() with stale value = %v, %v, want false", written, err)
+	}
+	written, err = a.CompareAndSetState(ctx, "budget", []byte("1"), []byte("2"))
+	if err != nil || !written {
+		t.Errorf("CompareAndSetState() = %v, %v, want true", written, err)
+	}
+	if value, _, _ := a.GetState(ctx, "budget"); string(value) != "2" {
+		t.Errorf("GetState() = %q, want %q", value, "2")
+	}
+}
+
+func TestHostServices_WithoutStateStore(t *testing.T) {
+	h := &hostServices{m: NewManager(nil, &ManagerConfig{}), spec: PluginSpec{Name: "dpanonymizer"}}
+	if _, _, err := h.GetState(context.Background(), "budget"); !errors.Is(err, ErrNoStateStore) {
+		t.Errorf("GetState() error = %v, want ErrNoStateStore", err)
+	}
+}
+
+func TestHostServices_GetSecret(t *testing.T) {
+	t.Setenv("TEST_TOKENIZATION_KEY", "from-env")
+	m := NewManager(nil, &ManagerConfig{Secrets: map[string]map[string]string{
+		"default/desensitization": {
+			"tokenization_key": "env:TEST_TOKENIZATION_KEY",
+			"api_key":          "inline",
+			"missing":          "env:TEST_UNSET_VARIABLE",
+		},
+	}})
+	h := &hostServices{m: m, spec: PluginSpec{Namespace: "default", Name: "desensitization"}}
+	ctx := context.Background()
+
+	if value, err := h.GetSecret(ctx, "tokenization_key"); err != nil || value != "from-env" {
+		t.Errorf("GetSecret(tokenization_key) = %q, %v, want %q", value, err, "from-env")
+	}
+	if value, err := h.GetSecret(ctx, "api_key"); err != nil || value != "inline" {
+		t.Errorf("GetSecret(api_key) = %q, %v, want %q", value, err, "inline")
+	}
+	for _, name := range []string{"missing", "unknown"} {
+		if _, err := h.GetSecret(ctx, name); !errors.Is(err, common.ErrSecretNotFound) {
+			t.Errorf("GetSecret(%s) error = %v, want ErrSecretNotFound", name, err)
+		}
+	}
+
+	other := &hostServices{m: m, spec: PluginSpec{Namespace: "default", Name: "converter"}}
+	if _, err := other.GetSecret(ctx, "api_key"); !errors.Is(err, common.ErrSecretNotFound) {
+		t.Errorf("GetSecret() of another plugin's secret error = %v, want ErrSecretNotFound", err)
+	}
+	namesake := &hostServices{m: m, spec: PluginSpec{Namespace: "community", Name: "desensitization"}}
+	if _, err := namesake.GetSecret(ctx, "api_key"); !errors.Is(err, common.ErrSecretNotFound) {
+		t.Errorf("GetSecret() of a namesake in another namespace error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+// recordRepo serves fixed plugin records
+type recordRepo struct {
+	repository.PluginRepository
+	records map[uint]*models.Plugin
+}
+
+func (r *recordRepo) FindAll(filters map[string]any) ([]*models.Plugin, error) {
+	var found []*models.Plugin
+	for _, record := range r.records {
+		if record.Namespace == filters["namespace"] && record.Name == filters["name"] && record.Status == filters["status"] {
+			found = append(found, record)
+		}
+	}
+	return found, nil
+}
+
+func (r *recordRepo) WithContext(ctx context.Context) repository.PluginRepository {
+	return r
+}
+
+func (r *recordRepo) FindByID(id uint) (*models.Plugin, error) {
+	record, ok := r.records[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return record, nil
+}
+
+func TestHostServices_CallPluginValidatesParams(t *testing.T) {
+	m, starter := newTestManager(LifecycleConfig{})
+	m.versions = NewVersionSelector(&recordRepo{records: map[uint]*models.Plugin{
+		1: {ID: 1, Namespace: "builtin", Name: "converter", Version: "1.0.0", Status: models.PluginStatusActive, Metadata: models.JSONMap{
+			"methods": []MethodMetadata{{
+				Name:       "ConvertToCSV",
+				Parameters: map[string]ParamSchema{"data": {Type: "string", Required: true}},
+			}},
+		}},
+	}}, nil)
+	if err := m.LoadPlugin(PluginSpec{ID: 1, Namespace: "builtin", Name: "converter"}); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+	caller := &hostServices{m: m, spec: PluginSpec{ID: 2, Namespace: "builtin", Name: "desensitization"}}
+
+	invalid, _ := structpb.NewStruct(map[string]any{"rows": "a,b"})
+	if _, err := caller.CallPlugin(context.Background(), "converter", "ConvertToCSV", invalid); !IsInvalidParams(err) {
+		t.Errorf("CallPlugin() error = %v, want invalid params", err)
+	}
+	if _, err := caller.CallPlugin(context.Background(), "converter", "Drop", nil); !IsInvalidParams(err) {
+		t.Errorf("CallPlugin() of an unknown method error = %v, want invalid params", err)
+	}
+
+	valid, _ := structpb.NewStruct(map[string]any{"data": "a,b"})
+	if _, err := caller.CallPlugin(context.Background(), "converter", "ConvertToCSV", valid); err != nil {
+		t.Errorf("CallPlugin() error = %v", err)
+	}
+	if calls := starter.process(0).calls.Load(); calls != 1 {
+		t.Errorf("plugin received %d calls, want only the valid one", calls)
+	}
+}
+
+// denyPrincipal is a CallPolicies denying every call of one principal
+type denyPrincipal string
+
+func (d denyPrincipal) Authorize(ctx context.Context, record *models.Plugin, method string, params map[string]any) error {
+	if principal := auth.PrincipalFrom(ctx); principal != nil && principal.Name == string(d) {
+		return fmt.Errorf("%s may not call %s", principal.Name, method)
+	}
+	return nil
+}
+
+func TestHostServices_CallPluginChecksPolicies(t *testing.T) {
+	m, starter := newTestManager(LifecycleConfig{})
+	m.versions = NewVersionSelector(&recordRepo{records: map[uint]*models.Plugin{
+		1: {ID: 1, Namespace: "builtin", Name: "converter", Version: "1.0.0", Status: models.PluginStatusActive},
+	}}, nil)
+	m.policies = denyPrincipal("plugin:builtin/desensitization")
+	if err := m.LoadPlugin(PluginSpec{ID: 1, Namespace: "builtin", Name: "converter"}); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	denied := &hostServices{m: m, spec: PluginSpec{ID: 2, Namespace: "builtin", Name: "desensitization"}}
+	if _, err := denied.CallPlugin(context.Background(), "converter", "ConvertToCSV", nil); err == nil {
+		t.Error("CallPlugin() of a denied plugin succeeded")
+	}
+	allowed := &hostServices{m: m, spec: PluginSpec{ID: 3, Namespace: "builtin", Name: "dpanonymizer"}}
+	if _, err := allowed.CallPlugin(context.Background(), "converter", "ConvertToCSV", nil); err != nil {
+		t.Errorf("CallPlugin() error = %v", err)
+	}
+	if calls := starter.process(0).calls.Load(); calls != 1 {
+		t.Errorf("plugin received %d calls, want only the allowed one", calls)
+	}
+}
+
+// pinnedAliasRepo serves fixed pinned aliases of every plugin
+type pinnedAliasRepo struct {
+	repository.PluginAliasRepository
+	pinned map[string]string
+}
+
+func (r *pinnedAliasRepo) WithContext(ctx context.Context) repository.PluginAliasRepository {
+	return r
+}
+
+func (r *pinnedAliasRepo) FindAll(namespace, name string) ([]*models.PluginAlias, error) {
+	var aliases []*models.PluginAlias
+	for alias, version := range r.pinned {
+		aliases = append(aliases, &models.PluginAlias{Namespace: namespace, Name: name, Alias: alias, Version: version})
+	}
+	return aliases, nil
+}
+
+func TestResolveCallTarget(t *testing.T) {
+	active := models.PluginStatusActive
+	m := NewManager(nil, &ManagerConfig{
+		HostCalls: map[string][]string{"builtin": {"community"}},
+		Plugins: &recordRepo{records: map[uint]*models.Plugin{
+			1: {ID: 1, Namespace: "builtin", Name: "converter", Version: "1.0.0", Status: active},
+			2: {ID: 2, Namespace: "builtin", Name: "desensitization", Version: "1.0.0", Status: active},
+			3: {ID: 3, Namespace: "builtin", Name: "converter", Version: "1.1.0", Status: active},
+			4: {ID: 4, Namespace: "builtin", Name: "converter", Version: "2.0.0-beta", Status: active},
+			5: {ID: 5, Namespace: "builtin", Name: "converter", Version: "3.0.0", Status: models.PluginStatusInactive},
+			6: {ID: 6, Namespace: "community", Name: "converter", Version: "1.0.0", Status: active},
+			7: {ID: 7, Namespace: "untrusted", Name: "converter", Version: "1.0.0", Status: active},
+		}},
+		Aliases: &pinnedAliasRepo{pinned: map[string]string{"stable": "1.0.0", "canary": "2.0.0-beta"}},
+	})
+	caller := PluginSpec{ID: 2, Namespace: "builtin", Name: "desensitization"}
+
+	tests := []struct {
+		target  string
+		want    uint
+		wantErr bool
+	}{
+		{"converter", 4, false},
+		{"converter@stable", 1, false},
+		{"converter@canary", 4, false},
+		{"converter@1.1.0", 3, false},
+		{"converter@3.0.0", 0, true},
+		{"community/converter", 6, false},
+		{"untrusted/converter", 0, true},
+		{"dpanonymizer", 0, true},
+		{"desensitization", 0, true},
+		{"builtin/desensitization@latest", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, err := m.resolveCallTarget(context.Background(), caller, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveCallTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.ID != tt.want {
+				t.Errorf("resolveCallTarget() = plugin %d, want %d", got.ID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrIntegrityCheckFailed is returned when a plugin binary does not match its
+// expected checksum or carries a signature no trusted publisher made
+var ErrIntegrityCheckFailed = errors.New("plugin integrity check failed")
+
+// IsIntegrityCheckFailed reports whether err was caused by a failed checksum or signature check
+func IsIntegrityCheckFailed(err error) bool {
+	return errors.Is(err, ErrIntegrityCheckFailed)
+}
+
+// Integrity describes what a downloaded plugin binary is expected to be
+type Integrity struct {
+	Namespace string // Namespace whose trusted publisher keys apply
+	Checksum  string // Hex-encoded SHA-256 of the binary
+	Signature string // Optional base64 ed25519 signature over the raw SHA-256 digest
+}
+
+// TrustStore holds the ed25519 public keys of trusted publishers per namespace.
+// A namespace with at least one key only accepts signed plugins.
+type TrustStore struct {
+	keys map[string][]ed25519.PublicKey
+}
+
+// NewTrustStore parses base64-encoded ed25519 public keys keyed by namespace
+func NewTrustStore(keys map[string][]string) (*TrustStore, error) {
+	store := &TrustStore{
+		keys: make(map[string][]ed25519.PublicKey),
+	}
+
+	for namespace, encodedKeys := range keys {
+		for _, encoded := range encodedKeys {
+			raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted key for namespace '%s': %w", namespace, err)
+			}
+			if len(raw) != ed25519.PublicKeySize {
+				return nil, fmt.Errorf("invalid trusted key for namespace '%s': expected %d bytes, got %d",
+					namespace, ed25519.PublicKeySize, len(raw))
+			}
+			store.keys[namespace] = append(store.keys[namespace], ed25519.PublicKey(raw))
+		}
+	}
+
+	return store, nil
+}
+
+// Verify checks a binary's SHA-256 digest against the expected integrity.
+// The signature is mandatory for namespaces with trusted keys and is
+// rejected for namespaces without any, since nothing could vouch for it.
+func (t *TrustStore) Verify(digest []byte, expected Integrity) error {
+	want, err := DecodeChecksum(expected.Checksum)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrIntegrityCheckFailed, err)
+	}
+
+	if !bytes.Equal(digest, want) {
+		return fmt.Errorf("%w: checksum mismatch (expected %s, got %x)",
+			ErrIntegrityCheckFailed, strings.ToLower(expected.Checksum), digest)
+	}
+
+	keys := t.keys[expected.Namespace]
+
+	if expected.Signature == "" {
+		if len(keys) > 0 {
+			return fmt.Errorf("%w: namespace '%s' requires a signed plugin", ErrIntegrityCheckFailed, expected.Namespace)
+		}
+		return nil
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("%w: no trusted publisher keys configured for namespace '%s'",
+			ErrIntegrityCheckFailed, expected.Namespace)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(expected.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature encoding: %v", ErrIntegrityCheckFailed, err)
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, digest, signature) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: signature does not match any trusted publisher of namespace '%s'",
+		ErrIntegrityCheckFailed, expected.Namespace)
+}
+
+// DecodeChecksum decodes a hex-encoded SHA-256 checksum
+func DecodeChecksum(checksum string) ([]byte, error) {
+	sum, err := hex.DecodeString(checksum)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum encoding: %w", err)
+	}
+	if len(sum) != sha256.Size {
+		return nil, fmt.Errorf("invalid checksum length: expected %d bytes, got %d", sha256.Size, len(sum))
+	}
+	return sum, nil
+}
@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func newTestTrustStore(t *testing.T, namespace string) (*TrustStore, ed25519.PrivateKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	store, err := NewTrustStore(map[string][]string{
+		namespace: {base64.StdEncoding.EncodeToString(pub)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create trust store: %v", err)
+	}
+
+	return store, priv
+}
+
+func TestTrustStore_Verify(t *testing.T) {
+	store, priv := newTestTrustStore(t, "official")
+	_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	digest := sha256.Sum256([]byte("plugin binary"))
+	checksum := hex.EncodeToString(digest[:])
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, digest[:]))
+	forged := base64.StdEncoding.EncodeToString(ed25519.Sign(otherPriv, digest[:]))
+
+	tests := []struct {
+		name     string
+		expected Integrity
+		wantErr  bool
+	}{
+		{
+			name:     "checksum only in unsigned namespace",
+			expected: Integrity{Namespace: "third-party", Checksum: checksum},
+			wantErr:  false,
+		},
+		{
+			name:     "checksum mismatch",
+			expected: Integrity{Namespace: "third-party", Checksum: hex.EncodeToString(make([]byte, 32))},
+			wantErr:  true,
+		},
+		{
+			name:     "malformed checksum",
+			expected: Integrity{Namespace: "third-party", Checksum: "abc"},
+			wantErr:  true,
+		},
+		{
+			name:     "valid signature from trusted publisher",
+			expected: Integrity{Namespace: "official", Checksum: checksum, Signature: signature},
+			wantErr:  false,
+		},
+		{
+			name:     "signature required in signed namespace",
+			expected: Integrity{Namespace: "official", Checksum: checksum},
+			wantErr:  true,
+		},
+		{
+			name:     "signature from untrusted key",
+			expected: Integrity{Namespace: "official", Checksum: checksum, Signature: forged},
+			wantErr:  true,
+		},
+		{
+			name:     "signature without trusted keys",
+			expected: Integrity{Namespace: "third-party", Checksum: checksum, Signature: signature},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.Verify(digest[:], tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !IsIntegrityCheckFailed(err) {
+				t.Errorf("Expected integrity error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewTrustStore_InvalidKey(t *testing.T) {
+	if _, err := NewTrustStore(map[string][]string{"official": {"not-base64!"}}); err == nil {
+		t.Error("Expected error for malformed key, got nil")
+	}
+	if _, err := NewTrustStore(map[string][]string{"official": {"c2hvcnQ="}}); err == nil {
+		t.Error("Expected error for short key, got nil")
+	}
+}
@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Runtime states of an active plugin, reported next to its stored status
+const (
+	RuntimeRunning    = "running"    // Processes are up and serving calls
+	RuntimeIdle       = "idle"       // Processes are stopped until the next call
+	RuntimeRestarting = "restarting" // Processes exited and are being restarted
+)
+
+// LifecycleConfig controls when the processes of an active plugin run
+type LifecycleConfig struct {
+	OnDemand     bool          // Start processes on the first call instead of at boot or activation
+	IdleShutdown time.Duration // Stop processes after this long without calls; zero keeps them running
+}
+
+// LifecycleConfigFromMap applies a per-plugin override stored in a plugin's
+// config under "lifecycle", e.g. {"on_demand": true, "idle_shutdown": "30m"},
+// on top of defaults. An idle_shutdown of "0s" keeps the plugin running.
+func LifecycleConfigFromMap(raw map[string]any, defaults LifecycleConfig) (LifecycleConfig, error) {
+	var override struct {
+		OnDemand     *bool   `json:"on_demand"`
+		IdleShutdown *string `json:"idle_shutdown"`
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return LifecycleConfig{}, fmt.Errorf("invalid lifecycle config: %w", err)
+	}
+	if err := json.Unmarshal(data, &override); err != nil {
+		return LifecycleConfig{}, fmt.Errorf("invalid lifecycle config: %w", err)
+	}
+
+	config := defaults
+	if override.OnDemand != nil {
+		config.OnDemand = *override.OnDemand
+	}
+	if override.IdleShutdown != nil {
+		config.IdleShutdown, err = time.ParseDuration(*override.IdleShutdown)
+		if err != nil {
+			return LifecycleConfig{}, fmt.Errorf("invalid lifecycle idle_shutdown: %w", err)
+		}
+		if config.IdleShutdown < 0 {
+			return LifecycleConfig{}, fmt.Errorf("invalid lifecycle idle_shutdown: must not be negative")
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,155 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/repository"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+func newTestManager(lifecycle LifecycleConfig) (*Manager, *fakeStarter) {
+	starter := &fakeStarter{}
+	m := NewManager(nil, &ManagerConfig{Lifecycle: lifecycle})
+	m.start = starter.start
+	return m, starter
+}
+
+// lastUsedRepo serves plugin records with a fixed LastUsedAt
+type lastUsedRepo struct {
+	repository.PluginRepository
+	lastUsedAt *int64
+}
+
+func (r *lastUsedRepo) FindByID(id uint) (*models.Plugin, error) {
+	return &models.Plugin{ID: id, Name: "test", LastUsedAt: r.lastUsedAt}, nil
+}
+
+func TestLifecycleConfigFromMap(t *testing.T) {
+	defaults := LifecycleConfig{OnDemand: true, IdleShutdown: time.Hour}
+
+	config, err := LifecycleConfigFromMap(map[string]any{"on_demand": false}, defaults)
+	if err != nil {
+		t.Fatalf("LifecycleConfigFromMap() error = %v", err)
+	}
+	if config.OnDemand || config.IdleShutdown != time.Hour {
+		t.Errorf("config = %+v, want on_demand overridden and idle_shutdown kept", config)
+	}
+
+	config, err = LifecycleConfigFromMap(map[string]any{"idle_shutdown": "0s"}, defaults)
+	if err != nil {
+		t.Fatalf("LifecycleConfigFromMap() error = %v", err)
+	}
+	if !config.OnDemand || config.IdleShutdown != 0 {
+		t.Errorf("config = %+v, want idle shutdown disabled", config)
+	}
+
+	if _, err := LifecycleConfigFromMap(map[string]any{"idle_shutdown": "-1m"}, defaults); err == nil {
+		t.Errorf("LifecycleConfigFromMap() accepted a negative idle_shutdown")
+	}
+}
+
+func TestManager_IdlePluginStartsOnFirstCall(t *testing.T) {
+	m, starter := newTestManager(LifecycleConfig{OnDemand: true})
+	spec := PluginSpec{ID: 1, Name: "test"}
+
+	if err := m.LoadPluginIdle(spec); err != nil {
+		t.Fatalf("LoadPluginIdle() error = %v", err)
+	}
+	if status := m.RuntimeStatus(1); status != RuntimeIdle {
+		t.Fatalf("RuntimeStatus() = %q, want %q", status, RuntimeIdle)
+	}
+	if err := m.CheckHealth(context.Background(), 1); err != nil {
+		t.Errorf("CheckHealth() error = %v, want nil for an idle plugin", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			raw, err := m.GetPluginClient(1)
+			if err != nil {
+				t.Errorf("GetPluginClient() error = %v", err)
+				return
+			}
+			if _, err := raw.(common.ContextPluginInterface).ExecuteContext(context.Background(), "Ping", nil); err != nil {
+				t.Errorf("ExecuteContext() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if starts := starter.starts.Load(); starts != 1 {
+		t.Errorf("concurrent first calls started %d processes, want 1", starts)
+	}
+	if status := m.RuntimeStatus(1); status != RuntimeRunning {
+		t.Errorf("RuntimeStatus() = %q, want %q", status, RuntimeRunning)
+	}
+
+	m.UnloadPlugin(1)
+	if status := m.RuntimeStatus(1); status != "" {
+		t.Errorf("RuntimeStatus() = %q after unload, want empty", status)
+	}
+}
+
+func TestManager_SuspendPlugin(t *testing.T) {
+	m, starter := newTestManager(LifecycleConfig{})
+	if err := m.LoadPlugin(PluginSpec{ID: 1, Name: "test"}); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	p, _ := m.runningPool(1)
+	w, _ := p.acquire()
+	if m.SuspendPlugin(1) {
+		t.Fatalf("SuspendPlugin() stopped a plugin with a call in flight")
+	}
+	p.release(w)
+
+	if !m.SuspendPlugin(1) {
+		t.Fatalf("SuspendPlugin() = false for a plugin without calls")
+	}
+	if !starter.process(0).Exited() || m.RuntimeStatus(1) != RuntimeIdle {
+		t.Fatalf("suspended plugin: exited = %v, status = %q", starter.process(0).Exited(), m.RuntimeStatus(1))
+	}
+
+	if _, err := m.GetPluginClient(1); err != nil {
+		t.Fatalf("GetPluginClient() error = %v", err)
+	}
+	if starts := starter.starts.Load(); starts != 2 {
+		t.Errorf("call after suspension started %d processes in total, want 2", starts)
+	}
+}
+
+func TestSupervisor_StopIdlePlugins(t *testing.T) {
+	m, _ := newTestManager(LifecycleConfig{IdleShutdown: time.Minute})
+	if err := m.LoadPlugin(PluginSpec{ID: 1, Name: "test"}); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	start := time.Now()
+	repo := &lastUsedRepo{}
+	s := NewSupervisor(m, repo, nil)
+
+	// Never called, but started too recently
+	s.stopIdlePlugins(start.Add(30 * time.Second))
+	if status := m.RuntimeStatus(1); status != RuntimeRunning {
+		t.Fatalf("RuntimeStatus() = %q right after start, want %q", status, RuntimeRunning)
+	}
+
+	// Running for long enough, but called 30 seconds ago
+	lastUsedAt := start.Add(90 * time.Second).Unix()
+	repo.lastUsedAt = &lastUsedAt
+	s.stopIdlePlugins(start.Add(2 * time.Minute))
+	if status := m.RuntimeStatus(1); status != RuntimeRunning {
+		t.Fatalf("RuntimeStatus() = %q after a recent call, want %q", status, RuntimeRunning)
+	}
+
+	s.stopIdlePlugins(start.Add(3 * time.Minute))
+	if status := m.RuntimeStatus(1); status != RuntimeIdle {
+		t.Errorf("RuntimeStatus() = %q after idle shutdown, want %q", status, RuntimeIdle)
+	}
+}
@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResourceLimits bound what the processes of one plugin may use. Memory, CPU
+// and Processes apply to all processes of the plugin together and are
+// enforced through a cgroup v2; OpenFiles applies to each process. Zero
+// fields are unlimited.
+type ResourceLimits struct {
+	Memory      int64         // Bytes of memory, swap excluded
+	CPU         float64       // CPU cores, e.g. 0.5 for half a core
+	OpenFiles   uint64        // Open file descriptors per process
+	Processes   int64         // Processes and threads
+	CallTimeout time.Duration // Wall-clock time of a single call
+}
+
+func (l ResourceLimits) needsCgroup() bool {
+	return l.Memory > 0 || l.CPU > 0 || l.Processes > 0
+}
+
+func (l ResourceLimits) confinesProcesses() bool {
+	return l.needsCgroup() || l.OpenFiles > 0
+}
+
+// sameConfinement reports whether processes are confined alike under l and
+// other. Only the call timeout may differ, since it is not applied through
+// the plugin's confinement.
+func (l ResourceLimits) sameConfinement(other ResourceLimits) bool {
+	l.CallTimeout, other.CallTimeout = 0, 0
+	return l == other
+}
+
+// LimitsFromMap reads the resource limits stored in a plugin's config under
+// "limits", e.g. {"memory": "256MiB", "cpu": 0.5, "open_files": 256,
+// "processes": 64, "call_timeout": "10s"}. Memory is a number of bytes or a
+// size with a K, M or G (or KiB, MiB, GiB) suffix.
+func LimitsFromMap(raw map[string]any) (ResourceLimits, error) {
+	var config struct {
+		Memory      any     `json:"memory"`
+		CPU         float64 `json:"cpu"`
+		OpenFiles   uint64  `json:"open_files"`
+		Processes   int64   `json:"processes"`
+		CallTimeout string  `json:"call_timeout"`
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return ResourceLimits{}, fmt.Errorf("invalid limits config: %w", err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ResourceLimits{}, fmt.Errorf("invalid limits config: %w", err)
+	}
+
+	limits := ResourceLimits{
+		CPU:       config.CPU,
+		OpenFiles: config.OpenFiles,
+		Processes: config.Processes,
+	}
+	if limits.CPU < 0 || limits.Processes < 0 {
+		return ResourceLimits{}, fmt.Errorf("invalid limits config: cpu and processes must not be negative")
+	}
+
+	switch memory := config.Memory.(type) {
+	case nil:
+	case float64:
+		limits.Memory = int64(memory)
+	case string:
+		limits.Memory, err = parseByteSize(memory)
+		if err != nil {
+			return ResourceLimits{}, fmt.Errorf("invalid limits memory: %w", err)
+		}
+	default:
+		return ResourceLimits{}, fmt.Errorf("invalid limits memory: expected a number of bytes or a size string")
+	}
+	if limits.Memory < 0 {
+		return ResourceLimits{}, fmt.Errorf("invalid limits memory: must not be negative")
+	}
+
+	if config.CallTimeout != "" {
+		limits.CallTimeout, err = time.ParseDuration(config.CallTimeout)
+		if err != nil {
+			return ResourceLimits{}, fmt.Errorf("invalid limits call_timeout: %w", err)
+		}
+		if limits.CallTimeout < 0 {
+			return ResourceLimits{}, fmt.Errorf("invalid limits call_timeout: must not be negative")
+		}
+	}
+
+	return limits, nil
+}
+
+// parseByteSize parses sizes such as "512", "64K", "256MiB" or "1G". The
+// suffixes are binary multiples, as with cgroup and ulimit.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	number := strings.TrimRight(s, "KMGiBkmgb")
+	unit := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(s[len(number):]), "B"), "I")
+
+	multipliers := map[string]int64{"": 1, "K": 1 << 10, "M": 1 << 20, "G": 1 << 30}
+	multiplier, ok := multipliers[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit in '%s'", s)
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size '%s'", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// formatByteSize formats a byte count with the largest binary unit that keeps it whole
+func formatByteSize(n int64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if n >= unit.size && n%unit.size == 0 {
+			return fmt.Sprintf("%d %s", n/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+// confinement applies the resource limits of one plugin to its processes.
+// It is created when the plugin's first process starts and kept across
+// restarts, so the limit violations it reports cover the whole time the
+// plugin was loaded.
+type confinement interface {
+	// prepare sets up cmd to start inside the confinement. The returned
+	// function is called once the process has started.
+	prepare(cmd *exec.Cmd) (func(), error)
+	// apply sets the limits that can only be set on a running process
+	apply(pid int) error
+	// violation describes the limits the processes ran into, or is empty
+	violation() string
+	close()
+}
+
+// noConfinement is used for plugins without process limits
+type noConfinement struct{}
+
+func (noConfinement) prepare(cmd *exec.Cmd) (func(), error) { return func() {}, nil }
+func (noConfinement) apply(pid int) error                   { return nil }
+func (noConfinement) violation() string                     { return "" }
+func (noConfinement) close()                                {}
@@ -0,0 +1,198 @@
+//go:build linux
+
+package plugin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// cgroupCPUPeriod is the cpu.max period, in microseconds, CPU limits are
+// converted to a quota of
+const cgroupCPUPeriod = 100000
+
+// cgroupConfinement starts the processes of a plugin in a cgroup of their own
+// below the cgroup root and caps their open files with prlimit
+type cgroupConfinement struct {
+	limits   ResourceLimits
+	path     string // Empty when only per-process limits are set
+	baseline cgroupEvents
+}
+
+// cgroupEvents counts how often the cgroup enforced a limit
+type cgroupEvents struct {
+	oomKills int64 // "oom_kill" in memory.events
+	pidsMax  int64 // "max" in pids.events
+}
+
+func newConfinement(cgroupRoot string, spec PluginSpec, limits ResourceLimits) (confinement, error) {
+	if !limits.confinesProcesses() {
+		return noConfinement{}, nil
+	}
+
+	c := &cgroupConfinement{limits: limits}
+	if !limits.needsCgroup() {
+		return c, nil
+	}
+
+	if err := enableControllers(cgroupRoot); err != nil {
+		return nil, err
+	}
+
+	c.path = filepath.Join(cgroupRoot, fmt.Sprintf("plugin-%d", spec.ID))
+	if err := os.Mkdir(c.path, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+
+	// Every file is written, so limits removed since an earlier load are lifted
+	memory, swap := "max", "max"
+	if limits.Memory > 0 {
+		memory, swap = strconv.FormatInt(limits.Memory, 10), "0"
+	}
+	cpu := fmt.Sprintf("max %d", cgroupCPUPeriod)
+	if limits.CPU > 0 {
+		cpu = fmt.Sprintf("%d %d", int64(limits.CPU*cgroupCPUPeriod), cgroupCPUPeriod)
+	}
+	pids := "max"
+	if limits.Processes > 0 {
+		pids = strconv.FormatInt(limits.Processes, 10)
+	}
+
+	settings := []struct {
+		file, value string
+	}{
+		{"memory.max", memory},
+		{"memory.swap.max", swap},
+		{"cpu.max", cpu},
+		{"pids.max", pids},
+	}
+	for _, setting := range settings {
+		err := os.WriteFile(filepath.Join(c.path, setting.file), []byte(setting.value), 0)
+		if err != nil {
+			// memory.swap.max is missing when swap accounting is disabled
+			if setting.file == "memory.swap.max" && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			os.Remove(c.path)
+			return nil, fmt.Errorf("failed to set cgroup %s: %w", setting.file, err)
+		}
+	}
+
+	c.baseline = c.events()
+	return c, nil
+}
+
+// enableControllers makes the cpu, memory and pids controllers available to
+// the cgroups below root. Root must be a cgroup v2 directory the host may
+// write to and must not contain processes itself.
+func enableControllers(root string) error {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return fmt.Errorf("failed to create cgroup root '%s': %w", root, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return fmt.Errorf("cgroup root '%s' is not a cgroup v2 directory: %w", root, err)
+	}
+
+	control := filepath.Join(root, "cgroup.subtree_control")
+	if err := os.WriteFile(control, []byte("+cpu +memory +pids"), 0); err != nil {
+		return fmt.Errorf("failed to enable cgroup controllers in '%s': %w", root, err)
+	}
+	return nil
+}
+
+func (c *cgroupConfinement) prepare(cmd *exec.Cmd) (func(), error) {
+	if c.path == "" {
+		return func() {}, nil
+	}
+
+	dir, err := os.Open(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cgroup: %w", err)
+	}
+
+	// The process is placed in the cgroup when it is created, before it runs
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(dir.Fd())
+
+	return func() { dir.Close() }, nil
+}
+
+func (c *cgroupConfinement) apply(pid int) error {
+	if c.limits.OpenFiles == 0 {
+		return nil
+	}
+
+	limit := &unix.Rlimit{Cur: c.limits.OpenFiles, Max: c.limits.OpenFiles}
+	if err := unix.Prlimit(pid, unix.RLIMIT_NOFILE, limit, nil); err != nil {
+		return fmt.Errorf("failed to limit open files: %w", err)
+	}
+	return nil
+}
+
+func (c *cgroupConfinement) violation() string {
+	if c.path == "" {
+		return ""
+	}
+
+	events := c.events()
+	var violations []string
+	if n := events.oomKills - c.baseline.oomKills; n > 0 {
+		violations = append(violations, fmt.Sprintf("memory limit of %s exceeded, %d process(es) killed", formatByteSize(c.limits.Memory), n))
+	}
+	if n := events.pidsMax - c.baseline.pidsMax; n > 0 {
+		violations = append(violations, fmt.Sprintf("process limit of %d reached %d time(s)", c.limits.Processes, n))
+	}
+	return strings.Join(violations, "; ")
+}
+
+func (c *cgroupConfinement) events() cgroupEvents {
+	return cgroupEvents{
+		oomKills: readCgroupCounter(filepath.Join(c.path, "memory.events"), "oom_kill"),
+		pidsMax:  readCgroupCounter(filepath.Join(c.path, "pids.events"), "max"),
+	}
+}
+
+// readCgroupCounter reads one counter of a flat-keyed cgroup events file.
+// Missing files and keys read as zero.
+func readCgroupCounter(path, key string) int64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), " ")
+		if ok && name == key {
+			n, _ := strconv.ParseInt(value, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// close removes the cgroup. This only succeeds once its processes have exited,
+// which is the case when the plugin's pool has been closed.
+func (c *cgroupConfinement) close() {
+	if c.path == "" {
+		return
+	}
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("Failed to remove cgroup", "path", c.path, "error", err)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCgroupConfinement_Violation(t *testing.T) {
+	dir := t.TempDir()
+	writeEvents := func(oomKills, pidsMax string) {
+		os.WriteFile(filepath.Join(dir, "memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill "+oomKills+"\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "pids.events"), []byte("max "+pidsMax+"\n"), 0644)
+	}
+
+	// Counters from an earlier load of the plugin don't count
+	writeEvents("2", "0")
+	c := &cgroupConfinement{path: dir, limits: ResourceLimits{Memory: 64 << 20, Processes: 32}}
+	c.baseline = c.events()
+	if violation := c.violation(); violation != "" {
+		t.Fatalf("violation() = %q, want none", violation)
+	}
+
+	writeEvents("3", "5")
+	want := "memory limit of 64 MiB exceeded, 1 process(es) killed; process limit of 32 reached 5 time(s)"
+	if violation := c.violation(); violation != want {
+		t.Errorf("violation() = %q, want %q", violation, want)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package plugin
+
+import "log/slog"
+
+// newConfinement only enforces limits on Linux. Elsewhere plugins run
+// unconfined; the per-call wall-clock limit applies on every platform.
+func newConfinement(cgroupRoot string, spec PluginSpec, limits ResourceLimits) (confinement, error) {
+	if limits.confinesProcesses() {
+		slog.Warn("Resource limits are only enforced on Linux", "plugin_id", spec.ID, "plugin", spec.Name)
+	}
+	return noConfinement{}, nil
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+func TestLimitsFromMap(t *testing.T) {
+	limits, err := LimitsFromMap(map[string]any{
+		"memory":       "256MiB",
+		"cpu":          0.5,
+		"open_files":   float64(128),
+		"processes":    float64(64),
+		"call_timeout": "10s",
+	})
+	if err != nil {
+		t.Fatalf("LimitsFromMap() error = %v", err)
+	}
+
+	want := ResourceLimits{Memory: 256 << 20, CPU: 0.5, OpenFiles: 128, Processes: 64, CallTimeout: 10 * time.Second}
+	if limits != want {
+		t.Errorf("LimitsFromMap() = %+v, want %+v", limits, want)
+	}
+
+	invalid := []map[string]any{
+		{"memory": "lots"},
+		{"memory": "12TB"},
+		{"memory": true},
+		{"cpu": -1.0},
+		{"call_timeout": "soon"},
+	}
+	for _, raw := range invalid {
+		if _, err := LimitsFromMap(raw); err == nil {
+			t.Errorf("LimitsFromMap(%v) accepted invalid limits", raw)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]int64{
+		"512":    512,
+		"64K":    64 << 10,
+		"64kb":   64 << 10,
+		"256MiB": 256 << 20,
+		"1.5G":   3 << 29,
+	}
+	for input, want := range tests {
+		got, err := parseByteSize(input)
+		if err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v, want %d", input, got, err, want)
+		}
+	}
+
+	if got := formatByteSize(256 << 20); got != "256 MiB" {
+		t.Errorf("formatByteSize() = %q, want \"256 MiB\"", got)
+	}
+}
+
+func TestManager_CallTimeoutLimit(t *testing.T) {
+	m, starter := newTestManager(LifecycleConfig{})
+	spec := PluginSpec{ID: 1, Name: "test", Limits: map[string]any{"call_timeout": "20ms"}}
+	if err := m.LoadPlugin(spec); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	raw, err := m.GetPluginClient(1)
+	if err != nil {
+		t.Fatalf("GetPluginClient() error = %v", err)
+	}
+
+	ctx, cancel := m.CallContext(context.Background(), 1)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); time.Until(deadline) > 20*time.Millisecond {
+		t.Errorf("call deadline in %s, want the plugin's 20ms limit", time.Until(deadline))
+	}
+
+	if _, err := raw.(common.ContextPluginInterface).ExecuteContext(ctx, "Hang", nil); err == nil {
+		t.Fatalf("ExecuteContext() error = nil, want a deadline error")
+	}
+	if !starter.process(0).Exited() {
+		t.Errorf("process that overran its call limit was not stopped")
+	}
+
+	// The pool replaces the stopped process to stay at MinSize
+	p, _ := m.runningPool(1)
+	waitForSize(t, p, 1)
+}
@@ -0,0 +1,224 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// defaultLogBufferSize is the number of log entries kept per plugin when no
+// buffer size is configured
+const defaultLogBufferSize = 1000
+
+// followerBacklog is how many entries a follower may fall behind before it
+// misses entries; plugins never wait for followers
+const followerBacklog = 256
+
+// LogEntry is a line a plugin logged, through its output or the Log host
+// service, or one go-plugin logged about its processes
+type LogEntry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"` // trace, debug, info, warn or error
+	Message string         `json:"message"`
+	Version string         `json:"version"` // Plugin version whose process logged the entry
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// LogBuffer keeps the most recent log entries of a plugin, across restarts
+// and upgrades, and hands new entries to followers
+type LogBuffer struct {
+	mu        sync.Mutex
+	entries   []LogEntry // ring of at most cap(entries) entries
+	next      int        // where the next entry goes once the ring is full
+	followers map[chan LogEntry]struct{}
+}
+
+func newLogBuffer(size int) *LogBuffer {
+	if size <= 0 {
+		size = defaultLogBufferSize
+	}
+	return &LogBuffer{
+		entries:   make([]LogEntry, 0, size),
+		followers: make(map[chan LogEntry]struct{}),
+	}
+}
+
+func (b *LogBuffer) append(entry LogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.entries) < cap(b.entries) {
+		b.entries = append(b.entries, entry)
+	} else {
+		b.entries[b.next] = entry
+		b.next = (b.next + 1) % len(b.entries)
+	}
+
+	for follower := range b.followers {
+		select {
+		case follower <- entry:
+		default:
+		}
+	}
+}
+
+// Tail returns up to n of the most recent entries, oldest first. n <= 0
+// returns every entry kept.
+func (b *LogBuffer) Tail(n int) []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tail(n)
+}
+
+func (b *LogBuffer) tail(n int) []LogEntry {
+	ordered := make([]LogEntry, 0, len(b.entries))
+	ordered = append(ordered, b.entries[b.next:]...)
+	ordered = append(ordered, b.entries[:b.next]...)
+	if n > 0 && n < len(ordered) {
+		ordered = ordered[len(ordered)-n:]
+	}
+	return ordered
+}
+
+// Follow returns up to n of the most recent entries like Tail, and a channel
+// receiving every entry appended after them until stop is called. A follower
+// that falls behind misses entries rather than holding up the plugin.
+func (b *LogBuffer) Follow(n int) (recent []LogEntry, entries <-chan LogEntry, stop func()) {
+	follower := make(chan LogEntry, followerBacklog)
+
+	b.mu.Lock()
+	recent = b.tail(n)
+	b.followers[follower] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return recent, follower, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.followers, follower)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Logs returns the log buffer of a plugin. Its entries are kept while the
+// plugin is unloaded, so a plugin that failed to start can still be looked into.
+func (m *Manager) Logs(pluginID uint) *LogBuffer {
+	m.logsMu.Lock()
+	defer m.logsMu.Unlock()
+
+	buffer, exists := m.logs[pluginID]
+	if !exists {
+		buffer = newLogBuffer(m.logBufferSize)
+		m.logs[pluginID] = buffer
+	}
+	return buffer
+}
+
+// DropLogs discards the log buffer of a plugin that is gone for good
+func (m *Manager) DropLogs(pluginID uint) {
+	m.logsMu.Lock()
+	defer m.logsMu.Unlock()
+	delete(m.logs, pluginID)
+}
+
+// pluginLogSink routes what the processes of one plugin version log to the
+// host logger, tagged with the plugin, and into the plugin's log buffer
+type pluginLogSink struct {
+	logger  *slog.Logger
+	buffer  *LogBuffer
+	version string
+}
+
+func (m *Manager) logSinkFor(spec PluginSpec) *pluginLogSink {
+	return &pluginLogSink{
+		logger:  m.logger.With("plugin_id", spec.ID, "namespace", spec.Namespace, "plugin", spec.Name, "version", spec.Version),
+		buffer:  m.Logs(spec.ID),
+		version: spec.Version,
+	}
+}
+
+// hclogger returns the logger go-plugin writes the plugin's stderr and its
+// own messages about the plugin's processes to
+func (s *pluginLogSink) hclogger() hclog.Logger {
+	logger := hclog.NewInterceptLogger(&hclog.LoggerOptions{
+		Name:   "plugin",
+		Output: io.Discard,
+		Level:  hclog.Debug,
+	})
+	logger.RegisterSink(s)
+	return logger
+}
+
+// writer returns a writer logging every line written to it at level, for
+// the plugin's stdout and stderr that go-plugin forwards over gRPC
+func (s *pluginLogSink) writer(level hclog.Level) io.Writer {
+	return &logWriter{sink: s, level: level}
+}
+
+// Accept implements hclog.SinkAdapter
+func (s *pluginLogSink) Accept(name string, level hclog.Level, msg string, args ...any) {
+	if level < hclog.Debug {
+		return
+	}
+
+	var fields map[string]any
+	attrs := make([]any, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		key, value := fmt.Sprint(args[i]), args[i+1]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		if fields == nil {
+			fields = make(map[string]any, len(args)/2)
+		}
+		fields[key] = value
+		attrs = append(attrs, slog.Any(key, value))
+	}
+
+	s.buffer.append(LogEntry{
+		Time:    time.Now(),
+		Level:   level.String(),
+		Message: msg,
+		Version: s.version,
+		Fields:  fields,
+	})
+	s.logger.Log(context.Background(), slogLevel(level), msg, attrs...)
+}
+
+// slogLevel maps hclog levels onto slog's, with trace below debug
+func slogLevel(level hclog.Level) slog.Level {
+	switch level {
+	case hclog.Trace:
+		return slog.LevelDebug - 4
+	case hclog.Debug:
+		return slog.LevelDebug
+	case hclog.Warn:
+		return slog.LevelWarn
+	case hclog.Error:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// logWriter logs each non-empty line of what is written to it
+type logWriter struct {
+	sink  *pluginLogSink
+	level hclog.Level
+}
+
+func (w *logWriter) Write(data []byte) (int, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, " \t\r"); line != "" {
+			w.sink.Accept("plugin", w.level, line)
+		}
+	}
+	return len(data), nil
+}
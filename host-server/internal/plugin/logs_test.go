@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+func messages(entries []LogEntry) string {
+	texts := make([]string, len(entries))
+	for i, entry := range entries {
+		texts[i] = entry.Message
+	}
+	return strings.Join(texts, " ")
+}
+
+func TestLogBuffer_KeepsMostRecent(t *testing.T) {
+	b := newLogBuffer(3)
+	for i := 1; i <= 5; i++ {
+		b.append(LogEntry{Message: fmt.Sprint(i)})
+	}
+
+	if got := messages(b.Tail(0)); got != "3 4 5" {
+		t.Errorf("Tail(0) = %s, want 3 4 5", got)
+	}
+	if got := messages(b.Tail(2)); got != "4 5" {
+		t.Errorf("Tail(2) = %s, want 4 5", got)
+	}
+}
+
+func TestLogBuffer_Follow(t *testing.T) {
+	b := newLogBuffer(10)
+	b.append(LogEntry{Message: "before"})
+
+	recent, entries, stop := b.Follow(0)
+	if got := messages(recent); got != "before" {
+		t.Errorf("Follow() recent = %s, want before", got)
+	}
+
+	b.append(LogEntry{Message: "after"})
+	select {
+	case entry := <-entries:
+		if entry.Message != "after" {
+			t.Errorf("followed entry = %s, want after", entry.Message)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("appended entry was not handed to the follower")
+	}
+
+	// Followers that do not keep up miss entries instead of blocking
+	for i := 0; i < followerBacklog+10; i++ {
+		b.append(LogEntry{Message: "flood"})
+	}
+
+	stop()
+	b.append(LogEntry{Message: "stopped"})
+	for len(entries) > 0 {
+		if entry := <-entries; entry.Message == "stopped" {
+			t.Errorf("entry was handed to a follower that stopped")
+		}
+	}
+}
+
+func TestPluginLogSink(t *testing.T) {
+	var output bytes.Buffer
+	m := NewManager(NewRegistry(), &ManagerConfig{
+		Logger: slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	sink := m.logSinkFor(PluginSpec{ID: 3, Name: "converter", Version: "1.2.0"})
+
+	sink.hclogger().Named("converter").Warn("disk almost full", "free", "1GB")
+	sink.hclogger().Trace("dropped")
+	fmt.Fprint(sink.writer(hclog.Info), "first line\nsecond line\n")
+
+	entries := m.Logs(3).Tail(0)
+	if got := messages(entries); got != "disk almost full first line second line" {
+		t.Fatalf("buffered entries = %s", got)
+	}
+	if entries[0].Level != "warn" || entries[0].Version != "1.2.0" || entries[0].Fields["free"] != "1GB" {
+		t.Errorf("buffered entry = %+v", entries[0])
+	}
+
+	logged := output.String()
+	for _, want := range []string{`"msg":"disk almost full"`, `"plugin_id":3`, `"plugin":"converter"`, `"version":"1.2.0"`, `"free":"1GB"`} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("host log %s does not contain %s", logged, want)
+		}
+	}
+
+	m.DropLogs(3)
+	if entries := m.Logs(3).Tail(0); len(entries) != 0 {
+		t.Errorf("Logs() after DropLogs() = %d entries, want none", len(entries))
+	}
+}
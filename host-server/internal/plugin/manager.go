@@ -1,52 +1,226 @@
 package plugin
 
 import (
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/repository"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/metrics"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
 )
 
 type Manager struct {
-	registry         *Registry
-	clients          map[uint]*plugin.Client
-	clientInterfaces map[uint]any
-	mu               sync.RWMutex
-	downloadTimeout  time.Duration
-	startupTimeout   time.Duration
+	registry        *Registry
+	pools           map[uint]*pool
+	specs           map[uint]PluginSpec // plugins that should be running, kept across crashes
+	trustStore      *TrustStore
+	mu              sync.RWMutex
+	downloadTimeout time.Duration
+	startupTimeout  time.Duration
+	callTimeout     time.Duration
+	poolConfig      PoolConfig
+	lifecycle       LifecycleConfig
+	lifecycles      map[uint]LifecycleConfig // resolved lifecycle of every loaded plugin
+	idle            map[uint]bool            // loaded plugins whose processes are stopped until the next call
+	waking          map[uint]*wakeup         // on-demand starts in progress
+	start           startFunc
+	cgroupRoot      string
+	limits          map[uint]ResourceLimits      // resource limits of every loaded plugin
+	confinements    map[uint]confinement         // created when a plugin's first process starts
+	sandboxPolicies map[string]SandboxPolicy     // keyed by namespace, see sandboxWildcard
+	sandboxes       map[sandboxKey]sandbox       // created when a sandboxed plugin's first process starts
+	confinementMu   sync.Mutex                   // guards confinements and sandboxes
+	state           StateStore                   // nil when plugins cannot keep state
+	secrets         map[string]map[string]string // secrets per plugin as namespace/name, see hostServices.GetSecret
+	hostCalls       map[string][]string          // namespaces plugins may call besides their own, see mayCall
+	versions        *VersionSelector             // resolves host call targets, nil when plugins cannot call each other
+	policies        CallPolicies                 // host calls are checked against them unless nil
+	logger          *slog.Logger                 // what plugins log goes here, tagged with the plugin
+	logs            map[uint]*LogBuffer          // recent log entries per plugin, see Logs
+	logBufferSize   int                          // entries kept per plugin
+	logsMu          sync.Mutex                   // guards logs
+	metrics         *metrics.Metrics
+}
+
+// wakeup is an on-demand start of an idle plugin, shared by the calls that
+// arrive while it is in progress
+type wakeup struct {
+	done chan struct{}
+	err  error
+}
+
+// ErrProtocolIncompatible is returned when a plugin speaks a protocol version
+// outside the range the host supports
+var ErrProtocolIncompatible = errors.New("plugin protocol version incompatible")
+
+// IsProtocolIncompatible reports whether err was caused by a protocol version mismatch
+func IsProtocolIncompatible(err error) bool {
+	return errors.Is(err, ErrProtocolIncompatible)
+}
+
+// IsStreamingUnsupported reports whether err was caused by a plugin that
+// predates streaming execution or does not implement it
+func IsStreamingUnsupported(err error) bool {
+	return errors.Is(err, common.ErrStreamingUnsupported)
+}
+
+// PluginSpec describes how to start a plugin process. The manager keeps the
+// spec of every loaded plugin so it can be started again after a crash.
+type PluginSpec struct {
+	ID        uint
+	Namespace string // Selects the sandbox policy, see SandboxPolicy
+	Name      string
+	Version   string
+	Path      string
+	Checksum  string         // Hex-encoded SHA-256, re-verified at every start
+	Pool      map[string]any // Per-plugin pool override, see PoolConfigFromMap
+	Lifecycle map[string]any // Per-plugin lifecycle override, see LifecycleConfigFromMap
+	Limits    map[string]any // Resource limits, see LimitsFromMap
+	Settings  map[string]any // Delivered to every process through Configure, see PluginSettings
+	Methods   []string       // Declared method names until a process advertises its own; others are recorded as metrics.MethodOther
+}
+
+// SpecFromModel builds the load spec for a stored plugin record
+func SpecFromModel(p *models.Plugin) PluginSpec {
+	spec := PluginSpec{
+		ID:        p.ID,
+		Namespace: p.Namespace,
+		Name:      p.Name,
+		Version:   p.Version,
+		Path:      p.BinaryPath,
+		Checksum:  p.Checksum,
+	}
+	if pool, ok := p.Config["pool"].(map[string]any); ok {
+		spec.Pool = pool
+	}
+	if lifecycle, ok := p.Config["lifecycle"].(map[string]any); ok {
+		spec.Lifecycle = lifecycle
+	}
+	if limits, ok := p.Config["limits"].(map[string]any); ok {
+		spec.Limits = limits
+	}
+	spec.Settings = PluginSettings(p.Config)
+	for _, method := range MethodsFromMetadata(p.Metadata) {
+		spec.Methods = append(spec.Methods, method.Name)
+	}
+	return spec
 }
 
 type ManagerConfig struct {
 	DownloadTimeout time.Duration
 	StartupTimeout  time.Duration
+	CallTimeout     time.Duration
+	TrustStore      *TrustStore                      // Trusted publisher keys; nil trusts no publisher
+	Pool            PoolConfig                       // Process pool sizing for plugins without an override
+	Lifecycle       LifecycleConfig                  // When processes run for plugins without an override
+	CgroupRoot      string                           // cgroup v2 directory the cgroups of plugins with limits are created in
+	Sandbox         map[string]SandboxPolicy         // Sandbox policy per namespace; "*" applies to all others
+	State           StateStore                       // Key-value state of plugins; nil disables it
+	Secrets         map[string]map[string]string     // Secrets per plugin as namespace/name; "env:NAME" values are read from the environment
+	HostCalls       map[string][]string              // Namespaces plugins may call besides their own, per namespace; "*" allows all
+	Plugins         repository.PluginRepository      // Plugin records host calls are resolved against; nil disables host calls
+	Aliases         repository.PluginAliasRepository // Version aliases host calls can name; nil only offers latest and stable
+	Policies        CallPolicies                     // Policies host calls are checked against; nil checks none
+	Logger          *slog.Logger                     // Host logger plugin output is written to; nil uses slog's default
+	LogBufferSize   int                              // Log entries kept per plugin; 0 keeps 1000
+	Metrics         *metrics.Metrics                 // Calls, restarts and downloads are recorded here; nil uses an unserved registry
 }
 
+// defaultCallTimeout bounds a plugin call when no call timeout is configured
+const defaultCallTimeout = 30 * time.Second
+
 func NewManager(registry *Registry, config *ManagerConfig) *Manager {
 	if config == nil {
 		config = &ManagerConfig{
 			DownloadTimeout: 5 * time.Minute,
 			StartupTimeout:  30 * time.Second,
+			CallTimeout:     defaultCallTimeout,
 		}
 	}
 
+	callTimeout := config.CallTimeout
+	if callTimeout <= 0 {
+		callTimeout = defaultCallTimeout
+	}
+
+	trustStore := config.TrustStore
+	if trustStore == nil {
+		trustStore, _ = NewTrustStore(nil)
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	recorder := config.Metrics
+	if recorder == nil {
+		recorder = metrics.New()
+	}
+
+	poolConfig, err := config.Pool.withDefaults(defaultPoolConfig())
+	if err != nil {
+		poolConfig = defaultPoolConfig()
+	}
+
 	m := &Manager{
-		registry:         registry,
-		clients:          make(map[uint]*plugin.Client),
-		clientInterfaces: make(map[uint]any),
-		downloadTimeout:  config.DownloadTimeout,
-		startupTimeout:   config.StartupTimeout,
+		registry:        registry,
+		pools:           make(map[uint]*pool),
+		specs:           make(map[uint]PluginSpec),
+		trustStore:      trustStore,
+		downloadTimeout: config.DownloadTimeout,
+		startupTimeout:  config.StartupTimeout,
+		callTimeout:     callTimeout,
+		poolConfig:      poolConfig,
+		lifecycle:       config.Lifecycle,
+		lifecycles:      make(map[uint]LifecycleConfig),
+		idle:            make(map[uint]bool),
+		waking:          make(map[uint]*wakeup),
+		cgroupRoot:      config.CgroupRoot,
+		limits:          make(map[uint]ResourceLimits),
+		confinements:    make(map[uint]confinement),
+		sandboxPolicies: config.Sandbox,
+		sandboxes:       make(map[sandboxKey]sandbox),
+		state:           config.State,
+		secrets:         config.Secrets,
+		hostCalls:       config.HostCalls,
+		policies:        config.Policies,
+		logger:          logger,
+		logs:            make(map[uint]*LogBuffer),
+		logBufferSize:   config.LogBufferSize,
+		metrics:         recorder,
+	}
+	if config.Plugins != nil {
+		m.versions = NewVersionSelector(config.Plugins, config.Aliases)
+	}
+	m.start = func(spec PluginSpec) (pluginProcess, any, error) {
+		return m.startPlugin(spec)
 	}
 
 	return m
 }
 
-func (m *Manager) DownloadPlugin(url, destPath string) error {
+// DownloadPlugin fetches a plugin binary and only moves it into place once
+// its SHA-256 checksum (and signature, where required) has been verified.
+func (m *Manager) DownloadPlugin(url, destPath string, expected Integrity) (err error) {
+	defer func(start time.Time) {
+		m.observeDownload(start, err)
+	}(time.Now())
+
 	client := &http.Client{
 		Timeout: m.downloadTimeout,
 	}
@@ -73,12 +247,24 @@ func (m *Manager) DownloadPlugin(url, destPath string) error {
 	}
 	defer out.Close()
 
-	_, err = io.Copy(out, resp.Body)
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(out, hash), resp.Body)
+	m.metrics.DownloadBytes.Add(float64(written))
 	if err != nil {
 		os.Remove(tempFile)
 		return fmt.Errorf("failed to save plugin: %w", err)
 	}
 
+	if err := out.Close(); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to save plugin: %w", err)
+	}
+
+	if err := m.trustStore.Verify(hash.Sum(nil), expected); err != nil {
+		os.Remove(tempFile)
+		return err
+	}
+
 	if err := os.Chmod(tempFile, 0755); err != nil {
 		os.Remove(tempFile)
 		return fmt.Errorf("failed to make plugin executable: %w", err)
@@ -92,97 +278,666 @@ func (m *Manager) DownloadPlugin(url, destPath string) error {
 	return nil
 }
 
-func (m *Manager) LoadPlugin(pluginID uint, pluginPath string, pluginName string) error {
+// LoadPlugin starts the process pool of a plugin with its minimum number of processes
+func (m *Manager) LoadPlugin(spec PluginSpec) error {
 	m.mu.RLock()
-	if _, exists := m.clients[pluginID]; exists {
+	if _, exists := m.pools[spec.ID]; exists {
 		m.mu.RUnlock()
 		return nil
 	}
 	m.mu.RUnlock()
 
-	pluginConfig, err := m.registry.GetPluginConfig(pluginName)
+	lifecycle, err := m.LifecycleFor(spec)
+	if err != nil {
+		return err
+	}
+	limits, err := limitsFor(spec)
+	if err != nil {
+		return err
+	}
+
+	p, err := m.newPoolFor(spec)
+	if err != nil {
+		m.mu.RLock()
+		_, wanted := m.specs[spec.ID]
+		m.mu.RUnlock()
+		if !wanted {
+			m.closeConfinement(spec.ID)
+			m.closeSandbox(spec.ID)
+		}
+		return err
+	}
+
+	m.mu.Lock()
+	m.pools[spec.ID] = p
+	m.specs[spec.ID] = spec
+	m.lifecycles[spec.ID] = lifecycle
+	m.limits[spec.ID] = limits
+	delete(m.idle, spec.ID)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// LoadPluginIdle loads a plugin without starting it. Its processes are
+// started by the first call.
+func (m *Manager) LoadPluginIdle(spec PluginSpec) error {
+	lifecycle, err := m.LifecycleFor(spec)
 	if err != nil {
-		return fmt.Errorf("failed to get plugin config for '%s': %w", pluginName, err)
+		return err
+	}
+	if _, err := m.poolConfigFor(spec); err != nil {
+		return err
+	}
+	limits, err := limitsFor(spec)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.pools[spec.ID]; exists {
+		return nil
+	}
+	m.specs[spec.ID] = spec
+	m.lifecycles[spec.ID] = lifecycle
+	m.limits[spec.ID] = limits
+	m.idle[spec.ID] = true
+
+	return nil
+}
+
+func (m *Manager) newPoolFor(spec PluginSpec) (*pool, error) {
+	poolConfig, err := m.poolConfigFor(spec)
+	if err != nil {
+		return nil, err
+	}
+	return newPool(spec, poolConfig, m.start)
+}
+
+// poolConfigFor applies the plugin's pool override, if any, on top of the host-wide pool config
+func (m *Manager) poolConfigFor(spec PluginSpec) (PoolConfig, error) {
+	if spec.Pool == nil {
+		return m.poolConfig, nil
+	}
+
+	override, err := PoolConfigFromMap(spec.Pool)
+	if err != nil {
+		return PoolConfig{}, err
+	}
+	return override.withDefaults(m.poolConfig)
+}
+
+func limitsFor(spec PluginSpec) (ResourceLimits, error) {
+	if spec.Limits == nil {
+		return ResourceLimits{}, nil
+	}
+	return LimitsFromMap(spec.Limits)
+}
+
+// confinementFor returns the confinement of a plugin, creating it on the
+// first start. Plugins without limits share a no-op confinement.
+func (m *Manager) confinementFor(spec PluginSpec) (confinement, error) {
+	limits, err := limitsFor(spec)
+	if err != nil || !limits.confinesProcesses() {
+		return noConfinement{}, err
+	}
+
+	m.confinementMu.Lock()
+	defer m.confinementMu.Unlock()
+
+	if c, exists := m.confinements[spec.ID]; exists {
+		return c, nil
+	}
+	c, err := newConfinement(m.cgroupRoot, spec, limits)
+	if err != nil {
+		return nil, err
+	}
+	m.confinements[spec.ID] = c
+	return c, nil
+}
+
+// closeConfinement releases the confinement of a plugin whose processes have been stopped
+func (m *Manager) closeConfinement(pluginID uint) {
+	m.confinementMu.Lock()
+	c, exists := m.confinements[pluginID]
+	delete(m.confinements, pluginID)
+	m.confinementMu.Unlock()
+
+	if exists {
+		c.close()
+	}
+}
+
+// LifecycleFor applies the plugin's lifecycle override, if any, on top of the
+// host-wide lifecycle config
+func (m *Manager) LifecycleFor(spec PluginSpec) (LifecycleConfig, error) {
+	if spec.Lifecycle == nil {
+		return m.lifecycle, nil
+	}
+	return LifecycleConfigFromMap(spec.Lifecycle, m.lifecycle)
+}
+
+// InspectPlugin starts a plugin just long enough to read its metadata and
+// negotiated protocol version, then stops it again. The plugin is not loaded.
+func (m *Manager) InspectPlugin(ctx context.Context, spec PluginSpec) (*common.MetadataResponse, int, error) {
+	defer func() {
+		// Inspecting a plugin that is not loaded must not leave its cgroup or sandbox behind
+		m.mu.RLock()
+		_, wanted := m.specs[spec.ID]
+		m.mu.RUnlock()
+		if !wanted {
+			m.closeConfinement(spec.ID)
+			m.closeSandbox(spec.ID)
+		}
+	}()
+
+	client, raw, err := m.startPlugin(spec)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer client.Kill()
+
+	getter, ok := raw.(common.ContextPluginInterface)
+	if !ok {
+		return nil, 0, fmt.Errorf("plugin does not implement common.ContextPluginInterface")
+	}
+
+	metadata, err := getter.GetMetadataContext(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get plugin metadata: %w", err)
+	}
+
+	return metadata, client.NegotiatedVersion(), nil
+}
+
+// startPlugin launches the plugin process, completes the handshake and
+// verifies the negotiated protocol version. The caller owns the returned client.
+func (m *Manager) startPlugin(spec PluginSpec) (*plugin.Client, any, error) {
+	pluginPath, pluginName := spec.Path, spec.Name
+
+	pluginConfig, err := m.registry.GetPluginConfig(pluginName, spec.Version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get plugin config for '%s': %w", pluginName, err)
 	}
 
 	if err := m.validatePluginBinary(pluginPath); err != nil {
-		return fmt.Errorf("invalid plugin binary at '%s': %w", pluginPath, err)
+		return nil, nil, fmt.Errorf("invalid plugin binary at '%s': %w", pluginPath, err)
+	}
+
+	var secureConfig *plugin.SecureConfig
+	if spec.Checksum != "" {
+		sum, err := DecodeChecksum(spec.Checksum)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrIntegrityCheckFailed, err)
+		}
+		secureConfig = &plugin.SecureConfig{
+			Checksum: sum,
+			Hash:     sha256.New(),
+		}
+	}
+
+	cmd := exec.Command(pluginPath)
+	policy := m.sandboxPolicyFor(spec)
+	if policy.Enabled {
+		sandbox, err := m.sandboxFor(spec, policy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to set up sandbox: %w", err)
+		}
+		cmd, err = sandbox.command()
+		if err != nil {
+			if IsIntegrityCheckFailed(err) {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("failed to set up sandbox: %w", err)
+		}
+		// go-plugin would checksum the sandbox helper; the sandbox verifies the plugin binary itself
+		secureConfig = nil
+	}
+
+	confinement, err := m.confinementFor(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to set up resource limits: %w", err)
+	}
+
+	started, err := confinement.prepare(cmd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to set up resource limits: %w", err)
 	}
 
+	sink := m.logSinkFor(spec)
 	client := plugin.NewClient(&plugin.ClientConfig{
-		HandshakeConfig: pluginConfig.HandshakeConfig,
-		Plugins:         pluginConfig.PluginMap,
-		Cmd:             exec.Command(pluginPath),
+		HandshakeConfig:  pluginConfig.HandshakeConfig,
+		VersionedPlugins: pluginConfig.VersionedPlugins,
+		Cmd:              cmd,
+		SecureConfig:     secureConfig,
+		SkipHostEnv:      policy.Enabled,
 		AllowedProtocols: []plugin.Protocol{
 			plugin.ProtocolGRPC,
 		},
 		StartTimeout: m.startupTimeout,
+		Logger:       sink.hclogger(),
+		Stderr:       io.Discard, // Lines are logged through Logger instead
+		SyncStdout:   sink.writer(hclog.Info),
+		SyncStderr:   sink.writer(hclog.Warn),
 	})
 
 	rpcClient, err := client.Client()
+	started()
 	if err != nil {
 		client.Kill()
-		return fmt.Errorf("failed to connect to plugin: %w", err)
+		if errors.Is(err, plugin.ErrChecksumsDoNotMatch) {
+			return nil, nil, fmt.Errorf("%w: %v", ErrIntegrityCheckFailed, err)
+		}
+		if strings.Contains(err.Error(), "incompatible API version") {
+			return nil, nil, fmt.Errorf("%w: %v", ErrProtocolIncompatible, err)
+		}
+		return nil, nil, fmt.Errorf("failed to connect to plugin: %w", err)
+	}
+
+	if err := confinement.apply(cmd.Process.Pid); err != nil {
+		client.Kill()
+		return nil, nil, fmt.Errorf("failed to set up resource limits: %w", err)
 	}
 
 	raw, err := rpcClient.Dispense(pluginConfig.PluginName)
 	if err != nil {
 		client.Kill()
-		return fmt.Errorf("failed to dispense plugin interface '%s': %w", pluginConfig.PluginName, err)
+		return nil, nil, fmt.Errorf("failed to dispense plugin interface '%s': %w", pluginConfig.PluginName, err)
 	}
 
-	if err := m.verifyProtocolVersion(raw); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), m.startupTimeout)
+	defer cancel()
+	if err := m.verifyProtocolVersion(ctx, raw, client.NegotiatedVersion()); err != nil {
+		client.Kill()
+		return nil, nil, fmt.Errorf("failed to verify protocol version: %w", err)
+	}
+	if err := m.connectHost(ctx, raw, spec); err != nil {
+		client.Kill()
+		return nil, nil, fmt.Errorf("failed to connect host services: %w", err)
+	}
+	if err := configureProcess(ctx, raw, spec.Settings); err != nil {
 		client.Kill()
-		return fmt.Errorf("protocol version incompatible: %w", err)
+		return nil, nil, fmt.Errorf("failed to configure plugin: %w", err)
+	}
+
+	return client, raw, nil
+}
+
+func (m *Manager) UnloadPlugin(pluginID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.specs, pluginID)
+	delete(m.lifecycles, pluginID)
+	delete(m.limits, pluginID)
+	delete(m.idle, pluginID)
+
+	if p, exists := m.pools[pluginID]; exists {
+		p.close()
+		delete(m.pools, pluginID)
 	}
+	m.closeConfinement(pluginID)
+	m.closeSandbox(pluginID)
 
+	return nil
+}
+
+// RestartPlugin kills the current processes of a loaded plugin (if any) and
+// starts its pool again from the spec it was originally loaded with.
+func (m *Manager) RestartPlugin(pluginID uint) error {
 	m.mu.Lock()
-	m.clients[pluginID] = client
-	m.clientInterfaces[pluginID] = raw
+	spec, wanted := m.specs[pluginID]
+	if !wanted {
+		m.mu.Unlock()
+		return fmt.Errorf("plugin not loaded")
+	}
+	if p, exists := m.pools[pluginID]; exists {
+		p.close()
+		delete(m.pools, pluginID)
+	}
 	m.mu.Unlock()
 
+	err := m.LoadPlugin(spec)
+	m.observeRestart(spec, err)
+	if err != nil {
+		return err
+	}
+
+	// The plugin may have been unloaded while it was starting; don't leak it
+	m.mu.RLock()
+	_, wanted = m.specs[pluginID]
+	m.mu.RUnlock()
+	if !wanted {
+		return m.UnloadPlugin(pluginID)
+	}
+
 	return nil
 }
 
-func (m *Manager) UnloadPlugin(pluginID uint) error {
+// LoadedPluginIDs returns the IDs of all plugins that are loaded, including
+// idle ones and those whose process has exited and is waiting to be restarted.
+func (m *Manager) LoadedPluginIDs() []uint {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]uint, 0, len(m.specs))
+	for id := range m.specs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// CrashedPluginIDs returns the IDs of running plugins whose processes have
+// all exited. It only looks at process state, so it is cheap enough to call
+// far more often than CheckHealth.
+func (m *Manager) CrashedPluginIDs() []uint {
+	m.mu.RLock()
+	pools := make(map[uint]*pool, len(m.pools))
+	for id, p := range m.pools {
+		pools[id] = p
+	}
+	m.mu.RUnlock()
+
+	var ids []uint
+	for id, p := range pools {
+		if p.crashed() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// CheckHealth reports whether a loaded plugin has processes alive and
+// answering GetMetadata within ctx. Processes that fail the probe are stopped;
+// the check only fails once none are left. Idle plugins have nothing to probe
+// and always pass.
+func (m *Manager) CheckHealth(ctx context.Context, pluginID uint) error {
+	m.mu.RLock()
+	p, exists := m.pools[pluginID]
+	idle := m.idle[pluginID]
+	m.mu.RUnlock()
+
+	if idle {
+		return nil
+	}
+	if !exists {
+		return fmt.Errorf("plugin process not running")
+	}
+
+	return p.health(ctx)
+}
+
+// GetPluginClient returns the client of a loaded plugin. It implements
+// common.ContextPluginInterface and common.StreamingPluginInterface and
+// dispatches every call to one process of the plugin's pool. An idle plugin
+// is started first.
+func (m *Manager) GetPluginClient(pluginID uint) (any, error) {
+	p, err := m.runningPool(pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	killOnTimeout := m.limits[pluginID].CallTimeout > 0
+	m.mu.RUnlock()
+
+	return &pooledClient{pool: p, killOnTimeout: killOnTimeout, metrics: m.metrics}, nil
+}
+
+// runningPool returns the pool of a loaded plugin, starting it if the plugin
+// is idle. Calls arriving while the plugin starts wait for that start.
+func (m *Manager) runningPool(pluginID uint) (*pool, error) {
+	m.mu.Lock()
+	if p, exists := m.pools[pluginID]; exists {
+		m.mu.Unlock()
+		return p, nil
+	}
+	spec, wanted := m.specs[pluginID]
+	if !wanted {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("plugin not loaded")
+	}
+	if !m.idle[pluginID] {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("plugin process is restarting")
+	}
+
+	w, starting := m.waking[pluginID]
+	if !starting {
+		w = &wakeup{done: make(chan struct{})}
+		m.waking[pluginID] = w
+	}
+	m.mu.Unlock()
+
+	if starting {
+		<-w.done
+		if w.err != nil {
+			return nil, w.err
+		}
+		return m.runningPool(pluginID)
+	}
+
+	p, err := m.newPoolFor(spec)
+
+	m.mu.Lock()
+	delete(m.waking, pluginID)
+	if err == nil {
+		_, wanted = m.specs[pluginID]
+		existing, loaded := m.pools[pluginID]
+		switch {
+		case !wanted:
+			// Unloaded while it was starting; don't leak it
+			p.close()
+			err = fmt.Errorf("plugin not loaded")
+		case loaded:
+			// Loaded again by someone else in the meantime
+			p.close()
+			p = existing
+		default:
+			m.pools[pluginID] = p
+			delete(m.idle, pluginID)
+		}
+	} else {
+		err = fmt.Errorf("failed to start idle plugin: %w", err)
+	}
+	m.mu.Unlock()
+
+	w.err = err
+	close(w.done)
+
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Started idle plugin on demand", "plugin_id", spec.ID, "plugin", spec.Name, "version", spec.Version)
+	return p, nil
+}
+
+// SuspendPlugin stops the processes of a loaded plugin but keeps it loaded, so
+// the next call starts them again. It leaves the plugin running and returns
+// false while a call is in flight.
+func (m *Manager) SuspendPlugin(pluginID uint) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	client, exists := m.clients[pluginID]
-	if !exists {
-		return nil
+	p, exists := m.pools[pluginID]
+	if !exists || !p.closeIfIdle() {
+		return false
 	}
 
-	client.Kill()
+	delete(m.pools, pluginID)
+	m.idle[pluginID] = true
+	return true
+}
 
-	delete(m.clients, pluginID)
-	delete(m.clientInterfaces, pluginID)
+// RuntimeStatus reports whether a loaded plugin is running, idle or being
+// restarted. It returns an empty string for plugins that are not loaded.
+func (m *Manager) RuntimeStatus(pluginID uint) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	return nil
+	switch {
+	case m.pools[pluginID] != nil:
+		return RuntimeRunning
+	case m.idle[pluginID]:
+		return RuntimeIdle
+	}
+	if _, wanted := m.specs[pluginID]; wanted {
+		return RuntimeRestarting
+	}
+	return ""
 }
 
-func (m *Manager) GetPluginClient(pluginID uint) (any, error) {
+// idleShutdown returns when the processes of a running plugin were started
+// and how long the plugin may go without calls. ok is false when the plugin
+// is not running or is never shut down for inactivity.
+func (m *Manager) idleShutdown(pluginID uint) (startedAt time.Time, timeout time.Duration, ok bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	clientInterface, exists := m.clientInterfaces[pluginID]
+	p, running := m.pools[pluginID]
+	timeout = m.lifecycles[pluginID].IdleShutdown
+	if !running || timeout <= 0 {
+		return time.Time{}, 0, false
+	}
+	return p.created, timeout, true
+}
+
+// NegotiatedVersion returns the protocol version agreed on with a loaded plugin
+func (m *Manager) NegotiatedVersion(pluginID uint) (int, error) {
+	m.mu.RLock()
+	p, exists := m.pools[pluginID]
+	m.mu.RUnlock()
+
 	if !exists {
-		return nil, fmt.Errorf("plugin not loaded")
+		return 0, fmt.Errorf("plugin not loaded")
+	}
+
+	return p.negotiatedVersion()
+}
+
+// LimitViolation describes the resource limits a loaded plugin's processes
+// ran into, such as being killed for exceeding the memory limit. It is empty
+// when no limit was hit or the plugin has no limits.
+func (m *Manager) LimitViolation(pluginID uint) string {
+	m.confinementMu.Lock()
+	c, exists := m.confinements[pluginID]
+	m.confinementMu.Unlock()
+
+	if !exists {
+		return ""
+	}
+	return c.violation()
+}
+
+// PoolStats returns a snapshot of a loaded plugin's process pool
+func (m *Manager) PoolStats(pluginID uint) (*PoolStats, bool) {
+	m.mu.RLock()
+	p, exists := m.pools[pluginID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil, false
 	}
+	return p.stats(), true
+}
+
+// MaintainPools stops processes that have been idle longer than their pool's
+// idle timeout and brings every pool back up to its minimum size
+func (m *Manager) MaintainPools() {
+	m.mu.RLock()
+	pools := make([]*pool, 0, len(m.pools))
+	for _, p := range m.pools {
+		pools = append(pools, p)
+	}
+	m.mu.RUnlock()
 
-	return clientInterface, nil
+	now := time.Now()
+	for _, p := range pools {
+		p.maintain(now)
+	}
+}
+
+// DescribeMethods asks a loaded plugin for its method descriptors
+func (m *Manager) DescribeMethods(ctx context.Context, pluginID uint) ([]MethodMetadata, error) {
+	metadata, err := m.describe(ctx, pluginID)
+	if err != nil {
+		return nil, err
+	}
+	return MethodsFromResponse(metadata), nil
+}
+
+// DescribeConfig asks a loaded plugin for the schema of the settings it takes.
+// It returns nil for plugins that take no settings.
+func (m *Manager) DescribeConfig(ctx context.Context, pluginID uint) (map[string]ParamSchema, error) {
+	metadata, err := m.describe(ctx, pluginID)
+	if err != nil {
+		return nil, err
+	}
+	return ConfigSchemaFromResponse(metadata), nil
+}
+
+func (m *Manager) describe(ctx context.Context, pluginID uint) (*common.MetadataResponse, error) {
+	raw, err := m.GetPluginClient(pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	getter, ok := raw.(common.ContextPluginInterface)
+	if !ok {
+		return nil, fmt.Errorf("plugin does not implement common.ContextPluginInterface")
+	}
+
+	callCtx, cancel := m.CallContext(ctx, pluginID)
+	defer cancel()
+
+	metadata, err := getter.GetMetadataContext(callCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plugin metadata: %w", err)
+	}
+
+	// Processes started later, e.g. by restarts, know the methods as well
+	m.mu.Lock()
+	if spec, wanted := m.specs[pluginID]; wanted {
+		spec.Methods = methodNames(metadata)
+		m.specs[pluginID] = spec
+	}
+	m.mu.Unlock()
+	return metadata, nil
+}
+
+// CallContext derives the context a single plugin call runs under: it inherits
+// cancellation from parent and is bounded by the plugin's call_timeout limit,
+// or else by the configured call timeout.
+func (m *Manager) CallContext(parent context.Context, pluginID uint) (context.Context, context.CancelFunc) {
+	m.mu.RLock()
+	timeout := m.limits[pluginID].CallTimeout
+	m.mu.RUnlock()
+
+	if timeout <= 0 {
+		timeout = m.callTimeout
+	}
+	return context.WithTimeout(parent, timeout)
 }
 
 func (m *Manager) UnloadAll() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	for _, client := range m.clients {
-		client.Kill()
+	for _, p := range m.pools {
+		p.close()
+	}
+	for id := range m.specs {
+		m.closeConfinement(id)
+		m.closeSandbox(id)
 	}
 
-	m.clients = make(map[uint]*plugin.Client)
-	m.clientInterfaces = make(map[uint]any)
+	m.pools = make(map[uint]*pool)
+	m.specs = make(map[uint]PluginSpec)
+	m.lifecycles = make(map[uint]LifecycleConfig)
+	m.limits = make(map[uint]ResourceLimits)
+	m.idle = make(map[uint]bool)
 }
 
 // validatePluginBinary validates that the plugin binary exists and is executable
@@ -203,51 +958,43 @@ func (m *Manager) validatePluginBinary(path string) error {
 	return nil
 }
 
-// verifyProtocolVersion verifies that the plugin's protocol version is compatible
-// with the host. This follows Terraform's approach of checking version ranges.
-func (m *Manager) verifyProtocolVersion(pluginInterface any) error {
-	type MetadataGetter interface {
-		GetMetadata() (*struct {
-			Name            string
-			Version         string
-			Description     string
-			Methods         []string
-			Capabilities    map[string]string
-			ProtocolVersion int32
-		}, error)
-	}
-
-	getter, ok := pluginInterface.(MetadataGetter)
+// verifyProtocolVersion verifies that the version the plugin reports in its
+// metadata agrees with the version negotiated during the handshake and lies
+// within the range the host supports.
+func (m *Manager) verifyProtocolVersion(ctx context.Context, pluginInterface any, negotiated int) error {
+	getter, ok := pluginInterface.(common.ContextPluginInterface)
 	if !ok {
-		// If plugin doesn't implement GetMetadata, assume it's compatible
-		// (for backward compatibility with plugins that don't report version)
-		return nil
+		return fmt.Errorf("%w: plugin does not implement common.ContextPluginInterface", ErrProtocolIncompatible)
 	}
 
-	metadata, err := getter.GetMetadata()
+	metadata, err := getter.GetMetadataContext(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get plugin metadata: %w", err)
 	}
 
 	pluginVersion := int(metadata.ProtocolVersion)
 
-	// If plugin doesn't report version (0), assume version 1 for backward compatibility
+	// Plugins that don't report a version speak whatever was negotiated
 	if pluginVersion == 0 {
-		pluginVersion = 1
+		pluginVersion = negotiated
 	}
 
-	// Check if version is in supported range
-	// We need to import the common package constants
-	// For now, hardcode the check (will be replaced with proper import)
-	minVersion := 1
-	maxVersion := 1
+	if pluginVersion < common.MinSupportedProtocolVersion || pluginVersion > common.MaxSupportedProtocolVersion {
+		return fmt.Errorf(
+			"%w: plugin protocol version %d is not supported (host supports versions %d-%d)",
+			ErrProtocolIncompatible,
+			pluginVersion,
+			common.MinSupportedProtocolVersion,
+			common.MaxSupportedProtocolVersion,
+		)
+	}
 
-	if pluginVersion < minVersion || pluginVersion > maxVersion {
+	if pluginVersion != negotiated {
 		return fmt.Errorf(
-			"plugin protocol version %d is not compatible (host supports versions %d-%d)",
+			"%w: plugin reports protocol version %d but negotiated version %d",
+			ErrProtocolIncompatible,
 			pluginVersion,
-			minVersion,
-			maxVersion,
+			negotiated,
 		)
 	}
 
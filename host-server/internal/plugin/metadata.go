@@ -3,21 +3,25 @@ package plugin
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/wylu1037/polyglot-plugin-host-server/internal/validator"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
 )
 
 // PluginMetadata contains structured metadata about a plugin
 type PluginMetadata struct {
-	Name            string           `json:"name" validate:"required"`
-	Version         string           `json:"version" validate:"required"`
-	Type            string           `json:"type" validate:"required"` // 插件自己声明的类型
-	Description     string           `json:"description"`
-	Author          string           `json:"author"`
-	ProtocolVersion int32            `json:"protocol_version" validate:"required,gt=0"`
-	Capabilities    []string         `json:"capabilities"`
-	Methods         []MethodMetadata `json:"methods" validate:"dive"`
-	Dependencies    []Dependency     `json:"dependencies,omitempty" validate:"dive"`
+	Name            string                 `json:"name" validate:"required"`
+	Version         string                 `json:"version" validate:"required"`
+	Type            string                 `json:"type" validate:"required"` // 插件自己声明的类型
+	Description     string                 `json:"description"`
+	Author          string                 `json:"author"`
+	ProtocolVersion int32                  `json:"protocol_version" validate:"required,gt=0"`
+	Capabilities    []string               `json:"capabilities"`
+	Methods         []MethodMetadata       `json:"methods" validate:"dive"`
+	Dependencies    []Dependency           `json:"dependencies,omitempty" validate:"dive"`
+	ConfigSchema    map[string]ParamSchema `json:"config_schema,omitempty" validate:"dive"` // 插件通过 Configure 接收的配置项
 }
 
 // MethodMetadata describes a method provided by the plugin
@@ -26,6 +30,8 @@ type MethodMetadata struct {
 	Description string                 `json:"description"`
 	Parameters  map[string]ParamSchema `json:"parameters" validate:"dive"`
 	Returns     ReturnSchema           `json:"returns"`
+	Examples    []MethodExample        `json:"examples,omitempty"`
+	StreamInput string                 `json:"stream_input,omitempty"` // 流式调用时从请求体读取的参数，为空表示不支持流式调用
 }
 
 // ParamSchema describes a method parameter
@@ -42,6 +48,13 @@ type ReturnSchema struct {
 	Description string `json:"description"`
 }
 
+// MethodExample is a sample call of a method
+type MethodExample struct {
+	Description string         `json:"description,omitempty"`
+	Params      map[string]any `json:"params"`
+	Result      any            `json:"result,omitempty"`
+}
+
 // Dependency describes a plugin dependency
 type Dependency struct {
 	Name    string `json:"name" validate:"required"`
@@ -49,6 +62,80 @@ type Dependency struct {
 	Type    string `json:"type" validate:"required"`    // plugin, library, service
 }
 
+// MethodsFromResponse converts the method descriptors a plugin advertises
+// into MethodMetadata. Plugins that only list method names yield
+// descriptors without parameters, which are not validated on call.
+func MethodsFromResponse(resp *common.MetadataResponse) []MethodMetadata {
+	described := make(map[string]*common.MethodDescriptor, len(resp.GetMethodDescriptors()))
+	for _, descriptor := range resp.GetMethodDescriptors() {
+		described[descriptor.GetName()] = descriptor
+	}
+
+	names := methodNames(resp)
+	methods := make([]MethodMetadata, 0, len(names))
+	for _, name := range names {
+		descriptor, ok := described[name]
+		if !ok {
+			methods = append(methods, MethodMetadata{Name: name})
+			continue
+		}
+		methods = append(methods, methodFromDescriptor(descriptor))
+	}
+	return methods
+}
+
+// methodNames returns the names of the methods a plugin advertises
+func methodNames(resp *common.MetadataResponse) []string {
+	if names := resp.GetMethods(); len(names) > 0 {
+		return names
+	}
+	return common.MethodNames(resp.GetMethodDescriptors())
+}
+
+func methodFromDescriptor(descriptor *common.MethodDescriptor) MethodMetadata {
+	method := MethodMetadata{
+		Name:        descriptor.GetName(),
+		Description: descriptor.GetDescription(),
+		Returns: ReturnSchema{
+			Type:        descriptor.GetReturns().GetType(),
+			Description: descriptor.GetReturns().GetDescription(),
+		},
+		StreamInput: descriptor.GetStreamInput(),
+	}
+
+	if len(descriptor.GetParameters()) > 0 {
+		method.Parameters = make(map[string]ParamSchema, len(descriptor.GetParameters()))
+		for name, param := range descriptor.GetParameters() {
+			method.Parameters[name] = paramFromDescriptor(param)
+		}
+	}
+
+	for _, example := range descriptor.GetExamples() {
+		sample := MethodExample{
+			Description: example.GetDescription(),
+			Params:      example.GetParams().AsMap(),
+		}
+		if example.GetResult() != nil {
+			sample.Result = example.GetResult().AsInterface()
+		}
+		method.Examples = append(method.Examples, sample)
+	}
+
+	return method
+}
+
+func paramFromDescriptor(param *common.ParamDescriptor) ParamSchema {
+	schema := ParamSchema{
+		Type:        param.GetType(),
+		Description: param.GetDescription(),
+		Required:    param.GetRequired(),
+	}
+	if param.GetDefault() != nil {
+		schema.Default = param.GetDefault().AsInterface()
+	}
+	return schema
+}
+
 func (m *PluginMetadata) Validate() error {
 	if err := validator.Validate(m); err != nil {
 		return err
@@ -111,7 +198,52 @@ func (p *PluginPath) Validate() error {
 	return nil
 }
 
-// ParsePluginPath parses a plugin path into structured components
+// PluginBinaryName is the file name every plugin binary must have
+const PluginBinaryName = "plugin"
+
+// ParsePluginPath parses a plugin path into structured components.
+// fullPath must live under basePath and follow the layout
+// {namespace}/{type}/{name}/{version}/{os}_{arch}/plugin.
 func ParsePluginPath(basePath, fullPath string) (*PluginPath, error) {
-	return nil, fmt.Errorf("not implemented yet")
+	rel, err := filepath.Rel(basePath, fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("path '%s' is not under '%s': %w", fullPath, basePath, err)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("path '%s' is not under '%s'", fullPath, basePath)
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("path '%s' does not match {namespace}/{type}/{name}/{version}/{os}_{arch}/%s", rel, PluginBinaryName)
+	}
+	if parts[5] != PluginBinaryName {
+		return nil, fmt.Errorf("plugin binary must be named '%s', got '%s'", PluginBinaryName, parts[5])
+	}
+
+	osName, arch, ok := strings.Cut(parts[4], "_")
+	if !ok {
+		return nil, fmt.Errorf("platform directory '%s' must be in {os}_{arch} form", parts[4])
+	}
+
+	path := &PluginPath{
+		BaseDir:   basePath,
+		Namespace: parts[0],
+		Type:      parts[1],
+		Name:      parts[2],
+		Version:   parts[3],
+		OS:        osName,
+		Arch:      arch,
+	}
+
+	if err := path.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid plugin path '%s': %w", rel, err)
+	}
+
+	return path, nil
+}
+
+// SemanticVersion returns the version without the conventional "v" directory prefix
+func (p *PluginPath) SemanticVersion() string {
+	return strings.TrimPrefix(p.Version, "v")
 }
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestParsePluginPath(t *testing.T) {
+	base := filepath.Join("bin", "plugins")
+
+	tests := []struct {
+		name    string
+		path    string
+		want    PluginPath
+		wantErr bool
+	}{
+		{
+			name: "standard layout",
+			path: filepath.Join(base, "builtin", "data-processing", "converter", "v1.0.0", "linux_amd64", "plugin"),
+			want: PluginPath{
+				BaseDir:   base,
+				Namespace: "builtin",
+				Type:      "data-processing",
+				Name:      "converter",
+				Version:   "v1.0.0",
+				OS:        "linux",
+				Arch:      "amd64",
+			},
+		},
+		{
+			name:    "missing platform directory",
+			path:    filepath.Join(base, "builtin", "data-processing", "converter", "v1.0.0", "plugin"),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported platform",
+			path:    filepath.Join(base, "builtin", "data-processing", "converter", "v1.0.0", "plan9_386", "plugin"),
+			wantErr: true,
+		},
+		{
+			name:    "platform without separator",
+			path:    filepath.Join(base, "builtin", "data-processing", "converter", "v1.0.0", "linux", "plugin"),
+			wantErr: true,
+		},
+		{
+			name:    "wrong binary name",
+			path:    filepath.Join(base, "builtin", "data-processing", "converter", "v1.0.0", "linux_amd64", "converter"),
+			wantErr: true,
+		},
+		{
+			name:    "outside base directory",
+			path:    filepath.Join("elsewhere", "builtin", "data-processing", "converter", "v1.0.0", "linux_amd64", "plugin"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePluginPath(base, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePluginPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if *got != tt.want {
+				t.Errorf("ParsePluginPath() = %+v, want %+v", *got, tt.want)
+			}
+			if got.SemanticVersion() != "1.0.0" {
+				t.Errorf("SemanticVersion() = %s, want 1.0.0", got.SemanticVersion())
+			}
+		})
+	}
+}
+
+func TestMethodsFromResponse(t *testing.T) {
+	resp := &common.MetadataResponse{
+		Methods: []string{"DPCount", "Legacy"},
+		MethodDescriptors: []*common.MethodDescriptor{
+			{
+				Name: "DPCount",
+				Parameters: map[string]*common.ParamDescriptor{
+					"values":                     {Type: "array", Required: true},
+					"max_partitions_contributed": {Type: "int", Default: structpb.NewNumberValue(1)},
+				},
+				Returns: &common.ReturnDescriptor{Type: "int"},
+				Examples: []*common.MethodExample{
+					{Params: common.MustStruct(map[string]any{"values": []any{1.0}}), Result: structpb.NewNumberValue(1)},
+				},
+			},
+		},
+	}
+
+	methods := MethodsFromResponse(resp)
+	if len(methods) != 2 {
+		t.Fatalf("MethodsFromResponse() returned %d methods, want 2", len(methods))
+	}
+
+	count := methods[0]
+	if !count.Parameters["values"].Required || count.Parameters["max_partitions_contributed"].Default != float64(1) {
+		t.Errorf("unexpected parameters: %+v", count.Parameters)
+	}
+	if count.Returns.Type != "int" || len(count.Examples) != 1 {
+		t.Errorf("unexpected returns or examples: %+v", count)
+	}
+
+	if legacy := methods[1]; legacy.Name != "Legacy" || legacy.Parameters != nil {
+		t.Errorf("undescribed method = %+v, want name only", legacy)
+	}
+}
@@ -0,0 +1,103 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apperrors "github.com/wylu1037/polyglot-plugin-host-server/internal/errors"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/metrics"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+// observe records a method call in the call metrics of the plugin. The call
+// failed with err or, if the plugin reported the failure itself, failed is set.
+// Methods the plugin does not declare are recorded as metrics.MethodOther.
+func (c *pooledClient) observe(ctx context.Context, method string, start time.Time, err error, failed bool) {
+	if c.metrics == nil {
+		return
+	}
+	if !c.pool.declares(method) {
+		method = metrics.MethodOther
+	}
+
+	spec := c.pool.spec
+	plugin := metrics.PluginLabel(spec.Namespace, spec.Name)
+	c.metrics.PluginCalls.WithLabelValues(plugin, spec.Version, method).Inc()
+	c.metrics.PluginCallDuration.WithLabelValues(plugin, spec.Version, method).Observe(time.Since(start).Seconds())
+	if err != nil || failed {
+		c.metrics.PluginCallErrors.WithLabelValues(plugin, spec.Version, method, callErrorCode(ctx, err)).Inc()
+	}
+}
+
+// callErrorCode is the API error code a failed call is answered with
+func callErrorCode(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, common.ErrStreamingUnsupported):
+		return apperrors.ErrCodePluginStreamUnsupported
+	case err != nil && ctx.Err() == context.DeadlineExceeded:
+		return apperrors.ErrCodePluginCallTimeout
+	case err != nil && ctx.Err() == context.Canceled:
+		return apperrors.ErrCodePluginCallCanceled
+	default:
+		return apperrors.ErrCodePluginCallFailed
+	}
+}
+
+// observeRestart records a supervisor restart of a plugin
+func (m *Manager) observeRestart(spec PluginSpec, err error) {
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultFailure
+	}
+	m.metrics.PluginRestarts.WithLabelValues(metrics.PluginLabel(spec.Namespace, spec.Name), spec.Version, result).Inc()
+}
+
+// observeDownload records a plugin download that started at start
+func (m *Manager) observeDownload(start time.Time, err error) {
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultFailure
+	}
+	m.metrics.DownloadDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+}
+
+var (
+	processesDesc = prometheus.NewDesc("plugin_processes",
+		"Running processes of loaded plugins.", []string{"plugin", "version"}, nil)
+	inFlightDesc = prometheus.NewDesc("plugin_calls_in_flight",
+		"Calls being served by the processes of loaded plugins.", []string{"plugin", "version"}, nil)
+)
+
+// poolCollector reports the process pools of loaded plugins at scrape time
+type poolCollector struct {
+	m *Manager
+}
+
+// Collector returns the collector of the manager's process pools, to be
+// registered with the host's metrics
+func (m *Manager) Collector() prometheus.Collector {
+	return poolCollector{m: m}
+}
+
+func (c poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- processesDesc
+	ch <- inFlightDesc
+}
+
+func (c poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.mu.RLock()
+	pools := make([]*pool, 0, len(c.m.pools))
+	for _, p := range c.m.pools {
+		pools = append(pools, p)
+	}
+	c.m.mu.RUnlock()
+
+	for _, p := range pools {
+		stats := p.stats()
+		labels := []string{metrics.PluginLabel(p.spec.Namespace, p.spec.Name), p.spec.Version}
+		ch <- prometheus.MustNewConstMetric(processesDesc, prometheus.GaugeValue, float64(stats.Size), labels...)
+		ch <- prometheus.MustNewConstMetric(inFlightDesc, prometheus.GaugeValue, float64(stats.InFlight), labels...)
+	}
+}
@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/metrics"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+func TestManager_CallMetrics(t *testing.T) {
+	recorder := metrics.New()
+	m, _ := newTestManager(LifecycleConfig{})
+	m.metrics = recorder
+	spec := PluginSpec{ID: 1, Namespace: "builtin", Name: "test", Version: "1.0.0", Methods: []string{"Echo", "Hang"}}
+	if err := m.LoadPlugin(spec); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	raw, err := m.GetPluginClient(1)
+	if err != nil {
+		t.Fatalf("GetPluginClient() error = %v", err)
+	}
+	client := raw.(common.ContextPluginInterface)
+
+	if _, err := client.ExecuteContext(context.Background(), "Echo", nil); err != nil {
+		t.Fatalf("ExecuteContext() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.ExecuteContext(ctx, "Hang", nil); err == nil {
+		t.Fatalf("ExecuteContext() error = nil, want a deadline error")
+	}
+
+	for _, method := range []string{"Undeclared", "Unknown"} {
+		if _, err := client.ExecuteContext(context.Background(), method, nil); err != nil {
+			t.Fatalf("ExecuteContext(%s) error = %v", method, err)
+		}
+	}
+
+	if got := testutil.ToFloat64(recorder.PluginCalls.WithLabelValues("builtin/test", "1.0.0", "Echo")); got != 1 {
+		t.Errorf("calls of Echo = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(recorder.PluginCalls.WithLabelValues("builtin/test", "1.0.0", metrics.MethodOther)); got != 2 {
+		t.Errorf("calls of undeclared methods = %v, want 2 under %s", got, metrics.MethodOther)
+	}
+	if got := testutil.CollectAndCount(recorder.PluginCalls); got != 3 {
+		t.Errorf("call series = %d, want Echo, Hang and %s", got, metrics.MethodOther)
+	}
+	if got := testutil.ToFloat64(recorder.PluginCallErrors.WithLabelValues("builtin/test", "1.0.0", "Hang", "PLUGIN_CALL_TIMEOUT")); got != 1 {
+		t.Errorf("timed out calls of Hang = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(recorder.PluginCallErrors); got != 1 {
+		t.Errorf("error series = %d, want only the timeout of Hang", got)
+	}
+
+	expected := `
+# HELP plugin_processes Running processes of loaded plugins.
+# TYPE plugin_processes gauge
+plugin_processes{plugin="builtin/test",version="1.0.0"} 1
+`
+	if err := testutil.CollectAndCompare(m.Collector(), strings.NewReader(expected), "plugin_processes"); err != nil {
+		t.Errorf("pool metrics: %v", err)
+	}
+}
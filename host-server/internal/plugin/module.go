@@ -3,10 +3,13 @@ package plugin
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
 	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/repository"
 	"github.com/wylu1037/polyglot-plugin-host-server/config"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/metrics"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/policy"
 	"go.uber.org/fx"
 	"gorm.io/gorm"
 )
@@ -14,17 +17,126 @@ import (
 var Module = fx.Options(
 	fx.Provide(provideRegistry),
 	fx.Provide(provideManager),
+	fx.Provide(provideSupervisor),
+	fx.Provide(provideDiscoverer),
+	fx.Provide(providePipelineEngine),
+	fx.Invoke(discoverPlugins),
 	fx.Invoke(autoLoadPlugins),
+	fx.Invoke(startSupervisor),
 )
 
 func provideRegistry() *Registry {
 	return NewRegistry()
 }
 
-func provideManager(cfg *config.Config, registry *Registry) *Manager {
-	return NewManager(registry, &ManagerConfig{
+type managerParams struct {
+	fx.In
+	Config   *config.Config
+	Logger   *slog.Logger
+	Metrics  *metrics.Metrics
+	Registry *Registry
+	State    repository.PluginStateRepository
+	Plugins  repository.PluginRepository
+	Aliases  repository.PluginAliasRepository
+	Policies *policy.Enforcer
+}
+
+func provideManager(p managerParams) (*Manager, error) {
+	cfg := p.Config
+	trustStore, err := NewTrustStore(cfg.Plugin.TrustedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trusted publisher keys: %w", err)
+	}
+
+	manager := NewManager(p.Registry, &ManagerConfig{
 		DownloadTimeout: cfg.Plugin.DownloadTimeout,
 		StartupTimeout:  cfg.Plugin.StartupTimeout,
+		CallTimeout:     cfg.Plugin.CallTimeout,
+		TrustStore:      trustStore,
+		Pool: PoolConfig{
+			MinSize:     cfg.Plugin.Pool.MinSize,
+			MaxSize:     cfg.Plugin.Pool.MaxSize,
+			Strategy:    cfg.Plugin.Pool.Strategy,
+			IdleTimeout: cfg.Plugin.Pool.IdleTimeout,
+		},
+		Lifecycle: LifecycleConfig{
+			OnDemand:     cfg.Plugin.Lifecycle.OnDemand,
+			IdleShutdown: cfg.Plugin.Lifecycle.IdleShutdown,
+		},
+		CgroupRoot:    cfg.Plugin.CgroupRoot,
+		Sandbox:       sandboxPolicies(cfg.Plugin.Sandbox),
+		State:         p.State,
+		Secrets:       cfg.Plugin.Secrets,
+		HostCalls:     cfg.Plugin.HostCalls,
+		Plugins:       p.Plugins,
+		Aliases:       p.Aliases,
+		Policies:      p.Policies,
+		Logger:        p.Logger,
+		LogBufferSize: cfg.Plugin.LogBufferSize,
+		Metrics:       p.Metrics,
+	})
+	if err := p.Metrics.Register(manager.Collector()); err != nil {
+		return nil, fmt.Errorf("failed to register plugin pool metrics: %w", err)
+	}
+	return manager, nil
+}
+
+func sandboxPolicies(configs map[string]config.SandboxConfig) map[string]SandboxPolicy {
+	policies := make(map[string]SandboxPolicy, len(configs))
+	for namespace, sc := range configs {
+		policies[namespace] = SandboxPolicy{
+			Enabled: sc.Enabled,
+			Network: sc.AllowNetwork,
+			Env:     sc.AllowEnv,
+			Seccomp: !sc.DisableSeccomp,
+		}
+	}
+	return policies
+}
+
+func provideSupervisor(cfg *config.Config, manager *Manager, repo repository.PluginRepository) *Supervisor {
+	sc := cfg.Plugin.Supervisor
+	return NewSupervisor(manager, repo, &SupervisorConfig{
+		HealthInterval: sc.HealthInterval,
+		HealthTimeout:  sc.HealthTimeout,
+		InitialBackoff: sc.InitialBackoff,
+		MaxBackoff:     sc.MaxBackoff,
+		MaxRestarts:    sc.MaxRestarts,
+		RestartWindow:  sc.RestartWindow,
+	})
+}
+
+func provideDiscoverer(cfg *config.Config, manager *Manager, repo repository.PluginRepository) *Discoverer {
+	return NewDiscoverer(manager, repo, cfg.Plugin.Dir)
+}
+
+func providePipelineEngine(manager *Manager, repo repository.PluginRepository, policies *policy.Enforcer) *PipelineEngine {
+	return NewPipelineEngine(repo, manager, policies)
+}
+
+// discoverPlugins is invoked before autoLoadPlugins so that binaries dropped
+// into the plugin directory are registered before active plugins are loaded.
+func discoverPlugins(lc fx.Lifecycle, cfg *config.Config, discoverer *Discoverer) {
+	if !cfg.Plugin.ScanOnStartup {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			result, err := discoverer.Scan(ctx)
+			if err != nil {
+				slog.Error("Failed to scan plugin directory", "dir", cfg.Plugin.Dir, "error", err)
+				return nil
+			}
+
+			for _, plugin := range result.Registered {
+				slog.Info("Discovered plugin", "plugin_id", plugin.ID, "namespace", plugin.Namespace, "plugin", plugin.Name, "version", plugin.Version)
+			}
+			for _, issue := range result.Skipped {
+				slog.Warn("Skipped plugin binary", "path", issue.Path, "reason", issue.Reason)
+			}
+			return nil
+		},
 	})
 }
 
@@ -48,16 +160,43 @@ func autoLoadPlugins(p autoLoadPluginsParams) {
 			}
 
 			if len(plugins) == 0 {
-				fmt.Println("📦 No active plugins to load")
+				slog.Info("No active plugins to load")
 				return nil
 			}
 
 			for _, plugin := range plugins {
-				if err := p.Manager.LoadPlugin(plugin.ID, plugin.BinaryPath, plugin.Name); err != nil {
-					fmt.Printf("❌ Failed to load plugin %s (ID: %d): %v\n", plugin.Name, plugin.ID, err)
-					p.Repo.UpdateStatus(plugin.ID, models.PluginStatusError)
+				spec := SpecFromModel(plugin)
+
+				// On-demand plugins were verified at activation and start with their first call
+				if lifecycle, err := p.Manager.LifecycleFor(spec); err == nil && lifecycle.OnDemand {
+					if err := p.Manager.LoadPluginIdle(spec); err != nil {
+						slog.Error("Failed to load plugin", "plugin_id", plugin.ID, "plugin", plugin.Name, "error", err)
+						p.Repo.UpdateStatusWithReason(plugin.ID, models.PluginStatusError, err.Error())
+					} else {
+						slog.Info("Loaded plugin, it starts on its first call", "plugin_id", plugin.ID, "plugin", plugin.Name, "version", plugin.Version)
+					}
+					continue
+				}
+
+				if err := p.Manager.LoadPlugin(spec); err != nil {
+					slog.Error("Failed to load plugin", "plugin_id", plugin.ID, "plugin", plugin.Name, "error", err)
+					p.Repo.UpdateStatusWithReason(plugin.ID, models.PluginStatusError, err.Error())
 				} else {
-					fmt.Printf("✅ Successfully loaded plugin %s (ID: %d)\n", plugin.Name, plugin.ID)
+					if version, err := p.Manager.NegotiatedVersion(plugin.ID); err == nil && version != plugin.ProtocolVersion {
+						p.Repo.UpdateProtocolVersion(plugin.ID, version)
+					}
+					// The binary may have changed since activation, so refresh its method descriptors and config schema
+					if methods, err := p.Manager.DescribeMethods(ctx, plugin.ID); err == nil {
+						if plugin.Metadata == nil {
+							plugin.Metadata = models.JSONMap{}
+						}
+						plugin.Metadata["methods"] = methods
+						if schema, err := p.Manager.DescribeConfig(ctx, plugin.ID); err == nil {
+							plugin.Metadata["config_schema"] = schema
+						}
+						p.Repo.UpdateMetadata(plugin.ID, plugin.Metadata)
+					}
+					slog.Info("Loaded plugin", "plugin_id", plugin.ID, "plugin", plugin.Name, "version", plugin.Version)
 				}
 			}
 
@@ -69,3 +208,18 @@ func autoLoadPlugins(p autoLoadPluginsParams) {
 		},
 	})
 }
+
+// startSupervisor is invoked after autoLoadPlugins so that supervision starts
+// once active plugins are loaded and stops before they are unloaded.
+func startSupervisor(lc fx.Lifecycle, supervisor *Supervisor) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			supervisor.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			supervisor.Stop()
+			return nil
+		},
+	})
+}
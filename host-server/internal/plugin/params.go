@@ -0,0 +1,221 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrInvalidParams is returned when call parameters do not match the method's declared schema
+var ErrInvalidParams = errors.New("invalid plugin call parameters")
+
+// IsInvalidParams reports whether err was caused by parameters failing schema validation
+func IsInvalidParams(err error) bool {
+	return errors.Is(err, ErrInvalidParams)
+}
+
+// MethodsFromMetadata extracts the method descriptors stored in a plugin
+// record's metadata. It returns nil when the metadata does not describe
+// methods in the PluginMetadata form, in which case calls are not validated.
+func MethodsFromMetadata(metadata map[string]any) []MethodMetadata {
+	raw, ok := metadata["methods"]
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+
+	var methods []MethodMetadata
+	if err := json.Unmarshal(data, &methods); err != nil {
+		return nil
+	}
+	return methods
+}
+
+// FindMethod returns the descriptor of the named method
+func FindMethod(methods []MethodMetadata, name string) (*MethodMetadata, bool) {
+	for i := range methods {
+		if methods[i].Name == name {
+			return &methods[i], true
+		}
+	}
+	return nil, false
+}
+
+// BuildCallParams validates params against the method's descriptor, when the
+// plugin describes its methods, and converts them to their wire form. Errors
+// wrap ErrInvalidParams.
+func BuildCallParams(record *models.Plugin, methods []MethodMetadata, method string, params map[string]any) (*structpb.Struct, error) {
+	if len(methods) > 0 {
+		descriptor, ok := FindMethod(methods, method)
+		if !ok {
+			return nil, fmt.Errorf("%w: plugin '%s' has no method '%s'", ErrInvalidParams, record.Name, method)
+		}
+
+		validated, err := descriptor.ValidateParams(params)
+		if err != nil {
+			return nil, err
+		}
+		params = validated
+	}
+
+	wireParams, err := structpb.NewStruct(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParams, err)
+	}
+	return wireParams, nil
+}
+
+// ValidateParams checks params against the method's declared parameters and
+// returns a copy with defaults filled in for omitted optional parameters.
+// Methods that declare no parameters accept anything, since older plugins
+// only list method names.
+func (m *MethodMetadata) ValidateParams(params map[string]any) (map[string]any, error) {
+	validated := make(map[string]any, len(params))
+	for key, value := range params {
+		validated[key] = value
+	}
+
+	if len(m.Parameters) == 0 {
+		return validated, nil
+	}
+
+	var problems []string
+
+	for key := range params {
+		if _, ok := m.Parameters[key]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown parameter '%s'", key))
+		}
+	}
+
+	for name, schema := range m.Parameters {
+		value, ok := params[name]
+		if !ok || value == nil {
+			if schema.Required {
+				problems = append(problems, fmt.Sprintf("missing required parameter '%s'", name))
+			} else if schema.Default != nil {
+				validated[name] = schema.Default
+			}
+			continue
+		}
+
+		if !matchesType(schema.Type, value) {
+			problems = append(problems, fmt.Sprintf("parameter '%s' must be of type %s", name, schema.Type))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("%w for method '%s': %s", ErrInvalidParams, m.Name, strings.Join(problems, "; "))
+	}
+
+	return validated, nil
+}
+
+// ValidateStreamParams is ValidateParams for a streaming call. The StreamInput
+// parameter arrives as the input stream, so it is neither required nor accepted
+// as a regular parameter.
+func (m *MethodMetadata) ValidateStreamParams(params map[string]any) (map[string]any, error) {
+	if m.StreamInput == "" {
+		return nil, fmt.Errorf("%w: method '%s' does not accept streamed input", ErrInvalidParams, m.Name)
+	}
+
+	rest := *m
+	rest.Parameters = make(map[string]ParamSchema, len(m.Parameters))
+	for name, schema := range m.Parameters {
+		if name != m.StreamInput {
+			rest.Parameters[name] = schema
+		}
+	}
+	if _, ok := params[m.StreamInput]; ok {
+		return nil, fmt.Errorf("%w for method '%s': parameter '%s' is read from the request body", ErrInvalidParams, m.Name, m.StreamInput)
+	}
+
+	return rest.ValidateParams(params)
+}
+
+// ParamsFromQuery converts query string values to the types the method
+// declares, so they can be validated like a JSON body. Undeclared parameters
+// are kept as strings; objects and arrays must be JSON encoded.
+func (m *MethodMetadata) ParamsFromQuery(query url.Values) (map[string]any, error) {
+	params := make(map[string]any, len(query))
+	var problems []string
+
+	for name := range query {
+		raw := query.Get(name)
+		schema, ok := m.Parameters[name]
+		if !ok {
+			params[name] = raw
+			continue
+		}
+
+		value, err := parseQueryValue(schema.Type, raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("parameter '%s' must be of type %s", name, schema.Type))
+			continue
+		}
+		params[name] = value
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("%w for method '%s': %s", ErrInvalidParams, m.Name, strings.Join(problems, "; "))
+	}
+
+	return params, nil
+}
+
+func parseQueryValue(schemaType, raw string) (any, error) {
+	switch schemaType {
+	case "int", "integer", "number", "float":
+		return strconv.ParseFloat(raw, 64)
+	case "bool", "boolean":
+		return strconv.ParseBool(raw)
+	case "object", "array":
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
+
+// matchesType reports whether a JSON-decoded value is of the declared schema type
+func matchesType(schemaType string, value any) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "int", "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number", "float":
+		_, ok := value.(float64)
+		return ok
+	case "bool", "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	default:
+		// Unknown or "any" types are left for the plugin to check
+		return true
+	}
+}
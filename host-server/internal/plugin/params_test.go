@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestMethodMetadata_ValidateParams(t *testing.T) {
+	method := MethodMetadata{
+		Name: "DPCount",
+		Parameters: map[string]ParamSchema{
+			"values":                     {Type: "array", Required: true},
+			"epsilon":                    {Type: "number", Required: true},
+			"max_partitions_contributed": {Type: "int", Default: float64(1)},
+			"label":                      {Type: "string"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		params  map[string]any
+		wantErr bool
+		check   func(map[string]any) bool
+	}{
+		{
+			name:   "valid params get defaults",
+			params: map[string]any{"values": []any{1.0, 2.0}, "epsilon": 0.5},
+			check: func(p map[string]any) bool {
+				return p["max_partitions_contributed"] == float64(1) && p["label"] == nil
+			},
+		},
+		{
+			name:    "missing required param",
+			params:  map[string]any{"values": []any{1.0}},
+			wantErr: true,
+		},
+		{
+			name:    "wrong type",
+			params:  map[string]any{"values": "1,2,3", "epsilon": 0.5},
+			wantErr: true,
+		},
+		{
+			name:    "fractional int",
+			params:  map[string]any{"values": []any{}, "epsilon": 0.5, "max_partitions_contributed": 1.5},
+			wantErr: true,
+		},
+		{
+			name:    "unknown param",
+			params:  map[string]any{"values": []any{}, "epsilon": 0.5, "extra": true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := method.ValidateParams(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !IsInvalidParams(err) {
+					t.Errorf("ValidateParams() error = %v, want ErrInvalidParams", err)
+				}
+				return
+			}
+			if tt.check != nil && !tt.check(got) {
+				t.Errorf("ValidateParams() = %v", got)
+			}
+		})
+	}
+}
+
+func TestMethodMetadata_ValidateParamsWithoutSchema(t *testing.T) {
+	method := MethodMetadata{Name: "ConvertToCSV"}
+
+	got, err := method.ValidateParams(map[string]any{"data": "[]", "delimiter": ";"})
+	if err != nil {
+		t.Fatalf("ValidateParams() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("ValidateParams() = %v, want params passed through", got)
+	}
+}
+
+func TestMethodsFromMetadata(t *testing.T) {
+	metadata := map[string]any{
+		"methods": []any{
+			map[string]any{
+				"name":       "DesensitizeName",
+				"parameters": map[string]any{"data": map[string]any{"type": "string", "required": true}},
+			},
+		},
+	}
+
+	methods := MethodsFromMetadata(metadata)
+	method, ok := FindMethod(methods, "DesensitizeName")
+	if !ok {
+		t.Fatalf("FindMethod() did not find DesensitizeName in %v", methods)
+	}
+	if !method.Parameters["data"].Required {
+		t.Errorf("expected 'data' to be required")
+	}
+
+	if methods := MethodsFromMetadata(map[string]any{"methods": []string{"DesensitizeName"}}); methods != nil {
+		t.Errorf("MethodsFromMetadata() = %v, want nil for name-only method lists", methods)
+	}
+}
+
+func TestMethodMetadata_StreamParams(t *testing.T) {
+	method := MethodMetadata{
+		Name: "ConvertToCSV",
+		Parameters: map[string]ParamSchema{
+			"data":      {Type: "string", Required: true},
+			"delimiter": {Type: "string", Default: ","},
+			"limit":     {Type: "int"},
+			"header":    {Type: "bool"},
+		},
+		StreamInput: "data",
+	}
+
+	params, err := method.ParamsFromQuery(url.Values{"limit": {"10"}, "header": {"false"}})
+	if err != nil {
+		t.Fatalf("ParamsFromQuery() error = %v", err)
+	}
+	if params["limit"] != float64(10) || params["header"] != false {
+		t.Errorf("ParamsFromQuery() = %v, want typed values", params)
+	}
+
+	validated, err := method.ValidateStreamParams(params)
+	if err != nil {
+		t.Fatalf("ValidateStreamParams() error = %v", err)
+	}
+	if validated["delimiter"] != "," {
+		t.Errorf("ValidateStreamParams() = %v, want the default delimiter", validated)
+	}
+
+	if _, err := method.ParamsFromQuery(url.Values{"limit": {"ten"}}); !IsInvalidParams(err) {
+		t.Errorf("ParamsFromQuery() error = %v, want ErrInvalidParams for a non-numeric int", err)
+	}
+	if _, err := method.ValidateStreamParams(map[string]any{"data": "[]"}); !IsInvalidParams(err) {
+		t.Errorf("ValidateStreamParams() error = %v, want ErrInvalidParams when the stream input is passed as a parameter", err)
+	}
+
+	method.StreamInput = ""
+	if _, err := method.ValidateStreamParams(nil); !IsInvalidParams(err) {
+		t.Errorf("ValidateStreamParams() error = %v, want ErrInvalidParams for a method that does not stream", err)
+	}
+}
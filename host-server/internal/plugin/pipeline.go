@@ -0,0 +1,588 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+// ErrInvalidPipeline is returned when a pipeline definition cannot run
+var ErrInvalidPipeline = errors.New("invalid pipeline")
+
+// IsInvalidPipeline reports whether err was caused by an invalid pipeline definition
+func IsInvalidPipeline(err error) bool {
+	return errors.Is(err, ErrInvalidPipeline)
+}
+
+// ErrPluginUnavailable is returned when a pipeline step names a plugin that
+// is not installed or not active
+var ErrPluginUnavailable = errors.New("plugin unavailable")
+
+// IsPluginUnavailable reports whether err was caused by a plugin that is not active
+func IsPluginUnavailable(err error) bool {
+	return errors.Is(err, ErrPluginUnavailable)
+}
+
+// What a pipeline does when one of its steps fails
+const (
+	StepOnErrorAbort    = "abort"    // Skip the remaining steps and fail the run
+	StepOnErrorContinue = "continue" // Run the remaining steps that do not depend on the failed one
+)
+
+// Status of a pipeline step after a run
+const (
+	StepStatusSucceeded = "succeeded"
+	StepStatusFailed    = "failed"
+	StepStatusSkipped   = "skipped"
+	StepStatusPlanned   = "planned" // Dry run: the step would run with the reported params
+)
+
+const (
+	// maxStepRetries bounds the retries of one step
+	maxStepRetries = 5
+	// stepRetryDelay is the delay before the first retry, doubled for each further one
+	stepRetryDelay = 100 * time.Millisecond
+	// defaultPluginNamespace is the namespace of steps that name none
+	defaultPluginNamespace = "default"
+)
+
+var stepNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidatePipeline checks a pipeline definition without resolving its
+// plugins: step names must be unique, inputs may only read the pipeline
+// input or earlier steps, and the error handling must be known.
+func ValidatePipeline(steps []models.PipelineStep) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("%w: a pipeline needs at least one step", ErrInvalidPipeline)
+	}
+
+	var problems []string
+	seen := make(map[string]bool, len(steps))
+
+	for i, step := range steps {
+		label := fmt.Sprintf("step %d", i+1)
+		if step.Name != "" {
+			label = fmt.Sprintf("step '%s'", step.Name)
+		}
+
+		switch {
+		case step.Name == "":
+			problems = append(problems, fmt.Sprintf("%s has no name", label))
+		case !stepNamePattern.MatchString(step.Name):
+			problems = append(problems, fmt.Sprintf("%s: names may only contain letters, digits, '_' and '-'", label))
+		case seen[step.Name]:
+			problems = append(problems, fmt.Sprintf("%s is defined twice", label))
+		}
+
+		if step.Plugin == "" {
+			problems = append(problems, fmt.Sprintf("%s has no plugin", label))
+		}
+		if step.Method == "" {
+			problems = append(problems, fmt.Sprintf("%s has no method", label))
+		}
+		if step.OnError != "" && step.OnError != StepOnErrorAbort && step.OnError != StepOnErrorContinue {
+			problems = append(problems, fmt.Sprintf("%s: on_error must be '%s' or '%s'", label, StepOnErrorAbort, StepOnErrorContinue))
+		}
+		if step.Retries < 0 || step.Retries > maxStepRetries {
+			problems = append(problems, fmt.Sprintf("%s: retries must be between 0 and %d", label, maxStepRetries))
+		}
+
+		for target, source := range step.Inputs {
+			if target == "" {
+				problems = append(problems, fmt.Sprintf("%s maps an input to an empty parameter", label))
+			}
+			if dependency, fromStep, err := parseSource(source); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: input '%s': %v", label, target, err))
+			} else if fromStep && !seen[dependency] {
+				problems = append(problems, fmt.Sprintf("%s: input '%s' reads step '%s', which does not run before it", label, target, dependency))
+			}
+		}
+		for param, encoding := range step.Encode {
+			if encoding != "json" {
+				problems = append(problems, fmt.Sprintf("%s: parameter '%s' has unknown encoding '%s'", label, param, encoding))
+			}
+		}
+
+		seen[step.Name] = true
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidPipeline, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// parseSource checks the source of a step input, which is either "input" or
+// "steps.<name>", optionally followed by the path into it. It returns the
+// step the source reads, if any.
+func parseSource(source string) (step string, fromStep bool, err error) {
+	segments := strings.Split(source, ".")
+	switch segments[0] {
+	case "input":
+		return "", false, nil
+	case "steps":
+		if len(segments) < 2 || segments[1] == "" {
+			return "", false, errors.New("source must name a step, as steps.<name>")
+		}
+		return segments[1], true, nil
+	default:
+		return "", false, fmt.Errorf("source '%s' must start with 'input' or 'steps'", source)
+	}
+}
+
+// PipelinePlugins looks up the plugins pipeline steps call. The plugin
+// repository implements it.
+type PipelinePlugins interface {
+	FindAll(filters map[string]any) ([]*models.Plugin, error)
+	UpdateLastUsedAt(id uint, timestamp int64) error
+}
+
+// PipelineClients provides the clients of loaded plugins. The Manager implements it.
+type PipelineClients interface {
+	GetPluginClient(pluginID uint) (any, error)
+	CallContext(parent context.Context, pluginID uint) (context.Context, context.CancelFunc)
+}
+
+// PipelinePolicies decides whether the principal running a pipeline may make
+// a step's call. The policy Enforcer implements it.
+type PipelinePolicies interface {
+	Authorize(ctx context.Context, record *models.Plugin, method string, params map[string]any) error
+}
+
+// PipelineEngine runs pipelines inside the host, so a chain of plugin calls
+// takes one request instead of a round trip per step
+type PipelineEngine struct {
+	plugins  PipelinePlugins
+	clients  PipelineClients
+	policies PipelinePolicies
+}
+
+// NewPipelineEngine creates an engine calling plugins through clients. Steps
+// are only checked against policies when policies is not nil.
+func NewPipelineEngine(plugins PipelinePlugins, clients PipelineClients, policies PipelinePolicies) *PipelineEngine {
+	return &PipelineEngine{
+		plugins:  plugins,
+		clients:  clients,
+		policies: policies,
+	}
+}
+
+// PipelineRun is the outcome of running a pipeline. Result is the result of
+// the last step.
+type PipelineRun struct {
+	Success bool
+	Result  any
+	Steps   []StepOutcome
+}
+
+// StepOutcome is the outcome of one pipeline step. CtxErr is set when the
+// step's context ended before its call completed.
+type StepOutcome struct {
+	Name     string
+	PluginID uint
+	Method   string
+	Status   string
+	Attempts int
+	Params   map[string]any // Params sent, or in a dry run those known before the run
+	Deferred []string       // Dry run: params that take the result of an earlier step
+	Result   any
+	Reason   string // Why a step was skipped
+	Err      error
+	CtxErr   error
+	Duration time.Duration
+}
+
+// Run executes steps in order with input as the pipeline input. A failed
+// step either aborts the run or, with on_error continue, only skips the steps
+// that read its result. In a dry run no plugin is called: every step is
+// resolved and its params are mapped and validated as far as they are known
+// before the run.
+func (e *PipelineEngine) Run(ctx context.Context, steps []models.PipelineStep, input map[string]any, dryRun bool) *PipelineRun {
+	run := &PipelineRun{Success: true, Steps: make([]StepOutcome, len(steps))}
+	results := make(map[string]any, len(steps))
+	failed := make(map[string]bool)
+	aborted := ""
+
+	for i, step := range steps {
+		outcome := &run.Steps[i]
+		*outcome = StepOutcome{Name: step.Name, Method: step.Method}
+
+		if aborted != "" {
+			outcome.Status = StepStatusSkipped
+			outcome.Reason = fmt.Sprintf("pipeline aborted at step '%s'", aborted)
+			continue
+		}
+		if dependency := failedDependency(step, failed); dependency != "" {
+			outcome.Status = StepStatusSkipped
+			outcome.Reason = fmt.Sprintf("step '%s' did not succeed", dependency)
+			failed[step.Name] = true
+			continue
+		}
+
+		if dryRun {
+			e.planStep(step, input, outcome)
+		} else {
+			e.runStep(ctx, step, input, results, outcome)
+		}
+
+		if outcome.Err != nil {
+			run.Success = false
+			failed[step.Name] = true
+			if step.OnError != StepOnErrorContinue {
+				aborted = step.Name
+			}
+			continue
+		}
+		results[step.Name] = outcome.Result
+		run.Result = outcome.Result
+	}
+
+	if len(steps) > 0 && run.Steps[len(steps)-1].Status != StepStatusSucceeded {
+		run.Result = nil
+	}
+	return run
+}
+
+// failedDependency returns a step that step reads and that did not succeed
+func failedDependency(step models.PipelineStep, failed map[string]bool) string {
+	var dependencies []string
+	for _, source := range step.Inputs {
+		if dependency, fromStep, _ := parseSource(source); fromStep && failed[dependency] {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	if len(dependencies) == 0 {
+		return ""
+	}
+	sort.Strings(dependencies)
+	return dependencies[0]
+}
+
+func (e *PipelineEngine) runStep(ctx context.Context, step models.PipelineStep, input map[string]any, results map[string]any, outcome *StepOutcome) {
+	started := time.Now()
+	defer func() { outcome.Duration = time.Since(started) }()
+	outcome.Status = StepStatusFailed
+
+	record, err := e.resolvePlugin(step)
+	if err != nil {
+		outcome.Err = err
+		return
+	}
+	outcome.PluginID = record.ID
+
+	params, _, err := mapStepParams(step, input, results)
+	if err != nil {
+		outcome.Err = err
+		return
+	}
+	outcome.Params = params
+
+	wireParams, err := BuildCallParams(record, MethodsFromMetadata(record.Metadata), step.Method, params)
+	if err != nil {
+		outcome.Err = err
+		return
+	}
+
+	if e.policies != nil {
+		if err := e.policies.Authorize(ctx, record, step.Method, wireParams.AsMap()); err != nil {
+			outcome.Err = err
+			return
+		}
+	}
+
+	clientInterface, err := e.clients.GetPluginClient(record.ID)
+	if err != nil {
+		outcome.Err = fmt.Errorf("failed to get plugin client: %w", err)
+		return
+	}
+	pluginClient, ok := clientInterface.(common.ContextPluginInterface)
+	if !ok {
+		outcome.Err = fmt.Errorf("plugin does not implement common.ContextPluginInterface")
+		return
+	}
+
+	e.plugins.UpdateLastUsedAt(record.ID, time.Now().Unix())
+
+	delay := stepRetryDelay
+	for attempt := 0; attempt <= step.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+				delay *= 2
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		outcome.Attempts++
+		outcome.Err, outcome.CtxErr = nil, nil
+
+		callCtx, cancel := e.clients.CallContext(ctx, record.ID)
+		resp, err := pluginClient.ExecuteContext(callCtx, step.Method, wireParams)
+		if err != nil {
+			outcome.Err = fmt.Errorf("plugin execution failed: %w", err)
+			outcome.CtxErr = callCtx.Err()
+		} else if !resp.Success {
+			outcome.Err = fmt.Errorf("plugin returned error: %s", resp.GetError())
+		}
+		cancel()
+
+		if outcome.Err == nil {
+			outcome.Status = StepStatusSucceeded
+			if resp.Result != nil {
+				outcome.Result = resp.Result.AsInterface()
+			}
+			return
+		}
+		if ctx.Err() != nil {
+			// The run itself was canceled, retrying cannot help
+			return
+		}
+	}
+}
+
+func (e *PipelineEngine) planStep(step models.PipelineStep, input map[string]any, outcome *StepOutcome) {
+	outcome.Status = StepStatusFailed
+
+	record, err := e.resolvePlugin(step)
+	if err != nil {
+		outcome.Err = err
+		return
+	}
+	outcome.PluginID = record.ID
+
+	params, deferred, err := mapStepParams(step, input, nil)
+	if err != nil {
+		outcome.Err = err
+		return
+	}
+	outcome.Params = params
+	outcome.Deferred = deferred
+
+	if err := validatePlannedParams(record, step.Method, params, deferred); err != nil {
+		outcome.Err = err
+		return
+	}
+	outcome.Status = StepStatusPlanned
+}
+
+// validatePlannedParams validates the params of a dry-run step. Deferred
+// params only get their value during a run, so they are checked to exist but
+// count as given.
+func validatePlannedParams(record *models.Plugin, method string, params map[string]any, deferred []string) error {
+	methods := MethodsFromMetadata(record.Metadata)
+	if len(methods) == 0 {
+		return nil
+	}
+
+	descriptor, ok := FindMethod(methods, method)
+	if !ok {
+		return fmt.Errorf("%w: plugin '%s' has no method '%s'", ErrInvalidParams, record.Name, method)
+	}
+	if len(descriptor.Parameters) == 0 || len(deferred) == 0 {
+		_, err := BuildCallParams(record, methods, method, params)
+		return err
+	}
+
+	known := *descriptor
+	known.Parameters = make(map[string]ParamSchema, len(descriptor.Parameters))
+	for name, schema := range descriptor.Parameters {
+		known.Parameters[name] = schema
+	}
+	for _, name := range deferred {
+		if _, ok := known.Parameters[name]; !ok {
+			return fmt.Errorf("%w for method '%s': unknown parameter '%s'", ErrInvalidParams, method, name)
+		}
+		delete(known.Parameters, name)
+	}
+	_, err := known.ValidateParams(params)
+	return err
+}
+
+// resolvePlugin finds the active plugin a step calls. When several versions
+// are active, the one installed last is called.
+func (e *PipelineEngine) resolvePlugin(step models.PipelineStep) (*models.Plugin, error) {
+	namespace := step.Namespace
+	if namespace == "" {
+		namespace = defaultPluginNamespace
+	}
+
+	plugins, err := e.plugins.FindAll(map[string]any{
+		"namespace": namespace,
+		"name":      step.Plugin,
+		"status":    models.PluginStatusActive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *models.Plugin
+	for _, p := range plugins {
+		if latest == nil || p.ID > latest.ID {
+			latest = p
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%w: no active plugin '%s/%s'", ErrPluginUnavailable, namespace, step.Plugin)
+	}
+	return latest, nil
+}
+
+// mapStepParams builds a step's params from its static params and inputs and
+// applies its encodings. Without results, as in a dry run, params read from
+// earlier steps are left out and reported as deferred.
+func mapStepParams(step models.PipelineStep, input map[string]any, results map[string]any) (map[string]any, []string, error) {
+	params, _ := copyValue(step.Params).(map[string]any)
+	if params == nil {
+		params = make(map[string]any)
+	}
+	sources := map[string]any{"input": input, "steps": results}
+
+	// Map in a fixed order, so a nested target always lands in its parent
+	targets := make([]string, 0, len(step.Inputs))
+	for target := range step.Inputs {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	deferred := make(map[string]bool)
+	for _, target := range targets {
+		source := step.Inputs[target]
+		if _, fromStep, _ := parseSource(source); fromStep && results == nil {
+			deferred[strings.SplitN(target, ".", 2)[0]] = true
+			continue
+		}
+
+		value, err := lookupPath(sources, source)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: input '%s': %v", ErrInvalidParams, target, err)
+		}
+		if err := assignPath(params, target, copyValue(value)); err != nil {
+			return nil, nil, fmt.Errorf("%w: input '%s': %v", ErrInvalidParams, target, err)
+		}
+	}
+
+	for param := range step.Encode {
+		if deferred[param] {
+			continue
+		}
+		value, ok := params[param]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: parameter '%s' to encode has no value", ErrInvalidParams, param)
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: failed to encode parameter '%s': %v", ErrInvalidParams, param, err)
+		}
+		params[param] = string(encoded)
+	}
+
+	for param := range deferred {
+		delete(params, param)
+	}
+	names := make([]string, 0, len(deferred))
+	for param := range deferred {
+		names = append(names, param)
+	}
+	sort.Strings(names)
+	return params, names, nil
+}
+
+// lookupPath reads the value at a dot-separated path of object keys and array indexes
+func lookupPath(root any, path string) (any, error) {
+	value := root
+	for i, segment := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]any:
+			next, ok := node[segment]
+			if !ok {
+				return nil, fmt.Errorf("'%s' has no field '%s'", joinPath(path, i), segment)
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("'%s' has no element '%s'", joinPath(path, i), segment)
+			}
+			value = node[index]
+		default:
+			return nil, fmt.Errorf("'%s' is not an object or array", joinPath(path, i))
+		}
+	}
+	return value, nil
+}
+
+// assignPath sets the value at a dot-separated path, creating the objects
+// on the way. Array elements can be replaced but not added.
+func assignPath(root map[string]any, path string, value any) error {
+	segments := strings.Split(path, ".")
+	var node any = root
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		switch current := node.(type) {
+		case map[string]any:
+			if last {
+				current[segment] = value
+				return nil
+			}
+			next, ok := current[segment]
+			if !ok || next == nil {
+				next = make(map[string]any)
+				current[segment] = next
+			}
+			node = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(current) {
+				return fmt.Errorf("'%s' has no element '%s'", joinPath(path, i), segment)
+			}
+			if last {
+				current[index] = value
+				return nil
+			}
+			node = current[index]
+		default:
+			return fmt.Errorf("'%s' is not an object or array", joinPath(path, i))
+		}
+	}
+	return nil
+}
+
+// joinPath returns the first n segments of path, for error messages
+func joinPath(path string, n int) string {
+	if n == 0 {
+		return "(root)"
+	}
+	return strings.Join(strings.Split(path, ".")[:n], ".")
+}
+
+// copyValue deep-copies a JSON value, so mapping never changes a stored step
+// or an earlier step's result
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for key, item := range v {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
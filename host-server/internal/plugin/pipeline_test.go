@@ -0,0 +1,293 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// funcClient is a plugin client that answers every call with handle
+type funcClient struct {
+	handle func(method string, params map[string]any) (any, error)
+	calls  int
+}
+
+func (c *funcClient) GetMetadataContext(ctx context.Context) (*common.MetadataResponse, error) {
+	return &common.MetadataResponse{}, nil
+}
+
+func (c *funcClient) ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (*common.ExecuteResponse, error) {
+	c.calls++
+	result, err := c.handle(method, params.AsMap())
+	if err != nil {
+		errMsg := err.Error()
+		return &common.ExecuteResponse{Success: false, Error: &errMsg}, nil
+	}
+	value, err := structpb.NewValue(result)
+	if err != nil {
+		return nil, err
+	}
+	return &common.ExecuteResponse{Success: true, Result: value}, nil
+}
+
+// fakePipelineHost serves active plugins by namespace/name
+type fakePipelineHost struct {
+	plugins []*models.Plugin
+	clients map[uint]*funcClient
+}
+
+func (h *fakePipelineHost) add(id uint, name string, methods []MethodMetadata, handle func(string, map[string]any) (any, error)) *funcClient {
+	metadata := models.JSONMap{}
+	if methods != nil {
+		metadata["methods"] = methods
+	}
+	h.plugins = append(h.plugins, &models.Plugin{ID: id, Namespace: "default", Name: name, Status: models.PluginStatusActive, Metadata: metadata})
+	if h.clients == nil {
+		h.clients = make(map[uint]*funcClient)
+	}
+	client := &funcClient{handle: handle}
+	h.clients[id] = client
+	return client
+}
+
+func (h *fakePipelineHost) FindAll(filters map[string]any) ([]*models.Plugin, error) {
+	var found []*models.Plugin
+	for _, p := range h.plugins {
+		if p.Namespace == filters["namespace"] && p.Name == filters["name"] && p.Status == filters["status"] {
+			found = append(found, p)
+		}
+	}
+	return found, nil
+}
+
+func (h *fakePipelineHost) UpdateLastUsedAt(id uint, timestamp int64) error { return nil }
+
+func (h *fakePipelineHost) GetPluginClient(pluginID uint) (any, error) {
+	client, ok := h.clients[pluginID]
+	if !ok {
+		return nil, fmt.Errorf("plugin not loaded")
+	}
+	return client, nil
+}
+
+func (h *fakePipelineHost) CallContext(parent context.Context, pluginID uint) (context.Context, context.CancelFunc) {
+	return context.WithCancel(parent)
+}
+
+func newTestPipelineHost() *fakePipelineHost {
+	h := &fakePipelineHost{}
+	h.add(1, "desensitization", []MethodMetadata{{
+		Name:       "DesensitizeName",
+		Parameters: map[string]ParamSchema{"data": {Type: "string", Required: true}},
+	}}, func(method string, params map[string]any) (any, error) {
+		name := []rune(params["data"].(string))
+		return string(name[0]) + strings.Repeat("*", len(name)-1), nil
+	})
+	h.add(2, "dpanonymizer", nil, func(method string, params map[string]any) (any, error) {
+		return params["value"].(float64) + 0.5, nil
+	})
+	h.add(3, "converter", []MethodMetadata{{
+		Name:       "ConvertToCSV",
+		Parameters: map[string]ParamSchema{"data": {Type: "string", Required: true}},
+	}}, func(method string, params map[string]any) (any, error) {
+		return "csv:" + params["data"].(string), nil
+	})
+	return h
+}
+
+var testPipeline = []models.PipelineStep{
+	{Name: "mask", Plugin: "desensitization", Method: "DesensitizeName", Inputs: map[string]string{"data": "input.name"}},
+	{Name: "noise", Plugin: "dpanonymizer", Method: "AddLaplaceNoise", Params: map[string]any{"epsilon": 1.0}, Inputs: map[string]string{"value": "input.salary"}},
+	{
+		Name: "csv", Plugin: "converter", Method: "ConvertToCSV",
+		Inputs: map[string]string{"data.name": "steps.mask", "data.salary": "steps.noise"},
+		Encode: map[string]string{"data": "json"},
+	},
+}
+
+func TestValidatePipeline(t *testing.T) {
+	if err := ValidatePipeline(testPipeline); err != nil {
+		t.Fatalf("ValidatePipeline() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		steps []models.PipelineStep
+	}{
+		{"no steps", nil},
+		{"duplicate name", []models.PipelineStep{
+			{Name: "a", Plugin: "p", Method: "m"},
+			{Name: "a", Plugin: "p", Method: "m"},
+		}},
+		{"forward reference", []models.PipelineStep{
+			{Name: "a", Plugin: "p", Method: "m", Inputs: map[string]string{"x": "steps.b"}},
+			{Name: "b", Plugin: "p", Method: "m"},
+		}},
+		{"unknown source", []models.PipelineStep{{Name: "a", Plugin: "p", Method: "m", Inputs: map[string]string{"x": "env.HOME"}}}},
+		{"unknown on_error", []models.PipelineStep{{Name: "a", Plugin: "p", Method: "m", OnError: "ignore"}}},
+		{"too many retries", []models.PipelineStep{{Name: "a", Plugin: "p", Method: "m", Retries: 99}}},
+		{"unknown encoding", []models.PipelineStep{{Name: "a", Plugin: "p", Method: "m", Encode: map[string]string{"x": "xml"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePipeline(tt.steps); !IsInvalidPipeline(err) {
+				t.Errorf("ValidatePipeline() error = %v, want ErrInvalidPipeline", err)
+			}
+		})
+	}
+}
+
+func TestPipelineEngine_Run(t *testing.T) {
+	host := newTestPipelineHost()
+	engine := NewPipelineEngine(host, host, nil)
+
+	run := engine.Run(context.Background(), testPipeline, map[string]any{"name": "张三", "salary": 100.0}, false)
+	if !run.Success {
+		t.Fatalf("Run() failed: %+v", run.Steps)
+	}
+
+	want := `csv:{"name":"张*","salary":100.5}`
+	if run.Result != want {
+		t.Errorf("Run() result = %v, want %v", run.Result, want)
+	}
+	for _, step := range run.Steps {
+		if step.Status != StepStatusSucceeded || step.Attempts != 1 {
+			t.Errorf("step %s = %s after %d attempts, want succeeded after 1", step.Name, step.Status, step.Attempts)
+		}
+	}
+}
+
+func TestPipelineEngine_StepErrorHandling(t *testing.T) {
+	host := newTestPipelineHost()
+	failures := 0
+	host.clients[2].handle = func(method string, params map[string]any) (any, error) {
+		failures++
+		return nil, fmt.Errorf("budget exhausted")
+	}
+	engine := NewPipelineEngine(host, host, nil)
+	input := map[string]any{"name": "张三", "salary": 100.0}
+
+	// By default the first failure aborts the run
+	run := engine.Run(context.Background(), testPipeline, input, false)
+	if run.Success || run.Result != nil {
+		t.Errorf("Run() = %v, %v, want a failed run without result", run.Success, run.Result)
+	}
+	if got := run.Steps[1].Status; got != StepStatusFailed {
+		t.Errorf("noise step = %s, want failed", got)
+	}
+	if got := run.Steps[2]; got.Status != StepStatusSkipped || !strings.Contains(got.Reason, "aborted") {
+		t.Errorf("csv step = %s (%s), want skipped because the run aborted", got.Status, got.Reason)
+	}
+
+	// With continue and retries, independent steps still run
+	steps := append([]models.PipelineStep(nil), testPipeline...)
+	steps[1].OnError = StepOnErrorContinue
+	steps[1].Retries = 2
+	steps = append(steps, models.PipelineStep{
+		Name: "mask_again", Plugin: "desensitization", Method: "DesensitizeName",
+		Inputs: map[string]string{"data": "input.name"},
+	})
+
+	failures = 0
+	run = engine.Run(context.Background(), steps, input, false)
+	if run.Success {
+		t.Error("Run() succeeded with a failed step")
+	}
+	if got := run.Steps[1].Attempts; got != 3 || failures != 3 {
+		t.Errorf("noise step attempts = %d (%d calls), want 3", got, failures)
+	}
+	if got := run.Steps[2]; got.Status != StepStatusSkipped || !strings.Contains(got.Reason, "noise") {
+		t.Errorf("csv step = %s (%s), want skipped because noise failed", got.Status, got.Reason)
+	}
+	if got := run.Steps[3].Status; got != StepStatusSucceeded {
+		t.Errorf("independent step = %s, want succeeded", got)
+	}
+}
+
+// denyEpsilonBelow is a PipelinePolicies denying calls with a smaller epsilon
+type denyEpsilonBelow float64
+
+func (d denyEpsilonBelow) Authorize(ctx context.Context, record *models.Plugin, method string, params map[string]any) error {
+	if epsilon, ok := params["epsilon"].(float64); ok && epsilon < float64(d) {
+		return fmt.Errorf("epsilon %v is below %v", epsilon, float64(d))
+	}
+	return nil
+}
+
+func TestPipelineEngine_Policies(t *testing.T) {
+	host := newTestPipelineHost()
+	engine := NewPipelineEngine(host, host, denyEpsilonBelow(2))
+
+	run := engine.Run(context.Background(), testPipeline, map[string]any{"name": "张三", "salary": 100.0}, false)
+	if run.Success {
+		t.Fatal("Run() succeeded with a denied step")
+	}
+	if got := run.Steps[1]; got.Status != StepStatusFailed || got.Err == nil || got.Attempts != 0 {
+		t.Errorf("noise step = %s after %d attempts (%v), want failed before calling the plugin", got.Status, got.Attempts, got.Err)
+	}
+	if host.clients[2].calls != 0 {
+		t.Error("denied step called the plugin")
+	}
+}
+
+func TestPipelineEngine_DryRun(t *testing.T) {
+	host := newTestPipelineHost()
+	engine := NewPipelineEngine(host, host, nil)
+
+	run := engine.Run(context.Background(), testPipeline, map[string]any{"name": "张三", "salary": 100.0}, true)
+	if !run.Success {
+		t.Fatalf("dry Run() failed: %+v", run.Steps)
+	}
+	for id, client := range host.clients {
+		if client.calls != 0 {
+			t.Errorf("dry run called plugin %d", id)
+		}
+	}
+	if got := run.Steps[0].Params["data"]; got != "张三" {
+		t.Errorf("mask params = %v, want the input mapped", run.Steps[0].Params)
+	}
+	if got := run.Steps[2]; got.Status != StepStatusPlanned || len(got.Deferred) != 1 || got.Deferred[0] != "data" {
+		t.Errorf("csv step = %s deferring %v, want planned deferring [data]", got.Status, got.Deferred)
+	}
+
+	// Missing input and unknown plugins are reported without running anything
+	steps := append([]models.PipelineStep(nil), testPipeline...)
+	steps[0].OnError = StepOnErrorContinue
+	steps[1].Plugin = "missing"
+	run = engine.Run(context.Background(), steps, map[string]any{}, true)
+	if run.Success {
+		t.Fatal("dry Run() succeeded with missing input and plugin")
+	}
+	if err := run.Steps[0].Err; !IsInvalidParams(err) {
+		t.Errorf("mask step error = %v, want ErrInvalidParams", err)
+	}
+	if err := run.Steps[1].Err; !IsPluginUnavailable(err) {
+		t.Errorf("noise step error = %v, want ErrPluginUnavailable", err)
+	}
+}
+
+func TestMapStepParams_DoesNotChangeSources(t *testing.T) {
+	step := models.PipelineStep{
+		Params: map[string]any{"data": map[string]any{"kept": 1.0}},
+		Inputs: map[string]string{"data.record": "steps.load.0"},
+	}
+	results := map[string]any{"load": []any{map[string]any{"id": 1.0}}}
+
+	params, _, err := mapStepParams(step, nil, results)
+	if err != nil {
+		t.Fatalf("mapStepParams() error = %v", err)
+	}
+	params["data"].(map[string]any)["record"].(map[string]any)["id"] = 2.0
+
+	if _, ok := step.Params["data"].(map[string]any)["record"]; ok {
+		t.Error("mapStepParams() changed the step's static params")
+	}
+	if results["load"].([]any)[0].(map[string]any)["id"] != 1.0 {
+		t.Error("mapStepParams() shares values with an earlier step's result")
+	}
+}
@@ -0,0 +1,671 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/metrics"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Pool dispatch strategies
+const (
+	PoolStrategyLeastLoaded = "least-loaded" // Pick the process with the fewest calls in flight
+	PoolStrategyRoundRobin  = "round-robin"  // Cycle through the processes in turn
+)
+
+// PoolConfig sizes the process pool of a plugin
+type PoolConfig struct {
+	MinSize     int           // Processes kept running while the plugin is loaded
+	MaxSize     int           // Upper bound the pool grows to while all processes are busy
+	Strategy    string        // PoolStrategyLeastLoaded or PoolStrategyRoundRobin
+	IdleTimeout time.Duration // Processes above MinSize idle for this long are stopped
+}
+
+func defaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MinSize:     1,
+		MaxSize:     1,
+		Strategy:    PoolStrategyLeastLoaded,
+		IdleTimeout: 5 * time.Minute,
+	}
+}
+
+// withDefaults fills unset fields from defaults and checks the result
+func (c PoolConfig) withDefaults(defaults PoolConfig) (PoolConfig, error) {
+	if c.MinSize <= 0 {
+		c.MinSize = defaults.MinSize
+	}
+	if c.MaxSize <= 0 {
+		c.MaxSize = defaults.MaxSize
+	}
+	if c.MaxSize < c.MinSize {
+		c.MaxSize = c.MinSize
+	}
+	if c.Strategy == "" {
+		c.Strategy = defaults.Strategy
+	}
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = defaults.IdleTimeout
+	}
+
+	if c.Strategy != PoolStrategyLeastLoaded && c.Strategy != PoolStrategyRoundRobin {
+		return c, fmt.Errorf("invalid pool strategy '%s' (must be '%s' or '%s')", c.Strategy, PoolStrategyLeastLoaded, PoolStrategyRoundRobin)
+	}
+	return c, nil
+}
+
+// PoolConfigFromMap reads a per-plugin pool override stored in a plugin's
+// config under "pool", e.g. {"min_size": 1, "max_size": 4, "idle_timeout": "2m"}.
+// Fields that are left out keep the host-wide defaults.
+func PoolConfigFromMap(raw map[string]any) (PoolConfig, error) {
+	var override struct {
+		MinSize     int    `json:"min_size"`
+		MaxSize     int    `json:"max_size"`
+		Strategy    string `json:"strategy"`
+		IdleTimeout string `json:"idle_timeout"`
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return PoolConfig{}, fmt.Errorf("invalid pool config: %w", err)
+	}
+	if err := json.Unmarshal(data, &override); err != nil {
+		return PoolConfig{}, fmt.Errorf("invalid pool config: %w", err)
+	}
+
+	config := PoolConfig{
+		MinSize:  override.MinSize,
+		MaxSize:  override.MaxSize,
+		Strategy: override.Strategy,
+	}
+	if override.IdleTimeout != "" {
+		config.IdleTimeout, err = time.ParseDuration(override.IdleTimeout)
+		if err != nil {
+			return PoolConfig{}, fmt.Errorf("invalid pool idle_timeout: %w", err)
+		}
+	}
+	return config, nil
+}
+
+// PoolStats is a snapshot of a plugin's process pool
+type PoolStats struct {
+	Strategy string        `json:"strategy"`
+	MinSize  int           `json:"min_size"`
+	MaxSize  int           `json:"max_size"`
+	Size     int           `json:"size"`     // Running processes
+	Starting int           `json:"starting"` // Processes being started to absorb load
+	InFlight int64         `json:"in_flight"`
+	Workers  []WorkerStats `json:"workers"`
+}
+
+// WorkerStats describes one process of a pool
+type WorkerStats struct {
+	PID        int    `json:"pid"`
+	InFlight   int64  `json:"in_flight"`
+	Calls      uint64 `json:"calls"`
+	StartedAt  int64  `json:"started_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+}
+
+// pluginProcess is the part of *plugin.Client the pool relies on
+type pluginProcess interface {
+	Exited() bool
+	Kill()
+	NegotiatedVersion() int
+}
+
+// startFunc starts one plugin process and dispenses its client interface
+type startFunc func(spec PluginSpec) (pluginProcess, any, error)
+
+// worker is one plugin process of a pool
+type worker struct {
+	process   pluginProcess
+	client    common.ContextPluginInterface
+	raw       any
+	inFlight  atomic.Int64
+	calls     atomic.Uint64
+	lastUsed  atomic.Int64 // Unix nanoseconds
+	startedAt time.Time
+	settings  int // settingsVersion the process was started or last brought up to date with
+}
+
+func newWorker(process pluginProcess, raw any) (*worker, error) {
+	client, ok := raw.(common.ContextPluginInterface)
+	if !ok {
+		return nil, fmt.Errorf("plugin does not implement common.ContextPluginInterface")
+	}
+
+	w := &worker{process: process, client: client, raw: raw, startedAt: time.Now()}
+	w.lastUsed.Store(w.startedAt.UnixNano())
+	return w, nil
+}
+
+func (w *worker) pid() int {
+	if client, ok := w.process.(*plugin.Client); ok {
+		if reattach := client.ReattachConfig(); reattach != nil {
+			return reattach.Pid
+		}
+	}
+	return 0
+}
+
+// pool runs between MinSize and MaxSize processes of one plugin. It grows by
+// one process whenever a call arrives while every process is busy, and
+// shrinks back to MinSize as processes sit idle.
+type pool struct {
+	spec     PluginSpec
+	config   PoolConfig
+	start    startFunc
+	mu       sync.Mutex
+	workers  []*worker
+	starting int
+	next     int // Round-robin cursor
+	closed   bool
+	created  time.Time
+
+	settingsVersion int             // Bumped whenever spec.Settings is replaced
+	methods         map[string]bool // Declared method names, see declares
+}
+
+// newPool starts MinSize processes. If any of them fails to start, the ones
+// already started are stopped again.
+func newPool(spec PluginSpec, config PoolConfig, start startFunc) (*pool, error) {
+	p := &pool{spec: spec, config: config, start: start, created: time.Now()}
+	p.declare(spec.Methods)
+
+	for i := 0; i < config.MinSize; i++ {
+		w, err := p.startWorker()
+		if err != nil {
+			p.close()
+			return nil, err
+		}
+		p.workers = append(p.workers, w)
+	}
+
+	return p, nil
+}
+
+func (p *pool) startWorker() (*worker, error) {
+	p.mu.Lock()
+	spec, version := p.spec, p.settingsVersion
+	p.mu.Unlock()
+
+	process, raw, err := p.start(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := newWorker(process, raw)
+	if err != nil {
+		process.Kill()
+		return nil, err
+	}
+	w.settings = version
+	return w, nil
+}
+
+// acquire picks a process for one call. The caller must release it.
+func (p *pool) acquire() (*worker, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("plugin not loaded")
+	}
+
+	dead := p.pruneExitedLocked()
+	w := p.pickLocked()
+	if w != nil {
+		if p.allBusyLocked() && len(p.workers)+p.starting < p.config.MaxSize {
+			p.starting++
+			go p.grow()
+		}
+		w.inFlight.Add(1)
+	}
+	p.mu.Unlock()
+
+	killAll(dead)
+
+	if w == nil {
+		return nil, fmt.Errorf("plugin process exited")
+	}
+	return w, nil
+}
+
+func (p *pool) pickLocked() *worker {
+	if len(p.workers) == 0 {
+		return nil
+	}
+
+	if p.config.Strategy == PoolStrategyRoundRobin {
+		w := p.workers[p.next%len(p.workers)]
+		p.next++
+		return w
+	}
+
+	w := p.workers[0]
+	for _, candidate := range p.workers[1:] {
+		if candidate.inFlight.Load() < w.inFlight.Load() {
+			w = candidate
+		}
+	}
+	return w
+}
+
+func (p *pool) allBusyLocked() bool {
+	for _, w := range p.workers {
+		if w.inFlight.Load() == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *pool) release(w *worker) {
+	w.lastUsed.Store(time.Now().UnixNano())
+	w.calls.Add(1)
+	w.inFlight.Add(-1)
+}
+
+// grow adds a process in the background, so the call that triggered it is
+// not held up by the process start
+func (p *pool) grow() {
+	w, err := p.startWorker()
+
+	p.mu.Lock()
+	// The settings may have changed while the process was starting
+	for err == nil && !p.closed && w.settings != p.settingsVersion {
+		settings, version := p.spec.Settings, p.settingsVersion
+		p.mu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
+		err = configureProcess(ctx, w.raw, settings)
+		cancel()
+		w.settings = version
+		p.mu.Lock()
+	}
+	p.starting--
+	closed := p.closed
+	if err == nil && !closed {
+		p.workers = append(p.workers, w)
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		if w != nil {
+			w.process.Kill()
+		}
+		slog.Error("Failed to grow process pool", "plugin_id", p.spec.ID, "plugin", p.spec.Name, "error", err)
+		return
+	}
+	if closed {
+		w.process.Kill()
+	}
+}
+
+// configure sends new settings to every process and keeps them for the
+// processes started later
+func (p *pool) configure(ctx context.Context, settings map[string]any) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.spec.Settings = settings
+	p.settingsVersion++
+	dead := p.pruneExitedLocked()
+	workers := append([]*worker(nil), p.workers...)
+	p.mu.Unlock()
+
+	killAll(dead)
+
+	for _, w := range workers {
+		if err := configureProcess(ctx, w.raw, settings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maintain drops exited processes, stops processes above MinSize that have
+// been idle for IdleTimeout, and starts processes to get back to MinSize
+func (p *pool) maintain(now time.Time) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+
+	stopped := p.pruneExitedLocked()
+
+	cutoff := now.Add(-p.config.IdleTimeout).UnixNano()
+	kept := p.workers[:0]
+	for i, w := range p.workers {
+		surplus := len(p.workers) - i + len(kept) - p.config.MinSize
+		if surplus > 0 && w.inFlight.Load() == 0 && w.lastUsed.Load() < cutoff {
+			stopped = append(stopped, w)
+			continue
+		}
+		kept = append(kept, w)
+	}
+	p.workers = kept
+
+	missing := p.config.MinSize - len(p.workers) - p.starting
+	p.starting += max(missing, 0)
+	p.mu.Unlock()
+
+	killAll(stopped)
+
+	for i := 0; i < missing; i++ {
+		p.grow()
+	}
+}
+
+// health probes every process, stopping those that do not answer. It fails
+// only when no process is left.
+func (p *pool) health(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return fmt.Errorf("plugin not loaded")
+	}
+	dead := p.pruneExitedLocked()
+	workers := append([]*worker(nil), p.workers...)
+	p.mu.Unlock()
+
+	var lastErr error
+	for _, w := range workers {
+		if _, err := w.client.GetMetadataContext(ctx); err != nil {
+			lastErr = err
+			dead = append(dead, w)
+		}
+	}
+
+	p.mu.Lock()
+	p.removeLocked(dead)
+	remaining := len(p.workers)
+	p.mu.Unlock()
+
+	killAll(dead)
+
+	if remaining == 0 {
+		if lastErr != nil {
+			return fmt.Errorf("health probe failed: %w", lastErr)
+		}
+		return fmt.Errorf("plugin process exited")
+	}
+	return nil
+}
+
+// crashed reports whether every process of the pool has exited without the
+// pool being closed or a replacement being started
+func (p *pool) crashed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.starting > 0 {
+		return false
+	}
+	for _, w := range p.workers {
+		if !w.process.Exited() {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *pool) pruneExitedLocked() []*worker {
+	var dead []*worker
+	for _, w := range p.workers {
+		if w.process.Exited() {
+			dead = append(dead, w)
+		}
+	}
+	p.removeLocked(dead)
+	return dead
+}
+
+func (p *pool) removeLocked(remove []*worker) {
+	if len(remove) == 0 {
+		return
+	}
+
+	kept := p.workers[:0]
+	for _, w := range p.workers {
+		drop := false
+		for _, r := range remove {
+			if w == r {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			kept = append(kept, w)
+		}
+	}
+	p.workers = kept
+}
+
+func (p *pool) negotiatedVersion() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.workers) == 0 {
+		return 0, fmt.Errorf("plugin process exited")
+	}
+	return p.workers[0].process.NegotiatedVersion(), nil
+}
+
+func (p *pool) stats() *PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := &PoolStats{
+		Strategy: p.config.Strategy,
+		MinSize:  p.config.MinSize,
+		MaxSize:  p.config.MaxSize,
+		Size:     len(p.workers),
+		Starting: p.starting,
+		Workers:  make([]WorkerStats, 0, len(p.workers)),
+	}
+	for _, w := range p.workers {
+		inFlight := w.inFlight.Load()
+		stats.InFlight += inFlight
+		stats.Workers = append(stats.Workers, WorkerStats{
+			PID:        w.pid(),
+			InFlight:   inFlight,
+			Calls:      w.calls.Load(),
+			StartedAt:  w.startedAt.Unix(),
+			LastUsedAt: time.Unix(0, w.lastUsed.Load()).Unix(),
+		})
+	}
+	return stats
+}
+
+// close stops every process. Processes still being started are stopped as
+// soon as they come up.
+func (p *pool) close() {
+	p.mu.Lock()
+	p.closed = true
+	workers := p.workers
+	p.workers = nil
+	p.mu.Unlock()
+
+	killAll(workers)
+}
+
+// discard stops one process, starting a replacement if the pool would
+// otherwise drop below MinSize
+func (p *pool) discard(w *worker) {
+	p.mu.Lock()
+	p.removeLocked([]*worker{w})
+	replace := !p.closed && len(p.workers)+p.starting < p.config.MinSize
+	if replace {
+		p.starting++
+	}
+	p.mu.Unlock()
+
+	w.process.Kill()
+	if replace {
+		go p.grow()
+	}
+}
+
+// closeIfIdle closes the pool unless a call is in flight or a process is
+// being started, and reports whether it did
+func (p *pool) closeIfIdle() bool {
+	p.mu.Lock()
+	if p.closed || p.starting > 0 {
+		p.mu.Unlock()
+		return false
+	}
+	for _, w := range p.workers {
+		if w.inFlight.Load() > 0 {
+			p.mu.Unlock()
+			return false
+		}
+	}
+	p.closed = true
+	workers := p.workers
+	p.workers = nil
+	p.mu.Unlock()
+
+	killAll(workers)
+	return true
+}
+
+// drainInterval is how often drain checks whether the calls in flight are done
+const drainInterval = 50 * time.Millisecond
+
+// drain closes the pool once the calls in flight have finished, and reports
+// whether they did. When ctx is done first, the pool is closed regardless.
+func (p *pool) drain(ctx context.Context) bool {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for !p.closeIfIdle() {
+		select {
+		case <-ctx.Done():
+			p.close()
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// inFlight returns the number of calls the pool's processes are busy with
+func (p *pool) inFlight() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var calls int64
+	for _, w := range p.workers {
+		calls += w.inFlight.Load()
+	}
+	return calls
+}
+
+func killAll(workers []*worker) {
+	for _, w := range workers {
+		w.process.Kill()
+	}
+}
+
+// declare replaces the method names the plugin declares
+func (p *pool) declare(methods []string) {
+	names := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		names[method] = true
+	}
+
+	p.mu.Lock()
+	p.methods = names
+	p.mu.Unlock()
+}
+
+// declares reports whether the plugin declares method, as of the spec it was
+// loaded with or the metadata its processes last advertised
+func (p *pool) declares(method string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.methods[method]
+}
+
+// pooledClient is the client handed out for a pooled plugin. Every call is
+// dispatched to one process of the pool. With killOnTimeout, a process whose
+// call runs past its deadline is stopped, since it may still be busy with it.
+// Method calls are recorded in metrics, unless it is nil.
+type pooledClient struct {
+	pool          *pool
+	killOnTimeout bool
+	metrics       *metrics.Metrics
+}
+
+func (c *pooledClient) overran(ctx context.Context, w *worker) {
+	if c.killOnTimeout && ctx.Err() == context.DeadlineExceeded {
+		c.pool.discard(w)
+	}
+}
+
+func (c *pooledClient) GetMetadataContext(ctx context.Context) (*common.MetadataResponse, error) {
+	w, err := c.pool.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer c.pool.release(w)
+
+	resp, err := w.client.GetMetadataContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.pool.declare(methodNames(resp))
+	return resp, nil
+}
+
+func (c *pooledClient) ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (resp *common.ExecuteResponse, err error) {
+	defer func(start time.Time) {
+		c.observe(ctx, method, start, err, resp != nil && !resp.Success)
+	}(time.Now())
+
+	w, err := c.pool.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer c.pool.release(w)
+
+	resp, err = w.client.ExecuteContext(ctx, method, params)
+	if err != nil {
+		c.overran(ctx, w)
+	}
+	return resp, err
+}
+
+func (c *pooledClient) ExecuteStream(ctx context.Context, method string, params *structpb.Struct, input io.Reader, output common.StreamWriter) (err error) {
+	defer func(start time.Time) {
+		c.observe(ctx, method, start, err, false)
+	}(time.Now())
+
+	w, err := c.pool.acquire()
+	if err != nil {
+		return err
+	}
+	defer c.pool.release(w)
+
+	streamer, ok := w.raw.(common.StreamingPluginInterface)
+	if !ok {
+		return common.ErrStreamingUnsupported
+	}
+	err = streamer.ExecuteStream(ctx, method, params, input, output)
+	if err != nil {
+		c.overran(ctx, w)
+	}
+	return err
+}
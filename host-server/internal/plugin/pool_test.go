@@ -0,0 +1,258 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fakeProcess stands in for a plugin process in pool tests
+type fakeProcess struct {
+	exited     atomic.Bool
+	healthy    atomic.Bool
+	configured atomic.Pointer[structpb.Struct]
+	calls      atomic.Int32 // ExecuteContext calls
+}
+
+func (f *fakeProcess) Exited() bool           { return f.exited.Load() }
+func (f *fakeProcess) Kill()                  { f.exited.Store(true) }
+func (f *fakeProcess) NegotiatedVersion() int { return common.CurrentProtocolVersion }
+
+func (f *fakeProcess) GetMetadataContext(ctx context.Context) (*common.MetadataResponse, error) {
+	if !f.healthy.Load() {
+		return nil, errors.New("not responding")
+	}
+	return &common.MetadataResponse{}, nil
+}
+
+func (f *fakeProcess) ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (*common.ExecuteResponse, error) {
+	f.calls.Add(1)
+	if method == "Hang" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &common.ExecuteResponse{Success: true}, nil
+}
+
+func (f *fakeProcess) Configure(ctx context.Context, config *structpb.Struct) error {
+	if _, ok := config.GetFields()["invalid"]; ok {
+		return errors.New("rejected")
+	}
+	f.configured.Store(config)
+	return nil
+}
+
+// fakeStarter starts fakeProcesses and remembers them in start order
+type fakeStarter struct {
+	mu       sync.Mutex
+	started  []*fakeProcess
+	settings []map[string]any // Settings of the spec each process was started with
+	starts   atomic.Int32
+}
+
+func (s *fakeStarter) start(spec PluginSpec) (pluginProcess, any, error) {
+	process := &fakeProcess{}
+	process.healthy.Store(true)
+
+	s.mu.Lock()
+	s.started = append(s.started, process)
+	s.settings = append(s.settings, spec.Settings)
+	s.mu.Unlock()
+	s.starts.Add(1)
+	return process, process, nil
+}
+
+func (s *fakeStarter) process(i int) *fakeProcess {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.started[i]
+}
+
+func newTestPool(t *testing.T, config PoolConfig) (*pool, *fakeStarter) {
+	t.Helper()
+
+	starter := &fakeStarter{}
+	config, err := config.withDefaults(defaultPoolConfig())
+	if err != nil {
+		t.Fatalf("withDefaults() error = %v", err)
+	}
+	p, err := newPool(PluginSpec{ID: 1, Name: "test"}, config, starter.start)
+	if err != nil {
+		t.Fatalf("newPool() error = %v", err)
+	}
+	t.Cleanup(p.close)
+	return p, starter
+}
+
+func waitForSize(t *testing.T, p *pool, size int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for p.stats().Size != size || p.stats().Starting != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pool size = %d, want %d", p.stats().Size, size)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPool_GrowsWhenAllBusy(t *testing.T) {
+	p, _ := newTestPool(t, PoolConfig{MinSize: 1, MaxSize: 2})
+
+	first, err := p.acquire()
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if p.stats().Starting != 0 {
+		t.Fatalf("pool grew although a process was idle")
+	}
+
+	// The only process is busy, so this call shares it and the pool grows
+	second, _ := p.acquire()
+	if second != first {
+		t.Errorf("second call was not dispatched to the running process")
+	}
+	waitForSize(t, p, 2)
+
+	third, _ := p.acquire()
+	if third == first {
+		t.Errorf("least-loaded dispatch picked the busy process")
+	}
+
+	// Already at MaxSize
+	p.acquire()
+	if p.stats().Starting != 0 {
+		t.Errorf("pool grew beyond MaxSize")
+	}
+}
+
+func TestPool_RoundRobin(t *testing.T) {
+	p, _ := newTestPool(t, PoolConfig{MinSize: 3, MaxSize: 3, Strategy: PoolStrategyRoundRobin})
+
+	seen := make(map[*worker]int)
+	for i := 0; i < 6; i++ {
+		w, err := p.acquire()
+		if err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+		p.release(w)
+		seen[w]++
+	}
+
+	if len(seen) != 3 {
+		t.Fatalf("round-robin used %d processes, want 3", len(seen))
+	}
+	for _, calls := range seen {
+		if calls != 2 {
+			t.Errorf("round-robin distribution = %v, want 2 calls each", seen)
+			break
+		}
+	}
+}
+
+func TestPool_MaintainReapsIdleAndRefills(t *testing.T) {
+	p, starter := newTestPool(t, PoolConfig{MinSize: 1, MaxSize: 3, IdleTimeout: time.Minute})
+
+	busy, _ := p.acquire()
+	p.acquire()
+	waitForSize(t, p, 2)
+	p.release(busy)
+	p.release(busy)
+
+	// Nothing has been idle for a minute yet
+	p.maintain(time.Now())
+	if size := p.stats().Size; size != 2 {
+		t.Fatalf("pool size after early maintain = %d, want 2", size)
+	}
+
+	p.maintain(time.Now().Add(2 * time.Minute))
+	if size := p.stats().Size; size != 1 {
+		t.Fatalf("pool size after idle timeout = %d, want MinSize 1", size)
+	}
+
+	// A crashed process is replaced to keep MinSize running
+	for i := 0; i < int(starter.starts.Load()); i++ {
+		starter.process(i).Kill()
+	}
+	p.maintain(time.Now())
+	if size := p.stats().Size; size != 1 || starter.starts.Load() != 3 {
+		t.Errorf("pool size = %d after %d starts, want a replacement process", size, starter.starts.Load())
+	}
+}
+
+func TestPool_HealthDropsUnresponsiveProcesses(t *testing.T) {
+	p, starter := newTestPool(t, PoolConfig{MinSize: 2, MaxSize: 2})
+
+	starter.process(0).healthy.Store(false)
+	if err := p.health(context.Background()); err != nil {
+		t.Fatalf("health() error = %v, want nil while a process is healthy", err)
+	}
+	if size := p.stats().Size; size != 1 || !starter.process(0).Exited() {
+		t.Errorf("unresponsive process was not stopped, pool size = %d", size)
+	}
+
+	starter.process(1).healthy.Store(false)
+	if err := p.health(context.Background()); err == nil {
+		t.Errorf("health() error = nil, want an error once no process is healthy")
+	}
+}
+
+func TestPool_ConfigureReachesEveryProcess(t *testing.T) {
+	p, starter := newTestPool(t, PoolConfig{MinSize: 2, MaxSize: 3})
+
+	settings := map[string]any{"csv_delimiter": ";"}
+	if err := p.configure(context.Background(), settings); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if got := starter.process(i).configured.Load().AsMap(); got["csv_delimiter"] != ";" {
+			t.Errorf("process %d settings = %v, want the new settings", i, got)
+		}
+	}
+
+	// Processes started later get the new settings with their spec
+	first, _ := p.acquire()
+	second, _ := p.acquire()
+	p.acquire()
+	waitForSize(t, p, 3)
+	p.release(first)
+	p.release(second)
+	starter.mu.Lock()
+	started := starter.settings[2]
+	starter.mu.Unlock()
+	if started["csv_delimiter"] != ";" {
+		t.Errorf("grown process started with settings %v, want the new settings", started)
+	}
+
+	if err := p.configure(context.Background(), map[string]any{"invalid": true}); !IsInvalidConfig(err) {
+		t.Errorf("configure() error = %v, want an invalid config error", err)
+	}
+}
+
+func TestPoolConfigFromMap(t *testing.T) {
+	override, err := PoolConfigFromMap(map[string]any{"max_size": float64(4), "idle_timeout": "2m"})
+	if err != nil {
+		t.Fatalf("PoolConfigFromMap() error = %v", err)
+	}
+
+	config, err := override.withDefaults(PoolConfig{MinSize: 1, MaxSize: 1, Strategy: PoolStrategyRoundRobin, IdleTimeout: time.Minute})
+	if err != nil {
+		t.Fatalf("withDefaults() error = %v", err)
+	}
+	if config.MinSize != 1 || config.MaxSize != 4 || config.Strategy != PoolStrategyRoundRobin || config.IdleTimeout != 2*time.Minute {
+		t.Errorf("merged config = %+v", config)
+	}
+
+	if _, err := (PoolConfig{Strategy: "random"}).withDefaults(defaultPoolConfig()); err == nil {
+		t.Errorf("withDefaults() accepted an unknown strategy")
+	}
+	if _, err := PoolConfigFromMap(map[string]any{"idle_timeout": "soon"}); err == nil {
+		t.Errorf("PoolConfigFromMap() accepted an invalid duration")
+	}
+}
@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"fmt"
+	"log/slog"
 	"sync"
 
 	"github.com/hashicorp/go-plugin"
@@ -9,13 +10,14 @@ import (
 )
 
 type PluginClientConfig struct {
-	PluginName      string // Plugin name (e.g., "converter", "desensitization")
-	HandshakeConfig plugin.HandshakeConfig
-	PluginMap       map[string]plugin.Plugin
+	PluginName       string // Plugin name (e.g., "converter", "desensitization")
+	Version          string // Optional: only this version of the plugin uses the config
+	HandshakeConfig  plugin.HandshakeConfig
+	VersionedPlugins map[int]plugin.PluginSet // Plugin sets keyed by protocol version
 }
 
 type Registry struct {
-	configs map[string]*PluginClientConfig // key: plugin name (e.g., "converter")
+	configs map[string]*PluginClientConfig // key: plugin name (e.g., "converter"), or name@version for a single version
 	mu      sync.RWMutex
 }
 
@@ -33,19 +35,19 @@ func (r *Registry) Register(config *PluginClientConfig) error {
 		return fmt.Errorf("plugin name cannot be empty")
 	}
 
-	r.configs[config.PluginName] = config
+	r.configs[registryKey(config.PluginName, config.Version)] = config
 	return nil
 }
 
-func createPluginMap(pluginName string) map[string]plugin.Plugin {
-	return map[string]plugin.Plugin{
-		pluginName: &common.PluginGRPCPlugin{},
-	}
-}
-
-func (r *Registry) GetPluginConfig(pluginName string) (*PluginClientConfig, error) {
+// GetPluginConfig returns the config registered for this version of the
+// plugin, or else the one for all its versions, since versions installed
+// side by side usually share it
+func (r *Registry) GetPluginConfig(pluginName, version string) (*PluginClientConfig, error) {
 	r.mu.RLock()
-	config, ok := r.configs[pluginName]
+	config, ok := r.configs[registryKey(pluginName, version)]
+	if !ok {
+		config, ok = r.configs[pluginName]
+	}
 	r.mu.RUnlock()
 
 	if !ok {
@@ -64,12 +66,19 @@ func (r *Registry) autoRegisterPlugin(pluginName string) (*PluginClientConfig, e
 	}
 
 	config := &PluginClientConfig{
-		PluginName:      pluginName,
-		HandshakeConfig: common.Handshake,
-		PluginMap:       createPluginMap(pluginName),
+		PluginName:       pluginName,
+		HandshakeConfig:  common.Handshake,
+		VersionedPlugins: common.VersionedPluginSets(pluginName),
 	}
 
 	r.configs[pluginName] = config
-	fmt.Printf("✨ Auto-registered plugin: %s\n", pluginName)
+	slog.Debug("Auto-registered plugin", "plugin", pluginName)
 	return config, nil
 }
+
+func registryKey(pluginName, version string) string {
+	if version == "" {
+		return pluginName
+	}
+	return pluginName + "@" + version
+}
@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// SandboxPolicy describes how the plugins of one namespace are isolated from
+// the host. A sandboxed plugin runs with a scrubbed environment, in a private
+// mount namespace that only contains its binary and the directory its socket
+// is created in, and, unless Network is set, without network access.
+type SandboxPolicy struct {
+	Enabled bool     // Run the namespace's plugins in a sandbox
+	Network bool     // Share the host's network namespace
+	Env     []string // Host environment variables passed to the plugin
+	Seccomp bool     // Only allow the syscalls a plugin needs, see seccompAllowlist
+}
+
+// sandboxWildcard is the namespace key whose policy applies to every
+// namespace without a policy of its own
+const sandboxWildcard = "*"
+
+// sandboxPolicyFor returns the sandbox policy of a plugin's namespace
+func (m *Manager) sandboxPolicyFor(spec PluginSpec) SandboxPolicy {
+	if policy, ok := m.sandboxPolicies[spec.Namespace]; ok {
+		return policy
+	}
+	return m.sandboxPolicies[sandboxWildcard]
+}
+
+// sandboxEnv carries the sandboxSpec from the host to the sandbox helper. Its
+// presence makes the host binary act as the helper, see SandboxInit.
+const sandboxEnv = "POLYGLOT_PLUGIN_SANDBOX"
+
+// sandboxSpec tells the sandbox helper what to expose to the plugin
+type sandboxSpec struct {
+	Binary    string `json:"binary"`     // Absolute path of the plugin binary on the host
+	Checksum  string `json:"checksum"`   // Expected hex SHA-256 of the binary, if any
+	Root      string `json:"root"`       // Empty host directory the sandbox root is mounted on
+	SocketDir string `json:"socket_dir"` // Host directory shared with the plugin for its socket
+	Seccomp   bool   `json:"seccomp"`
+}
+
+// sandbox runs the processes of one plugin isolated from the host. It is
+// created when the plugin's first process starts and kept across restarts.
+type sandbox interface {
+	// command returns the command that starts one sandboxed plugin process
+	command() (*exec.Cmd, error)
+	close()
+}
+
+// allowedEnv returns the variables of environ whose names are listed in allowed
+func allowedEnv(environ []string, allowed []string) []string {
+	var env []string
+	for _, variable := range environ {
+		name, _, _ := strings.Cut(variable, "=")
+		for _, allowedName := range allowed {
+			if name == allowedName {
+				env = append(env, variable)
+				break
+			}
+		}
+	}
+	return env
+}
+
+// helperEnv returns the environment the sandbox helper passes to the plugin:
+// its own, without the sandbox spec, and with temporary files (and so the
+// plugin's socket) going to the shared socket directory
+func helperEnv(environ []string, socketDir string) []string {
+	env := make([]string, 0, len(environ)+1)
+	for _, variable := range environ {
+		name, _, _ := strings.Cut(variable, "=")
+		if name == sandboxEnv || name == "TMPDIR" {
+			continue
+		}
+		env = append(env, variable)
+	}
+	return append(env, "TMPDIR="+socketDir)
+}
+
+// sandboxKey identifies the sandbox of one binary of a plugin. While a
+// plugin is upgraded, its old and new binary run side by side, each in its
+// own sandbox.
+type sandboxKey struct {
+	pluginID uint
+	binary   string
+}
+
+// sandboxFor returns the sandbox of a plugin, creating it on the first start
+func (m *Manager) sandboxFor(spec PluginSpec, policy SandboxPolicy) (sandbox, error) {
+	m.confinementMu.Lock()
+	defer m.confinementMu.Unlock()
+
+	key := sandboxKey{pluginID: spec.ID, binary: spec.Path}
+	if s, exists := m.sandboxes[key]; exists {
+		return s, nil
+	}
+	s, err := newSandbox(spec, policy)
+	if err != nil {
+		return nil, err
+	}
+	m.sandboxes[key] = s
+	return s, nil
+}
+
+// closeSandbox removes the sandboxes of a plugin whose processes have been stopped
+func (m *Manager) closeSandbox(pluginID uint) {
+	m.confinementMu.Lock()
+	var closed []sandbox
+	for key, s := range m.sandboxes {
+		if key.pluginID == pluginID {
+			closed = append(closed, s)
+			delete(m.sandboxes, key)
+		}
+	}
+	m.confinementMu.Unlock()
+
+	for _, s := range closed {
+		s.close()
+	}
+}
+
+// closeSandboxOf removes the sandbox of one binary of a plugin, once the
+// processes running that binary have been stopped
+func (m *Manager) closeSandboxOf(spec PluginSpec) {
+	key := sandboxKey{pluginID: spec.ID, binary: spec.Path}
+
+	m.confinementMu.Lock()
+	s, exists := m.sandboxes[key]
+	delete(m.sandboxes, key)
+	m.confinementMu.Unlock()
+
+	if exists {
+		s.close()
+	}
+}
@@ -0,0 +1,290 @@
+//go:build linux
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxBinary is where the plugin binary appears inside the sandbox
+const sandboxBinary = "/plugin"
+
+// hostBinary starts the host binary itself. Unlike its path, it stays
+// executable after the switch to the plugin user.
+const hostBinary = "/proc/self/exe"
+
+// nobodyID is the host user and group sandboxed plugins run as when the host
+// runs as root
+const nobodyID = 65534
+
+// namespaceSandbox starts the processes of a plugin in new user, mount, IPC
+// and UTS namespaces and, unless the policy allows network access, a new
+// network namespace that only has an unconfigured loopback device
+type namespaceSandbox struct {
+	spec   sandboxSpec
+	policy SandboxPolicy
+	dir    string // Host directory holding Root and SocketDir
+	uid    int    // Host user the plugin runs as
+	gid    int
+}
+
+func newSandbox(spec PluginSpec, policy SandboxPolicy) (sandbox, error) {
+	binary, err := filepath.Abs(spec.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve plugin binary: %w", err)
+	}
+
+	// The plugin is root inside its user namespace only; outside it is the
+	// host user, or nobody when the host runs as root
+	uid, gid := os.Geteuid(), os.Getegid()
+	if uid == 0 {
+		uid, gid = nobodyID, nobodyID
+	}
+
+	dir, err := os.MkdirTemp("", fmt.Sprintf("plugin-sandbox-%d-", spec.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	s := &namespaceSandbox{
+		spec: sandboxSpec{
+			Binary:    binary,
+			Checksum:  spec.Checksum,
+			Root:      filepath.Join(dir, "root"),
+			SocketDir: filepath.Join(dir, "sock"),
+			Seccomp:   policy.Seccomp,
+		},
+		policy: policy,
+		dir:    dir,
+		uid:    uid,
+		gid:    gid,
+	}
+
+	for _, path := range []string{s.spec.Root, s.spec.SocketDir} {
+		if err := os.Mkdir(path, 0700); err != nil {
+			s.close()
+			return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+		}
+	}
+	if os.Geteuid() == 0 {
+		for _, path := range []string{dir, s.spec.Root, s.spec.SocketDir} {
+			if err := os.Chown(path, uid, gid); err != nil {
+				s.close()
+				return nil, fmt.Errorf("failed to hand sandbox directory to the plugin user: %w", err)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+func (s *namespaceSandbox) command() (*exec.Cmd, error) {
+	// The helper checks again once the binary is mounted; this check only
+	// reports a mismatch before anything is started
+	if s.spec.Checksum != "" {
+		sum, err := fileChecksum(s.spec.Binary)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrIntegrityCheckFailed, err)
+		}
+		if !strings.EqualFold(sum, s.spec.Checksum) {
+			return nil, fmt.Errorf("%w: checksum mismatch (expected %s, got %s)",
+				ErrIntegrityCheckFailed, strings.ToLower(s.spec.Checksum), sum)
+		}
+	}
+
+	cmd, err := sandboxCommand(s.spec, s.policy)
+	if err != nil {
+		return nil, err
+	}
+
+	cloneflags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS)
+	if !s.policy.Network {
+		cloneflags |= syscall.CLONE_NEWNET
+	}
+	// Switching to root inside the namespace makes the process the mapped
+	// host user. Only a root host may (and has to) drop its supplementary
+	// groups; others may not call setgroups once mapped.
+	privileged := os.Geteuid() == 0
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:                 cloneflags,
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: s.uid, Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: s.gid, Size: 1}},
+		GidMappingsEnableSetgroups: privileged,
+		Credential:                 &syscall.Credential{Uid: 0, Gid: 0, NoSetGroups: !privileged},
+		Pdeathsig:                  syscall.SIGKILL,
+	}
+	return cmd, nil
+}
+
+// sandboxCommand returns a command that starts the host binary as the sandbox
+// helper, which isolates itself and then executes the plugin. Only the
+// allowed host environment variables are passed on.
+func sandboxCommand(spec sandboxSpec, policy SandboxPolicy) (*exec.Cmd, error) {
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(hostBinary)
+	cmd.Args = []string{"plugin-sandbox"}
+	cmd.Env = append(allowedEnv(os.Environ(), policy.Env), sandboxEnv+"="+string(encoded))
+	return cmd, nil
+}
+
+// close removes the sandbox directory. The mounts inside it only exist in the
+// namespaces of the plugin's processes and are gone once those have exited.
+func (s *namespaceSandbox) close() {
+	if err := os.RemoveAll(s.dir); err != nil {
+		slog.Warn("Failed to remove sandbox directory", "dir", s.dir, "error", err)
+	}
+}
+
+// SandboxInit runs the sandbox helper when the host binary was started as
+// one, and otherwise returns false. The helper builds the plugin's root
+// filesystem, installs the seccomp filter and executes the plugin; it never
+// returns. Call it first thing in main.
+func SandboxInit() bool {
+	encoded, ok := os.LookupEnv(sandboxEnv)
+	if !ok {
+		return false
+	}
+
+	var spec sandboxSpec
+	err := json.Unmarshal([]byte(encoded), &spec)
+	if err == nil {
+		err = execSandboxed(spec)
+	}
+	fmt.Fprintf(os.Stderr, "plugin sandbox: %v\n", err)
+	os.Exit(1)
+	return true
+}
+
+// execSandboxed replaces the helper with the plugin. It only returns on error.
+func execSandboxed(spec sandboxSpec) error {
+	// The seccomp filter and no_new_privs apply to the thread that sets them,
+	// which must therefore be the one that executes the plugin
+	runtime.LockOSThread()
+
+	if err := enterSandboxRoot(spec); err != nil {
+		return err
+	}
+
+	if spec.Checksum != "" {
+		sum, err := fileChecksum(sandboxBinary)
+		if err != nil {
+			return fmt.Errorf("failed to verify plugin binary: %w", err)
+		}
+		if !strings.EqualFold(sum, spec.Checksum) {
+			return fmt.Errorf("%w: checksum mismatch (expected %s, got %s)",
+				ErrIntegrityCheckFailed, strings.ToLower(spec.Checksum), sum)
+		}
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set no_new_privs: %w", err)
+	}
+	if spec.Seccomp {
+		if err := installSeccompFilter(); err != nil {
+			return err
+		}
+	}
+
+	env := helperEnv(os.Environ(), spec.SocketDir)
+	err := unix.Exec(sandboxBinary, []string{filepath.Base(spec.Binary)}, env)
+	// The sandbox has no shared libraries, so a dynamically linked plugin fails with ENOENT
+	return fmt.Errorf("failed to execute plugin (sandboxed plugins must be statically linked): %w", err)
+}
+
+// enterSandboxRoot makes a tmpfs holding only the plugin binary and the socket
+// directory the root of the helper's mount namespace. The socket directory
+// keeps its host path, so the socket address the plugin reports is valid on
+// the host as well.
+func enterSandboxRoot(spec sandboxSpec) error {
+	// Keep the mounts below from propagating back to the host
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+
+	root := spec.Root
+	if err := unix.Mount("tmpfs", root, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "size=1m,mode=0755"); err != nil {
+		return fmt.Errorf("failed to mount sandbox root: %w", err)
+	}
+
+	binary := filepath.Join(root, sandboxBinary)
+	if err := os.WriteFile(binary, nil, 0755); err != nil {
+		return fmt.Errorf("failed to create plugin binary mount point: %w", err)
+	}
+	if err := bindMount(spec.Binary, binary, unix.MS_RDONLY); err != nil {
+		return fmt.Errorf("failed to mount plugin binary: %w", err)
+	}
+
+	socketDir := filepath.Join(root, spec.SocketDir)
+	if err := os.MkdirAll(socketDir, 0755); err != nil {
+		return fmt.Errorf("failed to create socket directory mount point: %w", err)
+	}
+	if err := bindMount(spec.SocketDir, socketDir, unix.MS_NOEXEC); err != nil {
+		return fmt.Errorf("failed to mount socket directory: %w", err)
+	}
+
+	oldRoot := filepath.Join(root, ".old")
+	if err := os.Mkdir(oldRoot, 0700); err != nil {
+		return fmt.Errorf("failed to create old root mount point: %w", err)
+	}
+	if err := unix.PivotRoot(root, oldRoot); err != nil {
+		return fmt.Errorf("failed to pivot into sandbox root: %w", err)
+	}
+	if err := unix.Chdir("/"); err != nil {
+		return err
+	}
+	if err := unix.Unmount("/.old", unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("failed to detach host filesystem: %w", err)
+	}
+	if err := os.Remove("/.old"); err != nil {
+		return err
+	}
+
+	if err := unix.Mount("", "/", "", unix.MS_REMOUNT|unix.MS_BIND|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("failed to make sandbox root read-only: %w", err)
+	}
+	return nil
+}
+
+// bindMount bind-mounts source onto target with nosuid, nodev and the extra
+// flags. Flags of the source mount that a user namespace may not clear are
+// kept, or the remount would be refused.
+func bindMount(source, target string, flags uintptr) error {
+	if err := unix.Mount(source, target, "", unix.MS_BIND, ""); err != nil {
+		return err
+	}
+
+	var stat unix.Statfs_t
+	if err := unix.Statfs(target, &stat); err != nil {
+		return err
+	}
+	locked := []struct {
+		st int64
+		ms uintptr
+	}{
+		{unix.ST_RDONLY, unix.MS_RDONLY},
+		{unix.ST_NOEXEC, unix.MS_NOEXEC},
+		{unix.ST_NOATIME, unix.MS_NOATIME},
+		{unix.ST_NODIRATIME, unix.MS_NODIRATIME},
+		{unix.ST_RELATIME, unix.MS_RELATIME},
+	}
+	for _, flag := range locked {
+		if int64(stat.Flags)&flag.st != 0 {
+			flags |= flag.ms
+		}
+	}
+
+	return unix.Mount("", target, "", unix.MS_REMOUNT|unix.MS_BIND|unix.MS_NOSUID|unix.MS_NODEV|flags, "")
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package plugin
+
+import "fmt"
+
+// newSandbox refuses to start sandboxed plugins outside Linux rather than run
+// them unisolated
+func newSandbox(spec PluginSpec, policy SandboxPolicy) (sandbox, error) {
+	return nil, fmt.Errorf("plugin %s (ID: %d) must run sandboxed, which is only supported on Linux", spec.Name, spec.ID)
+}
+
+// SandboxInit returns false; the sandbox helper only exists on Linux
+func SandboxInit() bool {
+	return false
+}
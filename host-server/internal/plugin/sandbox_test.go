@@ -0,0 +1,46 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestManager_SandboxPolicyFor(t *testing.T) {
+	m := NewManager(nil, &ManagerConfig{
+		Sandbox: map[string]SandboxPolicy{
+			"*":        {Enabled: true, Seccomp: true},
+			"official": {Enabled: false},
+		},
+	})
+
+	if policy := m.sandboxPolicyFor(PluginSpec{Namespace: "official"}); policy.Enabled {
+		t.Errorf("official plugins are sandboxed, want their own policy to apply")
+	}
+	if policy := m.sandboxPolicyFor(PluginSpec{Namespace: "community"}); !policy.Enabled || !policy.Seccomp {
+		t.Errorf("sandboxPolicyFor(community) = %+v, want the wildcard policy", policy)
+	}
+
+	unconfigured := NewManager(nil, &ManagerConfig{})
+	if policy := unconfigured.sandboxPolicyFor(PluginSpec{Namespace: "community"}); policy.Enabled {
+		t.Errorf("plugins are sandboxed without any sandbox config")
+	}
+}
+
+func TestSandboxEnv(t *testing.T) {
+	host := []string{"PATH=/usr/bin", "TZ=UTC", "PLUGIN_HOST_DATABASE_PASSWORD=secret", "LANG=C"}
+
+	got := allowedEnv(host, []string{"TZ", "LANG"})
+	if want := []string{"TZ=UTC", "LANG=C"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("allowedEnv() = %v, want %v", got, want)
+	}
+	if got := allowedEnv(host, nil); len(got) != 0 {
+		t.Errorf("allowedEnv() = %v, want nothing passed without an allowlist", got)
+	}
+
+	helper := []string{"TZ=UTC", sandboxEnv + "={}", "TMPDIR=/tmp", "PLUGIN_MIN_PORT=10000"}
+	got = helperEnv(helper, "/run/sandbox/sock")
+	want := []string{"TZ=UTC", "PLUGIN_MIN_PORT=10000", "TMPDIR=/run/sandbox/sock"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("helperEnv() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build linux && amd64
+
+package plugin
+
+import "golang.org/x/sys/unix"
+
+const auditArch = unix.AUDIT_ARCH_X86_64
+
+// archSyscalls are the legacy syscalls only amd64 has, which older Go
+// runtimes and libraries still make
+var archSyscalls = []uintptr{
+	unix.SYS_ARCH_PRCTL, unix.SYS_OPEN, unix.SYS_STAT, unix.SYS_LSTAT,
+	unix.SYS_READLINK, unix.SYS_ACCESS, unix.SYS_PIPE, unix.SYS_DUP2,
+	unix.SYS_POLL, unix.SYS_SELECT, unix.SYS_EPOLL_WAIT, unix.SYS_EPOLL_CREATE,
+	unix.SYS_MKDIR, unix.SYS_UNLINK, unix.SYS_RENAME, unix.SYS_RENAMEAT, unix.SYS_ACCEPT,
+}
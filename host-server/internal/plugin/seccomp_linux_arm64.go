@@ -0,0 +1,11 @@
+//go:build linux && arm64
+
+package plugin
+
+import "golang.org/x/sys/unix"
+
+const auditArch = unix.AUDIT_ARCH_AARCH64
+
+// archSyscalls holds renameat, which arm64 keeps but newer architectures
+// only have as renameat2
+var archSyscalls = []uintptr{unix.SYS_RENAMEAT}
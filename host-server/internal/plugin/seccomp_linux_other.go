@@ -0,0 +1,9 @@
+//go:build linux && !amd64 && !arm64
+
+package plugin
+
+// auditArch is unset on architectures without a seccomp allowlist, so
+// sandboxes with seccomp enabled fail to start there
+const auditArch = 0
+
+var archSyscalls []uintptr
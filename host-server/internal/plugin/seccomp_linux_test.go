@@ -0,0 +1,59 @@
+//go:build linux && (amd64 || arm64)
+
+package plugin
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// runSeccompFilter evaluates the subset of classic BPF seccompFilter emits
+func runSeccompFilter(t *testing.T, filter []unix.SockFilter, arch uint32, nr uintptr) uint32 {
+	t.Helper()
+
+	var acc uint32
+	for pc := 0; pc < len(filter); pc++ {
+		ins := filter[pc]
+		switch ins.Code {
+		case unix.BPF_LD | unix.BPF_W | unix.BPF_ABS:
+			acc = map[uint32]uint32{0: uint32(nr), 4: arch}[ins.K]
+		case unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K:
+			if acc == ins.K {
+				pc += int(ins.Jt)
+			} else {
+				pc += int(ins.Jf)
+			}
+		case unix.BPF_RET | unix.BPF_K:
+			return ins.K
+		default:
+			t.Fatalf("unexpected instruction %#x at %d", ins.Code, pc)
+		}
+	}
+	t.Fatalf("filter fell off its end")
+	return 0
+}
+
+func TestSeccompFilter(t *testing.T) {
+	filter, err := seccompFilter()
+	if err != nil {
+		t.Fatalf("seccompFilter() error = %v", err)
+	}
+
+	for _, nr := range append(append([]uintptr{}, seccompAllowlist...), archSyscalls...) {
+		if action := runSeccompFilter(t, filter, auditArch, nr); action != unix.SECCOMP_RET_ALLOW {
+			t.Errorf("syscall %d = %#x, want allowed", nr, action)
+		}
+	}
+
+	denied := []uintptr{unix.SYS_MOUNT, unix.SYS_PTRACE, unix.SYS_UNSHARE, unix.SYS_SETNS, unix.SYS_PIVOT_ROOT}
+	for _, nr := range denied {
+		if action := runSeccompFilter(t, filter, auditArch, nr); action != unix.SECCOMP_RET_ERRNO|uint32(unix.EPERM) {
+			t.Errorf("syscall %d = %#x, want EPERM", nr, action)
+		}
+	}
+
+	if action := runSeccompFilter(t, filter, unix.AUDIT_ARCH_I386, unix.SYS_READ); action != unix.SECCOMP_RET_KILL_PROCESS {
+		t.Errorf("foreign architecture = %#x, want the process killed", action)
+	}
+}
@@ -0,0 +1,158 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/repository"
+	"golang.org/x/mod/semver"
+)
+
+// Channel aliases every plugin has. Unless pinned to a version, latest is
+// the highest active version and stable the highest active version that is
+// not a prerelease.
+const (
+	ChannelLatest = "latest"
+	ChannelStable = "stable"
+)
+
+// ErrVersionNotFound is returned when no active version of a plugin matches a version selector
+var ErrVersionNotFound = errors.New("no active plugin version matches")
+
+// IsVersionNotFound reports whether err was caused by a selector no active version matches
+func IsVersionNotFound(err error) bool {
+	return errors.Is(err, ErrVersionNotFound)
+}
+
+// ErrInvalidAlias is returned for alias names that cannot be told apart from versions
+var ErrInvalidAlias = errors.New("invalid plugin version alias")
+
+// IsInvalidAlias reports whether err was caused by an invalid alias name
+func IsInvalidAlias(err error) bool {
+	return errors.Is(err, ErrInvalidAlias)
+}
+
+// ValidateAlias checks that alias can name a version: anything that reads
+// as a version or version range would be ambiguous in a selector.
+func ValidateAlias(alias string) error {
+	if alias == "" || strings.ContainsAny(alias, " /|") {
+		return fmt.Errorf("%w: '%s' must be a single word", ErrInvalidAlias, alias)
+	}
+	if _, err := ParseVersionRange(alias); err == nil {
+		return fmt.Errorf("%w: '%s' is a version range", ErrInvalidAlias, alias)
+	}
+	return nil
+}
+
+// SelectVersion picks the plugin version a selector names among the active
+// versions of one plugin. The selector is an alias (pinned in aliases, which
+// maps alias to version), one of the latest and stable channels, an exact
+// version or a version range, which selects the highest matching version.
+// An empty selector means latest.
+func SelectVersion(active []*models.Plugin, selector string, aliases map[string]string) (*models.Plugin, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		selector = ChannelLatest
+	}
+
+	if version, pinned := aliases[selector]; pinned {
+		for _, candidate := range active {
+			if sameVersion(candidate.Version, version) {
+				return candidate, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: alias '%s' is pinned to version %s, which is not active", ErrVersionNotFound, selector, version)
+	}
+
+	var matches func(*models.Plugin) bool
+	switch selector {
+	case ChannelLatest:
+		matches = func(*models.Plugin) bool { return true }
+	case ChannelStable:
+		matches = func(p *models.Plugin) bool {
+			version := canonicalVersion(p.Version)
+			return version != "" && semver.Prerelease(version) == ""
+		}
+	default:
+		for _, candidate := range active {
+			if sameVersion(candidate.Version, selector) {
+				return candidate, nil
+			}
+		}
+		versions, err := ParseVersionRange(selector)
+		if err != nil {
+			return nil, fmt.Errorf("%w '%s': it is neither an alias, a version nor a version range", ErrVersionNotFound, selector)
+		}
+		matches = func(p *models.Plugin) bool { return versions.Contains(p.Version) }
+	}
+
+	var selected *models.Plugin
+	for _, candidate := range active {
+		if matches(candidate) && (selected == nil || compareVersions(candidate.Version, selected.Version) > 0) {
+			selected = candidate
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("%w '%s'", ErrVersionNotFound, selector)
+	}
+	return selected, nil
+}
+
+// VersionSelector resolves version selectors against the active versions
+// and pinned aliases of plugins. Calls by name through the API and calls
+// plugins make through host services go through it alike.
+type VersionSelector struct {
+	plugins repository.PluginRepository
+	aliases repository.PluginAliasRepository
+}
+
+// NewVersionSelector creates a VersionSelector. Without aliases, only the
+// unpinned latest and stable channels, versions and ranges select versions.
+func NewVersionSelector(plugins repository.PluginRepository, aliases repository.PluginAliasRepository) *VersionSelector {
+	return &VersionSelector{plugins: plugins, aliases: aliases}
+}
+
+// Select picks the active version of a plugin selector names, see SelectVersion
+func (v *VersionSelector) Select(ctx context.Context, namespace, name, selector string) (*models.Plugin, error) {
+	active, err := v.plugins.WithContext(ctx).FindAll(map[string]any{
+		"namespace": namespace,
+		"name":      name,
+		"status":    models.PluginStatusActive,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find active plugin versions: %w", err)
+	}
+
+	pinned, err := v.PinnedAliases(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return SelectVersion(active, selector, pinned)
+}
+
+// PinnedAliases maps the pinned aliases of a plugin to their versions
+func (v *VersionSelector) PinnedAliases(ctx context.Context, namespace, name string) (map[string]string, error) {
+	if v.aliases == nil {
+		return nil, nil
+	}
+
+	aliases, err := v.aliases.WithContext(ctx).FindAll(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make(map[string]string, len(aliases))
+	for _, alias := range aliases {
+		pinned[alias.Alias] = alias.Version
+	}
+	return pinned, nil
+}
+
+// sameVersion reports whether two versions are equal, with or without a "v" prefix
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
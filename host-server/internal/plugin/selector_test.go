@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+)
+
+func TestSelectVersion(t *testing.T) {
+	active := []*models.Plugin{
+		{ID: 1, Version: "1.0.0"},
+		{ID: 2, Version: "1.4.2"},
+		{ID: 3, Version: "2.0.0"},
+		{ID: 4, Version: "2.1.0-beta.1"},
+	}
+
+	tests := []struct {
+		selector string
+		aliases  map[string]string
+		wantID   uint
+	}{
+		{"", nil, 4},
+		{"latest", nil, 4},
+		{"stable", nil, 3},
+		{"1.4.2", nil, 2},
+		{"v1.0.0", nil, 1},
+		{"^1.0.0", nil, 2},
+		{">=1.0.0 <2.0.0 || 2.0.0", nil, 3},
+		{"lts", map[string]string{"lts": "1.0.0"}, 1},
+		{"stable", map[string]string{"stable": "1.4.2"}, 2},
+		{"", map[string]string{"latest": "2.0.0"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := SelectVersion(active, tt.selector, tt.aliases)
+			if err != nil {
+				t.Fatalf("SelectVersion() error = %v", err)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("SelectVersion() = %s, want plugin %d", got.Version, tt.wantID)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		selector string
+		aliases  map[string]string
+	}{
+		{"^3.0.0", nil},
+		{"1.2.0", nil},
+		{"lts", nil},
+		{"lts", map[string]string{"lts": "0.9.0"}},
+	} {
+		if _, err := SelectVersion(active, tt.selector, tt.aliases); !IsVersionNotFound(err) {
+			t.Errorf("SelectVersion(%q) error = %v, want ErrVersionNotFound", tt.selector, err)
+		}
+	}
+
+	if _, err := SelectVersion(nil, "latest", nil); !IsVersionNotFound(err) {
+		t.Errorf("SelectVersion() without active versions error = %v, want ErrVersionNotFound", err)
+	}
+}
+
+func TestValidateAlias(t *testing.T) {
+	for _, alias := range []string{"lts", "stable", "canary-2024"} {
+		if err := ValidateAlias(alias); err != nil {
+			t.Errorf("ValidateAlias(%q) error = %v", alias, err)
+		}
+	}
+	for _, alias := range []string{"", "1", "1.x", "^2.0.0", "a/b", "a b"} {
+		if err := ValidateAlias(alias); !IsInvalidAlias(err) {
+			t.Errorf("ValidateAlias(%q) error = %v, want ErrInvalidAlias", alias, err)
+		}
+	}
+}
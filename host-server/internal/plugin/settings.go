@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// hostConfigKeys are the keys of a plugin's config that configure the host
+// rather than the plugin. They are never sent to the plugin.
+var hostConfigKeys = []string{"pool", "lifecycle", "limits"}
+
+// IsHostConfigKey reports whether key of a plugin's config is read by the host
+func IsHostConfigKey(key string) bool {
+	for _, hostKey := range hostConfigKeys {
+		if key == hostKey {
+			return true
+		}
+	}
+	return false
+}
+
+// PluginSettings returns the part of a plugin's config that is delivered to
+// the plugin through Configure
+func PluginSettings(config models.JSONMap) map[string]any {
+	settings := make(map[string]any, len(config))
+	for key, value := range config {
+		if !IsHostConfigKey(key) {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// ErrInvalidConfig is returned when plugin settings do not match the config
+// schema the plugin declares, or the plugin rejects them
+var ErrInvalidConfig = errors.New("invalid plugin config")
+
+// IsInvalidConfig reports whether err was caused by settings the plugin does not accept
+func IsInvalidConfig(err error) bool {
+	return errors.Is(err, ErrInvalidConfig)
+}
+
+// ConfigSchemaFromResponse converts the config schema a plugin advertises
+// into ParamSchemas. It returns nil for plugins that take no settings.
+func ConfigSchemaFromResponse(resp *common.MetadataResponse) map[string]ParamSchema {
+	if len(resp.GetConfigSchema()) == 0 {
+		return nil
+	}
+
+	schema := make(map[string]ParamSchema, len(resp.GetConfigSchema()))
+	for name, descriptor := range resp.GetConfigSchema() {
+		schema[name] = paramFromDescriptor(descriptor)
+	}
+	return schema
+}
+
+// ConfigSchemaFromMetadata extracts the config schema stored in a plugin
+// record's metadata. It returns nil when the plugin declares none, in which
+// case its settings are not validated.
+func ConfigSchemaFromMetadata(metadata map[string]any) map[string]ParamSchema {
+	raw, ok := metadata["config_schema"]
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+
+	var schema map[string]ParamSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil
+	}
+	return schema
+}
+
+// ValidateSettings checks settings against a plugin's config schema. Unlike
+// call parameters, omitted settings are not filled with defaults: the plugin
+// falls back to its own. A plugin without a schema accepts anything.
+func ValidateSettings(schema map[string]ParamSchema, settings map[string]any) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	var problems []string
+
+	for key, value := range settings {
+		param, ok := schema[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown setting '%s'", key))
+			continue
+		}
+		if value != nil && !matchesType(param.Type, value) {
+			problems = append(problems, fmt.Sprintf("setting '%s' must be of type %s", key, param.Type))
+		}
+	}
+
+	for name, param := range schema {
+		if value, ok := settings[name]; param.Required && (!ok || value == nil) {
+			problems = append(problems, fmt.Sprintf("missing required setting '%s'", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// configureProcess sends settings to one plugin process. Plugins that predate
+// Configure or do not implement it keep running without settings.
+func configureProcess(ctx context.Context, raw any, settings map[string]any) error {
+	configurable, ok := raw.(common.ConfigurablePluginInterface)
+	if !ok {
+		return nil
+	}
+
+	config, err := structpb.NewStruct(settings)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	err = configurable.Configure(ctx, config)
+	if errors.Is(err, common.ErrConfigureUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+// ConfigurePlugin sends new settings to every running process of a loaded
+// plugin and keeps them for the processes started later. If a process
+// rejects them, processes that already took them may keep them; callers
+// restore the previous settings the same way. Host config keys are
+// rejected: the pool, lifecycle and limits of a running plugin only change
+// when it is loaded again or upgraded.
+func (m *Manager) ConfigurePlugin(ctx context.Context, pluginID uint, settings map[string]any) error {
+	for key := range settings {
+		if IsHostConfigKey(key) {
+			return fmt.Errorf("%w: '%s' configures the host, not the plugin", ErrInvalidConfig, key)
+		}
+	}
+
+	m.mu.Lock()
+	spec, wanted := m.specs[pluginID]
+	if !wanted {
+		m.mu.Unlock()
+		return fmt.Errorf("plugin not loaded")
+	}
+	spec.Settings = settings
+	m.specs[pluginID] = spec
+	p, running := m.pools[pluginID]
+	m.mu.Unlock()
+
+	if !running {
+		// Idle plugins receive the settings when they start
+		return nil
+	}
+	return p.configure(ctx, settings)
+}
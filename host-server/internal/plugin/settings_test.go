@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+func TestPluginSettings(t *testing.T) {
+	settings := PluginSettings(models.JSONMap{
+		"csv_delimiter": ";",
+		"pool":          map[string]any{"max_size": 2},
+		"lifecycle":     map[string]any{"on_demand": true},
+		"limits":        map[string]any{"memory": "64MiB"},
+	})
+
+	if len(settings) != 1 || settings["csv_delimiter"] != ";" {
+		t.Errorf("PluginSettings() = %v, want only the plugin's own settings", settings)
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	schema := map[string]ParamSchema{
+		"csv_delimiter": {Type: "string"},
+		"html_styled":   {Type: "bool"},
+		"api_key":       {Type: "string", Required: true},
+	}
+
+	tests := []struct {
+		name     string
+		settings map[string]any
+		wantErr  bool
+	}{
+		{"valid", map[string]any{"api_key": "k", "html_styled": false}, false},
+		{"missing required", map[string]any{"csv_delimiter": ";"}, true},
+		{"wrong type", map[string]any{"api_key": "k", "html_styled": "no"}, true},
+		{"unknown setting", map[string]any{"api_key": "k", "default_format": "csv"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSettings(schema, tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !IsInvalidConfig(err) {
+				t.Errorf("ValidateSettings() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+
+	// Plugins that declare no schema accept anything
+	if err := ValidateSettings(nil, map[string]any{"anything": 1.0}); err != nil {
+		t.Errorf("ValidateSettings() without schema error = %v", err)
+	}
+}
+
+func TestConfigSchemaRoundTrip(t *testing.T) {
+	resp := &common.MetadataResponse{
+		ConfigSchema: map[string]*common.ParamDescriptor{
+			"csv_delimiter": {Type: "string", Description: "Delimiter", Default: common.MustValue(",")},
+		},
+	}
+
+	// The schema is stored in JSON metadata and read back for validation
+	metadata := models.JSONMap{"config_schema": ConfigSchemaFromResponse(resp)}
+	schema := ConfigSchemaFromMetadata(metadata)
+	if param, ok := schema["csv_delimiter"]; !ok || param.Type != "string" || param.Default != "," {
+		t.Errorf("ConfigSchemaFromMetadata() = %v", schema)
+	}
+
+	if schema := ConfigSchemaFromResponse(&common.MetadataResponse{}); schema != nil {
+		t.Errorf("ConfigSchemaFromResponse() = %v, want nil for a plugin without settings", schema)
+	}
+}
+
+func TestManager_ConfigurePluginRejectsHostKeys(t *testing.T) {
+	m, _ := newTestManager(LifecycleConfig{})
+	if err := m.LoadPlugin(PluginSpec{ID: 1, Name: "converter"}); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	err := m.ConfigurePlugin(context.Background(), 1, map[string]any{"limits": map[string]any{"memory": "64MiB"}})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("ConfigurePlugin() error = %v, want ErrInvalidConfig", err)
+	}
+	if settings := m.specs[1].Settings; settings != nil {
+		t.Errorf("settings = %v, want the rejected ones not kept", settings)
+	}
+}
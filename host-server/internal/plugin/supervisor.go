@@ -0,0 +1,318 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/plugins/repository"
+)
+
+// SupervisorConfig controls health probing and crash recovery
+type SupervisorConfig struct {
+	HealthInterval time.Duration // How often every loaded plugin is probed
+	HealthTimeout  time.Duration // Deadline for a single GetMetadata probe
+	InitialBackoff time.Duration // Delay before the first restart attempt
+	MaxBackoff     time.Duration // Upper bound for the exponential restart delay
+	MaxRestarts    int           // Restarts allowed within RestartWindow before giving up
+	RestartWindow  time.Duration // Sliding window the restart budget is counted over
+}
+
+func defaultSupervisorConfig() SupervisorConfig {
+	return SupervisorConfig{
+		HealthInterval: 10 * time.Second,
+		HealthTimeout:  3 * time.Second,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		MaxRestarts:    5,
+		RestartWindow:  10 * time.Minute,
+	}
+}
+
+// exitCheckInterval is how often the supervisor looks for plugins whose
+// processes have exited. Unlike health probes this costs no RPC, so crashes
+// are noticed well before the next health round.
+const exitCheckInterval = 500 * time.Millisecond
+
+// Supervisor watches loaded plugins, restarts crashed or unresponsive
+// processes with exponential backoff, and marks a plugin as errored once
+// its restart budget is spent. A plugin that failed because it ran into its
+// resource limits is not restarted but marked as limit_exceeded. Each round
+// also trims idle pool processes and stops plugins that have gone without
+// calls for their idle shutdown time.
+type Supervisor struct {
+	manager    *Manager
+	repo       repository.PluginRepository
+	config     SupervisorConfig
+	mu         sync.Mutex
+	restarts   map[uint][]time.Time // restart timestamps inside the window
+	recovering map[uint]bool        // plugins with a recovery in progress
+	stop       chan struct{}
+	wg         sync.WaitGroup
+}
+
+func NewSupervisor(manager *Manager, repo repository.PluginRepository, config *SupervisorConfig) *Supervisor {
+	cfg := defaultSupervisorConfig()
+	if config != nil {
+		if config.HealthInterval > 0 {
+			cfg.HealthInterval = config.HealthInterval
+		}
+		if config.HealthTimeout > 0 {
+			cfg.HealthTimeout = config.HealthTimeout
+		}
+		if config.InitialBackoff > 0 {
+			cfg.InitialBackoff = config.InitialBackoff
+		}
+		if config.MaxBackoff > 0 {
+			cfg.MaxBackoff = config.MaxBackoff
+		}
+		if config.MaxRestarts > 0 {
+			cfg.MaxRestarts = config.MaxRestarts
+		}
+		if config.RestartWindow > 0 {
+			cfg.RestartWindow = config.RestartWindow
+		}
+	}
+
+	return &Supervisor{
+		manager:    manager,
+		repo:       repo,
+		config:     cfg,
+		restarts:   make(map[uint][]time.Time),
+		recovering: make(map[uint]bool),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start begins periodic health supervision in the background
+func (s *Supervisor) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop halts supervision and waits for in-flight recoveries to finish
+func (s *Supervisor) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Supervisor) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.HealthInterval)
+	defer ticker.Stop()
+	exits := time.NewTicker(exitCheckInterval)
+	defer exits.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-exits.C:
+			s.checkExited()
+		case <-ticker.C:
+			s.checkAll()
+		}
+	}
+}
+
+// checkExited starts recovering plugins whose processes have all exited,
+// without waiting for the next health round to notice
+func (s *Supervisor) checkExited() {
+	for _, id := range s.manager.CrashedPluginIDs() {
+		s.startRecovery(id, "plugin process exited")
+	}
+}
+
+func (s *Supervisor) checkAll() {
+	s.manager.MaintainPools()
+	s.stopIdlePlugins(time.Now())
+
+	for _, id := range s.manager.LoadedPluginIDs() {
+		s.mu.Lock()
+		busy := s.recovering[id]
+		s.mu.Unlock()
+		if busy {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.HealthTimeout)
+		err := s.manager.CheckHealth(ctx, id)
+		cancel()
+		if err == nil {
+			continue
+		}
+
+		s.startRecovery(id, err.Error())
+	}
+}
+
+// startRecovery recovers a plugin in the background unless a recovery of it
+// is already in progress
+func (s *Supervisor) startRecovery(id uint, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.recovering[id] {
+		return
+	}
+	s.recovering[id] = true
+
+	s.wg.Add(1)
+	go s.recover(id, reason)
+}
+
+// stopIdlePlugins stops running plugins whose last call, as recorded in
+// LastUsedAt, lies further back than their idle shutdown time. A plugin is
+// never considered idle for longer than its processes have been running.
+func (s *Supervisor) stopIdlePlugins(now time.Time) {
+	for _, id := range s.manager.LoadedPluginIDs() {
+		startedAt, timeout, ok := s.manager.idleShutdown(id)
+		if !ok || now.Sub(startedAt) < timeout {
+			continue
+		}
+
+		s.mu.Lock()
+		busy := s.recovering[id]
+		s.mu.Unlock()
+		if busy {
+			continue
+		}
+
+		record, err := s.repo.FindByID(id)
+		if err != nil {
+			continue
+		}
+		if record.LastUsedAt != nil {
+			if lastUsed := time.Unix(*record.LastUsedAt, 0); now.Sub(lastUsed) < timeout {
+				continue
+			}
+		}
+
+		if s.manager.SuspendPlugin(id) {
+			slog.Info("Stopped plugin without calls", "plugin_id", id, "plugin", record.Name, "idle_shutdown", timeout)
+		}
+	}
+}
+
+// recover restarts a failed plugin, backing off exponentially between
+// attempts until it comes back healthy or the restart budget runs out.
+func (s *Supervisor) recover(id uint, reason string) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.recovering, id)
+		s.mu.Unlock()
+	}()
+
+	slog.Warn("Plugin is unhealthy", "plugin_id", id, "reason", reason)
+
+	backoff := s.config.InitialBackoff
+	for {
+		if violation := s.manager.LimitViolation(id); violation != "" {
+			s.stopForViolation(id, violation)
+			return
+		}
+
+		if !s.takeRestartBudget(id) {
+			s.giveUp(id, reason)
+			return
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(backoff):
+		}
+
+		err := s.manager.RestartPlugin(id)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), s.config.HealthTimeout)
+			err = s.manager.CheckHealth(ctx, id)
+			cancel()
+		}
+		if err == nil {
+			slog.Info("Restarted plugin", "plugin_id", id)
+			return
+		}
+
+		if !s.stillLoaded(id) {
+			// Deactivated or uninstalled while we were recovering it
+			return
+		}
+
+		reason = err.Error()
+		slog.Error("Failed to restart plugin", "plugin_id", id, "reason", reason)
+
+		backoff *= 2
+		if backoff > s.config.MaxBackoff {
+			backoff = s.config.MaxBackoff
+		}
+	}
+}
+
+// takeRestartBudget records a restart attempt, returning false when the
+// plugin has already used all restarts allowed within the window.
+func (s *Supervisor) takeRestartBudget(id uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-s.config.RestartWindow)
+	recent := s.restarts[id][:0]
+	for _, at := range s.restarts[id] {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+
+	if len(recent) >= s.config.MaxRestarts {
+		s.restarts[id] = recent
+		return false
+	}
+
+	s.restarts[id] = append(recent, time.Now())
+	return true
+}
+
+func (s *Supervisor) giveUp(id uint, reason string) {
+	statusReason := fmt.Sprintf("restart budget exhausted (%d restarts in %s): %s",
+		s.config.MaxRestarts, s.config.RestartWindow, reason)
+
+	slog.Error("Giving up on plugin", "plugin_id", id, "reason", statusReason)
+
+	s.manager.UnloadPlugin(id)
+	if err := s.repo.UpdateStatusWithReason(id, models.PluginStatusError, statusReason); err != nil {
+		slog.Error("Failed to record error status of plugin", "plugin_id", id, "error", err)
+	}
+
+	s.mu.Lock()
+	delete(s.restarts, id)
+	s.mu.Unlock()
+}
+
+// stopForViolation unloads a plugin that ran into its resource limits.
+// Restarting it would most likely hit the same limits again.
+func (s *Supervisor) stopForViolation(id uint, violation string) {
+	slog.Warn("Stopping plugin, it exceeded its resource limits", "plugin_id", id, "violation", violation)
+
+	s.manager.UnloadPlugin(id)
+	if err := s.repo.UpdateStatusWithReason(id, models.PluginStatusLimitExceeded, violation); err != nil {
+		slog.Error("Failed to record limit_exceeded status of plugin", "plugin_id", id, "error", err)
+	}
+
+	s.mu.Lock()
+	delete(s.restarts, id)
+	s.mu.Unlock()
+}
+
+func (s *Supervisor) stillLoaded(id uint) bool {
+	for _, loaded := range s.manager.LoadedPluginIDs() {
+		if loaded == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSupervisor_Defaults(t *testing.T) {
+	s := NewSupervisor(nil, nil, &SupervisorConfig{MaxRestarts: 2})
+
+	if s.config.MaxRestarts != 2 {
+		t.Errorf("Expected max restarts 2, got %d", s.config.MaxRestarts)
+	}
+	if s.config.HealthInterval != 10*time.Second {
+		t.Errorf("Expected default health interval 10s, got %v", s.config.HealthInterval)
+	}
+}
+
+func TestSupervisor_RestartBudget(t *testing.T) {
+	s := NewSupervisor(nil, nil, &SupervisorConfig{
+		MaxRestarts:   2,
+		RestartWindow: time.Minute,
+	})
+
+	if !s.takeRestartBudget(1) || !s.takeRestartBudget(1) {
+		t.Fatal("Expected first two restarts to be allowed")
+	}
+	if s.takeRestartBudget(1) {
+		t.Error("Expected third restart within the window to be refused")
+	}
+	if !s.takeRestartBudget(2) {
+		t.Error("Expected restart budget to be tracked per plugin")
+	}
+}
+
+func TestSupervisor_RestartBudgetWindowExpires(t *testing.T) {
+	s := NewSupervisor(nil, nil, &SupervisorConfig{
+		MaxRestarts:   1,
+		RestartWindow: time.Minute,
+	})
+
+	s.restarts[1] = []time.Time{time.Now().Add(-2 * time.Minute)}
+
+	if !s.takeRestartBudget(1) {
+		t.Error("Expected restarts outside the window to be forgotten")
+	}
+}
+
+func TestSupervisor_RestartsExitedPluginBeforeHealthRound(t *testing.T) {
+	m, starter := newTestManager(LifecycleConfig{})
+	if err := m.LoadPlugin(PluginSpec{ID: 1, Name: "converter"}); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	s := NewSupervisor(m, nil, &SupervisorConfig{
+		HealthInterval: time.Hour,
+		InitialBackoff: time.Millisecond,
+	})
+	s.Start()
+	defer s.Stop()
+
+	starter.process(0).Kill()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for starter.starts.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("exited plugin was not restarted before the next health round")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+// UpgradeCheck runs against the processes of a new plugin version before any
+// call is routed to them. metadata is what the new version advertises and
+// negotiated the protocol version agreed on with it.
+type UpgradeCheck func(ctx context.Context, metadata *common.MetadataResponse, negotiated int) error
+
+// UpgradeResult describes how the processes of the previous version went away
+type UpgradeResult struct {
+	InFlight int64 // Calls in flight on the previous version when calls were switched over
+	Drained  bool  // Whether those calls finished before its processes were stopped
+}
+
+// UpgradePlugin replaces the processes of a loaded plugin with those of a new
+// version without dropping calls. spec describes the new version under the
+// plugin's ID. Its pool is started next to the running one and has to pass a
+// health probe and check before calls are switched over to it in one step.
+// Calls in flight finish on the previous version, whose processes are stopped
+// once they are done or the plugin's call timeout has passed. If the new
+// version fails to start or a check fails, its processes are stopped and the
+// previous version keeps serving. The new version runs in the confinement of
+// the previous one, so its resource limits other than the call timeout must
+// not change.
+func (m *Manager) UpgradePlugin(ctx context.Context, spec PluginSpec, check UpgradeCheck) (*UpgradeResult, error) {
+	m.mu.RLock()
+	previous, wanted := m.specs[spec.ID]
+	previousLimits := m.limits[spec.ID]
+	drainTimeout := previousLimits.CallTimeout
+	m.mu.RUnlock()
+
+	if !wanted {
+		return nil, fmt.Errorf("plugin not loaded")
+	}
+	if drainTimeout <= 0 {
+		drainTimeout = m.callTimeout
+	}
+
+	lifecycle, err := m.LifecycleFor(spec)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := limitsFor(spec)
+	if err != nil {
+		return nil, err
+	}
+	if !limits.sameConfinement(previousLimits) {
+		return nil, fmt.Errorf("%w: resource limits cannot change in an upgrade", ErrInvalidConfig)
+	}
+
+	p, err := m.newPoolFor(spec)
+	if err != nil {
+		m.discardUpgrade(previous, spec)
+		return nil, err
+	}
+	reject := func(err error) (*UpgradeResult, error) {
+		p.close()
+		m.discardUpgrade(previous, spec)
+		return nil, err
+	}
+
+	if err := p.health(ctx); err != nil {
+		return reject(err)
+	}
+	client := &pooledClient{pool: p}
+	metadata, err := client.GetMetadataContext(ctx)
+	if err != nil {
+		return reject(fmt.Errorf("failed to get plugin metadata: %w", err))
+	}
+	negotiated, err := p.negotiatedVersion()
+	if err != nil {
+		return reject(err)
+	}
+	if err := check(ctx, metadata, negotiated); err != nil {
+		return reject(err)
+	}
+	spec.Methods = methodNames(metadata)
+
+	m.mu.Lock()
+	if _, wanted := m.specs[spec.ID]; !wanted {
+		m.mu.Unlock()
+		return reject(fmt.Errorf("plugin was unloaded during the upgrade"))
+	}
+	old := m.pools[spec.ID]
+	m.pools[spec.ID] = p
+	m.specs[spec.ID] = spec
+	m.lifecycles[spec.ID] = lifecycle
+	m.limits[spec.ID] = limits
+	delete(m.idle, spec.ID)
+	m.mu.Unlock()
+
+	result := &UpgradeResult{Drained: true}
+	if old != nil {
+		result.InFlight = old.inFlight()
+
+		drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		result.Drained = old.drain(drainCtx)
+		cancel()
+	}
+	if previous.Path != spec.Path {
+		m.closeSandboxOf(previous)
+	}
+
+	if !result.Drained {
+		slog.Warn("Stopped previous plugin version with calls still in flight",
+			"plugin_id", spec.ID, "plugin", spec.Name, "version", previous.Version, "drain_timeout", drainTimeout.Round(time.Second))
+	}
+	return result, nil
+}
+
+// discardUpgrade releases the sandbox of a new version that will not run.
+// The cgroup is shared with the previous version and stays.
+func (m *Manager) discardUpgrade(previous, upgrade PluginSpec) {
+	if previous.Path != upgrade.Path {
+		m.closeSandboxOf(upgrade)
+	}
+}
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+func acceptUpgrade(context.Context, *common.MetadataResponse, int) error { return nil }
+
+func TestManager_UpgradePluginDrainsPreviousVersion(t *testing.T) {
+	m, starter := newTestManager(LifecycleConfig{})
+	if err := m.LoadPlugin(PluginSpec{ID: 1, Name: "test", Version: "1.0.0"}); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	previous, _ := m.runningPool(1)
+	w, _ := previous.acquire()
+
+	type upgraded struct {
+		result *UpgradeResult
+		err    error
+	}
+	done := make(chan upgraded)
+	go func() {
+		result, err := m.UpgradePlugin(context.Background(), PluginSpec{ID: 1, Name: "test", Version: "2.0.0"}, acceptUpgrade)
+		done <- upgraded{result, err}
+	}()
+
+	// Calls are switched over while the previous version is still busy
+	deadline := time.Now().Add(time.Second)
+	for {
+		current, _ := m.runningPool(1)
+		if current != previous {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("calls were not switched over to the new version")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if starter.process(0).Exited() {
+		t.Fatalf("previous version was stopped with a call in flight")
+	}
+	previous.release(w)
+
+	got := <-done
+	if got.err != nil {
+		t.Fatalf("UpgradePlugin() error = %v", got.err)
+	}
+	if got.result.InFlight != 1 || !got.result.Drained {
+		t.Errorf("UpgradePlugin() = %+v, want one drained call", got.result)
+	}
+	if !starter.process(0).Exited() || starter.process(1).Exited() {
+		t.Errorf("previous exited = %v, new exited = %v; want only the previous version stopped",
+			starter.process(0).Exited(), starter.process(1).Exited())
+	}
+	if version := m.specs[1].Version; version != "2.0.0" {
+		t.Errorf("loaded spec version = %s, want 2.0.0", version)
+	}
+}
+
+func TestManager_UpgradePluginRollsBack(t *testing.T) {
+	m, starter := newTestManager(LifecycleConfig{})
+	if err := m.LoadPlugin(PluginSpec{ID: 1, Name: "test", Version: "1.0.0"}); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+	previous, _ := m.runningPool(1)
+
+	rejected := errors.New("rejected")
+	_, err := m.UpgradePlugin(context.Background(), PluginSpec{ID: 1, Name: "test", Version: "2.0.0"},
+		func(context.Context, *common.MetadataResponse, int) error { return rejected })
+	if !errors.Is(err, rejected) {
+		t.Fatalf("UpgradePlugin() error = %v, want the check's error", err)
+	}
+
+	if current, _ := m.runningPool(1); current != previous || starter.process(0).Exited() {
+		t.Errorf("previous version does not keep serving after a failed upgrade")
+	}
+	if !starter.process(1).Exited() {
+		t.Errorf("new version was not stopped after a failed upgrade")
+	}
+	if version := m.specs[1].Version; version != "1.0.0" {
+		t.Errorf("loaded spec version = %s, want 1.0.0", version)
+	}
+
+	if _, err := m.UpgradePlugin(context.Background(), PluginSpec{ID: 2, Name: "test"}, acceptUpgrade); err == nil {
+		t.Errorf("UpgradePlugin() of a plugin that is not loaded succeeded")
+	}
+}
+
+func TestManager_UpgradePluginKeepsLimits(t *testing.T) {
+	m, starter := newTestManager(LifecycleConfig{})
+	if err := m.LoadPlugin(PluginSpec{ID: 1, Name: "test", Version: "1.0.0"}); err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	limited := PluginSpec{ID: 1, Name: "test", Version: "2.0.0", Limits: map[string]any{"processes": 64}}
+	if _, err := m.UpgradePlugin(context.Background(), limited, acceptUpgrade); !IsInvalidConfig(err) {
+		t.Fatalf("UpgradePlugin() with new limits error = %v, want ErrInvalidConfig", err)
+	}
+	if starts := starter.starts.Load(); starts != 1 || m.specs[1].Version != "1.0.0" {
+		t.Errorf("upgrade with new limits started %d processes, loaded version %s", starts, m.specs[1].Version)
+	}
+
+	timed := PluginSpec{ID: 1, Name: "test", Version: "2.0.0", Limits: map[string]any{"call_timeout": "5s"}}
+	if _, err := m.UpgradePlugin(context.Background(), timed, acceptUpgrade); err != nil {
+		t.Errorf("UpgradePlugin() with a new call timeout error = %v", err)
+	}
+}
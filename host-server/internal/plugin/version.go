@@ -0,0 +1,249 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// VersionRange is a set of semantic versions written as in npm or Cargo:
+// comparators separated by spaces must all match (">=1.0.0 <2.0.0") and
+// alternatives are separated by "||". Besides =, >, >=, < and <=, a
+// comparator may be a caret ("^1.2.0", same major version), a tilde
+// ("~1.2.0", same minor version) or a partial version with wildcards ("1.x",
+// "1.2.*"). An empty range or "*" matches any version.
+type VersionRange struct {
+	raw  string
+	sets [][]versionConstraint
+}
+
+// versionConstraint compares against a canonical "vMAJOR.MINOR.PATCH" version
+type versionConstraint struct {
+	op      string
+	version string
+}
+
+// ParseVersionRange parses a version range
+func ParseVersionRange(s string) (*VersionRange, error) {
+	r := &VersionRange{raw: strings.TrimSpace(s)}
+
+	for _, alternative := range strings.Split(s, "||") {
+		tokens := strings.Fields(alternative)
+		set := []versionConstraint{}
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+			// Allow a space between the operator and the version, as in ">= 1.0.0"
+			if strings.Trim(token, "<>=^~") == "" && i+1 < len(tokens) {
+				i++
+				token += tokens[i]
+			}
+
+			constraints, err := parseVersionConstraint(token)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version range '%s': %w", s, err)
+			}
+			set = append(set, constraints...)
+		}
+		r.sets = append(r.sets, set)
+	}
+
+	return r, nil
+}
+
+// Contains reports whether version, with or without a "v" prefix, is in the
+// range. Versions that are not semantic versions are in no range.
+func (r *VersionRange) Contains(version string) bool {
+	version = canonicalVersion(version)
+	if version == "" {
+		return false
+	}
+
+	for _, set := range r.sets {
+		if matchesAll(set, version) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *VersionRange) String() string {
+	if r.raw == "" {
+		return "*"
+	}
+	return r.raw
+}
+
+func matchesAll(constraints []versionConstraint, version string) bool {
+	for _, c := range constraints {
+		cmp := semver.Compare(version, c.version)
+		var ok bool
+		switch c.op {
+		case "=":
+			ok = cmp == 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalVersion returns version in the "vMAJOR.MINOR.PATCH" form
+// golang.org/x/mod/semver compares, or "" if it is not a semantic version
+func canonicalVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if !semver.IsValid(version) {
+		return ""
+	}
+	return version
+}
+
+// partialVersion is a version whose minor and patch may be left out or be wildcards
+type partialVersion struct {
+	major, minor, patch int
+	given               int    // number of components given: 0 for "*", up to 3
+	suffix              string // prerelease and build, only with all three components
+}
+
+func (v partialVersion) String() string {
+	return fmt.Sprintf("v%d.%d.%d%s", v.major, v.minor, v.patch, v.suffix)
+}
+
+func (v partialVersion) nextMajor() string {
+	return fmt.Sprintf("v%d.0.0", v.major+1)
+}
+
+func (v partialVersion) nextMinor() string {
+	return fmt.Sprintf("v%d.%d.0", v.major, v.minor+1)
+}
+
+func (v partialVersion) nextPatch() string {
+	return fmt.Sprintf("v%d.%d.%d", v.major, v.minor, v.patch+1)
+}
+
+func parsePartialVersion(s string) (partialVersion, error) {
+	var v partialVersion
+
+	core := strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core, v.suffix = core[:i], core[i:]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("'%s' is not a version", s)
+	}
+
+	numbers := []*int{&v.major, &v.minor, &v.patch}
+	for i, part := range parts {
+		if part == "*" || part == "x" || part == "X" {
+			// Everything after a wildcard is a wildcard too
+			for _, rest := range parts[i+1:] {
+				if rest != "*" && rest != "x" && rest != "X" {
+					return v, fmt.Errorf("'%s' has a version number after a wildcard", s)
+				}
+			}
+			break
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("'%s' is not a version", s)
+		}
+		*numbers[i] = n
+		v.given++
+	}
+
+	if v.suffix != "" && v.given < 3 {
+		return v, fmt.Errorf("'%s' has a prerelease without a patch version", s)
+	}
+	if v.given == 3 && !semver.IsValid(v.String()) {
+		return v, fmt.Errorf("'%s' is not a version", s)
+	}
+	return v, nil
+}
+
+// parseVersionConstraint expands one comparator into plain comparisons
+func parseVersionConstraint(token string) ([]versionConstraint, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(token, prefix) {
+			op = prefix
+			break
+		}
+	}
+
+	v, err := parsePartialVersion(token[len(op):])
+	if err != nil {
+		return nil, err
+	}
+
+	// below is the exclusive upper bound of a partial version, e.g. v2.0.0 for "1.x"
+	below := func() string {
+		switch v.given {
+		case 1:
+			return v.nextMajor()
+		case 2:
+			return v.nextMinor()
+		}
+		return v.nextPatch()
+	}
+
+	switch {
+	case v.given == 0:
+		if op == ">" || op == "<" {
+			return nil, fmt.Errorf("'%s' matches no version", token)
+		}
+		return nil, nil
+	case op == "" || op == "=":
+		if v.given == 3 {
+			return []versionConstraint{{"=", v.String()}}, nil
+		}
+		return []versionConstraint{{">=", v.String()}, {"<", below()}}, nil
+	case op == ">":
+		if v.given == 3 {
+			return []versionConstraint{{">", v.String()}}, nil
+		}
+		return []versionConstraint{{">=", below()}}, nil
+	case op == ">=" || op == "<":
+		return []versionConstraint{{op, v.String()}}, nil
+	case op == "<=":
+		if v.given == 3 {
+			return []versionConstraint{{"<=", v.String()}}, nil
+		}
+		return []versionConstraint{{"<", below()}}, nil
+	case op == "~":
+		if v.given == 1 {
+			return []versionConstraint{{">=", v.String()}, {"<", v.nextMajor()}}, nil
+		}
+		return []versionConstraint{{">=", v.String()}, {"<", v.nextMinor()}}, nil
+	default: // "^": the leftmost non-zero component may not change
+		upper := v.nextMajor()
+		if v.major == 0 && v.given >= 2 {
+			upper = v.nextMinor()
+			if v.minor == 0 && v.given == 3 {
+				upper = v.nextPatch()
+			}
+		}
+		return []versionConstraint{{">=", v.String()}, {"<", upper}}, nil
+	}
+}
+
+// compareVersions compares two plugin versions like semver.Compare, with or
+// without a "v" prefix. Versions that are not semantic versions sort first.
+func compareVersions(a, b string) int {
+	return semver.Compare(canonicalVersion(a), canonicalVersion(b))
+}
@@ -0,0 +1,54 @@
+package plugin
+
+import "testing"
+
+func TestVersionRange_Contains(t *testing.T) {
+	tests := []struct {
+		versions string
+		in       []string
+		out      []string
+	}{
+		{"", []string{"0.0.1", "v3.2.1"}, []string{"latest"}},
+		{"*", []string{"1.0.0"}, nil},
+		{"1.2.3", []string{"1.2.3", "v1.2.3"}, []string{"1.2.4", "1.2.3-beta"}},
+		{">=1.0.0 <2.0.0", []string{"1.0.0", "1.9.9"}, []string{"0.9.9", "2.0.0"}},
+		{">= 1.0.0 < 2", []string{"1.5.0"}, []string{"2.0.0"}},
+		{"^1.2.0", []string{"1.2.0", "1.9.0"}, []string{"1.1.9", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"~1.2.0", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"~1", []string{"1.9.0"}, []string{"2.0.0"}},
+		{"1.x", []string{"1.0.0", "1.99.0"}, []string{"2.0.0", "0.9.0"}},
+		{"1.2.*", []string{"1.2.7"}, []string{"1.3.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"^1.0.0 || ^3.0.0", []string{"1.1.0", "3.0.1"}, []string{"2.0.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.versions, func(t *testing.T) {
+			r, err := ParseVersionRange(tt.versions)
+			if err != nil {
+				t.Fatalf("ParseVersionRange() error = %v", err)
+			}
+			for _, v := range tt.in {
+				if !r.Contains(v) {
+					t.Errorf("%q should contain %s", tt.versions, v)
+				}
+			}
+			for _, v := range tt.out {
+				if r.Contains(v) {
+					t.Errorf("%q should not contain %s", tt.versions, v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseVersionRange_Invalid(t *testing.T) {
+	for _, versions := range []string{"latest", "1.2.3.4", "1.x.3", ">=1.0-beta", "<*", "^a.b"} {
+		if _, err := ParseVersionRange(versions); err == nil {
+			t.Errorf("ParseVersionRange(%q) should fail", versions)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/app/modules/policies/repository"
+	"github.com/wylu1037/polyglot-plugin-host-server/internal/auth"
+)
+
+// DeniedError is returned for calls a policy decision denies
+type DeniedError struct {
+	Decision *Decision
+}
+
+func (e *DeniedError) Error() string {
+	return e.Decision.Reason
+}
+
+// IsDenied reports whether err was caused by a policy denying a call
+func IsDenied(err error) bool {
+	var denied *DeniedError
+	return errors.As(err, &denied)
+}
+
+// Enforcer decides on plugin calls with the policies stored in the database.
+// Policies are read for every decision, so changes apply to the next call.
+type Enforcer struct {
+	repo repository.PolicyRepository
+}
+
+func NewEnforcer(repo repository.PolicyRepository) *Enforcer {
+	return &Enforcer{repo: repo}
+}
+
+// Policies are the policies that may apply to the calls of one plugin
+type Policies struct {
+	namespace string
+	plugin    string
+	policies  []*models.PluginPolicy
+}
+
+// Load reads the policies that may apply to calls of a plugin, for deciding
+// on many calls with one query
+func (e *Enforcer) Load(ctx context.Context, namespace, plugin string) (*Policies, error) {
+	policies, err := e.repo.WithContext(ctx).FindForPlugin(namespace, plugin)
+	if err != nil {
+		return nil, err
+	}
+	return &Policies{namespace: namespace, plugin: plugin, policies: policies}, nil
+}
+
+// Decide decides on the principal in ctx calling method with params
+func (p *Policies) Decide(ctx context.Context, method string, params map[string]any) *Decision {
+	return Evaluate(p.policies, Call{
+		Principal: PrincipalName(ctx),
+		Namespace: p.namespace,
+		Plugin:    p.plugin,
+		Method:    method,
+		Params:    params,
+	})
+}
+
+// Authorize returns a DeniedError unless the principal in ctx may call method
+// of record with params
+func (e *Enforcer) Authorize(ctx context.Context, record *models.Plugin, method string, params map[string]any) error {
+	policies, err := e.Load(ctx, record.Namespace, record.Name)
+	if err != nil {
+		return err
+	}
+	if decision := policies.Decide(ctx, method, params); !decision.Allowed {
+		return &DeniedError{Decision: decision}
+	}
+	return nil
+}
+
+// Explain decides on call without calling anything, for testing policies
+func (e *Enforcer) Explain(ctx context.Context, call Call) (*Decision, error) {
+	policies, err := e.repo.WithContext(ctx).FindForPlugin(call.Namespace, call.Plugin)
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate(policies, call), nil
+}
+
+// PrincipalName is the name policies match the principal in ctx by. Calls
+// outside the API carry no principal and only match "*".
+func PrincipalName(ctx context.Context) string {
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		return principal.Name
+	}
+	return ""
+}
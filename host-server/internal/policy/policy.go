@@ -0,0 +1,272 @@
+// Package policy decides whether a principal may call a plugin method with
+// the given parameters. Policies allow or deny {principal, namespace, plugin,
+// method} combinations, optionally only while constraints on the call's
+// parameters hold:
+//
+//   - A deny policy that applies to a call denies it.
+//   - Otherwise an allow policy that applies to the call allows it.
+//   - A method that some allow policy targets is restricted: calls that no
+//     allow policy applies to are denied.
+//   - Calls of methods that no allow policy targets are allowed.
+//
+// So deny policies block calls, and allow policies turn a method into an
+// allow list of principals and parameters.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+)
+
+// Constraint operators
+const (
+	OpEq    = "eq"
+	OpNe    = "ne"
+	OpLt    = "lt"
+	OpLte   = "lte"
+	OpGt    = "gt"
+	OpGte   = "gte"
+	OpIn    = "in"
+	OpNotIn = "not_in"
+)
+
+// opSymbols is how constraints are written in explanations
+var opSymbols = map[string]string{
+	OpEq: "==", OpNe: "!=", OpLt: "<", OpLte: "<=", OpGt: ">", OpGte: ">=", OpIn: "in", OpNotIn: "not in",
+}
+
+// Call is a plugin method call to decide on. Params are the parameters the
+// plugin would receive, with the defaults of its method descriptor.
+type Call struct {
+	Principal string
+	Namespace string
+	Plugin    string
+	Method    string
+	Params    map[string]any
+}
+
+func (c Call) target() string {
+	return fmt.Sprintf("%s/%s.%s", c.Namespace, c.Plugin, c.Method)
+}
+
+// Decision is whether a call is allowed, and why
+type Decision struct {
+	Allowed     bool
+	Reason      string
+	Policy      *models.PluginPolicy // The policy that decided, if any
+	Evaluations []Evaluation         // Every policy considered, in creation order
+}
+
+// Evaluation explains whether one policy applies to a call
+type Evaluation struct {
+	Policy  *models.PluginPolicy
+	Applies bool
+	Reason  string
+}
+
+// Validate checks a policy before it is stored
+func Validate(policy *models.PluginPolicy) error {
+	if policy.Effect != models.PolicyEffectAllow && policy.Effect != models.PolicyEffectDeny {
+		return fmt.Errorf("invalid effect: %s (must be 'allow' or 'deny')", policy.Effect)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"principal", policy.Principal},
+		{"namespace", policy.Namespace},
+		{"plugin", policy.Plugin},
+		{"method", policy.Method},
+	} {
+		if field.value == "" {
+			return fmt.Errorf("%s is required (use '*' to match any)", field.name)
+		}
+	}
+
+	for i, constraint := range policy.Constraints {
+		if err := validateConstraint(constraint); err != nil {
+			return fmt.Errorf("constraints[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateConstraint(c models.PolicyConstraint) error {
+	if c.Param == "" {
+		return errors.New("param is required")
+	}
+	switch c.Op {
+	case OpEq, OpNe:
+		if c.Value == nil {
+			return errors.New("value is required")
+		}
+	case OpLt, OpLte, OpGt, OpGte:
+		if _, ok := number(c.Value); !ok {
+			return fmt.Errorf("%s needs a number, got %v", c.Op, c.Value)
+		}
+	case OpIn, OpNotIn:
+		if _, ok := c.Value.([]any); !ok {
+			return fmt.Errorf("%s needs a list, got %v", c.Op, c.Value)
+		}
+	default:
+		return fmt.Errorf("unknown op: %s (must be one of eq, ne, lt, lte, gt, gte, in, not_in)", c.Op)
+	}
+	return nil
+}
+
+// Evaluate decides on call with policies, which are considered in order
+func Evaluate(policies []*models.PluginPolicy, call Call) *Decision {
+	decision := &Decision{Evaluations: make([]Evaluation, 0, len(policies))}
+	var allow, deny *models.PluginPolicy
+	restricted := false
+
+	for _, policy := range policies {
+		evaluation := Evaluation{Policy: policy}
+		switch {
+		case !matches(policy.Namespace, call.Namespace) || !matches(policy.Plugin, call.Plugin) || !matches(policy.Method, call.Method):
+			evaluation.Reason = fmt.Sprintf("targets %s/%s.%s", policy.Namespace, policy.Plugin, policy.Method)
+		case !matches(policy.Principal, call.Principal):
+			evaluation.Reason = fmt.Sprintf("applies to principal %s", policy.Principal)
+		default:
+			failed := failedConstraints(policy.Constraints, call.Params)
+			if len(failed) > 0 {
+				evaluation.Reason = strings.Join(failed, "; ")
+			} else {
+				evaluation.Applies = true
+				evaluation.Reason = "applies"
+			}
+		}
+
+		if policy.Effect == models.PolicyEffectAllow && matches(policy.Namespace, call.Namespace) &&
+			matches(policy.Plugin, call.Plugin) && matches(policy.Method, call.Method) {
+			restricted = true
+		}
+		if evaluation.Applies {
+			switch {
+			case policy.Effect == models.PolicyEffectDeny && deny == nil:
+				deny = policy
+			case policy.Effect == models.PolicyEffectAllow && allow == nil:
+				allow = policy
+			}
+		}
+		decision.Evaluations = append(decision.Evaluations, evaluation)
+	}
+
+	switch {
+	case deny != nil:
+		decision.Policy = deny
+		decision.Reason = fmt.Sprintf("policy '%s' denies %s calling %s", deny.Name, call.Principal, call.target())
+	case allow != nil:
+		decision.Allowed = true
+		decision.Policy = allow
+		decision.Reason = fmt.Sprintf("policy '%s' allows %s calling %s", allow.Name, call.Principal, call.target())
+	case restricted:
+		decision.Reason = fmt.Sprintf("%s is restricted by allow policies and none of them applies to %s with these parameters", call.target(), call.Principal)
+	default:
+		decision.Allowed = true
+		decision.Reason = fmt.Sprintf("no policy restricts %s", call.target())
+	}
+	return decision
+}
+
+func matches(pattern, value string) bool {
+	return pattern == models.PolicyWildcard || pattern == value
+}
+
+// failedConstraints explains each constraint that does not hold for params
+func failedConstraints(constraints models.PolicyConstraints, params map[string]any) []string {
+	var failed []string
+	for _, c := range constraints {
+		value, ok := lookup(params, c.Param)
+		if !ok {
+			failed = append(failed, fmt.Sprintf("%s is not set", c.Param))
+			continue
+		}
+		if !holds(c, value) {
+			failed = append(failed, fmt.Sprintf("%s %s %v does not hold (got %v)", c.Param, opSymbols[c.Op], c.Value, value))
+		}
+	}
+	return failed
+}
+
+// lookup reads a dotted path from params
+func lookup(params map[string]any, path string) (any, bool) {
+	var current any = params
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func holds(c models.PolicyConstraint, value any) bool {
+	switch c.Op {
+	case OpEq:
+		return equal(value, c.Value)
+	case OpNe:
+		return !equal(value, c.Value)
+	case OpIn, OpNotIn:
+		list, _ := c.Value.([]any)
+		found := false
+		for _, item := range list {
+			if equal(value, item) {
+				found = true
+				break
+			}
+		}
+		return found == (c.Op == OpIn)
+	}
+
+	actual, ok := number(value)
+	limit, limitOK := number(c.Value)
+	if !ok || !limitOK {
+		return false
+	}
+	switch c.Op {
+	case OpLt:
+		return actual < limit
+	case OpLte:
+		return actual <= limit
+	case OpGt:
+		return actual > limit
+	case OpGte:
+		return actual >= limit
+	}
+	return false
+}
+
+// equal compares parameter values, numbers by value whatever their type
+func equal(a, b any) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func number(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+)
+
+func testPolicies() []*models.PluginPolicy {
+	return []*models.PluginPolicy{
+		{
+			ID: 1, Name: "dp-min-epsilon", Effect: models.PolicyEffectAllow,
+			Principal: "*", Namespace: "default", Plugin: "dpanonymizer", Method: "AddLaplaceNoise",
+			Constraints: models.PolicyConstraints{{Param: "epsilon", Op: OpGte, Value: 0.5}},
+		},
+		{
+			ID: 2, Name: "dp-analysts", Effect: models.PolicyEffectAllow,
+			Principal: "jwt:analyst", Namespace: "default", Plugin: "dpanonymizer", Method: "AddLaplaceNoise",
+		},
+		{
+			ID: 3, Name: "no-detokenize", Effect: models.PolicyEffectDeny,
+			Principal: "*", Namespace: "*", Plugin: "desensitization", Method: "Detokenize",
+		},
+		{
+			ID: 4, Name: "ci-formats", Effect: models.PolicyEffectDeny,
+			Principal: "api_key:ci", Namespace: "default", Plugin: "converter", Method: "*",
+			Constraints: models.PolicyConstraints{{Param: "options.format", Op: OpNotIn, Value: []any{"csv", "json"}}},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name    string
+		call    Call
+		allowed bool
+		policy  uint // The policy that decides, 0 for none
+	}{
+		{
+			name:    "unrestricted method",
+			call:    Call{Principal: "api_key:ci", Namespace: "default", Plugin: "desensitization", Method: "DesensitizeName"},
+			allowed: true,
+		},
+		{
+			name:    "constraint holds",
+			call:    Call{Principal: "api_key:ci", Namespace: "default", Plugin: "dpanonymizer", Method: "AddLaplaceNoise", Params: map[string]any{"epsilon": 1.0}},
+			allowed: true, policy: 1,
+		},
+		{
+			name: "constraint fails",
+			call: Call{Principal: "api_key:ci", Namespace: "default", Plugin: "dpanonymizer", Method: "AddLaplaceNoise", Params: map[string]any{"epsilon": 0.1}},
+		},
+		{
+			name: "constrained param missing",
+			call: Call{Principal: "api_key:ci", Namespace: "default", Plugin: "dpanonymizer", Method: "AddLaplaceNoise"},
+		},
+		{
+			name:    "allowed principal without constraints",
+			call:    Call{Principal: "jwt:analyst", Namespace: "default", Plugin: "dpanonymizer", Method: "AddLaplaceNoise", Params: map[string]any{"epsilon": 0.1}},
+			allowed: true, policy: 2,
+		},
+		{
+			name:   "denied in every namespace",
+			call:   Call{Principal: "jwt:analyst", Namespace: "acme", Plugin: "desensitization", Method: "Detokenize"},
+			policy: 3,
+		},
+		{
+			name:   "deny constraint holds",
+			call:   Call{Principal: "api_key:ci", Namespace: "default", Plugin: "converter", Method: "ConvertToXML", Params: map[string]any{"options": map[string]any{"format": "xml"}}},
+			policy: 4,
+		},
+		{
+			name:    "deny constraint fails",
+			call:    Call{Principal: "api_key:ci", Namespace: "default", Plugin: "converter", Method: "ConvertToCSV", Params: map[string]any{"options": map[string]any{"format": "csv"}}},
+			allowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := Evaluate(testPolicies(), tt.call)
+			if decision.Allowed != tt.allowed {
+				t.Errorf("Allowed = %v, want %v: %s", decision.Allowed, tt.allowed, decision.Reason)
+			}
+
+			var policy uint
+			if decision.Policy != nil {
+				policy = decision.Policy.ID
+			}
+			if policy != tt.policy {
+				t.Errorf("decided by policy %d, want %d: %s", policy, tt.policy, decision.Reason)
+			}
+			if len(decision.Evaluations) != len(testPolicies()) {
+				t.Errorf("explained %d policies, want all of them", len(decision.Evaluations))
+			}
+		})
+	}
+}
+
+func TestEvaluate_Explanations(t *testing.T) {
+	decision := Evaluate(testPolicies(), Call{
+		Principal: "api_key:ci", Namespace: "default", Plugin: "dpanonymizer", Method: "AddLaplaceNoise",
+		Params: map[string]any{"epsilon": 0.1},
+	})
+
+	want := []string{
+		"epsilon >= 0.5 does not hold (got 0.1)",
+		"applies to principal jwt:analyst",
+		"targets */desensitization.Detokenize",
+		"targets default/converter.*",
+	}
+	for i, evaluation := range decision.Evaluations {
+		if evaluation.Applies || evaluation.Reason != want[i] {
+			t.Errorf("policy %s: applies = %v, reason = %q, want %q", evaluation.Policy.Name, evaluation.Applies, evaluation.Reason, want[i])
+		}
+	}
+	if !strings.Contains(decision.Reason, "restricted") {
+		t.Errorf("Reason = %q, want the method reported as restricted", decision.Reason)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *models.PluginPolicy {
+		return &models.PluginPolicy{
+			Name: "p", Effect: models.PolicyEffectAllow,
+			Principal: "*", Namespace: "*", Plugin: "dpanonymizer", Method: "*",
+			Constraints: models.PolicyConstraints{{Param: "epsilon", Op: OpGte, Value: 0.5}},
+		}
+	}
+	if err := Validate(valid()); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	invalid := map[string]func(p *models.PluginPolicy){
+		"unknown effect":    func(p *models.PluginPolicy) { p.Effect = "audit" },
+		"empty method":      func(p *models.PluginPolicy) { p.Method = "" },
+		"unknown op":        func(p *models.PluginPolicy) { p.Constraints[0].Op = "between" },
+		"non-numeric bound": func(p *models.PluginPolicy) { p.Constraints[0].Value = "0.5" },
+		"in without list": func(p *models.PluginPolicy) {
+			p.Constraints[0] = models.PolicyConstraint{Param: "format", Op: OpIn, Value: "csv"}
+		},
+		"constraint no param": func(p *models.PluginPolicy) { p.Constraints[0].Param = "" },
+	}
+	for name, mutate := range invalid {
+		p := valid()
+		mutate(p)
+		if err := Validate(p); err == nil {
+			t.Errorf("Validate() accepted a policy with %s", name)
+		}
+	}
+}
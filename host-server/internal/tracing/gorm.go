@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormTracerName is the instrumentation name of database query spans
+const gormTracerName = "github.com/wylu1037/polyglot-plugin-host-server/internal/tracing/gorm"
+
+// GormPlugin records a client span for every query GORM runs, named after
+// the operation and table like "SELECT plugins". Queries are traced under the
+// span in the statement's context, so repositories need to run with the
+// request's context (db.WithContext) for their queries to join its trace.
+type GormPlugin struct {
+	tracer trace.Tracer
+}
+
+// NewGormPlugin creates the GORM plugin recording spans through provider
+func NewGormPlugin(provider trace.TracerProvider) *GormPlugin {
+	return &GormPlugin{tracer: provider.Tracer(gormTracerName)}
+}
+
+// Name implements gorm.Plugin
+func (p *GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize implements gorm.Plugin, registering callbacks around each of
+// GORM's operations
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", p.before("INSERT")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", p.after),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", p.before("SELECT")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", p.after),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", p.before("UPDATE")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", p.after),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("DELETE")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", p.after),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", p.before("")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", p.after),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	)
+}
+
+// before starts the span of a query, named after operation until the SQL
+// of the query is known
+func (p *GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		db.Statement.Context, _ = p.tracer.Start(db.Statement.Context, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", "postgresql")),
+		)
+	}
+}
+
+// after ends the span before started, recording the statement without its
+// parameter values
+func (p *GormPlugin) after(db *gorm.DB) {
+	span := trace.SpanFromContext(db.Statement.Context)
+	defer span.End()
+	if !span.IsRecording() {
+		return
+	}
+
+	statement := db.Statement.SQL.String()
+	if operation, _, _ := strings.Cut(strings.TrimSpace(statement), " "); operation != "" {
+		operation = strings.ToUpper(operation)
+		name := operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		span.SetName(name)
+		span.SetAttributes(attribute.String("db.operation.name", operation))
+	}
+	if db.Statement.Table != "" {
+		span.SetAttributes(attribute.String("db.collection.name", db.Statement.Table))
+	}
+	span.SetAttributes(
+		attribute.String("db.query.text", statement),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-host-server/app/database/models"
+	"github.com/wylu1037/polyglot-plugin-host-server/config"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/fx/fxtest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestNew_Exporters(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, exporter := range []string{"none", "stdout", "otlp"} {
+		lc := fxtest.NewLifecycle(t)
+		provider, err := New(lc, &config.Config{Tracing: config.TracingConfig{
+			Exporter: exporter, Endpoint: "localhost:4317", Insecure: true, ServiceName: "test", SampleRatio: 1,
+		}}, logger)
+		if err != nil {
+			t.Fatalf("New() with %s exporter error = %v", exporter, err)
+		}
+
+		// Spans are only recorded with an exporter to send them to
+		_, recording := provider.(*sdktrace.TracerProvider)
+		if recording != (exporter != "none") {
+			t.Errorf("New() with %s exporter returned %T", exporter, provider)
+		}
+		lc.RequireStart().RequireStop()
+	}
+
+	if _, err := New(fxtest.NewLifecycle(t), &config.Config{Tracing: config.TracingConfig{Exporter: "jaeger"}}, logger); err == nil {
+		t.Errorf("New() accepted an unknown exporter")
+	}
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	// Dry runs build the SQL of queries without a database to run them against
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.Use(NewGormPlugin(provider)); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	db.WithContext(ctx).First(&models.Plugin{}, 7)
+	db.WithContext(ctx).Model(&models.Plugin{}).Where("id = ?", 7).Update("last_used_at", 1)
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want 2 queries and the request", len(spans))
+	}
+	for i, want := range []string{"SELECT plugins", "UPDATE plugins"} {
+		span := spans[i]
+		if span.Name() != want {
+			t.Errorf("span %d name = %s, want %s", i, span.Name(), want)
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of the request span", span.Name())
+		}
+	}
+}
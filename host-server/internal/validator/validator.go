@@ -2,6 +2,7 @@ package validator
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -13,7 +14,25 @@ type Validator struct {
 
 // defaultValidator is the global singleton instance
 var defaultValidator = &Validator{
-	validator: validator.New(),
+	validator: newValidate(),
+}
+
+// newValidate creates a go-playground validator with the custom tags:
+//
+//	pathsegment: a single file path element, such as a plugin name used in
+//	its binary path; no separators, "." or ".."
+func newValidate() *validator.Validate {
+	v := validator.New()
+	v.RegisterValidation("pathsegment", func(fl validator.FieldLevel) bool {
+		return IsPathSegment(fl.Field().String())
+	})
+	return v
+}
+
+// IsPathSegment reports whether value is usable as one element of a file
+// path without leaving the directory it is joined to
+func IsPathSegment(value string) bool {
+	return value != "" && value != "." && value != ".." && !strings.ContainsAny(value, "/\\\x00")
 }
 
 // New returns the global validator instance (singleton pattern)
@@ -61,6 +80,8 @@ func formatError(err error) error {
 				return fmt.Errorf("%s must be a valid email address", e.Field())
 			case "url":
 				return fmt.Errorf("%s must be a valid URL", e.Field())
+			case "pathsegment":
+				return fmt.Errorf("%s must not contain path separators or be '.' or '..'", e.Field())
 			default:
 				return fmt.Errorf("%s failed validation: %s", e.Field(), e.Tag())
 			}
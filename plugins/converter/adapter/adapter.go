@@ -1,15 +1,23 @@
 package adapter
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"sync"
 
 	"github.com/wylu1037/polyglot-plugin-showcase/plugins/converter/impl"
 	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // ConverterAdapter adapts the converter implementation to the common plugin interface
 type ConverterAdapter struct {
 	impl *impl.ConverterImpl
+
+	mu     sync.RWMutex
+	config *structpb.Struct // Settings from the host, see configSchema
 }
 
 func NewConverterAdapter() *ConverterAdapter {
@@ -19,28 +27,59 @@ func NewConverterAdapter() *ConverterAdapter {
 }
 
 func (a *ConverterAdapter) GetMetadata() (*common.MetadataResponse, error) {
+	return a.GetMetadataContext(context.Background())
+}
+
+func (a *ConverterAdapter) GetMetadataContext(ctx context.Context) (*common.MetadataResponse, error) {
 	return &common.MetadataResponse{
 		Name:        "converter",
 		Version:     "1.0.0",
 		Description: "Data format converter plugin - converts JSON to CSV, TXT, HTML",
-		Methods: []string{
-			"ConvertToCSV",
-			"ConvertToTXT",
-			"ConvertToHTML",
-		},
+		Methods:     common.MethodNames(methodDescriptors),
 		Capabilities: map[string]string{
 			"type":           "converter",
 			"input_format":   "json",
 			"output_formats": "csv,txt,html",
 		},
-		ProtocolVersion: common.CurrentProtocolVersion,
+		ProtocolVersion:   common.CurrentProtocolVersion,
+		MethodDescriptors: methodDescriptors,
+		ConfigSchema:      configSchema,
 	}, nil
 }
 
-func (a *ConverterAdapter) Execute(method string, params map[string]string) (*common.ExecuteResponse, error) {
-	data, ok := params["data"]
-	if !ok {
-		errMsg := "missing 'data' parameter"
+// Configure replaces the settings that provide the defaults of the options
+func (a *ConverterAdapter) Configure(ctx context.Context, config *structpb.Struct) error {
+	if err := common.ValidateConfig(configSchema, config); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.config = proto.Clone(config).(*structpb.Struct)
+	a.mu.Unlock()
+	return nil
+}
+
+// withSettings fills in the options of method the call leaves out from the settings
+func (a *ConverterAdapter) withSettings(method string, params *structpb.Struct) *structpb.Struct {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return common.WithDefaults(params, a.config, settingDefaults[method])
+}
+
+func (a *ConverterAdapter) Execute(method string, params *structpb.Struct) (*common.ExecuteResponse, error) {
+	return a.ExecuteContext(context.Background(), method, params)
+}
+
+func (a *ConverterAdapter) ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (*common.ExecuteResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	args := common.NewParams(a.withSettings(method, params))
+
+	data, err := args.String("data")
+	if err != nil {
+		errMsg := err.Error()
 		return &common.ExecuteResponse{
 			Success: false,
 			Error:   &errMsg,
@@ -48,15 +87,16 @@ func (a *ConverterAdapter) Execute(method string, params map[string]string) (*co
 	}
 
 	// Extract options from params (all params except 'data' are considered options)
-	options := make(map[string]string)
-	for key, value := range params {
-		if key != "data" {
-			options[key] = value
-		}
+	options, err := args.Strings("data")
+	if err != nil {
+		errMsg := err.Error()
+		return &common.ExecuteResponse{
+			Success: false,
+			Error:   &errMsg,
+		}, nil
 	}
 
 	var result string
-	var err error
 
 	// Route to appropriate method
 	switch method {
@@ -74,6 +114,10 @@ func (a *ConverterAdapter) Execute(method string, params map[string]string) (*co
 		}, nil
 	}
 
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
 	if err != nil {
 		errMsg := err.Error()
 		return &common.ExecuteResponse{
@@ -83,7 +127,29 @@ func (a *ConverterAdapter) Execute(method string, params map[string]string) (*co
 	}
 
 	return &common.ExecuteResponse{
-		Result:  &result,
+		Result:  structpb.NewStringValue(result),
 		Success: true,
 	}, nil
 }
+
+// ExecuteStream converts the JSON read from input without buffering it.
+// The streamed input replaces the 'data' parameter; the remaining parameters
+// are options, as in ExecuteContext.
+func (a *ConverterAdapter) ExecuteStream(ctx context.Context, method string, params *structpb.Struct, input io.Reader, output common.StreamWriter) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	options, err := common.NewParams(a.withSettings(method, params)).Strings("data")
+	if err != nil {
+		return err
+	}
+
+	switch method {
+	case "ConvertToCSV":
+		output.SetContentType("text/csv")
+		return a.impl.ConvertToCSVStream(input, output, options)
+	default:
+		return fmt.Errorf("method does not support streaming: %s", method)
+	}
+}
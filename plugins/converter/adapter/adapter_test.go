@@ -0,0 +1,150 @@
+package adapter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConverterAdapter_MethodExamples(t *testing.T) {
+	a := NewConverterAdapter()
+
+	metadata, err := a.GetMetadataContext(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadataContext() error = %v", err)
+	}
+
+	for _, method := range metadata.MethodDescriptors {
+		for _, example := range method.Examples {
+			t.Run(method.Name+"/"+example.Description, func(t *testing.T) {
+				resp, err := a.ExecuteContext(context.Background(), method.Name, example.Params)
+				if err != nil {
+					t.Fatalf("ExecuteContext() error = %v", err)
+				}
+				if !resp.Success {
+					t.Fatalf("ExecuteContext() failed: %s", resp.GetError())
+				}
+				if !proto.Equal(resp.Result, example.Result) {
+					t.Errorf("ExecuteContext() result = %v, want %v", resp.Result, example.Result)
+				}
+			})
+		}
+	}
+}
+
+// recordingWriter is a common.StreamWriter that keeps everything written to it
+type recordingWriter struct {
+	strings.Builder
+	contentType string
+}
+
+func (w *recordingWriter) SetContentType(contentType string) {
+	if w.Len() == 0 {
+		w.contentType = contentType
+	}
+}
+
+func TestConverterAdapter_StreamExamples(t *testing.T) {
+	a := NewConverterAdapter()
+
+	for _, method := range methodDescriptors {
+		if method.StreamInput == "" {
+			continue
+		}
+		for _, example := range method.Examples {
+			t.Run(method.Name+"/"+example.Description, func(t *testing.T) {
+				fields := example.Params.AsMap()
+				input := fields[method.StreamInput].(string)
+				delete(fields, method.StreamInput)
+
+				output := &recordingWriter{}
+				err := a.ExecuteStream(context.Background(), method.Name, common.MustStruct(fields), strings.NewReader(input), output)
+				if err != nil {
+					t.Fatalf("ExecuteStream() error = %v", err)
+				}
+				if output.String() != example.Result.GetStringValue() {
+					t.Errorf("ExecuteStream() = %q, want %q", output.String(), example.Result.GetStringValue())
+				}
+				if output.contentType == "" {
+					t.Errorf("ExecuteStream() did not set a content type")
+				}
+			})
+		}
+	}
+}
+
+func TestConverterAdapter_StreamUnsupportedMethod(t *testing.T) {
+	a := NewConverterAdapter()
+
+	err := a.ExecuteStream(context.Background(), "ConvertToHTML", nil, strings.NewReader(`{}`), &recordingWriter{})
+	if err == nil {
+		t.Errorf("ExecuteStream() error = nil, want an error for a method without StreamInput")
+	}
+}
+
+func TestConverterAdapter_ConfigureDefaults(t *testing.T) {
+	a := NewConverterAdapter()
+	data := `[{"name":"John","age":"30"}]`
+
+	if err := a.Configure(context.Background(), common.MustStruct(map[string]any{"csv_delimiter": ";"})); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   string
+	}{
+		{"setting applies", map[string]any{"data": data}, "age;name\n30;John\n"},
+		{"call option wins", map[string]any{"data": data, "delimiter": "|"}, "age|name\n30|John\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := a.ExecuteContext(context.Background(), "ConvertToCSV", common.MustStruct(tt.params))
+			if err != nil || !resp.Success {
+				t.Fatalf("ExecuteContext() = %v, %v", resp, err)
+			}
+			if got := resp.Result.GetStringValue(); got != tt.want {
+				t.Errorf("ExecuteContext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	output := &recordingWriter{}
+	if err := a.ExecuteStream(context.Background(), "ConvertToCSV", nil, strings.NewReader(data), output); err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	if output.String() != "age;name\n30;John\n" {
+		t.Errorf("ExecuteStream() = %q, want the configured delimiter", output.String())
+	}
+}
+
+func TestConverterAdapter_ConfigureRejectsInvalid(t *testing.T) {
+	a := NewConverterAdapter()
+	ctx := context.Background()
+
+	if err := a.Configure(ctx, common.MustStruct(map[string]any{"html_styled": false})); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	for _, config := range []map[string]any{
+		{"html_styled": "no"},
+		{"unknown": true},
+	} {
+		if err := a.Configure(ctx, common.MustStruct(config)); err == nil {
+			t.Errorf("Configure(%v) error = nil, want an error", config)
+		}
+	}
+
+	// The rejected settings leave the accepted ones in place
+	resp, err := a.ExecuteContext(ctx, "ConvertToHTML", common.MustStruct(map[string]any{"data": `{"name":"John"}`}))
+	if err != nil || !resp.Success {
+		t.Fatalf("ExecuteContext() = %v, %v", resp, err)
+	}
+	if strings.Contains(resp.Result.GetStringValue(), "data-table") {
+		t.Errorf("ExecuteContext() produced a styled table after html_styled was set to false")
+	}
+}
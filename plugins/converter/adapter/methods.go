@@ -0,0 +1,128 @@
+package adapter
+
+import (
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+// dataParam is the JSON input shared by every conversion method
+var dataParam = &common.ParamDescriptor{
+	Type:        "string",
+	Description: "JSON object or array of objects to convert",
+	Required:    true,
+}
+
+var methodDescriptors = []*common.MethodDescriptor{
+	{
+		Name:        "ConvertToCSV",
+		Description: "Convert JSON data to CSV with a header row of sorted keys",
+		Parameters: map[string]*common.ParamDescriptor{
+			"data": dataParam,
+			"delimiter": {
+				Type:        "string",
+				Description: "Field delimiter; defaults to the csv_delimiter setting",
+			},
+		},
+		Returns: &common.ReturnDescriptor{
+			Type:        "string",
+			Description: "CSV document",
+		},
+		StreamInput: "data",
+		Examples: []*common.MethodExample{
+			{
+				Description: "Semicolon separated",
+				Params: common.MustStruct(map[string]any{
+					"data":      `[{"name":"John","age":"30"}]`,
+					"delimiter": ";",
+				}),
+				Result: common.MustValue("age;name\n30;John\n"),
+			},
+		},
+	},
+	{
+		Name:        "ConvertToTXT",
+		Description: "Convert JSON data to human readable text",
+		Parameters: map[string]*common.ParamDescriptor{
+			"data": dataParam,
+			"format": {
+				Type:        "string",
+				Description: "Output format: key-value or json-pretty; defaults to the txt_format setting",
+			},
+		},
+		Returns: &common.ReturnDescriptor{
+			Type:        "string",
+			Description: "Formatted text",
+		},
+		Examples: []*common.MethodExample{
+			{
+				Description: "Key-value listing",
+				Params: common.MustStruct(map[string]any{
+					"data":   `{"name":"John","age":"30"}`,
+					"format": "key-value",
+				}),
+				Result: common.MustValue("age: 30\nname: John\n"),
+			},
+		},
+	},
+	{
+		Name:        "ConvertToHTML",
+		Description: "Convert JSON data to an HTML table",
+		Parameters: map[string]*common.ParamDescriptor{
+			"data": dataParam,
+			"styled": {
+				Type:        "bool",
+				Description: "Add the data-table class and inline styles; defaults to the html_styled setting",
+			},
+			"full_page": {
+				Type:        "bool",
+				Description: "Wrap the table in a complete HTML document; defaults to the html_full_page setting",
+			},
+		},
+		Returns: &common.ReturnDescriptor{
+			Type:        "string",
+			Description: "HTML table or document",
+		},
+		Examples: []*common.MethodExample{
+			{
+				Description: "Unstyled table",
+				Params: common.MustStruct(map[string]any{
+					"data":   `{"name":"John"}`,
+					"styled": false,
+				}),
+				Result: common.MustValue("<table>\n  <thead>\n    <tr>\n      <th>name</th>\n    </tr>\n  </thead>\n  <tbody>\n    <tr>\n      <td>John</td>\n    </tr>\n  </tbody>\n</table>\n"),
+			},
+		},
+	},
+}
+
+// configSchema declares the settings the host may send with Configure. They
+// are the defaults for the options of the conversion methods.
+var configSchema = map[string]*common.ParamDescriptor{
+	"csv_delimiter": {
+		Type:        "string",
+		Description: "Default field delimiter of ConvertToCSV",
+		Default:     common.MustValue(","),
+	},
+	"txt_format": {
+		Type:        "string",
+		Description: "Default output format of ConvertToTXT: key-value or json-pretty",
+		Default:     common.MustValue("key-value"),
+	},
+	"html_styled": {
+		Type:        "bool",
+		Description: "Whether ConvertToHTML styles tables by default",
+		Default:     common.MustValue(true),
+	},
+	"html_full_page": {
+		Type:        "bool",
+		Description: "Whether ConvertToHTML produces complete HTML documents by default",
+		Default:     common.MustValue(false),
+	},
+}
+
+// settingDefaults maps the options of each method to the settings that
+// provide their defaults
+var settingDefaults = map[string]map[string]string{
+	"ConvertToCSV":  {"delimiter": "csv_delimiter"},
+	"ConvertToTXT":  {"format": "txt_format"},
+	"ConvertToHTML": {"styled": "html_styled", "full_page": "html_full_page"},
+}
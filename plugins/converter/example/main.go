@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/go-plugin"
 	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const RUN_PATH = "/Users/wenyanglu/Workspace/github/polyglot-plugin-showcase/host-server/bin/plugins/builtin/data-processing/converter/v1.0.0/darwin_arm64/plugin"
@@ -82,83 +83,83 @@ func main() {
 
 	// Convert to CSV
 	fmt.Println("=== Convert to CSV ===")
-	csvResult, err := converterPlugin.Execute("ConvertToCSV", map[string]string{"data": jsonData})
+	csvResult, err := converterPlugin.Execute("ConvertToCSV", newParams(map[string]any{"data": jsonData}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if csvResult.Success {
-		fmt.Printf("%s\n\n", *csvResult.Result)
+		fmt.Printf("%v\n\n", csvResult.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *csvResult.Error)
 	}
 
 	// Convert to CSV with custom delimiter
 	fmt.Println("=== Convert to CSV (with semicolon delimiter) ===")
-	csvWithDelimiter, err := converterPlugin.Execute("ConvertToCSV", map[string]string{
+	csvWithDelimiter, err := converterPlugin.Execute("ConvertToCSV", newParams(map[string]any{
 		"data":      jsonData,
 		"delimiter": ";",
-	})
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if csvWithDelimiter.Success {
-		fmt.Printf("%s\n\n", *csvWithDelimiter.Result)
+		fmt.Printf("%v\n\n", csvWithDelimiter.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *csvWithDelimiter.Error)
 	}
 
 	// Convert to TXT (key-value format)
 	fmt.Println("=== Convert to TXT (key-value format) ===")
-	txtResult, err := converterPlugin.Execute("ConvertToTXT", map[string]string{
+	txtResult, err := converterPlugin.Execute("ConvertToTXT", newParams(map[string]any{
 		"data":   jsonData,
 		"format": "key-value",
-	})
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if txtResult.Success {
-		fmt.Printf("%s\n\n", *txtResult.Result)
+		fmt.Printf("%v\n\n", txtResult.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *txtResult.Error)
 	}
 
 	// Convert to TXT (pretty JSON format)
 	fmt.Println("=== Convert to TXT (pretty JSON format) ===")
-	txtPretty, err := converterPlugin.Execute("ConvertToTXT", map[string]string{
+	txtPretty, err := converterPlugin.Execute("ConvertToTXT", newParams(map[string]any{
 		"data":   jsonData,
 		"format": "json-pretty",
-	})
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if txtPretty.Success {
-		fmt.Printf("%s\n\n", *txtPretty.Result)
+		fmt.Printf("%v\n\n", txtPretty.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *txtPretty.Error)
 	}
 
 	// Convert to HTML (styled table)
 	fmt.Println("=== Convert to HTML (styled table) ===")
-	htmlResult, err := converterPlugin.Execute("ConvertToHTML", map[string]string{
+	htmlResult, err := converterPlugin.Execute("ConvertToHTML", newParams(map[string]any{
 		"data":   jsonData,
 		"styled": "true",
-	})
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if htmlResult.Success {
-		fmt.Printf("%s\n\n", *htmlResult.Result)
+		fmt.Printf("%v\n\n", htmlResult.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *htmlResult.Error)
 	}
 
 	// Convert to HTML (full page)
 	fmt.Println("=== Convert to HTML (full page) ===")
-	paramsMap := map[string]string{
+	paramsMap := newParams(map[string]any{
 		"data":      jsonData,
-		"styled":    "true",
-		"full_page": "true",
-	}
+		"styled":    true,
+		"full_page": true,
+	})
 	htmlFullPage, err := converterPlugin.Execute("ConvertToHTML", paramsMap)
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if htmlFullPage.Success {
-		fmt.Printf("%s\n\n", *htmlFullPage.Result)
+		fmt.Printf("%v\n\n", htmlFullPage.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *htmlFullPage.Error)
 	}
@@ -166,11 +167,11 @@ func main() {
 	// Example with single object
 	singleObject := `{"id": "1", "name": "John Doe", "email": "john@example.com"}`
 	fmt.Println("=== Single Object to CSV ===")
-	singleCSV, err := converterPlugin.Execute("ConvertToCSV", map[string]string{"data": singleObject})
+	singleCSV, err := converterPlugin.Execute("ConvertToCSV", newParams(map[string]any{"data": singleObject}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if singleCSV.Success {
-		fmt.Printf("%s\n\n", *singleCSV.Result)
+		fmt.Printf("%v\n\n", singleCSV.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *singleCSV.Error)
 	}
@@ -185,17 +186,26 @@ func main() {
 		"roles": ["admin", "user"]
 	}`
 	fmt.Println("=== Nested Object to TXT ===")
-	nestedTXT, err := converterPlugin.Execute("ConvertToTXT", map[string]string{
+	nestedTXT, err := converterPlugin.Execute("ConvertToTXT", newParams(map[string]any{
 		"data":   nestedObject,
 		"format": "key-value",
-	})
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if nestedTXT.Success {
-		fmt.Printf("%s\n\n", *nestedTXT.Result)
+		fmt.Printf("%v\n\n", nestedTXT.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *nestedTXT.Error)
 	}
 
 	fmt.Println("=== All examples completed ===")
 }
+
+// newParams builds typed Execute parameters from a plain map
+func newParams(params map[string]any) *structpb.Struct {
+	s, err := structpb.NewStruct(params)
+	if err != nil {
+		log.Fatalf("Failed to build params: %v", err)
+	}
+	return s
+}
@@ -2,11 +2,13 @@
 package impl
 
 import (
+	"bufio"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"html"
+	"io"
 	"sort"
 	"strings"
 )
@@ -22,39 +24,28 @@ func (c *ConverterImpl) ConvertToCSV(jsonData string, options map[string]string)
 		return "", errors.New("JSON data cannot be empty")
 	}
 
-	// Parse JSON data
-	var data interface{}
-	if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-		return "", fmt.Errorf("invalid JSON data: %w", err)
+	var builder strings.Builder
+	if err := c.ConvertToCSVStream(strings.NewReader(jsonData), &builder, options); err != nil {
+		return "", err
 	}
 
-	// Convert to array of maps
-	var records []map[string]interface{}
-	switch v := data.(type) {
-	case map[string]interface{}:
-		records = []map[string]interface{}{v}
-	case []interface{}:
-		for _, item := range v {
-			if m, ok := item.(map[string]interface{}); ok {
-				records = append(records, m)
-			} else {
-				return "", errors.New("JSON array must contain objects")
-			}
-		}
-	default:
-		return "", errors.New("JSON data must be an object or array of objects")
-	}
+	return builder.String(), nil
+}
 
-	if len(records) == 0 {
-		return "", errors.New("no data to convert")
+// ConvertToCSVStream converts JSON data read from r to CSV written to w.
+// Array elements are decoded and written one row at a time, so arbitrarily
+// large arrays are converted without holding them in memory. Headers are the
+// sorted keys of the first record; keys that only appear in later records are
+// dropped, as in ConvertToCSV.
+func (c *ConverterImpl) ConvertToCSVStream(r io.Reader, w io.Writer, options map[string]string) error {
+	input := bufio.NewReader(r)
+	first, err := peekNonSpace(input)
+	if err == io.EOF {
+		return errors.New("JSON data cannot be empty")
 	}
-
-	// Extract headers (keys from first record, sorted for consistency)
-	headers := make([]string, 0, len(records[0]))
-	for key := range records[0] {
-		headers = append(headers, key)
+	if err != nil {
+		return fmt.Errorf("failed to read JSON data: %w", err)
 	}
-	sort.Strings(headers)
 
 	// Check if custom delimiter is specified
 	delimiter := ","
@@ -62,41 +53,102 @@ func (c *ConverterImpl) ConvertToCSV(jsonData string, options map[string]string)
 		delimiter = delim
 	}
 
-	// Build CSV
-	var builder strings.Builder
-	writer := csv.NewWriter(&builder)
+	writer := csv.NewWriter(w)
+	writer.Comma = rune(delimiter[0])
 
-	// Set custom delimiter if specified
-	if len(delimiter) > 0 {
-		writer.Comma = rune(delimiter[0])
-	}
+	var headers []string
+	writeRecord := func(record map[string]interface{}) error {
+		if headers == nil {
+			// Extract headers (keys from first record, sorted for consistency)
+			headers = make([]string, 0, len(record))
+			for key := range record {
+				headers = append(headers, key)
+			}
+			sort.Strings(headers)
 
-	// Write headers
-	if err := writer.Write(headers); err != nil {
-		return "", fmt.Errorf("failed to write CSV headers: %w", err)
-	}
+			if err := writer.Write(headers); err != nil {
+				return fmt.Errorf("failed to write CSV headers: %w", err)
+			}
+		}
 
-	// Write data rows
-	for _, record := range records {
 		row := make([]string, len(headers))
 		for i, header := range headers {
 			if val, ok := record[header]; ok {
 				row[i] = fmt.Sprintf("%v", val)
-			} else {
-				row[i] = ""
 			}
 		}
 		if err := writer.Write(row); err != nil {
-			return "", fmt.Errorf("failed to write CSV row: %w", err)
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(input)
+	switch first {
+	case '{':
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			return fmt.Errorf("invalid JSON data: %w", err)
+		}
+		if err := writeRecord(record); err != nil {
+			return err
+		}
+	case '[':
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("invalid JSON data: %w", err)
+		}
+		for decoder.More() {
+			var item interface{}
+			if err := decoder.Decode(&item); err != nil {
+				return fmt.Errorf("invalid JSON data: %w", err)
+			}
+			record, ok := item.(map[string]interface{})
+			if !ok {
+				return errors.New("JSON array must contain objects")
+			}
+			if err := writeRecord(record); err != nil {
+				return err
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("invalid JSON data: %w", err)
+		}
+		if headers == nil {
+			return errors.New("no data to convert")
 		}
+	default:
+		var data interface{}
+		if err := decoder.Decode(&data); err != nil {
+			return fmt.Errorf("invalid JSON data: %w", err)
+		}
+		return errors.New("JSON data must be an object or array of objects")
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid JSON data: unexpected data after top-level value")
 	}
 
 	writer.Flush()
 	if err := writer.Error(); err != nil {
-		return "", fmt.Errorf("CSV writer error: %w", err)
+		return fmt.Errorf("CSV writer error: %w", err)
 	}
 
-	return builder.String(), nil
+	return nil
+}
+
+// peekNonSpace skips leading JSON whitespace and returns the next byte without consuming it
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		return b, r.UnreadByte()
+	}
 }
 
 // ConvertToTXT converts JSON data to plain text format.
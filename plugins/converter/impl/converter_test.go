@@ -1,6 +1,7 @@
 package impl
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -74,6 +75,110 @@ func TestConverterImpl_ConvertToCSV(t *testing.T) {
 	}
 }
 
+func TestConverterImpl_ConvertToCSVStream(t *testing.T) {
+	converter := &ConverterImpl{}
+
+	tests := []struct {
+		name     string
+		jsonData string
+		options  map[string]string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "array of objects",
+			jsonData: ` [{"name":"John","age":30},{"name":"Jane","age":25}] `,
+			options:  map[string]string{},
+			want:     "age,name\n30,John\n25,Jane\n",
+		},
+		{
+			name:     "keys missing from the first record are dropped",
+			jsonData: `[{"name":"John"},{"name":"Jane","age":25}]`,
+			options:  map[string]string{"delimiter": ";"},
+			want:     "name\nJohn\nJane\n",
+		},
+		{
+			name:     "single object",
+			jsonData: `{"name":"John"}`,
+			options:  map[string]string{},
+			want:     "name\nJohn\n",
+		},
+		{
+			name:     "empty array",
+			jsonData: `[]`,
+			wantErr:  true,
+		},
+		{
+			name:     "non-object element",
+			jsonData: `[{"name":"John"},1]`,
+			wantErr:  true,
+		},
+		{
+			name:     "scalar",
+			jsonData: `"John"`,
+			wantErr:  true,
+		},
+		{
+			name:     "truncated array",
+			jsonData: `[{"name":"John"},`,
+			wantErr:  true,
+		},
+		{
+			name:     "trailing data",
+			jsonData: `[{"name":"John"}] []`,
+			wantErr:  true,
+		},
+		{
+			name:     "whitespace only",
+			jsonData: " \n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			err := converter.ConvertToCSVStream(strings.NewReader(tt.jsonData), &output, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConvertToCSVStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && output.String() != tt.want {
+				t.Errorf("ConvertToCSVStream() = %q, want %q", output.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestConverterImpl_ConvertToCSVStreamLargeArray(t *testing.T) {
+	converter := &ConverterImpl{}
+
+	var input strings.Builder
+	input.WriteString("[")
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			input.WriteString(",")
+		}
+		fmt.Fprintf(&input, `{"id":%d,"name":"user-%d"}`, i, i)
+	}
+	input.WriteString("]")
+
+	streamed := &strings.Builder{}
+	if err := converter.ConvertToCSVStream(strings.NewReader(input.String()), streamed, nil); err != nil {
+		t.Fatalf("ConvertToCSVStream() error = %v", err)
+	}
+
+	buffered, err := converter.ConvertToCSV(input.String(), nil)
+	if err != nil {
+		t.Fatalf("ConvertToCSV() error = %v", err)
+	}
+	if streamed.String() != buffered {
+		t.Errorf("streamed output differs from ConvertToCSV output")
+	}
+	if lines := strings.Count(streamed.String(), "\n"); lines != 10001 {
+		t.Errorf("ConvertToCSVStream() wrote %d lines, want 10001", lines)
+	}
+}
+
 func TestConverterImpl_ConvertToTXT(t *testing.T) {
 	converter := &ConverterImpl{}
 
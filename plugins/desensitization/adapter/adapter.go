@@ -1,15 +1,19 @@
 package adapter
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/wylu1037/polyglot-plugin-showcase/plugins/desensitization/impl"
 	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // DesensitizationAdapter adapts the desensitization implementation to the common plugin interface
 type DesensitizationAdapter struct {
 	impl *impl.DesensitzerImpl
+	host common.HostServices // nil when the host provides no services
 }
 
 func NewDesensitizationAdapter() *DesensitizationAdapter {
@@ -18,30 +22,46 @@ func NewDesensitizationAdapter() *DesensitizationAdapter {
 	}
 }
 
+// tokenizationKeySecret names the host secret Tokenize keys its tokens with
+const tokenizationKeySecret = "tokenization_key"
+
+// SetHost implements common.HostAwarePluginInterface
+func (a *DesensitizationAdapter) SetHost(host common.HostServices) {
+	a.host = host
+}
+
 func (a *DesensitizationAdapter) GetMetadata() (*common.MetadataResponse, error) {
+	return a.GetMetadataContext(context.Background())
+}
+
+func (a *DesensitizationAdapter) GetMetadataContext(ctx context.Context) (*common.MetadataResponse, error) {
 	return &common.MetadataResponse{
 		Name:        "desensitization",
 		Version:     "1.0.0",
 		Description: "Data desensitization plugin",
-		Methods: []string{
-			"DesensitizeName",
-			"DesensitizeTelNo",
-			"DesensitizeIDNumber",
-			"DesensitizeEmail",
-			"DesensitizeBankCard",
-			"DesensitizeAddress",
-		},
+		Methods:     common.MethodNames(methodDescriptors),
 		Capabilities: map[string]string{
 			"type": "desensitization",
 		},
-		ProtocolVersion: common.CurrentProtocolVersion,
+		ProtocolVersion:   common.CurrentProtocolVersion,
+		MethodDescriptors: methodDescriptors,
 	}, nil
 }
 
-func (a *DesensitizationAdapter) Execute(method string, params map[string]string) (*common.ExecuteResponse, error) {
-	data, ok := params["data"]
-	if !ok {
-		errMsg := "missing 'data' parameter"
+func (a *DesensitizationAdapter) Execute(method string, params *structpb.Struct) (*common.ExecuteResponse, error) {
+	return a.ExecuteContext(context.Background(), method, params)
+}
+
+func (a *DesensitizationAdapter) ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (*common.ExecuteResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	args := common.NewParams(params)
+
+	data, err := args.String("data")
+	if err != nil {
+		errMsg := err.Error()
 		return &common.ExecuteResponse{
 			Success: false,
 			Error:   &errMsg,
@@ -49,7 +69,6 @@ func (a *DesensitizationAdapter) Execute(method string, params map[string]string
 	}
 
 	var result string
-	var err error
 
 	// Route to appropriate method
 	switch method {
@@ -65,6 +84,8 @@ func (a *DesensitizationAdapter) Execute(method string, params map[string]string
 		result, err = a.impl.DesensitizeBankCard(data)
 	case "DesensitizeAddress":
 		result, err = a.impl.DesensitizeAddress(data)
+	case "Tokenize":
+		result, err = a.tokenize(ctx, data)
 	default:
 		errMsg := fmt.Sprintf("unknown method: %s", method)
 		return &common.ExecuteResponse{
@@ -73,6 +94,10 @@ func (a *DesensitizationAdapter) Execute(method string, params map[string]string
 		}, nil
 	}
 
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
 	if err != nil {
 		errMsg := err.Error()
 		return &common.ExecuteResponse{
@@ -82,7 +107,24 @@ func (a *DesensitizationAdapter) Execute(method string, params map[string]string
 	}
 
 	return &common.ExecuteResponse{
-		Result:  &result,
+		Result:  structpb.NewStringValue(result),
 		Success: true,
 	}, nil
 }
+
+// tokenize looks the key up on every call, so a rotated secret takes effect
+// without restarting the plugin
+func (a *DesensitizationAdapter) tokenize(ctx context.Context, data string) (string, error) {
+	if a.host == nil {
+		return "", errors.New("tokenization requires host services")
+	}
+
+	key, err := a.host.GetSecret(ctx, tokenizationKeySecret)
+	if errors.Is(err, common.ErrSecretNotFound) {
+		return "", fmt.Errorf("host has no '%s' secret for this plugin", tokenizationKeySecret)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get tokenization key: %w", err)
+	}
+	return a.impl.Tokenize(key, data)
+}
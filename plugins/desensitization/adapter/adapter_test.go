@@ -0,0 +1,66 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDesensitizationAdapter_MethodExamples(t *testing.T) {
+	a := NewDesensitizationAdapter()
+
+	metadata, err := a.GetMetadataContext(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadataContext() error = %v", err)
+	}
+
+	for _, method := range metadata.MethodDescriptors {
+		for _, example := range method.Examples {
+			t.Run(method.Name, func(t *testing.T) {
+				resp, err := a.ExecuteContext(context.Background(), method.Name, example.Params)
+				if err != nil {
+					t.Fatalf("ExecuteContext() error = %v", err)
+				}
+				if !resp.Success {
+					t.Fatalf("ExecuteContext() failed: %s", resp.GetError())
+				}
+				if !proto.Equal(resp.Result, example.Result) {
+					t.Errorf("ExecuteContext() result = %v, want %v", resp.Result, example.Result)
+				}
+			})
+		}
+	}
+}
+
+func TestDesensitizationAdapter_Tokenize(t *testing.T) {
+	ctx := context.Background()
+	params := common.MustStruct(map[string]any{"data": "13812345678"})
+
+	a := NewDesensitizationAdapter()
+	resp, err := a.ExecuteContext(ctx, "Tokenize", params)
+	if err != nil {
+		t.Fatalf("ExecuteContext() error = %v", err)
+	}
+	if resp.Success {
+		t.Error("Tokenize without host services should fail")
+	}
+
+	a.SetHost(&common.MemoryHost{})
+	resp, _ = a.ExecuteContext(ctx, "Tokenize", params)
+	if resp.Success {
+		t.Error("Tokenize without a tokenization_key secret should fail")
+	}
+
+	a.SetHost(&common.MemoryHost{Secrets: map[string]string{"tokenization_key": "secret"}})
+	resp, err = a.ExecuteContext(ctx, "Tokenize", params)
+	if err != nil || !resp.Success {
+		t.Fatalf("ExecuteContext() = %v, %v", resp, err)
+	}
+
+	want, _ := a.impl.Tokenize("secret", "13812345678")
+	if got := resp.Result.GetStringValue(); got != want {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package adapter
+
+import (
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+// desensitizeMethod describes a method that masks the single string in "data"
+func desensitizeMethod(name, description, input, example, masked string) *common.MethodDescriptor {
+	return &common.MethodDescriptor{
+		Name:        name,
+		Description: description,
+		Parameters: map[string]*common.ParamDescriptor{
+			"data": {
+				Type:        "string",
+				Description: input,
+				Required:    true,
+			},
+		},
+		Returns: &common.ReturnDescriptor{
+			Type:        "string",
+			Description: "Masked value",
+		},
+		Examples: []*common.MethodExample{
+			{
+				Params: common.MustStruct(map[string]any{"data": example}),
+				Result: common.MustValue(masked),
+			},
+		},
+	}
+}
+
+var methodDescriptors = []*common.MethodDescriptor{
+	desensitizeMethod("DesensitizeName",
+		"Keep the first character of a name and mask the rest",
+		"Person name", "张三", "张*"),
+	desensitizeMethod("DesensitizeTelNo",
+		"Mask the middle four digits of a telephone number",
+		"Telephone number", "13812345678", "138****5678"),
+	desensitizeMethod("DesensitizeIDNumber",
+		"Mask the middle digits of an ID card number",
+		"ID card number", "110101199001011234", "11**************34"),
+	desensitizeMethod("DesensitizeEmail",
+		"Mask most of the user part of an email address",
+		"Email address", "user@example.com", "u***@example.com"),
+	desensitizeMethod("DesensitizeBankCard",
+		"Mask the middle digits of a bank card number",
+		"Bank card number", "6222021234567890123", "622202**********123"),
+	desensitizeMethod("DesensitizeAddress",
+		"Keep the province and city of an address and mask the rest",
+		"Postal address", "北京市朝阳区某某街道123号", "北京市朝阳区********"),
+	{
+		Name:        "Tokenize",
+		Description: "Replace a value with a deterministic token keyed by the host's tokenization_key secret",
+		Parameters: map[string]*common.ParamDescriptor{
+			"data": {
+				Type:        "string",
+				Description: "Value to tokenize",
+				Required:    true,
+			},
+		},
+		Returns: &common.ReturnDescriptor{
+			Type:        "string",
+			Description: "Hex-encoded HMAC-SHA256 of the value",
+		},
+	},
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/hashicorp/go-plugin"
 	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const RUN_PATH = "/Users/wenyanglu/Workspace/github/polyglot-plugin-showcase/host-server/bin/plugins/builtin/data-processing/desensitization/v1.0.0/darwin_arm64/plugin"
@@ -51,63 +52,63 @@ func main() {
 	fmt.Println("🧪 Data Desensitization Plugin Test:")
 
 	testName := "John Doe"
-	result, err := pluginInterface.Execute("DesensitizeName", map[string]string{"data": testName})
+	result, err := pluginInterface.Execute("DesensitizeName", newParams(map[string]any{"data": testName}))
 	if err != nil {
 		log.Printf("❌ Error desensitizing name: %v", err)
 	} else if !result.Success {
 		log.Printf("❌ Desensitize failed: %s", *result.Error)
 	} else {
-		fmt.Printf("👤 Name: \n  Original: %s\n  Desensitized: %s\n\n", testName, *result.Result)
+		fmt.Printf("👤 Name: \n  Original: %s\n  Desensitized: %v\n\n", testName, result.Result.AsInterface())
 	}
 
 	testPhone := "13812345678"
-	result, err = pluginInterface.Execute("DesensitizeTelNo", map[string]string{"data": testPhone})
+	result, err = pluginInterface.Execute("DesensitizeTelNo", newParams(map[string]any{"data": testPhone}))
 	if err != nil {
 		log.Printf("❌ Error desensitizing phone: %v", err)
 	} else if !result.Success {
 		log.Printf("❌ Desensitize failed: %s", *result.Error)
 	} else {
-		fmt.Printf("📞 Phone Number: \n  Original: %s\n  Desensitized: %s\n\n", testPhone, *result.Result)
+		fmt.Printf("📞 Phone Number: \n  Original: %s\n  Desensitized: %v\n\n", testPhone, result.Result.AsInterface())
 	}
 
 	testID := "110101199001011234"
-	result, err = pluginInterface.Execute("DesensitizeIDNumber", map[string]string{"data": testID})
+	result, err = pluginInterface.Execute("DesensitizeIDNumber", newParams(map[string]any{"data": testID}))
 	if err != nil {
 		log.Printf("❌ Error desensitizing ID: %v", err)
 	} else if !result.Success {
 		log.Printf("❌ Desensitize failed: %s", *result.Error)
 	} else {
-		fmt.Printf("🆔 ID Number: \n  Original: %s\n  Desensitized: %s\n\n", testID, *result.Result)
+		fmt.Printf("🆔 ID Number: \n  Original: %s\n  Desensitized: %v\n\n", testID, result.Result.AsInterface())
 	}
 
 	testEmail := "user@example.com"
-	result, err = pluginInterface.Execute("DesensitizeEmail", map[string]string{"data": testEmail})
+	result, err = pluginInterface.Execute("DesensitizeEmail", newParams(map[string]any{"data": testEmail}))
 	if err != nil {
 		log.Printf("❌ Error desensitizing email: %v", err)
 	} else if !result.Success {
 		log.Printf("❌ Desensitize failed: %s", *result.Error)
 	} else {
-		fmt.Printf("📧 Email: \n  Original: %s\n  Desensitized: %s\n\n", testEmail, *result.Result)
+		fmt.Printf("📧 Email: \n  Original: %s\n  Desensitized: %v\n\n", testEmail, result.Result.AsInterface())
 	}
 
 	testCard := "6222021234567890123"
-	result, err = pluginInterface.Execute("DesensitizeBankCard", map[string]string{"data": testCard})
+	result, err = pluginInterface.Execute("DesensitizeBankCard", newParams(map[string]any{"data": testCard}))
 	if err != nil {
 		log.Printf("❌ Error desensitizing bank card: %v", err)
 	} else if !result.Success {
 		log.Printf("❌ Desensitize failed: %s", *result.Error)
 	} else {
-		fmt.Printf("💳 Bank Card: \n  Original: %s\n  Desensitized: %s\n\n", testCard, *result.Result)
+		fmt.Printf("💳 Bank Card: \n  Original: %s\n  Desensitized: %v\n\n", testCard, result.Result.AsInterface())
 	}
 
 	testAddress := "123 Some Street, Chaoyang District, Beijing"
-	result, err = pluginInterface.Execute("DesensitizeAddress", map[string]string{"data": testAddress})
+	result, err = pluginInterface.Execute("DesensitizeAddress", newParams(map[string]any{"data": testAddress}))
 	if err != nil {
 		log.Printf("❌ Error desensitizing address: %v", err)
 	} else if !result.Success {
 		log.Printf("❌ Desensitize failed: %s", *result.Error)
 	} else {
-		fmt.Printf("🏠 Address: \n  Original: %s\n  Desensitized: %s\n\n", testAddress, *result.Result)
+		fmt.Printf("🏠 Address: \n  Original: %s\n  Desensitized: %v\n\n", testAddress, result.Result.AsInterface())
 	}
 
 	fmt.Println("✅ All tests completed.")
@@ -118,3 +119,12 @@ func main() {
 		fmt.Println("\n👋 Plugin process has exited.")
 	}
 }
+
+// newParams builds typed Execute parameters from a plain map
+func newParams(params map[string]any) *structpb.Struct {
+	s, err := structpb.NewStruct(params)
+	if err != nil {
+		log.Fatalf("Failed to build params: %v", err)
+	}
+	return s
+}
@@ -2,6 +2,9 @@
 package impl
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"regexp"
 	"strings"
@@ -167,3 +170,20 @@ func (d *DesensitzerImpl) DesensitizeAddress(address string) (string, error) {
 
 	return string(runes), nil
 }
+
+// Tokenize replaces a value with a deterministic token: the hex-encoded
+// HMAC-SHA256 of the value under key. Equal values map to equal tokens, so
+// tokenized data can still be joined, but the value cannot be recovered
+// without the key.
+func (d *DesensitzerImpl) Tokenize(key, data string) (string, error) {
+	if key == "" {
+		return "", errors.New("tokenization key cannot be empty")
+	}
+	if data == "" {
+		return "", errors.New("data cannot be empty")
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -212,3 +212,28 @@ func BenchmarkDesensitizeEmail(b *testing.B) {
 		_, _ = d.DesensitizeEmail("user@example.com")
 	}
 }
+
+func TestTokenize(t *testing.T) {
+	d := &DesensitzerImpl{}
+
+	token, err := d.Tokenize("secret", "13812345678")
+	if err != nil {
+		t.Fatalf("Tokenize() error = %v", err)
+	}
+	if len(token) != 64 {
+		t.Errorf("Tokenize() = %v, want a hex-encoded SHA-256", token)
+	}
+	if again, _ := d.Tokenize("secret", "13812345678"); again != token {
+		t.Errorf("Tokenize() = %v, want the same token %v for the same value", again, token)
+	}
+	if other, _ := d.Tokenize("other", "13812345678"); other == token {
+		t.Error("Tokenize() returned the same token under another key")
+	}
+
+	if _, err := d.Tokenize("", "13812345678"); err == nil {
+		t.Error("Tokenize() with empty key should fail")
+	}
+	if _, err := d.Tokenize("secret", ""); err == nil {
+		t.Error("Tokenize() with empty data should fail")
+	}
+}
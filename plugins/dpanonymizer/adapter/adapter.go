@@ -1,17 +1,18 @@
 package adapter
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"strconv"
 
 	"github.com/wylu1037/polyglot-plugin-showcase/plugins/dpanonymizer/impl"
 	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // DPAnonymizerAdapter adapts the differential privacy anonymizer implementation to the common plugin interface
 type DPAnonymizerAdapter struct {
 	impl *impl.DPAnonymizerImpl
+	host common.HostServices // nil when the host provides no services
 }
 
 func NewDPAnonymizerAdapter() *DPAnonymizerAdapter {
@@ -20,47 +21,64 @@ func NewDPAnonymizerAdapter() *DPAnonymizerAdapter {
 	}
 }
 
+// SetHost implements common.HostAwarePluginInterface
+func (a *DPAnonymizerAdapter) SetHost(host common.HostServices) {
+	a.host = host
+}
+
 func (a *DPAnonymizerAdapter) GetMetadata() (*common.MetadataResponse, error) {
+	return a.GetMetadataContext(context.Background())
+}
+
+func (a *DPAnonymizerAdapter) GetMetadataContext(ctx context.Context) (*common.MetadataResponse, error) {
 	return &common.MetadataResponse{
 		Name:        "dpanonymizer",
 		Version:     "1.0.0",
 		Description: "Differential Privacy Anonymization plugin using Google's DP library",
-		Methods: []string{
-			"AddLaplaceNoise",
-			"AddGaussianNoise",
-			"DPCount",
-			"DPSum",
-			"DPMean",
-			"DPVariance",
-		},
+		Methods:     common.MethodNames(methodDescriptors),
 		Capabilities: map[string]string{
 			"type":              "anonymization",
 			"privacy_mechanism": "differential_privacy",
 			"noise_types":       "laplace,gaussian",
 			"aggregations":      "count,sum,mean,variance",
 		},
-		ProtocolVersion: common.CurrentProtocolVersion,
+		ProtocolVersion:   common.CurrentProtocolVersion,
+		MethodDescriptors: methodDescriptors,
 	}, nil
 }
 
-func (a *DPAnonymizerAdapter) Execute(method string, params map[string]string) (*common.ExecuteResponse, error) {
-	var result string
+func (a *DPAnonymizerAdapter) Execute(method string, params *structpb.Struct) (*common.ExecuteResponse, error) {
+	return a.ExecuteContext(context.Background(), method, params)
+}
+
+func (a *DPAnonymizerAdapter) ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (*common.ExecuteResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	args := common.NewParams(params)
+
+	var result any
 	var err error
 
 	// Route to appropriate method
 	switch method {
 	case "AddLaplaceNoise":
-		result, err = a.executeLaplaceNoise(params)
+		result, err = a.executeLaplaceNoise(args)
 	case "AddGaussianNoise":
-		result, err = a.executeGaussianNoise(params)
+		result, err = a.executeGaussianNoise(args)
 	case "DPCount":
-		result, err = a.executeDPCount(params)
+		result, err = a.executeDPCount(args)
 	case "DPSum":
-		result, err = a.executeDPSum(params)
+		result, err = a.executeDPSum(args)
 	case "DPMean":
-		result, err = a.executeDPMean(params)
+		result, err = a.executeDPMean(args)
 	case "DPVariance":
-		result, err = a.executeDPVariance(params)
+		result, err = a.executeDPVariance(args)
+	case "SetPrivacyBudget":
+		result, err = a.executeSetPrivacyBudget(ctx, args)
+	case "GetPrivacyBudget":
+		result, err = a.executeGetPrivacyBudget(ctx, args)
 	default:
 		errMsg := fmt.Sprintf("unknown method: %s", method)
 		return &common.ExecuteResponse{
@@ -69,6 +87,24 @@ func (a *DPAnonymizerAdapter) Execute(method string, params map[string]string) (
 		}, nil
 	}
 
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	switch method {
+	case "AddLaplaceNoise", "AddGaussianNoise", "DPCount", "DPSum", "DPMean", "DPVariance":
+		// A result is only released once its epsilon is charged, so failed
+		// calls do not spend the budget
+		if err == nil {
+			err = a.chargeBudget(ctx, args)
+		}
+	}
+
+	var value *structpb.Value
+	if err == nil {
+		value, err = structpb.NewValue(result)
+	}
+
 	if err != nil {
 		errMsg := err.Error()
 		return &common.ExecuteResponse{
@@ -78,226 +114,160 @@ func (a *DPAnonymizerAdapter) Execute(method string, params map[string]string) (
 	}
 
 	return &common.ExecuteResponse{
-		Result:  &result,
+		Result:  value,
 		Success: true,
 	}, nil
 }
 
-func (a *DPAnonymizerAdapter) executeLaplaceNoise(params map[string]string) (string, error) {
-	value, err := strconv.ParseFloat(params["value"], 64)
+func (a *DPAnonymizerAdapter) executeLaplaceNoise(args common.Params) (float64, error) {
+	value, err := args.Float("value")
 	if err != nil {
-		return "", fmt.Errorf("invalid value parameter: %w", err)
+		return 0, err
 	}
 
-	epsilon, err := strconv.ParseFloat(params["epsilon"], 64)
+	epsilon, err := args.Float("epsilon")
 	if err != nil {
-		return "", fmt.Errorf("invalid epsilon parameter: %w", err)
+		return 0, err
 	}
 
-	sensitivity, err := strconv.ParseFloat(params["sensitivity"], 64)
+	sensitivity, err := args.Float("sensitivity")
 	if err != nil {
-		return "", fmt.Errorf("invalid sensitivity parameter: %w", err)
+		return 0, err
 	}
 
-	noisyValue, err := a.impl.AddLaplaceNoise(value, epsilon, sensitivity)
-	if err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("%.6f", noisyValue), nil
+	return a.impl.AddLaplaceNoise(value, epsilon, sensitivity)
 }
 
-func (a *DPAnonymizerAdapter) executeGaussianNoise(params map[string]string) (string, error) {
-	value, err := strconv.ParseFloat(params["value"], 64)
-	if err != nil {
-		return "", fmt.Errorf("invalid value parameter: %w", err)
-	}
-
-	epsilon, err := strconv.ParseFloat(params["epsilon"], 64)
+func (a *DPAnonymizerAdapter) executeGaussianNoise(args common.Params) (float64, error) {
+	value, err := args.Float("value")
 	if err != nil {
-		return "", fmt.Errorf("invalid epsilon parameter: %w", err)
+		return 0, err
 	}
 
-	delta, err := strconv.ParseFloat(params["delta"], 64)
+	epsilon, err := args.Float("epsilon")
 	if err != nil {
-		return "", fmt.Errorf("invalid delta parameter: %w", err)
+		return 0, err
 	}
 
-	sensitivity, err := strconv.ParseFloat(params["sensitivity"], 64)
+	delta, err := args.Float("delta")
 	if err != nil {
-		return "", fmt.Errorf("invalid sensitivity parameter: %w", err)
+		return 0, err
 	}
 
-	noisyValue, err := a.impl.AddGaussianNoise(value, epsilon, delta, sensitivity)
+	sensitivity, err := args.Float("sensitivity")
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	return fmt.Sprintf("%.6f", noisyValue), nil
+	return a.impl.AddGaussianNoise(value, epsilon, delta, sensitivity)
 }
 
-func (a *DPAnonymizerAdapter) executeDPCount(params map[string]string) (string, error) {
-	valuesJSON, ok := params["values"]
-	if !ok {
-		return "", fmt.Errorf("missing values parameter")
-	}
-
-	var values []float64
-	if err := json.Unmarshal([]byte(valuesJSON), &values); err != nil {
-		return "", fmt.Errorf("invalid values parameter: %w", err)
-	}
-
-	epsilon, err := strconv.ParseFloat(params["epsilon"], 64)
+func (a *DPAnonymizerAdapter) executeDPCount(args common.Params) (int64, error) {
+	values, err := args.FloatSlice("values")
 	if err != nil {
-		return "", fmt.Errorf("invalid epsilon parameter: %w", err)
+		return 0, err
 	}
 
-	delta, err := strconv.ParseFloat(params["delta"], 64)
+	epsilon, err := args.Float("epsilon")
 	if err != nil {
-		return "", fmt.Errorf("invalid delta parameter: %w", err)
+		return 0, err
 	}
 
-	maxPartitionsContributed, err := strconv.ParseInt(params["max_partitions_contributed"], 10, 64)
+	delta, err := args.Float("delta")
 	if err != nil {
-		return "", fmt.Errorf("invalid max_partitions_contributed parameter: %w", err)
+		return 0, err
 	}
 
-	count, err := a.impl.DPCount(values, epsilon, delta, maxPartitionsContributed)
+	maxPartitionsContributed, err := maxPartitionsContributed(args)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	return fmt.Sprintf("%d", count), nil
+	return a.impl.DPCount(values, epsilon, delta, maxPartitionsContributed)
 }
 
-func (a *DPAnonymizerAdapter) executeDPSum(params map[string]string) (string, error) {
-	valuesJSON, ok := params["values"]
-	if !ok {
-		return "", fmt.Errorf("missing values parameter")
-	}
-
-	var values []float64
-	if err := json.Unmarshal([]byte(valuesJSON), &values); err != nil {
-		return "", fmt.Errorf("invalid values parameter: %w", err)
-	}
-
-	epsilon, err := strconv.ParseFloat(params["epsilon"], 64)
-	if err != nil {
-		return "", fmt.Errorf("invalid epsilon parameter: %w", err)
-	}
-
-	delta, err := strconv.ParseFloat(params["delta"], 64)
-	if err != nil {
-		return "", fmt.Errorf("invalid delta parameter: %w", err)
-	}
-
-	lowerBound, err := strconv.ParseFloat(params["lower_bound"], 64)
-	if err != nil {
-		return "", fmt.Errorf("invalid lower_bound parameter: %w", err)
+// maxPartitionsContributed falls back to the declared default of 1 when omitted
+func maxPartitionsContributed(args common.Params) (int64, error) {
+	if !args.Has("max_partitions_contributed") {
+		return 1, nil
 	}
-
-	upperBound, err := strconv.ParseFloat(params["upper_bound"], 64)
-	if err != nil {
-		return "", fmt.Errorf("invalid upper_bound parameter: %w", err)
-	}
-
-	maxPartitionsContributed, err := strconv.ParseInt(params["max_partitions_contributed"], 10, 64)
-	if err != nil {
-		return "", fmt.Errorf("invalid max_partitions_contributed parameter: %w", err)
-	}
-
-	sum, err := a.impl.DPSum(values, epsilon, delta, lowerBound, upperBound, maxPartitionsContributed)
-	if err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("%.6f", sum), nil
+	return args.Int("max_partitions_contributed")
 }
 
-func (a *DPAnonymizerAdapter) executeDPMean(params map[string]string) (string, error) {
-	valuesJSON, ok := params["values"]
-	if !ok {
-		return "", fmt.Errorf("missing values parameter")
-	}
-
-	var values []float64
-	if err := json.Unmarshal([]byte(valuesJSON), &values); err != nil {
-		return "", fmt.Errorf("invalid values parameter: %w", err)
-	}
+// boundedParams holds the parameters shared by the bounded aggregations
+type boundedParams struct {
+	values                   []float64
+	epsilon                  float64
+	delta                    float64
+	lowerBound               float64
+	upperBound               float64
+	maxPartitionsContributed int64
+}
 
-	epsilon, err := strconv.ParseFloat(params["epsilon"], 64)
+func parseBoundedParams(args common.Params) (*boundedParams, error) {
+	values, err := args.FloatSlice("values")
 	if err != nil {
-		return "", fmt.Errorf("invalid epsilon parameter: %w", err)
+		return nil, err
 	}
 
-	delta, err := strconv.ParseFloat(params["delta"], 64)
+	epsilon, err := args.Float("epsilon")
 	if err != nil {
-		return "", fmt.Errorf("invalid delta parameter: %w", err)
+		return nil, err
 	}
 
-	lowerBound, err := strconv.ParseFloat(params["lower_bound"], 64)
+	delta, err := args.Float("delta")
 	if err != nil {
-		return "", fmt.Errorf("invalid lower_bound parameter: %w", err)
+		return nil, err
 	}
 
-	upperBound, err := strconv.ParseFloat(params["upper_bound"], 64)
+	lowerBound, err := args.Float("lower_bound")
 	if err != nil {
-		return "", fmt.Errorf("invalid upper_bound parameter: %w", err)
+		return nil, err
 	}
 
-	maxPartitionsContributed, err := strconv.ParseInt(params["max_partitions_contributed"], 10, 64)
+	upperBound, err := args.Float("upper_bound")
 	if err != nil {
-		return "", fmt.Errorf("invalid max_partitions_contributed parameter: %w", err)
+		return nil, err
 	}
 
-	mean, err := a.impl.DPMean(values, epsilon, delta, lowerBound, upperBound, maxPartitionsContributed)
+	maxPartitionsContributed, err := maxPartitionsContributed(args)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("%.6f", mean), nil
+	return &boundedParams{
+		values:                   values,
+		epsilon:                  epsilon,
+		delta:                    delta,
+		lowerBound:               lowerBound,
+		upperBound:               upperBound,
+		maxPartitionsContributed: maxPartitionsContributed,
+	}, nil
 }
 
-func (a *DPAnonymizerAdapter) executeDPVariance(params map[string]string) (string, error) {
-	valuesJSON, ok := params["values"]
-	if !ok {
-		return "", fmt.Errorf("missing values parameter")
-	}
-
-	var values []float64
-	if err := json.Unmarshal([]byte(valuesJSON), &values); err != nil {
-		return "", fmt.Errorf("invalid values parameter: %w", err)
-	}
-
-	epsilon, err := strconv.ParseFloat(params["epsilon"], 64)
-	if err != nil {
-		return "", fmt.Errorf("invalid epsilon parameter: %w", err)
-	}
-
-	delta, err := strconv.ParseFloat(params["delta"], 64)
+func (a *DPAnonymizerAdapter) executeDPSum(args common.Params) (float64, error) {
+	p, err := parseBoundedParams(args)
 	if err != nil {
-		return "", fmt.Errorf("invalid delta parameter: %w", err)
+		return 0, err
 	}
 
-	lowerBound, err := strconv.ParseFloat(params["lower_bound"], 64)
-	if err != nil {
-		return "", fmt.Errorf("invalid lower_bound parameter: %w", err)
-	}
+	return a.impl.DPSum(p.values, p.epsilon, p.delta, p.lowerBound, p.upperBound, p.maxPartitionsContributed)
+}
 
-	upperBound, err := strconv.ParseFloat(params["upper_bound"], 64)
+func (a *DPAnonymizerAdapter) executeDPMean(args common.Params) (float64, error) {
+	p, err := parseBoundedParams(args)
 	if err != nil {
-		return "", fmt.Errorf("invalid upper_bound parameter: %w", err)
+		return 0, err
 	}
 
-	maxPartitionsContributed, err := strconv.ParseInt(params["max_partitions_contributed"], 10, 64)
-	if err != nil {
-		return "", fmt.Errorf("invalid max_partitions_contributed parameter: %w", err)
-	}
+	return a.impl.DPMean(p.values, p.epsilon, p.delta, p.lowerBound, p.upperBound, p.maxPartitionsContributed)
+}
 
-	variance, err := a.impl.DPVariance(values, epsilon, delta, lowerBound, upperBound, maxPartitionsContributed)
+func (a *DPAnonymizerAdapter) executeDPVariance(args common.Params) (float64, error) {
+	p, err := parseBoundedParams(args)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	return fmt.Sprintf("%.6f", variance), nil
+	return a.impl.DPVariance(p.values, p.epsilon, p.delta, p.lowerBound, p.upperBound, p.maxPartitionsContributed)
 }
@@ -0,0 +1,92 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Results are noisy, so examples are only checked for success and result kind
+func TestDPAnonymizerAdapter_MethodExamples(t *testing.T) {
+	a := NewDPAnonymizerAdapter()
+
+	metadata, err := a.GetMetadataContext(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetadataContext() error = %v", err)
+	}
+
+	for _, method := range metadata.MethodDescriptors {
+		for _, example := range method.Examples {
+			t.Run(method.Name, func(t *testing.T) {
+				resp, err := a.ExecuteContext(context.Background(), method.Name, example.Params)
+				if err != nil {
+					t.Fatalf("ExecuteContext() error = %v", err)
+				}
+				if !resp.Success {
+					t.Fatalf("ExecuteContext() failed: %s", resp.GetError())
+				}
+				if _, ok := resp.Result.GetKind().(*structpb.Value_NumberValue); !ok {
+					t.Errorf("ExecuteContext() result = %v, want a number", resp.Result)
+				}
+			})
+		}
+	}
+}
+
+func TestDPAnonymizerAdapter_PrivacyBudget(t *testing.T) {
+	ctx := context.Background()
+	a := NewDPAnonymizerAdapter()
+	a.SetHost(&common.MemoryHost{})
+
+	noise := func(budget string) *common.ExecuteResponse {
+		resp, err := a.ExecuteContext(ctx, "AddLaplaceNoise", common.MustStruct(map[string]any{
+			"value": 100.0, "epsilon": 0.4, "sensitivity": 1.0, "budget": budget,
+		}))
+		if err != nil {
+			t.Fatalf("ExecuteContext() error = %v", err)
+		}
+		return resp
+	}
+
+	if resp := noise("reports"); resp.Success {
+		t.Error("charging an unknown budget should fail")
+	}
+
+	resp, err := a.ExecuteContext(ctx, "SetPrivacyBudget", common.MustStruct(map[string]any{"budget": "reports", "total": 1.0}))
+	if err != nil || !resp.Success {
+		t.Fatalf("SetPrivacyBudget = %v, %v", resp, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if resp := noise("reports"); !resp.Success {
+			t.Fatalf("call %d within the budget failed: %s", i+1, resp.GetError())
+		}
+	}
+	if resp := noise("reports"); resp.Success {
+		t.Error("call beyond the budget should fail")
+	}
+
+	resp, err = a.ExecuteContext(ctx, "GetPrivacyBudget", common.MustStruct(map[string]any{"budget": "reports"}))
+	if err != nil || !resp.Success {
+		t.Fatalf("GetPrivacyBudget = %v, %v", resp, err)
+	}
+	fields := resp.Result.GetStructValue().GetFields()
+	if spent := fields["spent"].GetNumberValue(); spent < 0.79 || spent > 0.81 {
+		t.Errorf("spent = %v, want 0.8", spent)
+	}
+}
+
+func TestDPAnonymizerAdapter_BudgetRequiresHost(t *testing.T) {
+	a := NewDPAnonymizerAdapter()
+	resp, err := a.ExecuteContext(context.Background(), "AddLaplaceNoise", common.MustStruct(map[string]any{
+		"value": 100.0, "epsilon": 1.0, "sensitivity": 1.0, "budget": "reports",
+	}))
+	if err != nil {
+		t.Fatalf("ExecuteContext() error = %v", err)
+	}
+	if resp.Success {
+		t.Error("charging a budget without host services should fail")
+	}
+}
@@ -0,0 +1,160 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+// privacyBudget is the epsilon a named budget allows in total and has spent,
+// stored as JSON in the host state under budgetKey
+type privacyBudget struct {
+	Total float64 `json:"total"`
+	Spent float64 `json:"spent"`
+}
+
+// maxBudgetAttempts bounds the retries of a budget update that keeps losing
+// the race against other processes of the plugin
+const maxBudgetAttempts = 10
+
+func budgetKey(name string) string {
+	return "budget/" + name
+}
+
+// updateBudget applies update to the stored budget with compare-and-set, so
+// concurrent processes of the plugin never overspend. update receives nil for
+// a budget that does not exist yet.
+func (a *DPAnonymizerAdapter) updateBudget(ctx context.Context, name string, update func(*privacyBudget) (*privacyBudget, error)) (*privacyBudget, error) {
+	if a.host == nil {
+		return nil, errors.New("privacy budgets require host services")
+	}
+	if name == "" {
+		return nil, errors.New("budget name cannot be empty")
+	}
+
+	for attempt := 0; attempt < maxBudgetAttempts; attempt++ {
+		current, found, err := a.host.GetState(ctx, budgetKey(name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read budget '%s': %w", name, err)
+		}
+
+		var budget *privacyBudget
+		if found {
+			budget = &privacyBudget{}
+			if err := json.Unmarshal(current, budget); err != nil {
+				return nil, fmt.Errorf("budget '%s' is corrupt: %w", name, err)
+			}
+		}
+
+		updated, err := update(budget)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(updated)
+		if err != nil {
+			return nil, err
+		}
+
+		written, err := a.host.CompareAndSetState(ctx, budgetKey(name), current, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write budget '%s': %w", name, err)
+		}
+		if written {
+			return updated, nil
+		}
+	}
+	return nil, fmt.Errorf("budget '%s' is updated concurrently, try again", name)
+}
+
+// chargeBudget spends epsilon of the budget named by the optional "budget"
+// parameter. Calls without it are not accounted.
+func (a *DPAnonymizerAdapter) chargeBudget(ctx context.Context, args common.Params) error {
+	if !args.Has("budget") {
+		return nil
+	}
+	name, err := args.String("budget")
+	if err != nil {
+		return err
+	}
+	epsilon, err := args.Float("epsilon")
+	if err != nil {
+		return err
+	}
+	if epsilon <= 0 {
+		return errors.New("epsilon must be positive")
+	}
+
+	_, err = a.updateBudget(ctx, name, func(budget *privacyBudget) (*privacyBudget, error) {
+		if budget == nil {
+			return nil, fmt.Errorf("unknown privacy budget '%s'", name)
+		}
+		if budget.Spent+epsilon > budget.Total {
+			return nil, fmt.Errorf("privacy budget '%s' exhausted: %g of %g spent, %g requested",
+				name, budget.Spent, budget.Total, epsilon)
+		}
+		return &privacyBudget{Total: budget.Total, Spent: budget.Spent + epsilon}, nil
+	})
+	return err
+}
+
+// executeSetPrivacyBudget creates a budget or changes its total; the epsilon
+// already spent is kept
+func (a *DPAnonymizerAdapter) executeSetPrivacyBudget(ctx context.Context, args common.Params) (map[string]any, error) {
+	name, err := args.String("budget")
+	if err != nil {
+		return nil, err
+	}
+	total, err := args.Float("total")
+	if err != nil {
+		return nil, err
+	}
+	if total < 0 {
+		return nil, errors.New("total must not be negative")
+	}
+
+	budget, err := a.updateBudget(ctx, name, func(budget *privacyBudget) (*privacyBudget, error) {
+		if budget == nil {
+			return &privacyBudget{Total: total}, nil
+		}
+		return &privacyBudget{Total: total, Spent: budget.Spent}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return budgetResult(budget), nil
+}
+
+func (a *DPAnonymizerAdapter) executeGetPrivacyBudget(ctx context.Context, args common.Params) (map[string]any, error) {
+	if a.host == nil {
+		return nil, errors.New("privacy budgets require host services")
+	}
+	name, err := args.String("budget")
+	if err != nil {
+		return nil, err
+	}
+
+	value, found, err := a.host.GetState(ctx, budgetKey(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget '%s': %w", name, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown privacy budget '%s'", name)
+	}
+
+	var budget privacyBudget
+	if err := json.Unmarshal(value, &budget); err != nil {
+		return nil, fmt.Errorf("budget '%s' is corrupt: %w", name, err)
+	}
+	return budgetResult(&budget), nil
+}
+
+func budgetResult(budget *privacyBudget) map[string]any {
+	return map[string]any{
+		"total":     budget.Total,
+		"spent":     budget.Spent,
+		"remaining": budget.Total - budget.Spent,
+	}
+}
@@ -0,0 +1,188 @@
+package adapter
+
+import (
+	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+)
+
+// Parameters shared by the differentially private methods
+var (
+	epsilonParam = &common.ParamDescriptor{
+		Type:        "number",
+		Description: "Privacy budget; smaller values add more noise",
+		Required:    true,
+	}
+	deltaParam = &common.ParamDescriptor{
+		Type:        "number",
+		Description: "Probability of exceeding the epsilon guarantee, between 0 and 1 (e.g. 1e-5)",
+		Required:    true,
+	}
+	sensitivityParam = &common.ParamDescriptor{
+		Type:        "number",
+		Description: "Maximum change one individual can cause in the value",
+		Required:    true,
+	}
+	valuesParam = &common.ParamDescriptor{
+		Type:        "array",
+		Description: "Numeric values to aggregate",
+		Required:    true,
+	}
+	lowerBoundParam = &common.ParamDescriptor{
+		Type:        "number",
+		Description: "Values below this bound are clamped to it",
+		Required:    true,
+	}
+	upperBoundParam = &common.ParamDescriptor{
+		Type:        "number",
+		Description: "Values above this bound are clamped to it",
+		Required:    true,
+	}
+	maxPartitionsParam = &common.ParamDescriptor{
+		Type:        "int",
+		Description: "Maximum number of partitions one individual contributes to",
+		Default:     common.MustValue(1),
+	}
+	budgetParam = &common.ParamDescriptor{
+		Type:        "string",
+		Description: "Privacy budget to charge epsilon against; the call fails once it is exhausted",
+	}
+	budgetNameParam = &common.ParamDescriptor{
+		Type:        "string",
+		Description: "Name of the privacy budget",
+		Required:    true,
+	}
+	budgetReturns = &common.ReturnDescriptor{
+		Type:        "object",
+		Description: "Total, spent and remaining epsilon of the budget",
+	}
+)
+
+// boundedMethod describes an aggregation over clamped values
+func boundedMethod(name, description, returns string, result float64) *common.MethodDescriptor {
+	return &common.MethodDescriptor{
+		Name:        name,
+		Description: description,
+		Parameters: map[string]*common.ParamDescriptor{
+			"values":                     valuesParam,
+			"epsilon":                    epsilonParam,
+			"delta":                      deltaParam,
+			"lower_bound":                lowerBoundParam,
+			"upper_bound":                upperBoundParam,
+			"max_partitions_contributed": maxPartitionsParam,
+			"budget":                     budgetParam,
+		},
+		Returns: &common.ReturnDescriptor{
+			Type:        "number",
+			Description: returns,
+		},
+		Examples: []*common.MethodExample{
+			{
+				Params: common.MustStruct(map[string]any{
+					"values":      []any{10.0, 20.0, 30.0, 40.0, 50.0},
+					"epsilon":     1.0,
+					"delta":       1e-5,
+					"lower_bound": 0.0,
+					"upper_bound": 100.0,
+				}),
+				Result: common.MustValue(result),
+			},
+		},
+	}
+}
+
+var methodDescriptors = []*common.MethodDescriptor{
+	{
+		Name:        "AddLaplaceNoise",
+		Description: "Add Laplace noise to a single value (pure epsilon-DP)",
+		Parameters: map[string]*common.ParamDescriptor{
+			"value":       {Type: "number", Description: "Original value", Required: true},
+			"epsilon":     epsilonParam,
+			"sensitivity": sensitivityParam,
+			"budget":      budgetParam,
+		},
+		Returns: &common.ReturnDescriptor{
+			Type:        "number",
+			Description: "Noisy value",
+		},
+		Examples: []*common.MethodExample{
+			{
+				Params: common.MustStruct(map[string]any{
+					"value":       100.0,
+					"epsilon":     1.0,
+					"sensitivity": 1.0,
+				}),
+				Result: common.MustValue(100.73),
+			},
+		},
+	},
+	{
+		Name:        "AddGaussianNoise",
+		Description: "Add Gaussian noise to a single value ((epsilon, delta)-DP)",
+		Parameters: map[string]*common.ParamDescriptor{
+			"value":       {Type: "number", Description: "Original value", Required: true},
+			"epsilon":     epsilonParam,
+			"delta":       deltaParam,
+			"sensitivity": sensitivityParam,
+			"budget":      budgetParam,
+		},
+		Returns: &common.ReturnDescriptor{
+			Type:        "number",
+			Description: "Noisy value",
+		},
+		Examples: []*common.MethodExample{
+			{
+				Params: common.MustStruct(map[string]any{
+					"value":       100.0,
+					"epsilon":     1.0,
+					"delta":       1e-5,
+					"sensitivity": 1.0,
+				}),
+				Result: common.MustValue(98.41),
+			},
+		},
+	},
+	{
+		Name:        "DPCount",
+		Description: "Differentially private count of the values",
+		Parameters: map[string]*common.ParamDescriptor{
+			"values":                     valuesParam,
+			"epsilon":                    epsilonParam,
+			"delta":                      deltaParam,
+			"max_partitions_contributed": maxPartitionsParam,
+			"budget":                     budgetParam,
+		},
+		Returns: &common.ReturnDescriptor{
+			Type:        "int",
+			Description: "Noisy count",
+		},
+		Examples: []*common.MethodExample{
+			{
+				Params: common.MustStruct(map[string]any{
+					"values":  []any{1.0, 2.0, 3.0, 4.0, 5.0},
+					"epsilon": 1.0,
+					"delta":   1e-5,
+				}),
+				Result: common.MustValue(6),
+			},
+		},
+	},
+	boundedMethod("DPSum", "Differentially private sum of the clamped values", "Noisy sum", 152.8),
+	boundedMethod("DPMean", "Differentially private mean of the clamped values", "Noisy mean", 29.6),
+	boundedMethod("DPVariance", "Differentially private variance of the clamped values", "Noisy variance", 214.3),
+	{
+		Name:        "SetPrivacyBudget",
+		Description: "Create a privacy budget or change its total epsilon; epsilon already spent is kept",
+		Parameters: map[string]*common.ParamDescriptor{
+			"budget": budgetNameParam,
+			"total":  {Type: "number", Description: "Total epsilon the budget allows", Required: true},
+		},
+		Returns: budgetReturns,
+	},
+	{
+		Name:        "GetPrivacyBudget",
+		Description: "Report the epsilon a privacy budget has spent and has left",
+		Parameters: map[string]*common.ParamDescriptor{
+			"budget": budgetNameParam,
+		},
+		Returns: budgetReturns,
+	},
+}
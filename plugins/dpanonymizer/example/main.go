@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -10,6 +9,7 @@ import (
 
 	"github.com/hashicorp/go-plugin"
 	"github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const RUN_PATH = "/Users/wenyanglu/Workspace/github/polyglot-plugin-showcase/host-server/bin/plugins/builtin/data-processing/dpanonymizer/v1.0.0/darwin_arm64/plugin"
@@ -77,15 +77,15 @@ func main() {
 	fmt.Println("Epsilon: 1.0 (privacy budget)")
 	fmt.Println("Sensitivity: 1.0")
 
-	laplaceResult, err := dpPlugin.Execute("AddLaplaceNoise", map[string]string{
-		"value":       "100.0",
-		"epsilon":     "1.0",
-		"sensitivity": "1.0",
-	})
+	laplaceResult, err := dpPlugin.Execute("AddLaplaceNoise", newParams(map[string]any{
+		"value":       100.0,
+		"epsilon":     1.0,
+		"sensitivity": 1.0,
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if laplaceResult.Success {
-		fmt.Printf("Noisy value: %s\n\n", *laplaceResult.Result)
+		fmt.Printf("Noisy value: %v\n\n", laplaceResult.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *laplaceResult.Error)
 	}
@@ -96,16 +96,16 @@ func main() {
 	fmt.Println("Epsilon: 1.0, Delta: 1e-5")
 	fmt.Println("Sensitivity: 1.0")
 
-	gaussianResult, err := dpPlugin.Execute("AddGaussianNoise", map[string]string{
-		"value":       "100.0",
-		"epsilon":     "1.0",
-		"delta":       "0.00001",
-		"sensitivity": "1.0",
-	})
+	gaussianResult, err := dpPlugin.Execute("AddGaussianNoise", newParams(map[string]any{
+		"value":       100.0,
+		"epsilon":     1.0,
+		"delta":       0.00001,
+		"sensitivity": 1.0,
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if gaussianResult.Success {
-		fmt.Printf("Noisy value: %s\n\n", *gaussianResult.Result)
+		fmt.Printf("Noisy value: %v\n\n", gaussianResult.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *gaussianResult.Error)
 	}
@@ -113,20 +113,19 @@ func main() {
 	// Example 3: Differentially Private Count
 	fmt.Println("=== Example 3: Differentially Private Count ===")
 	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
-	valuesJSON, _ := json.Marshal(values)
 	fmt.Printf("Counting %d values\n", len(values))
 	fmt.Println("Epsilon: 1.0, Delta: 1e-5")
 
-	countResult, err := dpPlugin.Execute("DPCount", map[string]string{
-		"values":                     string(valuesJSON),
-		"epsilon":                    "1.0",
-		"delta":                      "0.00001",
-		"max_partitions_contributed": "1",
-	})
+	countResult, err := dpPlugin.Execute("DPCount", newParams(map[string]any{
+		"values":                     numberList(values),
+		"epsilon":                    1.0,
+		"delta":                      0.00001,
+		"max_partitions_contributed": 1,
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if countResult.Success {
-		fmt.Printf("Noisy count: %s (actual: %d)\n\n", *countResult.Result, len(values))
+		fmt.Printf("Noisy count: %v (actual: %d)\n\n", countResult.Result.AsInterface(), len(values))
 	} else {
 		fmt.Printf("Error: %s\n\n", *countResult.Error)
 	}
@@ -134,7 +133,6 @@ func main() {
 	// Example 4: Differentially Private Sum
 	fmt.Println("=== Example 4: Differentially Private Sum ===")
 	sumValues := []float64{10.0, 20.0, 30.0, 40.0, 50.0}
-	sumValuesJSON, _ := json.Marshal(sumValues)
 	actualSum := 0.0
 	for _, v := range sumValues {
 		actualSum += v
@@ -143,18 +141,18 @@ func main() {
 	fmt.Println("Epsilon: 1.0, Delta: 1e-5")
 	fmt.Println("Bounds: [0, 100]")
 
-	sumResult, err := dpPlugin.Execute("DPSum", map[string]string{
-		"values":                     string(sumValuesJSON),
-		"epsilon":                    "1.0",
-		"delta":                      "0.00001",
-		"lower_bound":                "0.0",
-		"upper_bound":                "100.0",
-		"max_partitions_contributed": "1",
-	})
+	sumResult, err := dpPlugin.Execute("DPSum", newParams(map[string]any{
+		"values":                     numberList(sumValues),
+		"epsilon":                    1.0,
+		"delta":                      0.00001,
+		"lower_bound":                0.0,
+		"upper_bound":                100.0,
+		"max_partitions_contributed": 1,
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if sumResult.Success {
-		fmt.Printf("Noisy sum: %s\n\n", *sumResult.Result)
+		fmt.Printf("Noisy sum: %v\n\n", sumResult.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *sumResult.Error)
 	}
@@ -162,7 +160,6 @@ func main() {
 	// Example 5: Differentially Private Mean
 	fmt.Println("=== Example 5: Differentially Private Mean ===")
 	meanValues := []float64{10.0, 20.0, 30.0, 40.0, 50.0}
-	meanValuesJSON, _ := json.Marshal(meanValues)
 	actualMean := 0.0
 	for _, v := range meanValues {
 		actualMean += v
@@ -172,18 +169,18 @@ func main() {
 	fmt.Println("Epsilon: 1.0, Delta: 1e-5")
 	fmt.Println("Bounds: [0, 100]")
 
-	meanResult, err := dpPlugin.Execute("DPMean", map[string]string{
-		"values":                     string(meanValuesJSON),
-		"epsilon":                    "1.0",
-		"delta":                      "0.00001",
-		"lower_bound":                "0.0",
-		"upper_bound":                "100.0",
-		"max_partitions_contributed": "1",
-	})
+	meanResult, err := dpPlugin.Execute("DPMean", newParams(map[string]any{
+		"values":                     numberList(meanValues),
+		"epsilon":                    1.0,
+		"delta":                      0.00001,
+		"lower_bound":                0.0,
+		"upper_bound":                100.0,
+		"max_partitions_contributed": 1,
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if meanResult.Success {
-		fmt.Printf("Noisy mean: %s\n\n", *meanResult.Result)
+		fmt.Printf("Noisy mean: %v\n\n", meanResult.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *meanResult.Error)
 	}
@@ -191,7 +188,6 @@ func main() {
 	// Example 6: Differentially Private Variance
 	fmt.Println("=== Example 6: Differentially Private Variance ===")
 	varianceValues := []float64{10.0, 20.0, 30.0, 40.0, 50.0}
-	varianceValuesJSON, _ := json.Marshal(varianceValues)
 
 	// Calculate actual variance
 	mean := 0.0
@@ -209,21 +205,39 @@ func main() {
 	fmt.Println("Epsilon: 1.0, Delta: 1e-5")
 	fmt.Println("Bounds: [0, 100]")
 
-	varianceResult, err := dpPlugin.Execute("DPVariance", map[string]string{
-		"values":                     string(varianceValuesJSON),
-		"epsilon":                    "1.0",
-		"delta":                      "0.00001",
-		"lower_bound":                "0.0",
-		"upper_bound":                "100.0",
-		"max_partitions_contributed": "1",
-	})
+	varianceResult, err := dpPlugin.Execute("DPVariance", newParams(map[string]any{
+		"values":                     numberList(varianceValues),
+		"epsilon":                    1.0,
+		"delta":                      0.00001,
+		"lower_bound":                0.0,
+		"upper_bound":                100.0,
+		"max_partitions_contributed": 1,
+	}))
 	if err != nil {
 		log.Printf("Error: %v\n", err)
 	} else if varianceResult.Success {
-		fmt.Printf("Noisy variance: %s\n\n", *varianceResult.Result)
+		fmt.Printf("Noisy variance: %v\n\n", varianceResult.Result.AsInterface())
 	} else {
 		fmt.Printf("Error: %s\n\n", *varianceResult.Error)
 	}
 
 	fmt.Println("=== All examples completed ===")
 }
+
+// newParams builds typed Execute parameters from a plain map
+func newParams(params map[string]any) *structpb.Struct {
+	s, err := structpb.NewStruct(params)
+	if err != nil {
+		log.Fatalf("Failed to build params: %v", err)
+	}
+	return s
+}
+
+// numberList converts values into the []any form structpb expects for a list
+func numberList(values []float64) []any {
+	list := make([]any, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	return list
+}
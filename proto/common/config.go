@@ -0,0 +1,154 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrConfigureUnsupported is returned when the plugin predates protocol v4
+// or does not implement ConfigurablePluginInterface.
+var ErrConfigureUnsupported = errors.New("plugin does not support configuration")
+
+// ConfigurablePluginInterface is implemented by plugins that take settings
+// from the host. Plugins implement it next to PluginInterface and declare the
+// settings they accept in MetadataResponse.ConfigSchema; the host-side
+// GRPCClient implements it as well.
+type ConfigurablePluginInterface interface {
+	// Configure replaces the plugin's settings. Every call carries the
+	// complete settings, so settings missing from config revert to the
+	// plugin's defaults. An invalid config must leave the current one in place.
+	Configure(ctx context.Context, config *structpb.Struct) error
+}
+
+// Configure sends the plugin its settings
+func (m *GRPCClient) Configure(ctx context.Context, config *structpb.Struct) (err error) {
+	if m.protocolVersion < ConfigurableProtocolVersion {
+		return ErrConfigureUnsupported
+	}
+	ctx, span := startClientSpan(ctx, Plugin_Configure_FullMethodName)
+	defer func() { endSpan(span, err) }()
+
+	if config == nil {
+		config = &structpb.Struct{}
+	}
+
+	resp, err := m.client.Configure(ctx, &ConfigureRequest{Config: config})
+	if status.Code(err) == codes.Unimplemented {
+		return ErrConfigureUnsupported
+	}
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.GetError())
+	}
+	return nil
+}
+
+// Configure hands the settings to Impl. A rejected config is reported in the
+// response rather than as a gRPC error, like a failed Execute.
+func (m *GRPCServer) Configure(ctx context.Context, req *ConfigureRequest) (*ConfigureResponse, error) {
+	impl, ok := m.Impl.(ConfigurablePluginInterface)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, ErrConfigureUnsupported.Error())
+	}
+
+	ctx, span := startServerSpan(ctx, Plugin_Configure_FullMethodName)
+	err := impl.Configure(ctx, req.Config)
+	endSpan(span, err)
+	if err != nil {
+		errMsg := err.Error()
+		return &ConfigureResponse{Success: false, Error: &errMsg}, nil
+	}
+	return &ConfigureResponse{Success: true}, nil
+}
+
+// WithDefaults returns params with the settings in defaults added for every
+// parameter the call leaves out. defaults maps parameter names to settings
+// names; parameters passed with the call always win.
+func WithDefaults(params *structpb.Struct, settings *structpb.Struct, defaults map[string]string) *structpb.Struct {
+	merged := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(params.GetFields())+len(defaults))}
+	for param, setting := range defaults {
+		if value, ok := settings.GetFields()[setting]; ok {
+			merged.Fields[param] = value
+		}
+	}
+	for key, value := range params.GetFields() {
+		if _, isNull := value.GetKind().(*structpb.Value_NullValue); isNull {
+			if _, hasDefault := merged.Fields[key]; hasDefault {
+				continue
+			}
+		}
+		merged.Fields[key] = value
+	}
+	return merged
+}
+
+// ValidateConfig checks settings against the schema a plugin declares in
+// MetadataResponse.ConfigSchema: every setting must be declared and of the
+// declared type, and required settings must be present.
+func ValidateConfig(schema map[string]*ParamDescriptor, config *structpb.Struct) error {
+	var problems []string
+
+	for key, value := range config.GetFields() {
+		descriptor, ok := schema[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown setting '%s'", key))
+			continue
+		}
+		if !valueMatchesType(descriptor.GetType(), value) {
+			problems = append(problems, fmt.Sprintf("setting '%s' must be of type %s", key, descriptor.GetType()))
+		}
+	}
+	for name, descriptor := range schema {
+		if _, ok := config.GetFields()[name]; descriptor.GetRequired() && !ok {
+			problems = append(problems, fmt.Sprintf("missing required setting '%s'", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func valueMatchesType(schemaType string, value *structpb.Value) bool {
+	switch schemaType {
+	case "string", "int", "integer", "number", "float", "bool", "boolean", "object", "array":
+	default:
+		// Unknown or "any" types are left for the plugin to check
+		return true
+	}
+
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_NullValue:
+		return true
+	case *structpb.Value_StringValue:
+		return schemaType == "string"
+	case *structpb.Value_NumberValue:
+		switch schemaType {
+		case "int", "integer":
+			return kind.NumberValue == math.Trunc(kind.NumberValue)
+		case "number", "float":
+			return true
+		}
+		return false
+	case *structpb.Value_BoolValue:
+		return schemaType == "bool" || schemaType == "boolean"
+	case *structpb.Value_StructValue:
+		return schemaType == "object"
+	case *structpb.Value_ListValue:
+		return schemaType == "array"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,37 @@
+package common
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// MethodNames returns the names of the described methods, in order,
+// for filling MetadataResponse.Methods alongside MethodDescriptors
+func MethodNames(descriptors []*MethodDescriptor) []string {
+	names := make([]string, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		names = append(names, descriptor.GetName())
+	}
+	return names
+}
+
+// MustStruct converts a literal map to a Struct and panics if a value cannot
+// be represented. It is meant for static descriptor and example literals.
+func MustStruct(fields map[string]any) *structpb.Struct {
+	s, err := structpb.NewStruct(fields)
+	if err != nil {
+		panic(fmt.Sprintf("invalid struct literal: %v", err))
+	}
+	return s
+}
+
+// MustValue converts a literal to a Value and panics if it cannot be represented.
+// It is meant for static descriptor and example literals.
+func MustValue(v any) *structpb.Value {
+	value, err := structpb.NewValue(v)
+	if err != nil {
+		panic(fmt.Sprintf("invalid value literal: %v", err))
+	}
+	return value
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/hashicorp/go-plugin"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const (
@@ -14,11 +15,23 @@ const (
 
 	// CurrentProtocolVersion is the current protocol version.
 	// New plugins should use this version.
-	CurrentProtocolVersion = 1
+	CurrentProtocolVersion = 5
 
 	// MaxSupportedProtocolVersion is the maximum protocol version we support.
 	// This allows forward compatibility with newer plugins.
-	MaxSupportedProtocolVersion = 1
+	MaxSupportedProtocolVersion = 5
+
+	// TypedProtocolVersion is the first protocol version with typed parameters and results.
+	TypedProtocolVersion = 2
+
+	// StreamingProtocolVersion is the first protocol version with ExecuteStream.
+	StreamingProtocolVersion = 3
+
+	// ConfigurableProtocolVersion is the first protocol version with Configure.
+	ConfigurableProtocolVersion = 4
+
+	// HostServicesProtocolVersion is the first protocol version with ConnectHost.
+	HostServicesProtocolVersion = 5
 
 	// This is a randomly generated 64-character hex string
 	// to prevent unauthorized processes from being mistakenly identified as plugins.
@@ -26,7 +39,35 @@ const (
 )
 
 // Version history:
-// v1 (current): Initial release with GetMetadata and Execute methods
+// v1: Initial release with GetMetadata and Execute methods, string-only parameters and results
+// v2: Execute carries typed parameters (google.protobuf.Struct) and results (google.protobuf.Value)
+// v3: ExecuteStream streams chunked input and output
+// v4: Configure delivers the plugin's settings, declared in MetadataResponse.config_schema
+// v5 (current): ConnectHost gives the plugin access to HostService over the go-plugin broker
+
+// protocolPlugins maps each protocol version to the plugin type that speaks it.
+// When a new protocol version is introduced, add its plugin type here and bump
+// MaxSupportedProtocolVersion so hosts keep serving older plugins side by side.
+var protocolPlugins = map[int]func() plugin.Plugin{
+	1: func() plugin.Plugin { return &PluginGRPCPlugin{protocolVersion: 1} },
+	2: func() plugin.Plugin { return &PluginGRPCPlugin{protocolVersion: 2} },
+	3: func() plugin.Plugin { return &PluginGRPCPlugin{protocolVersion: 3} },
+	4: func() plugin.Plugin { return &PluginGRPCPlugin{protocolVersion: 4} },
+	5: func() plugin.Plugin { return &PluginGRPCPlugin{protocolVersion: 5} },
+}
+
+// VersionedPluginSets returns the plugin sets the host offers during the
+// go-plugin handshake, keyed by protocol version. go-plugin negotiates the
+// highest version both sides support and rejects plugins outside this set.
+func VersionedPluginSets(pluginName string) map[int]plugin.PluginSet {
+	sets := make(map[int]plugin.PluginSet)
+	for version := MinSupportedProtocolVersion; version <= MaxSupportedProtocolVersion; version++ {
+		if factory, ok := protocolPlugins[version]; ok {
+			sets[version] = plugin.PluginSet{pluginName: factory()}
+		}
+	}
+	return sets
+}
 
 // Handshake is a common handshake that is shared by all plugins.
 // Reference: Terraform's plugin system uses similar handshake mechanism.
@@ -41,53 +82,125 @@ var Handshake = plugin.HandshakeConfig{
 
 // PluginGRPCPlugin is the implementation of plugin.GRPCPlugin
 type PluginGRPCPlugin struct {
-	plugin.Plugin                 // Embedded plugin.Plugin to satisfy the plugin.PluginSet
-	Impl          PluginInterface // Impl Injection
+	plugin.Plugin                   // Embedded plugin.Plugin to satisfy the plugin.PluginSet
+	Impl            PluginInterface // Impl Injection
+	protocolVersion int             // Negotiated protocol version; zero means CurrentProtocolVersion
 }
 
 func (p *PluginGRPCPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
-	RegisterPluginServer(s, &GRPCServer{Impl: p.Impl})
+	RegisterPluginServer(s, &GRPCServer{Impl: p.Impl, broker: broker})
 	return nil
 }
 
 func (p *PluginGRPCPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (any, error) {
-	return &GRPCClient{client: NewPluginClient(c)}, nil
+	version := p.protocolVersion
+	if version == 0 {
+		version = CurrentProtocolVersion
+	}
+	return &GRPCClient{client: NewPluginClient(c), broker: broker, protocolVersion: version}, nil
 }
 
-// GRPCClient is an implementation of PluginInterface that talks over RPC
+// GRPCClient is an implementation of PluginInterface and ContextPluginInterface that talks over RPC.
+// Against a protocol v1 plugin it flattens parameters to strings and wraps the string result
+// in a Value, so callers always deal in typed parameters and results.
 type GRPCClient struct {
-	client PluginClient
+	client          PluginClient
+	broker          *plugin.GRPCBroker
+	protocolVersion int
+}
+
+// ProtocolVersion returns the protocol version negotiated with the plugin
+func (m *GRPCClient) ProtocolVersion() int {
+	return m.protocolVersion
 }
 
 func (m *GRPCClient) GetMetadata() (*MetadataResponse, error) {
-	resp, err := m.client.GetMetadata(context.Background(), &MetadataRequest{})
+	return m.GetMetadataContext(context.Background())
+}
+
+func (m *GRPCClient) GetMetadataContext(ctx context.Context) (_ *MetadataResponse, err error) {
+	ctx, span := startClientSpan(ctx, Plugin_GetMetadata_FullMethodName)
+	defer func() { endSpan(span, err) }()
+
+	resp, err := m.client.GetMetadata(ctx, &MetadataRequest{})
 	if err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (m *GRPCClient) Execute(method string, params map[string]string) (*ExecuteResponse, error) {
-	resp, err := m.client.Execute(context.Background(), &ExecuteRequest{
-		Method: method,
-		Params: params,
-	})
+func (m *GRPCClient) Execute(method string, params *structpb.Struct) (*ExecuteResponse, error) {
+	return m.ExecuteContext(context.Background(), method, params)
+}
+
+// ExecuteContext runs method in the plugin. The call is traced as a child of
+// the span in ctx, whose trace context the plugin receives in the call's metadata.
+func (m *GRPCClient) ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (resp *ExecuteResponse, err error) {
+	ctx, span := startClientSpan(ctx, Plugin_Execute_FullMethodName, pluginMethodAttribute(method))
+	defer func() { endExecuteSpan(span, resp, err) }()
+
+	if params == nil {
+		params = &structpb.Struct{}
+	}
+
+	req := &ExecuteRequest{Method: method}
+	if m.protocolVersion < TypedProtocolVersion {
+		legacyParams, err := flattenParams(params)
+		if err != nil {
+			return nil, err
+		}
+		req.LegacyParams = legacyParams
+	} else {
+		req.Params = params
+	}
+
+	resp, err = m.client.Execute(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+
+	if resp.Result == nil && resp.LegacyResult != nil {
+		resp.Result = structpb.NewStringValue(*resp.LegacyResult)
+	}
 	return resp, nil
 }
 
-// GRPCServer is the gRPC server that GRPCClient talks to
+// GRPCServer is the gRPC server that GRPCClient talks to.
+// The incoming request context is handed to Impl when it implements
+// ContextPluginInterface, so deadlines and cancellation reach the plugin.
+// It carries the span of the call, a child of the host's span, under which
+// the plugin can create spans of its own.
 type GRPCServer struct {
 	UnimplementedPluginServer
-	Impl PluginInterface
+	Impl   PluginInterface
+	broker *plugin.GRPCBroker
 }
 
-func (m *GRPCServer) GetMetadata(ctx context.Context, req *MetadataRequest) (*MetadataResponse, error) {
-	return m.Impl.GetMetadata()
+func (m *GRPCServer) GetMetadata(ctx context.Context, req *MetadataRequest) (_ *MetadataResponse, err error) {
+	ctx, span := startServerSpan(ctx, Plugin_GetMetadata_FullMethodName)
+	defer func() { endSpan(span, err) }()
+
+	return WithContext(m.Impl).GetMetadataContext(ctx)
 }
 
-func (m *GRPCServer) Execute(ctx context.Context, req *ExecuteRequest) (*ExecuteResponse, error) {
-	return m.Impl.Execute(req.Method, req.Params)
+func (m *GRPCServer) Execute(ctx context.Context, req *ExecuteRequest) (resp *ExecuteResponse, err error) {
+	ctx, span := startServerSpan(ctx, Plugin_Execute_FullMethodName, pluginMethodAttribute(req.Method))
+	defer func() { endExecuteSpan(span, resp, err) }()
+
+	// A request without typed params comes from a protocol v1 host
+	if req.Params == nil {
+		resp, err = WithContext(m.Impl).ExecuteContext(ctx, req.Method, expandLegacyParams(req.LegacyParams))
+		if err != nil || resp == nil || resp.Result == nil {
+			return resp, err
+		}
+		legacyResult, err := flattenValue(resp.Result)
+		if err != nil {
+			return nil, err
+		}
+		resp.LegacyResult = &legacyResult
+		resp.Result = nil
+		return resp, nil
+	}
+
+	return WithContext(m.Impl).ExecuteContext(ctx, req.Method, req.Params)
 }
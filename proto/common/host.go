@@ -0,0 +1,287 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrHostServicesUnsupported is returned when the plugin predates protocol v5
+// or does not implement HostAwarePluginInterface.
+var ErrHostServicesUnsupported = errors.New("plugin does not support host services")
+
+// ErrSecretNotFound is returned by GetSecret for secrets the host does not
+// have for the plugin
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Host log levels accepted by HostServices.Log
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// HostServices are the services the host provides to a plugin. The host
+// implements them once per plugin, so state and secrets are scoped to it;
+// plugins receive a client for them through HostAwarePluginInterface.
+type HostServices interface {
+	// Log writes a structured entry to the host log
+	Log(ctx context.Context, level, message string, fields map[string]string) error
+
+	// GetState reads a value of the plugin's key-value state. found is false
+	// for keys that have never been set or were deleted.
+	GetState(ctx context.Context, key string) (value []byte, found bool, err error)
+
+	// SetState writes a value of the plugin's key-value state
+	SetState(ctx context.Context, key string, value []byte) error
+
+	// CompareAndSetState writes value only if key currently holds expected,
+	// or does not exist when expected is empty, and reports whether it did.
+	// Processes of the same plugin use it to update state without races.
+	CompareAndSetState(ctx context.Context, key string, expected, value []byte) (bool, error)
+
+	// DeleteState removes a value of the plugin's key-value state
+	DeleteState(ctx context.Context, key string) error
+
+	// GetSecret returns a secret the host configured for the plugin, or
+	// ErrSecretNotFound
+	GetSecret(ctx context.Context, name string) (string, error)
+
+	// CallPlugin executes a method of another loaded plugin, named either in
+	// the caller's namespace or as namespace/name, and returns its result
+	CallPlugin(ctx context.Context, plugin, method string, params *structpb.Struct) (*structpb.Value, error)
+}
+
+// HostAwarePluginInterface is implemented by plugins that call back into the
+// host. SetHost is called once per process, before the plugin is configured
+// or serves calls; the plugin keeps host for its lifetime.
+type HostAwarePluginInterface interface {
+	SetHost(host HostServices)
+}
+
+// ConnectHost serves host to the plugin over the go-plugin broker
+func (m *GRPCClient) ConnectHost(ctx context.Context, host HostServices) error {
+	if m.protocolVersion < HostServicesProtocolVersion || m.broker == nil {
+		return ErrHostServicesUnsupported
+	}
+
+	serviceID := m.broker.NextId()
+	go m.broker.AcceptAndServe(serviceID, func(opts []grpc.ServerOption) *grpc.Server {
+		s := grpc.NewServer(append(opts, grpc.ChainUnaryInterceptor(traceUnaryServer))...)
+		RegisterHostServiceServer(s, &hostServer{impl: host})
+		return s
+	})
+
+	_, err := m.client.ConnectHost(ctx, &ConnectHostRequest{ServiceId: serviceID})
+	if status.Code(err) == codes.Unimplemented {
+		return ErrHostServicesUnsupported
+	}
+	return err
+}
+
+// ConnectHost dials the host's HostService and hands a client for it to Impl
+func (m *GRPCServer) ConnectHost(ctx context.Context, req *ConnectHostRequest) (*ConnectHostResponse, error) {
+	impl, ok := m.Impl.(HostAwarePluginInterface)
+	if !ok || m.broker == nil {
+		return nil, status.Error(codes.Unimplemented, ErrHostServicesUnsupported.Error())
+	}
+
+	conn, err := m.broker.Dial(req.ServiceId)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to dial host services: %v", err)
+	}
+	impl.SetHost(&hostClient{client: NewHostServiceClient(tracedConn{conn})})
+	return &ConnectHostResponse{}, nil
+}
+
+// hostServer serves a host's HostServices to its plugin
+type hostServer struct {
+	UnimplementedHostServiceServer
+	impl HostServices
+}
+
+func (s *hostServer) Log(ctx context.Context, req *LogRequest) (*LogResponse, error) {
+	if err := s.impl.Log(ctx, req.Level, req.Message, req.Fields); err != nil {
+		return nil, err
+	}
+	return &LogResponse{}, nil
+}
+
+func (s *hostServer) GetState(ctx context.Context, req *GetStateRequest) (*GetStateResponse, error) {
+	value, found, err := s.impl.GetState(ctx, req.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &GetStateResponse{Found: found, Value: value}, nil
+}
+
+func (s *hostServer) SetState(ctx context.Context, req *SetStateRequest) (*SetStateResponse, error) {
+	if req.Conditional {
+		written, err := s.impl.CompareAndSetState(ctx, req.Key, req.Expected, req.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &SetStateResponse{Written: written}, nil
+	}
+
+	if err := s.impl.SetState(ctx, req.Key, req.Value); err != nil {
+		return nil, err
+	}
+	return &SetStateResponse{Written: true}, nil
+}
+
+func (s *hostServer) DeleteState(ctx context.Context, req *DeleteStateRequest) (*DeleteStateResponse, error) {
+	if err := s.impl.DeleteState(ctx, req.Key); err != nil {
+		return nil, err
+	}
+	return &DeleteStateResponse{}, nil
+}
+
+func (s *hostServer) GetSecret(ctx context.Context, req *GetSecretRequest) (*GetSecretResponse, error) {
+	value, err := s.impl.GetSecret(ctx, req.Name)
+	if errors.Is(err, ErrSecretNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &GetSecretResponse{Value: value}, nil
+}
+
+// CallPlugin reports a failed call in the response, like Execute
+func (s *hostServer) CallPlugin(ctx context.Context, req *CallPluginRequest) (*ExecuteResponse, error) {
+	result, err := s.impl.CallPlugin(ctx, req.Plugin, req.Method, req.Params)
+	if err != nil {
+		errMsg := err.Error()
+		return &ExecuteResponse{Success: false, Error: &errMsg}, nil
+	}
+	return &ExecuteResponse{Success: true, Result: result}, nil
+}
+
+// hostClient is the HostServices a plugin receives in SetHost
+type hostClient struct {
+	client HostServiceClient
+}
+
+func (c *hostClient) Log(ctx context.Context, level, message string, fields map[string]string) error {
+	_, err := c.client.Log(ctx, &LogRequest{Level: level, Message: message, Fields: fields})
+	return err
+}
+
+func (c *hostClient) GetState(ctx context.Context, key string) ([]byte, bool, error) {
+	resp, err := c.client.GetState(ctx, &GetStateRequest{Key: key})
+	if err != nil {
+		return nil, false, err
+	}
+	return resp.Value, resp.Found, nil
+}
+
+func (c *hostClient) SetState(ctx context.Context, key string, value []byte) error {
+	_, err := c.client.SetState(ctx, &SetStateRequest{Key: key, Value: value})
+	return err
+}
+
+func (c *hostClient) CompareAndSetState(ctx context.Context, key string, expected, value []byte) (bool, error) {
+	resp, err := c.client.SetState(ctx, &SetStateRequest{Key: key, Value: value, Conditional: true, Expected: expected})
+	if err != nil {
+		return false, err
+	}
+	return resp.Written, nil
+}
+
+func (c *hostClient) DeleteState(ctx context.Context, key string) error {
+	_, err := c.client.DeleteState(ctx, &DeleteStateRequest{Key: key})
+	return err
+}
+
+func (c *hostClient) GetSecret(ctx context.Context, name string) (string, error) {
+	resp, err := c.client.GetSecret(ctx, &GetSecretRequest{Name: name})
+	if status.Code(err) == codes.NotFound {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return resp.Value, nil
+}
+
+func (c *hostClient) CallPlugin(ctx context.Context, plugin, method string, params *structpb.Struct) (*structpb.Value, error) {
+	resp, err := c.client.CallPlugin(ctx, &CallPluginRequest{Plugin: plugin, Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.GetError())
+	}
+	return resp.Result, nil
+}
+
+// MemoryHost is an in-memory HostServices for running plugins without a
+// host, e.g. in tests. Log entries are dropped and CallPlugin fails.
+type MemoryHost struct {
+	mu      sync.Mutex
+	State   map[string][]byte
+	Secrets map[string]string
+}
+
+func (h *MemoryHost) Log(ctx context.Context, level, message string, fields map[string]string) error {
+	return nil
+}
+
+func (h *MemoryHost) GetState(ctx context.Context, key string) ([]byte, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	value, found := h.State[key]
+	return value, found, nil
+}
+
+func (h *MemoryHost) SetState(ctx context.Context, key string, value []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.setLocked(key, value)
+	return nil
+}
+
+func (h *MemoryHost) CompareAndSetState(ctx context.Context, key string, expected, value []byte) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	current, found := h.State[key]
+	if (len(expected) == 0 && found) || (len(expected) > 0 && !bytes.Equal(current, expected)) {
+		return false, nil
+	}
+	h.setLocked(key, value)
+	return true, nil
+}
+
+func (h *MemoryHost) setLocked(key string, value []byte) {
+	if h.State == nil {
+		h.State = make(map[string][]byte)
+	}
+	h.State[key] = value
+}
+
+func (h *MemoryHost) DeleteState(ctx context.Context, key string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.State, key)
+	return nil
+}
+
+func (h *MemoryHost) GetSecret(ctx context.Context, name string) (string, error) {
+	value, ok := h.Secrets[name]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (h *MemoryHost) CallPlugin(ctx context.Context, plugin, method string, params *structpb.Struct) (*structpb.Value, error) {
+	return nil, errors.New("no plugins to call")
+}
@@ -2,11 +2,57 @@
 // All plugins must implement this interface for generic invocation.
 package common
 
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
 // PluginInterface is the common interface that all plugins must implement
 type PluginInterface interface {
 	// GetMetadata returns plugin metadata
 	GetMetadata() (*MetadataResponse, error)
 
-	// Execute executes a plugin method with generic parameters
-	Execute(method string, params map[string]string) (*ExecuteResponse, error)
+	// Execute executes a plugin method with typed parameters
+	Execute(method string, params *structpb.Struct) (*ExecuteResponse, error)
+}
+
+// ContextPluginInterface is the context-aware variant of PluginInterface.
+// The context carries the caller's deadline and cancellation across the
+// gRPC boundary, so implementations should stop work once ctx is done.
+type ContextPluginInterface interface {
+	// GetMetadataContext returns plugin metadata
+	GetMetadataContext(ctx context.Context) (*MetadataResponse, error)
+
+	// ExecuteContext executes a plugin method with typed parameters
+	ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (*ExecuteResponse, error)
+}
+
+// legacyImpl adapts a PluginInterface that is not context-aware.
+// The context is only checked before the call is dispatched.
+type legacyImpl struct {
+	impl PluginInterface
+}
+
+func (l *legacyImpl) GetMetadataContext(ctx context.Context) (*MetadataResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.impl.GetMetadata()
+}
+
+func (l *legacyImpl) ExecuteContext(ctx context.Context, method string, params *structpb.Struct) (*ExecuteResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.impl.Execute(method, params)
+}
+
+// WithContext returns the context-aware form of impl, wrapping it when
+// it only implements PluginInterface.
+func WithContext(impl PluginInterface) ContextPluginInterface {
+	if ctxImpl, ok := impl.(ContextPluginInterface); ok {
+		return ctxImpl
+	}
+	return &legacyImpl{impl: impl}
 }
@@ -0,0 +1,197 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Params gives plugins typed access to Execute parameters.
+// Scalars that arrive as strings are parsed on demand, so the same adapter
+// code also serves protocol v1 hosts, which can only send strings.
+type Params struct {
+	fields map[string]*structpb.Value
+}
+
+// NewParams wraps the parameters passed to Execute
+func NewParams(params *structpb.Struct) Params {
+	return Params{fields: params.GetFields()}
+}
+
+// Has reports whether the parameter is present and not null
+func (p Params) Has(key string) bool {
+	value, ok := p.fields[key]
+	if !ok {
+		return false
+	}
+	_, isNull := value.GetKind().(*structpb.Value_NullValue)
+	return !isNull
+}
+
+func (p Params) get(key string) (*structpb.Value, error) {
+	if !p.Has(key) {
+		return nil, fmt.Errorf("missing '%s' parameter", key)
+	}
+	return p.fields[key], nil
+}
+
+// String returns a string parameter. Non-string values are returned in their JSON form.
+func (p Params) String(key string) (string, error) {
+	value, err := p.get(key)
+	if err != nil {
+		return "", err
+	}
+	return flattenValue(value)
+}
+
+// Float returns a numeric parameter
+func (p Params) Float(key string) (float64, error) {
+	value, err := p.get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		return kind.NumberValue, nil
+	case *structpb.Value_StringValue:
+		number, err := strconv.ParseFloat(kind.StringValue, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s parameter: %w", key, err)
+		}
+		return number, nil
+	default:
+		return 0, fmt.Errorf("invalid %s parameter: expected a number", key)
+	}
+}
+
+// Int returns an integer parameter, rejecting numbers with a fractional part
+func (p Params) Int(key string) (int64, error) {
+	value, err := p.get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		if kind.NumberValue != math.Trunc(kind.NumberValue) {
+			return 0, fmt.Errorf("invalid %s parameter: expected an integer, got %v", key, kind.NumberValue)
+		}
+		return int64(kind.NumberValue), nil
+	case *structpb.Value_StringValue:
+		number, err := strconv.ParseInt(kind.StringValue, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s parameter: %w", key, err)
+		}
+		return number, nil
+	default:
+		return 0, fmt.Errorf("invalid %s parameter: expected an integer", key)
+	}
+}
+
+// Bool returns a boolean parameter
+func (p Params) Bool(key string) (bool, error) {
+	value, err := p.get(key)
+	if err != nil {
+		return false, err
+	}
+
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_BoolValue:
+		return kind.BoolValue, nil
+	case *structpb.Value_StringValue:
+		b, err := strconv.ParseBool(kind.StringValue)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s parameter: %w", key, err)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("invalid %s parameter: expected a boolean", key)
+	}
+}
+
+// FloatSlice returns an array-of-numbers parameter. A string holding a JSON
+// array is accepted as well.
+func (p Params) FloatSlice(key string) ([]float64, error) {
+	value, err := p.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_ListValue:
+		numbers := make([]float64, 0, len(kind.ListValue.GetValues()))
+		for i, item := range kind.ListValue.GetValues() {
+			number, ok := item.GetKind().(*structpb.Value_NumberValue)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s parameter: element %d is not a number", key, i)
+			}
+			numbers = append(numbers, number.NumberValue)
+		}
+		return numbers, nil
+	case *structpb.Value_StringValue:
+		var numbers []float64
+		if err := json.Unmarshal([]byte(kind.StringValue), &numbers); err != nil {
+			return nil, fmt.Errorf("invalid %s parameter: %w", key, err)
+		}
+		return numbers, nil
+	default:
+		return nil, fmt.Errorf("invalid %s parameter: expected an array of numbers", key)
+	}
+}
+
+// Strings returns every parameter except the excluded ones flattened to strings,
+// for implementations that take loosely typed options
+func (p Params) Strings(exclude ...string) (map[string]string, error) {
+	skip := make(map[string]bool, len(exclude))
+	for _, key := range exclude {
+		skip[key] = true
+	}
+
+	options := make(map[string]string, len(p.fields))
+	for key, value := range p.fields {
+		if skip[key] {
+			continue
+		}
+		str, err := flattenValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter: %w", key, err)
+		}
+		options[key] = str
+	}
+	return options, nil
+}
+
+// flattenParams converts typed parameters to the protocol v1 string map.
+// Strings are passed through and everything else is JSON-encoded, so arrays
+// and objects survive the trip.
+func flattenParams(params *structpb.Struct) (map[string]string, error) {
+	return NewParams(params).Strings()
+}
+
+func flattenValue(value *structpb.Value) (string, error) {
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_StringValue:
+		return kind.StringValue, nil
+	case *structpb.Value_NullValue, nil:
+		return "", nil
+	}
+
+	data, err := json.Marshal(value.AsInterface())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// expandLegacyParams converts protocol v1 string parameters to a Struct of string values
+func expandLegacyParams(legacyParams map[string]string) *structpb.Struct {
+	params := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(legacyParams))}
+	for key, value := range legacyParams {
+		params.Fields[key] = structpb.NewStringValue(value)
+	}
+	return params
+}
@@ -9,6 +9,7 @@ package common
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -58,15 +59,17 @@ func (*MetadataRequest) Descriptor() ([]byte, []int) {
 }
 
 type MetadataResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Version         string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Description     string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Methods         []string               `protobuf:"bytes,4,rep,name=methods,proto3" json:"methods,omitempty"`                                                                                     // Available methods
-	Capabilities    map[string]string      `protobuf:"bytes,5,rep,name=capabilities,proto3" json:"capabilities,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Additional capabilities
-	ProtocolVersion int32                  `protobuf:"varint,6,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`                                             // Protocol version the plugin was built with
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state             protoimpl.MessageState      `protogen:"open.v1"`
+	Name              string                      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version           string                      `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Description       string                      `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Methods           []string                    `protobuf:"bytes,4,rep,name=methods,proto3" json:"methods,omitempty"`                                                                                                         // Available methods
+	Capabilities      map[string]string           `protobuf:"bytes,5,rep,name=capabilities,proto3" json:"capabilities,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`                     // Additional capabilities
+	ProtocolVersion   int32                       `protobuf:"varint,6,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`                                                                 // Protocol version the plugin was built with
+	MethodDescriptors []*MethodDescriptor         `protobuf:"bytes,7,rep,name=method_descriptors,json=methodDescriptors,proto3" json:"method_descriptors,omitempty"`                                                            // Per-method schemas, one for each entry in methods
+	ConfigSchema      map[string]*ParamDescriptor `protobuf:"bytes,8,rep,name=config_schema,json=configSchema,proto3" json:"config_schema,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Settings accepted by Configure, keyed by name
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *MetadataResponse) Reset() {
@@ -141,29 +144,946 @@ func (x *MetadataResponse) GetProtocolVersion() int32 {
 	return 0
 }
 
+func (x *MetadataResponse) GetMethodDescriptors() []*MethodDescriptor {
+	if x != nil {
+		return x.MethodDescriptors
+	}
+	return nil
+}
+
+func (x *MetadataResponse) GetConfigSchema() map[string]*ParamDescriptor {
+	if x != nil {
+		return x.ConfigSchema
+	}
+	return nil
+}
+
+// MethodDescriptor describes how to call a plugin method
+type MethodDescriptor struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Name          string                      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                      `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Parameters    map[string]*ParamDescriptor `protobuf:"bytes,3,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Parameters keyed by name
+	Returns       *ReturnDescriptor           `protobuf:"bytes,4,opt,name=returns,proto3" json:"returns,omitempty"`
+	Examples      []*MethodExample            `protobuf:"bytes,5,rep,name=examples,proto3" json:"examples,omitempty"`
+	StreamInput   string                      `protobuf:"bytes,6,opt,name=stream_input,json=streamInput,proto3" json:"stream_input,omitempty"` // Parameter that ExecuteStream reads from the input stream instead; empty if the method does not stream
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MethodDescriptor) Reset() {
+	*x = MethodDescriptor{}
+	mi := &file_common_plugin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodDescriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodDescriptor) ProtoMessage() {}
+
+func (x *MethodDescriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodDescriptor.ProtoReflect.Descriptor instead.
+func (*MethodDescriptor) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MethodDescriptor) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MethodDescriptor) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *MethodDescriptor) GetParameters() map[string]*ParamDescriptor {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *MethodDescriptor) GetReturns() *ReturnDescriptor {
+	if x != nil {
+		return x.Returns
+	}
+	return nil
+}
+
+func (x *MethodDescriptor) GetExamples() []*MethodExample {
+	if x != nil {
+		return x.Examples
+	}
+	return nil
+}
+
+func (x *MethodDescriptor) GetStreamInput() string {
+	if x != nil {
+		return x.StreamInput
+	}
+	return ""
+}
+
+type ParamDescriptor struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // string, int, number, bool, object, array
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Required      bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	Default       *structpb.Value        `protobuf:"bytes,4,opt,name=default,proto3" json:"default,omitempty"` // Used by the host when an optional parameter is omitted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParamDescriptor) Reset() {
+	*x = ParamDescriptor{}
+	mi := &file_common_plugin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParamDescriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParamDescriptor) ProtoMessage() {}
+
+func (x *ParamDescriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParamDescriptor.ProtoReflect.Descriptor instead.
+func (*ParamDescriptor) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ParamDescriptor) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ParamDescriptor) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ParamDescriptor) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *ParamDescriptor) GetDefault() *structpb.Value {
+	if x != nil {
+		return x.Default
+	}
+	return nil
+}
+
+type ReturnDescriptor struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // Type of ExecuteResponse.result
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReturnDescriptor) Reset() {
+	*x = ReturnDescriptor{}
+	mi := &file_common_plugin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReturnDescriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReturnDescriptor) ProtoMessage() {}
+
+func (x *ReturnDescriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReturnDescriptor.ProtoReflect.Descriptor instead.
+func (*ReturnDescriptor) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ReturnDescriptor) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ReturnDescriptor) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type MethodExample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Description   string                 `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Params        *structpb.Struct       `protobuf:"bytes,2,opt,name=params,proto3" json:"params,omitempty"`
+	Result        *structpb.Value        `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"` // Illustrative result; may differ between calls
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MethodExample) Reset() {
+	*x = MethodExample{}
+	mi := &file_common_plugin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodExample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodExample) ProtoMessage() {}
+
+func (x *MethodExample) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodExample.ProtoReflect.Descriptor instead.
+func (*MethodExample) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MethodExample) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *MethodExample) GetParams() *structpb.Struct {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *MethodExample) GetResult() *structpb.Value {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
 type ExecuteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Method        string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`                                                                           // Method to execute
-	Params        map[string]string      `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Generic string parameters
+	Method        string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`                                                                                                           // Method to execute
+	LegacyParams  map[string]string      `protobuf:"bytes,2,rep,name=legacy_params,json=legacyParams,proto3" json:"legacy_params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Protocol v1 only: parameters flattened to strings
+	Params        *structpb.Struct       `protobuf:"bytes,3,opt,name=params,proto3" json:"params,omitempty"`                                                                                                           // Typed parameters (protocol v2+)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteRequest) Reset() {
+	*x = ExecuteRequest{}
+	mi := &file_common_plugin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteRequest) ProtoMessage() {}
+
+func (x *ExecuteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ExecuteRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ExecuteRequest) GetLegacyParams() map[string]string {
+	if x != nil {
+		return x.LegacyParams
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetParams() *structpb.Struct {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type ExecuteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LegacyResult  *string                `protobuf:"bytes,1,opt,name=legacy_result,json=legacyResult,proto3,oneof" json:"legacy_result,omitempty"` // Protocol v1 only: result as a string
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`                                    // Whether the execution was successful
+	Error         *string                `protobuf:"bytes,3,opt,name=error,proto3,oneof" json:"error,omitempty"`                                   // Optional error message if the execution was not successful
+	Result        *structpb.Value        `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`                                       // Typed result of the execution (protocol v2+, present on success)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteResponse) Reset() {
+	*x = ExecuteResponse{}
+	mi := &file_common_plugin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteResponse) ProtoMessage() {}
+
+func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteResponse.ProtoReflect.Descriptor instead.
+func (*ExecuteResponse) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExecuteResponse) GetLegacyResult() string {
+	if x != nil && x.LegacyResult != nil {
+		return *x.LegacyResult
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetResult() *structpb.Value {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type ExecuteStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ExecuteStreamRequest_Header
+	//	*ExecuteStreamRequest_Chunk
+	Payload       isExecuteStreamRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteStreamRequest) Reset() {
+	*x = ExecuteStreamRequest{}
+	mi := &file_common_plugin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteStreamRequest) ProtoMessage() {}
+
+func (x *ExecuteStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteStreamRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteStreamRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExecuteStreamRequest) GetPayload() isExecuteStreamRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ExecuteStreamRequest) GetHeader() *ExecuteStreamHeader {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecuteStreamRequest_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *ExecuteStreamRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*ExecuteStreamRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isExecuteStreamRequest_Payload interface {
+	isExecuteStreamRequest_Payload()
+}
+
+type ExecuteStreamRequest_Header struct {
+	Header *ExecuteStreamHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"` // First message: what to execute
+}
+
+type ExecuteStreamRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"` // Following messages: input data
+}
+
+func (*ExecuteStreamRequest_Header) isExecuteStreamRequest_Payload() {}
+
+func (*ExecuteStreamRequest_Chunk) isExecuteStreamRequest_Payload() {}
+
+type ExecuteStreamHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Method        string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"` // Method to execute
+	Params        *structpb.Struct       `protobuf:"bytes,2,opt,name=params,proto3" json:"params,omitempty"` // Typed parameters
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteStreamHeader) Reset() {
+	*x = ExecuteStreamHeader{}
+	mi := &file_common_plugin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteStreamHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteStreamHeader) ProtoMessage() {}
+
+func (x *ExecuteStreamHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteStreamHeader.ProtoReflect.Descriptor instead.
+func (*ExecuteStreamHeader) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExecuteStreamHeader) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ExecuteStreamHeader) GetParams() *structpb.Struct {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type ExecuteStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`                                // Output data
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // Media type of the output, set on the first message
+	Error         *string                `protobuf:"bytes,3,opt,name=error,proto3,oneof" json:"error,omitempty"`                          // Set on the last message if the execution failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteStreamResponse) Reset() {
+	*x = ExecuteStreamResponse{}
+	mi := &file_common_plugin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteStreamResponse) ProtoMessage() {}
+
+func (x *ExecuteStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteStreamResponse.ProtoReflect.Descriptor instead.
+func (*ExecuteStreamResponse) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExecuteStreamResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *ExecuteStreamResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ExecuteStreamResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+type ConfigureRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        *structpb.Struct       `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"` // Complete settings; keys that are absent fall back to the plugin's defaults
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureRequest) Reset() {
+	*x = ConfigureRequest{}
+	mi := &file_common_plugin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureRequest) ProtoMessage() {}
+
+func (x *ConfigureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureRequest.ProtoReflect.Descriptor instead.
+func (*ConfigureRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ConfigureRequest) GetConfig() *structpb.Struct {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type ConfigureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`  // Whether the plugin accepted the settings
+	Error         *string                `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"` // Why the settings were rejected
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureResponse) Reset() {
+	*x = ConfigureResponse{}
+	mi := &file_common_plugin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureResponse) ProtoMessage() {}
+
+func (x *ConfigureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureResponse.ProtoReflect.Descriptor instead.
+func (*ConfigureResponse) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ConfigureResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ConfigureResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+type ConnectHostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServiceId     uint32                 `protobuf:"varint,1,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"` // Broker ID to dial for HostService
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectHostRequest) Reset() {
+	*x = ConnectHostRequest{}
+	mi := &file_common_plugin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectHostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectHostRequest) ProtoMessage() {}
+
+func (x *ConnectHostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectHostRequest.ProtoReflect.Descriptor instead.
+func (*ConnectHostRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ConnectHostRequest) GetServiceId() uint32 {
+	if x != nil {
+		return x.ServiceId
+	}
+	return 0
+}
+
+type ConnectHostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectHostResponse) Reset() {
+	*x = ConnectHostResponse{}
+	mi := &file_common_plugin_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectHostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectHostResponse) ProtoMessage() {}
+
+func (x *ConnectHostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectHostResponse.ProtoReflect.Descriptor instead.
+func (*ConnectHostResponse) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{14}
+}
+
+type LogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         string                 `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"` // debug, info, warn or error
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Fields        map[string]string      `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Structured context of the entry
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogRequest) Reset() {
+	*x = LogRequest{}
+	mi := &file_common_plugin_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRequest) ProtoMessage() {}
+
+func (x *LogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRequest.ProtoReflect.Descriptor instead.
+func (*LogRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *LogRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogRequest) GetFields() map[string]string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type LogResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogResponse) Reset() {
+	*x = LogResponse{}
+	mi := &file_common_plugin_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogResponse) ProtoMessage() {}
+
+func (x *LogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogResponse.ProtoReflect.Descriptor instead.
+func (*LogResponse) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{16}
+}
+
+type GetStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStateRequest) Reset() {
+	*x = GetStateRequest{}
+	mi := &file_common_plugin_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStateRequest) ProtoMessage() {}
+
+func (x *GetStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStateRequest.ProtoReflect.Descriptor instead.
+func (*GetStateRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetStateRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type GetStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Found         bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecuteRequest) Reset() {
-	*x = ExecuteRequest{}
-	mi := &file_common_plugin_proto_msgTypes[2]
+func (x *GetStateResponse) Reset() {
+	*x = GetStateResponse{}
+	mi := &file_common_plugin_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecuteRequest) String() string {
+func (x *GetStateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecuteRequest) ProtoMessage() {}
+func (*GetStateResponse) ProtoMessage() {}
 
-func (x *ExecuteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_common_plugin_proto_msgTypes[2]
+func (x *GetStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -174,49 +1094,50 @@ func (x *ExecuteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecuteRequest.ProtoReflect.Descriptor instead.
-func (*ExecuteRequest) Descriptor() ([]byte, []int) {
-	return file_common_plugin_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use GetStateResponse.ProtoReflect.Descriptor instead.
+func (*GetStateResponse) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *ExecuteRequest) GetMethod() string {
+func (x *GetStateResponse) GetFound() bool {
 	if x != nil {
-		return x.Method
+		return x.Found
 	}
-	return ""
+	return false
 }
 
-func (x *ExecuteRequest) GetParams() map[string]string {
+func (x *GetStateResponse) GetValue() []byte {
 	if x != nil {
-		return x.Params
+		return x.Value
 	}
 	return nil
 }
 
-type ExecuteResponse struct {
+type SetStateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Result        *string                `protobuf:"bytes,1,opt,name=result,proto3,oneof" json:"result,omitempty"` // Result of the execution (present on success)
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`    // Whether the execution was successful
-	Error         *string                `protobuf:"bytes,3,opt,name=error,proto3,oneof" json:"error,omitempty"`   // Optional error message if the execution was not successful
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Conditional   bool                   `protobuf:"varint,3,opt,name=conditional,proto3" json:"conditional,omitempty"` // Only write if the current value equals expected
+	Expected      []byte                 `protobuf:"bytes,4,opt,name=expected,proto3" json:"expected,omitempty"`        // With conditional: the value the key must hold; empty if it must not exist
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecuteResponse) Reset() {
-	*x = ExecuteResponse{}
-	mi := &file_common_plugin_proto_msgTypes[3]
+func (x *SetStateRequest) Reset() {
+	*x = SetStateRequest{}
+	mi := &file_common_plugin_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecuteResponse) String() string {
+func (x *SetStateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecuteResponse) ProtoMessage() {}
+func (*SetStateRequest) ProtoMessage() {}
 
-func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_common_plugin_proto_msgTypes[3]
+func (x *SetStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -227,63 +1148,438 @@ func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecuteResponse.ProtoReflect.Descriptor instead.
-func (*ExecuteResponse) Descriptor() ([]byte, []int) {
-	return file_common_plugin_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use SetStateRequest.ProtoReflect.Descriptor instead.
+func (*SetStateRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *ExecuteResponse) GetResult() string {
-	if x != nil && x.Result != nil {
-		return *x.Result
+func (x *SetStateRequest) GetKey() string {
+	if x != nil {
+		return x.Key
 	}
 	return ""
 }
 
-func (x *ExecuteResponse) GetSuccess() bool {
+func (x *SetStateRequest) GetValue() []byte {
 	if x != nil {
-		return x.Success
+		return x.Value
+	}
+	return nil
+}
+
+func (x *SetStateRequest) GetConditional() bool {
+	if x != nil {
+		return x.Conditional
 	}
 	return false
 }
 
-func (x *ExecuteResponse) GetError() string {
-	if x != nil && x.Error != nil {
-		return *x.Error
+func (x *SetStateRequest) GetExpected() []byte {
+	if x != nil {
+		return x.Expected
+	}
+	return nil
+}
+
+type SetStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Written       bool                   `protobuf:"varint,1,opt,name=written,proto3" json:"written,omitempty"` // False if a conditional write found a different value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetStateResponse) Reset() {
+	*x = SetStateResponse{}
+	mi := &file_common_plugin_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetStateResponse) ProtoMessage() {}
+
+func (x *SetStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetStateResponse.ProtoReflect.Descriptor instead.
+func (*SetStateResponse) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SetStateResponse) GetWritten() bool {
+	if x != nil {
+		return x.Written
+	}
+	return false
+}
+
+type DeleteStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteStateRequest) Reset() {
+	*x = DeleteStateRequest{}
+	mi := &file_common_plugin_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteStateRequest) ProtoMessage() {}
+
+func (x *DeleteStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteStateRequest.ProtoReflect.Descriptor instead.
+func (*DeleteStateRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteStateRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type DeleteStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteStateResponse) Reset() {
+	*x = DeleteStateResponse{}
+	mi := &file_common_plugin_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteStateResponse) ProtoMessage() {}
+
+func (x *DeleteStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteStateResponse.ProtoReflect.Descriptor instead.
+func (*DeleteStateResponse) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{22}
+}
+
+type GetSecretRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSecretRequest) Reset() {
+	*x = GetSecretRequest{}
+	mi := &file_common_plugin_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSecretRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSecretRequest) ProtoMessage() {}
+
+func (x *GetSecretRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSecretRequest.ProtoReflect.Descriptor instead.
+func (*GetSecretRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetSecretRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetSecretResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSecretResponse) Reset() {
+	*x = GetSecretResponse{}
+	mi := &file_common_plugin_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSecretResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSecretResponse) ProtoMessage() {}
+
+func (x *GetSecretResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSecretResponse.ProtoReflect.Descriptor instead.
+func (*GetSecretResponse) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetSecretResponse) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type CallPluginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plugin        string                 `protobuf:"bytes,1,opt,name=plugin,proto3" json:"plugin,omitempty"` // Name of the plugin to call, in the caller's namespace, or namespace/name
+	Method        string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Params        *structpb.Struct       `protobuf:"bytes,3,opt,name=params,proto3" json:"params,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallPluginRequest) Reset() {
+	*x = CallPluginRequest{}
+	mi := &file_common_plugin_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallPluginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallPluginRequest) ProtoMessage() {}
+
+func (x *CallPluginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_plugin_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallPluginRequest.ProtoReflect.Descriptor instead.
+func (*CallPluginRequest) Descriptor() ([]byte, []int) {
+	return file_common_plugin_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CallPluginRequest) GetPlugin() string {
+	if x != nil {
+		return x.Plugin
+	}
+	return ""
+}
+
+func (x *CallPluginRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
 	}
 	return ""
 }
 
+func (x *CallPluginRequest) GetParams() *structpb.Struct {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
 var File_common_plugin_proto protoreflect.FileDescriptor
 
 const file_common_plugin_proto_rawDesc = "" +
 	"\n" +
-	"\x13common/plugin.proto\x12\x06common\"\x11\n" +
-	"\x0fMetadataRequest\"\xb8\x02\n" +
+	"\x13common/plugin.proto\x12\x06common\x1a\x1cgoogle/protobuf/struct.proto\"\x11\n" +
+	"\x0fMetadataRequest\"\xac\x04\n" +
 	"\x10MetadataResponse\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x18\n" +
 	"\amethods\x18\x04 \x03(\tR\amethods\x12N\n" +
 	"\fcapabilities\x18\x05 \x03(\v2*.common.MetadataResponse.CapabilitiesEntryR\fcapabilities\x12)\n" +
-	"\x10protocol_version\x18\x06 \x01(\x05R\x0fprotocolVersion\x1a?\n" +
+	"\x10protocol_version\x18\x06 \x01(\x05R\x0fprotocolVersion\x12G\n" +
+	"\x12method_descriptors\x18\a \x03(\v2\x18.common.MethodDescriptorR\x11methodDescriptors\x12O\n" +
+	"\rconfig_schema\x18\b \x03(\v2*.common.MetadataResponse.ConfigSchemaEntryR\fconfigSchema\x1a?\n" +
 	"\x11CapabilitiesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9f\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aX\n" +
+	"\x11ConfigSchemaEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.common.ParamDescriptorR\x05value:\x028\x01\"\xf4\x02\n" +
+	"\x10MethodDescriptor\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12H\n" +
+	"\n" +
+	"parameters\x18\x03 \x03(\v2(.common.MethodDescriptor.ParametersEntryR\n" +
+	"parameters\x122\n" +
+	"\areturns\x18\x04 \x01(\v2\x18.common.ReturnDescriptorR\areturns\x121\n" +
+	"\bexamples\x18\x05 \x03(\v2\x15.common.MethodExampleR\bexamples\x12!\n" +
+	"\fstream_input\x18\x06 \x01(\tR\vstreamInput\x1aV\n" +
+	"\x0fParametersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.common.ParamDescriptorR\x05value:\x028\x01\"\x95\x01\n" +
+	"\x0fParamDescriptor\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\brequired\x18\x03 \x01(\bR\brequired\x120\n" +
+	"\adefault\x18\x04 \x01(\v2\x16.google.protobuf.ValueR\adefault\"H\n" +
+	"\x10ReturnDescriptor\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"\x92\x01\n" +
+	"\rMethodExample\x12 \n" +
+	"\vdescription\x18\x01 \x01(\tR\vdescription\x12/\n" +
+	"\x06params\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x06params\x12.\n" +
+	"\x06result\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\x06result\"\xe9\x01\n" +
 	"\x0eExecuteRequest\x12\x16\n" +
-	"\x06method\x18\x01 \x01(\tR\x06method\x12:\n" +
-	"\x06params\x18\x02 \x03(\v2\".common.ExecuteRequest.ParamsEntryR\x06params\x1a9\n" +
-	"\vParamsEntry\x12\x10\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12M\n" +
+	"\rlegacy_params\x18\x02 \x03(\v2(.common.ExecuteRequest.LegacyParamsEntryR\flegacyParams\x12/\n" +
+	"\x06params\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x06params\x1a?\n" +
+	"\x11LegacyParamsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"x\n" +
-	"\x0fExecuteResponse\x12\x1b\n" +
-	"\x06result\x18\x01 \x01(\tH\x00R\x06result\x88\x01\x01\x12\x18\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xbc\x01\n" +
+	"\x0fExecuteResponse\x12(\n" +
+	"\rlegacy_result\x18\x01 \x01(\tH\x00R\flegacyResult\x88\x01\x01\x12\x18\n" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x19\n" +
-	"\x05error\x18\x03 \x01(\tH\x01R\x05error\x88\x01\x01B\t\n" +
-	"\a_resultB\b\n" +
-	"\x06_error2\x86\x01\n" +
+	"\x05error\x18\x03 \x01(\tH\x01R\x05error\x88\x01\x01\x12.\n" +
+	"\x06result\x18\x04 \x01(\v2\x16.google.protobuf.ValueR\x06resultB\x10\n" +
+	"\x0e_legacy_resultB\b\n" +
+	"\x06_error\"p\n" +
+	"\x14ExecuteStreamRequest\x125\n" +
+	"\x06header\x18\x01 \x01(\v2\x1b.common.ExecuteStreamHeaderH\x00R\x06header\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\t\n" +
+	"\apayload\"^\n" +
+	"\x13ExecuteStreamHeader\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12/\n" +
+	"\x06params\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x06params\"u\n" +
+	"\x15ExecuteStreamResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x19\n" +
+	"\x05error\x18\x03 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"C\n" +
+	"\x10ConfigureRequest\x12/\n" +
+	"\x06config\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06config\"R\n" +
+	"\x11ConfigureResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x88\x01\x01B\b\n" +
+	"\x06_error\"3\n" +
+	"\x12ConnectHostRequest\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x01 \x01(\rR\tserviceId\"\x15\n" +
+	"\x13ConnectHostResponse\"\xaf\x01\n" +
+	"\n" +
+	"LogRequest\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x126\n" +
+	"\x06fields\x18\x03 \x03(\v2\x1e.common.LogRequest.FieldsEntryR\x06fields\x1a9\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\r\n" +
+	"\vLogResponse\"#\n" +
+	"\x0fGetStateRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\">\n" +
+	"\x10GetStateResponse\x12\x14\n" +
+	"\x05found\x18\x01 \x01(\bR\x05found\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\"w\n" +
+	"\x0fSetStateRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12 \n" +
+	"\vconditional\x18\x03 \x01(\bR\vconditional\x12\x1a\n" +
+	"\bexpected\x18\x04 \x01(\fR\bexpected\",\n" +
+	"\x10SetStateResponse\x12\x18\n" +
+	"\awritten\x18\x01 \x01(\bR\awritten\"&\n" +
+	"\x12DeleteStateRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\x15\n" +
+	"\x13DeleteStateResponse\"&\n" +
+	"\x10GetSecretRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\")\n" +
+	"\x11GetSecretResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\"t\n" +
+	"\x11CallPluginRequest\x12\x16\n" +
+	"\x06plugin\x18\x01 \x01(\tR\x06plugin\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12/\n" +
+	"\x06params\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x06params2\xe2\x02\n" +
 	"\x06Plugin\x12@\n" +
 	"\vGetMetadata\x12\x17.common.MetadataRequest\x1a\x18.common.MetadataResponse\x12:\n" +
-	"\aExecute\x12\x16.common.ExecuteRequest\x1a\x17.common.ExecuteResponseB\x8c\x01\n" +
+	"\aExecute\x12\x16.common.ExecuteRequest\x1a\x17.common.ExecuteResponse\x12P\n" +
+	"\rExecuteStream\x12\x1c.common.ExecuteStreamRequest\x1a\x1d.common.ExecuteStreamResponse(\x010\x01\x12@\n" +
+	"\tConfigure\x12\x18.common.ConfigureRequest\x1a\x19.common.ConfigureResponse\x12F\n" +
+	"\vConnectHost\x12\x1a.common.ConnectHostRequest\x1a\x1b.common.ConnectHostResponse2\x87\x03\n" +
+	"\vHostService\x12.\n" +
+	"\x03Log\x12\x12.common.LogRequest\x1a\x13.common.LogResponse\x12=\n" +
+	"\bGetState\x12\x17.common.GetStateRequest\x1a\x18.common.GetStateResponse\x12=\n" +
+	"\bSetState\x12\x17.common.SetStateRequest\x1a\x18.common.SetStateResponse\x12F\n" +
+	"\vDeleteState\x12\x1a.common.DeleteStateRequest\x1a\x1b.common.DeleteStateResponse\x12@\n" +
+	"\tGetSecret\x12\x18.common.GetSecretRequest\x1a\x19.common.GetSecretResponse\x12@\n" +
+	"\n" +
+	"CallPlugin\x12\x19.common.CallPluginRequest\x1a\x17.common.ExecuteResponseB\x8c\x01\n" +
 	"\n" +
 	"com.commonB\vPluginProtoP\x01Z9github.com/wylu1037/polyglot-plugin-showcase/proto/common\xa2\x02\x03CXX\xaa\x02\x06Common\xca\x02\x06Common\xe2\x02\x12Common\\GPBMetadata\xea\x02\x06Commonb\x06proto3"
 
@@ -299,27 +1595,89 @@ func file_common_plugin_proto_rawDescGZIP() []byte {
 	return file_common_plugin_proto_rawDescData
 }
 
-var file_common_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_common_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_common_plugin_proto_goTypes = []any{
-	(*MetadataRequest)(nil),  // 0: common.MetadataRequest
-	(*MetadataResponse)(nil), // 1: common.MetadataResponse
-	(*ExecuteRequest)(nil),   // 2: common.ExecuteRequest
-	(*ExecuteResponse)(nil),  // 3: common.ExecuteResponse
-	nil,                      // 4: common.MetadataResponse.CapabilitiesEntry
-	nil,                      // 5: common.ExecuteRequest.ParamsEntry
+	(*MetadataRequest)(nil),       // 0: common.MetadataRequest
+	(*MetadataResponse)(nil),      // 1: common.MetadataResponse
+	(*MethodDescriptor)(nil),      // 2: common.MethodDescriptor
+	(*ParamDescriptor)(nil),       // 3: common.ParamDescriptor
+	(*ReturnDescriptor)(nil),      // 4: common.ReturnDescriptor
+	(*MethodExample)(nil),         // 5: common.MethodExample
+	(*ExecuteRequest)(nil),        // 6: common.ExecuteRequest
+	(*ExecuteResponse)(nil),       // 7: common.ExecuteResponse
+	(*ExecuteStreamRequest)(nil),  // 8: common.ExecuteStreamRequest
+	(*ExecuteStreamHeader)(nil),   // 9: common.ExecuteStreamHeader
+	(*ExecuteStreamResponse)(nil), // 10: common.ExecuteStreamResponse
+	(*ConfigureRequest)(nil),      // 11: common.ConfigureRequest
+	(*ConfigureResponse)(nil),     // 12: common.ConfigureResponse
+	(*ConnectHostRequest)(nil),    // 13: common.ConnectHostRequest
+	(*ConnectHostResponse)(nil),   // 14: common.ConnectHostResponse
+	(*LogRequest)(nil),            // 15: common.LogRequest
+	(*LogResponse)(nil),           // 16: common.LogResponse
+	(*GetStateRequest)(nil),       // 17: common.GetStateRequest
+	(*GetStateResponse)(nil),      // 18: common.GetStateResponse
+	(*SetStateRequest)(nil),       // 19: common.SetStateRequest
+	(*SetStateResponse)(nil),      // 20: common.SetStateResponse
+	(*DeleteStateRequest)(nil),    // 21: common.DeleteStateRequest
+	(*DeleteStateResponse)(nil),   // 22: common.DeleteStateResponse
+	(*GetSecretRequest)(nil),      // 23: common.GetSecretRequest
+	(*GetSecretResponse)(nil),     // 24: common.GetSecretResponse
+	(*CallPluginRequest)(nil),     // 25: common.CallPluginRequest
+	nil,                           // 26: common.MetadataResponse.CapabilitiesEntry
+	nil,                           // 27: common.MetadataResponse.ConfigSchemaEntry
+	nil,                           // 28: common.MethodDescriptor.ParametersEntry
+	nil,                           // 29: common.ExecuteRequest.LegacyParamsEntry
+	nil,                           // 30: common.LogRequest.FieldsEntry
+	(*structpb.Value)(nil),        // 31: google.protobuf.Value
+	(*structpb.Struct)(nil),       // 32: google.protobuf.Struct
 }
 var file_common_plugin_proto_depIdxs = []int32{
-	4, // 0: common.MetadataResponse.capabilities:type_name -> common.MetadataResponse.CapabilitiesEntry
-	5, // 1: common.ExecuteRequest.params:type_name -> common.ExecuteRequest.ParamsEntry
-	0, // 2: common.Plugin.GetMetadata:input_type -> common.MetadataRequest
-	2, // 3: common.Plugin.Execute:input_type -> common.ExecuteRequest
-	1, // 4: common.Plugin.GetMetadata:output_type -> common.MetadataResponse
-	3, // 5: common.Plugin.Execute:output_type -> common.ExecuteResponse
-	4, // [4:6] is the sub-list for method output_type
-	2, // [2:4] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	26, // 0: common.MetadataResponse.capabilities:type_name -> common.MetadataResponse.CapabilitiesEntry
+	2,  // 1: common.MetadataResponse.method_descriptors:type_name -> common.MethodDescriptor
+	27, // 2: common.MetadataResponse.config_schema:type_name -> common.MetadataResponse.ConfigSchemaEntry
+	28, // 3: common.MethodDescriptor.parameters:type_name -> common.MethodDescriptor.ParametersEntry
+	4,  // 4: common.MethodDescriptor.returns:type_name -> common.ReturnDescriptor
+	5,  // 5: common.MethodDescriptor.examples:type_name -> common.MethodExample
+	31, // 6: common.ParamDescriptor.default:type_name -> google.protobuf.Value
+	32, // 7: common.MethodExample.params:type_name -> google.protobuf.Struct
+	31, // 8: common.MethodExample.result:type_name -> google.protobuf.Value
+	29, // 9: common.ExecuteRequest.legacy_params:type_name -> common.ExecuteRequest.LegacyParamsEntry
+	32, // 10: common.ExecuteRequest.params:type_name -> google.protobuf.Struct
+	31, // 11: common.ExecuteResponse.result:type_name -> google.protobuf.Value
+	9,  // 12: common.ExecuteStreamRequest.header:type_name -> common.ExecuteStreamHeader
+	32, // 13: common.ExecuteStreamHeader.params:type_name -> google.protobuf.Struct
+	32, // 14: common.ConfigureRequest.config:type_name -> google.protobuf.Struct
+	30, // 15: common.LogRequest.fields:type_name -> common.LogRequest.FieldsEntry
+	32, // 16: common.CallPluginRequest.params:type_name -> google.protobuf.Struct
+	3,  // 17: common.MetadataResponse.ConfigSchemaEntry.value:type_name -> common.ParamDescriptor
+	3,  // 18: common.MethodDescriptor.ParametersEntry.value:type_name -> common.ParamDescriptor
+	0,  // 19: common.Plugin.GetMetadata:input_type -> common.MetadataRequest
+	6,  // 20: common.Plugin.Execute:input_type -> common.ExecuteRequest
+	8,  // 21: common.Plugin.ExecuteStream:input_type -> common.ExecuteStreamRequest
+	11, // 22: common.Plugin.Configure:input_type -> common.ConfigureRequest
+	13, // 23: common.Plugin.ConnectHost:input_type -> common.ConnectHostRequest
+	15, // 24: common.HostService.Log:input_type -> common.LogRequest
+	17, // 25: common.HostService.GetState:input_type -> common.GetStateRequest
+	19, // 26: common.HostService.SetState:input_type -> common.SetStateRequest
+	21, // 27: common.HostService.DeleteState:input_type -> common.DeleteStateRequest
+	23, // 28: common.HostService.GetSecret:input_type -> common.GetSecretRequest
+	25, // 29: common.HostService.CallPlugin:input_type -> common.CallPluginRequest
+	1,  // 30: common.Plugin.GetMetadata:output_type -> common.MetadataResponse
+	7,  // 31: common.Plugin.Execute:output_type -> common.ExecuteResponse
+	10, // 32: common.Plugin.ExecuteStream:output_type -> common.ExecuteStreamResponse
+	12, // 33: common.Plugin.Configure:output_type -> common.ConfigureResponse
+	14, // 34: common.Plugin.ConnectHost:output_type -> common.ConnectHostResponse
+	16, // 35: common.HostService.Log:output_type -> common.LogResponse
+	18, // 36: common.HostService.GetState:output_type -> common.GetStateResponse
+	20, // 37: common.HostService.SetState:output_type -> common.SetStateResponse
+	22, // 38: common.HostService.DeleteState:output_type -> common.DeleteStateResponse
+	24, // 39: common.HostService.GetSecret:output_type -> common.GetSecretResponse
+	7,  // 40: common.HostService.CallPlugin:output_type -> common.ExecuteResponse
+	30, // [30:41] is the sub-list for method output_type
+	19, // [19:30] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_common_plugin_proto_init() }
@@ -327,16 +1685,22 @@ func file_common_plugin_proto_init() {
 	if File_common_plugin_proto != nil {
 		return
 	}
-	file_common_plugin_proto_msgTypes[3].OneofWrappers = []any{}
+	file_common_plugin_proto_msgTypes[7].OneofWrappers = []any{}
+	file_common_plugin_proto_msgTypes[8].OneofWrappers = []any{
+		(*ExecuteStreamRequest_Header)(nil),
+		(*ExecuteStreamRequest_Chunk)(nil),
+	}
+	file_common_plugin_proto_msgTypes[10].OneofWrappers = []any{}
+	file_common_plugin_proto_msgTypes[12].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_common_plugin_proto_rawDesc), len(file_common_plugin_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   31,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_common_plugin_proto_goTypes,
 		DependencyIndexes: file_common_plugin_proto_depIdxs,
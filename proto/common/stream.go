@@ -0,0 +1,222 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// streamChunkSize is the largest chunk sent in a single stream message
+const streamChunkSize = 32 * 1024
+
+// ErrStreamingUnsupported is returned when the plugin predates protocol v3
+// or does not implement StreamingPluginInterface.
+var ErrStreamingUnsupported = errors.New("plugin does not support streaming execution")
+
+// StreamWriter receives the output of a streaming execution
+type StreamWriter interface {
+	io.Writer
+
+	// SetContentType sets the media type of the output. It only has an
+	// effect before the first Write.
+	SetContentType(contentType string)
+}
+
+// StreamingPluginInterface is implemented by plugins whose methods can consume
+// input and produce output incrementally. Plugins implement it next to
+// PluginInterface; the host-side GRPCClient implements it as well.
+type StreamingPluginInterface interface {
+	// ExecuteStream executes a plugin method, reading its input from input
+	// and writing its output to output as it is produced
+	ExecuteStream(ctx context.Context, method string, params *structpb.Struct, input io.Reader, output StreamWriter) error
+}
+
+// ExecuteStream streams input to the plugin and copies its output to output.
+// The context is cancelled as soon as ExecuteStream returns, which stops the
+// plugin if output could not be written.
+func (m *GRPCClient) ExecuteStream(ctx context.Context, method string, params *structpb.Struct, input io.Reader, output StreamWriter) (err error) {
+	if m.protocolVersion < StreamingProtocolVersion {
+		return ErrStreamingUnsupported
+	}
+	ctx, span := startClientSpan(ctx, Plugin_ExecuteStream_FullMethodName, pluginMethodAttribute(method))
+	defer func() { endSpan(span, err) }()
+	if params == nil {
+		params = &structpb.Struct{}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := m.client.ExecuteStream(ctx)
+	if err != nil {
+		return err
+	}
+
+	header := &ExecuteStreamRequest{
+		Payload: &ExecuteStreamRequest_Header{
+			Header: &ExecuteStreamHeader{Method: method, Params: params},
+		},
+	}
+	if err := stream.Send(header); err != nil {
+		return streamError(err)
+	}
+
+	// Input is pumped concurrently so the plugin can start emitting output
+	// before the whole input has been read
+	inputErr := make(chan error, 1)
+	go func() {
+		buf := make([]byte, streamChunkSize)
+		for {
+			n, err := input.Read(buf)
+			if n > 0 {
+				chunk := &ExecuteStreamRequest{
+					Payload: &ExecuteStreamRequest_Chunk{Chunk: append([]byte(nil), buf[:n]...)},
+				}
+				if sendErr := stream.Send(chunk); sendErr != nil {
+					// The plugin finished without consuming all input
+					return
+				}
+			}
+			if err == io.EOF {
+				_ = stream.CloseSend()
+				return
+			}
+			if err != nil {
+				inputErr <- err
+				cancel()
+				return
+			}
+		}
+	}()
+
+	contentTypeSet := false
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			select {
+			case readErr := <-inputErr:
+				return readErr
+			default:
+				return streamError(err)
+			}
+		}
+
+		if !contentTypeSet && resp.ContentType != "" {
+			output.SetContentType(resp.ContentType)
+			contentTypeSet = true
+		}
+		if len(resp.Chunk) > 0 {
+			if _, err := output.Write(resp.Chunk); err != nil {
+				return err
+			}
+		}
+		if resp.Error != nil {
+			return errors.New(*resp.Error)
+		}
+	}
+}
+
+func streamError(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return ErrStreamingUnsupported
+	}
+	return err
+}
+
+// ExecuteStream serves a streaming execution. The first request message must
+// carry the header; the rest carry input chunks. An error returned by the
+// plugin is reported in the last response message.
+func (m *GRPCServer) ExecuteStream(stream Plugin_ExecuteStreamServer) error {
+	impl, ok := m.Impl.(StreamingPluginInterface)
+	if !ok {
+		return status.Error(codes.Unimplemented, ErrStreamingUnsupported.Error())
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	header := first.GetHeader()
+	if header == nil {
+		return status.Error(codes.InvalidArgument, "first stream message must be a header")
+	}
+
+	ctx, span := startServerSpan(stream.Context(), Plugin_ExecuteStream_FullMethodName, pluginMethodAttribute(header.Method))
+	input := &streamReader{stream: stream}
+	output := &streamSender{stream: stream}
+
+	execErr := impl.ExecuteStream(ctx, header.Method, header.Params, input, output)
+	endSpan(span, execErr)
+
+	last := &ExecuteStreamResponse{}
+	if !output.sent {
+		last.ContentType = output.contentType
+	}
+	if execErr != nil {
+		errMsg := execErr.Error()
+		last.Error = &errMsg
+	}
+	return stream.Send(last)
+}
+
+// streamReader exposes the input chunks of a stream as an io.Reader
+type streamReader struct {
+	stream  Plugin_ExecuteStreamServer
+	pending []byte
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		if msg.GetHeader() != nil {
+			return 0, errors.New("unexpected header in input stream")
+		}
+		r.pending = msg.GetChunk()
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// streamSender sends every write as it happens, split into chunks of at
+// most streamChunkSize. The content type travels with the first message.
+type streamSender struct {
+	stream      Plugin_ExecuteStreamServer
+	contentType string
+	sent        bool
+}
+
+func (w *streamSender) SetContentType(contentType string) {
+	if !w.sent {
+		w.contentType = contentType
+	}
+}
+
+func (w *streamSender) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), streamChunkSize)
+		resp := &ExecuteStreamResponse{Chunk: p[:n]}
+		if !w.sent {
+			resp.ContentType = w.contentType
+		}
+		if err := w.stream.Send(resp); err != nil {
+			return written, err
+		}
+		w.sent = true
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
@@ -0,0 +1,141 @@
+package common
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TracerName is the instrumentation name of the spans GRPCClient and
+// GRPCServer record
+const TracerName = "github.com/wylu1037/polyglot-plugin-showcase/proto/common"
+
+// tracePropagator carries W3C trace context and baggage in gRPC metadata. It
+// is fixed rather than otel's global propagator so hosts and plugins agree on
+// it without either having to install one.
+var tracePropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// metadataCarrier adapts gRPC metadata to propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// startClientSpan starts the span of an outgoing call of fullMethod and
+// injects its trace context into the call's metadata. Without a tracer
+// provider installed the span records nothing, but the caller's trace
+// context still reaches the other side.
+func startClientSpan(ctx context.Context, fullMethod string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(TracerName).Start(ctx, spanName(fullMethod),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(rpcAttributes(fullMethod)...),
+		trace.WithAttributes(attrs...),
+	)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	tracePropagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// startServerSpan extracts the caller's trace context from the metadata of an
+// incoming call of fullMethod and starts the call's span as its child, so
+// the implementation can create spans of its own under it from ctx
+func startServerSpan(ctx context.Context, fullMethod string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = tracePropagator.Extract(ctx, metadataCarrier(md))
+	}
+	return otel.Tracer(TracerName).Start(ctx, spanName(fullMethod),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(rpcAttributes(fullMethod)...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan ends span, marking it failed when err is set
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// endExecuteSpan ends the span of an Execute call, which failed with err or
+// with the error the plugin reported in resp
+func endExecuteSpan(span trace.Span, resp *ExecuteResponse, err error) {
+	if err == nil && resp != nil && !resp.Success {
+		span.SetStatus(otelcodes.Error, resp.GetError())
+	}
+	endSpan(span, err)
+}
+
+// tracedConn traces the unary calls made over a connection, such as the
+// calls of a plugin to its host's services
+type tracedConn struct {
+	grpc.ClientConnInterface
+}
+
+func (c tracedConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) (err error) {
+	ctx, span := startClientSpan(ctx, method)
+	defer func() { endSpan(span, err) }()
+
+	return c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+}
+
+// traceUnaryServer traces the unary calls a server serves, continuing the
+// caller's trace
+func traceUnaryServer(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (_ any, err error) {
+	ctx, span := startServerSpan(ctx, info.FullMethod)
+	defer func() { endSpan(span, err) }()
+
+	return handler(ctx, req)
+}
+
+// spanName is the span name of a gRPC method, "service/method"
+func spanName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	service, method, _ := strings.Cut(spanName(fullMethod), "/")
+	return []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}
+}
+
+// pluginMethodAttribute is the plugin method an Execute or ExecuteStream
+// call runs
+func pluginMethodAttribute(method string) attribute.KeyValue {
+	return attribute.String("plugin.method", method)
+}